@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	ai "go-fiber-template/domain/aimodel"
+	"go-fiber-template/src/evaluation"
+	"strings"
+)
+
+func runEval(args []string) error {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	fixtures := flags.String("fixtures", "eval/fixtures", "directory of fixture profiles (entities.BodyData JSON)")
+	replay := flags.String("replay", "eval/replay", "directory of recorded responses")
+	out := flags.String("out", "eval-report.json", "where to write the JSON report")
+	assertions := flags.String("assert", strings.Join(evaluation.DefaultAssertions, ","), "comma separated assertions to run")
+	lang := flags.String("lang", "", "language for fixtures that do not set one (en or th)")
+	record := flags.Bool("record", false, "generate with the configured plan models (GEMINI_API_KEY) and overwrite the recordings")
+	minScore := flags.Float64("min-score", 0, "fail when the overall score is below this value")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config := evaluation.Config{
+		FixturesDir: *fixtures,
+		ReplayDir:   *replay,
+		Language:    *lang,
+	}
+	for _, name := range strings.Split(*assertions, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			config.Assertions = append(config.Assertions, name)
+		}
+	}
+	if *record {
+		modelConfig, err := ai.NewModelConfigFromEnv()
+		if err != nil {
+			return err
+		}
+		models, err := ai.NewModelRouter(modelConfig)
+		if err != nil {
+			return err
+		}
+		config.Live = models.For(ai.FeaturePlan)
+	}
+
+	report, err := evaluation.Run(config)
+	if err != nil {
+		return err
+	}
+	if err := evaluation.WriteReport(report, *out); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range report.Results {
+		status := "PASS"
+		switch {
+		case result.Error != "":
+			status = "ERROR"
+			failed++
+		case !result.Passed:
+			status = "FAIL"
+		}
+		line := fmt.Sprintf("%-6s %-30s %.2f", status, result.Fixture, result.Score)
+		if result.StaleRecording {
+			line += "  (recording made with a different prompt)"
+		}
+		fmt.Println(line)
+		if result.Error != "" {
+			fmt.Println("       " + result.Error)
+		}
+		for _, assertion := range result.Assertions {
+			for _, detail := range assertion.Details {
+				if !assertion.Passed {
+					fmt.Printf("       %s: %s\n", assertion.Name, detail)
+				}
+			}
+		}
+	}
+	fmt.Printf("\n%d/%d fixtures passed, score %.2f, report written to %s\n", report.Passed, report.Fixtures, report.Score, *out)
+	if failed > 0 {
+		return fmt.Errorf("%d fixtures could not be evaluated", failed)
+	}
+	if report.Score < *minScore {
+		return fmt.Errorf("score %.2f is below -min-score %.2f", report.Score, *minScore)
+	}
+	return nil
+}
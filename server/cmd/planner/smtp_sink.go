@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runSMTPSink accepts every message sent to it and writes each one to an
+// .eml file, so email reminders can be tested end to end without a real mail
+// server. It speaks just enough SMTP for net/smtp: no TLS and no AUTH, so
+// leave SMTP_USERNAME empty when pointing the server at it.
+func runSMTPSink(args []string) error {
+	flags := flag.NewFlagSet("smtp-sink", flag.ContinueOnError)
+	addr := flags.String("addr", "127.0.0.1:2525", "address to listen on")
+	dir := flags.String("dir", "smtp-sink", "directory to write received messages to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("smtp-sink listening on %s, writing to %s\n", listener.Addr(), *dir)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go serveSMTP(conn, *dir)
+	}
+}
+
+func serveSMTP(conn net.Conn, dir string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		fmt.Fprintf(conn, "%s\r\n", line)
+	}
+	var from string
+	var to []string
+	reply("220 planner smtp-sink ready")
+	for {
+		conn.SetDeadline(time.Now().Add(time.Minute))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			reply("250-planner smtp-sink")
+			reply("250 8BITMIME")
+		case "HELO":
+			reply("250 planner smtp-sink")
+		case "MAIL":
+			from = smtpAddress(arg)
+			to = nil
+			reply("250 OK")
+		case "RCPT":
+			to = append(to, smtpAddress(arg))
+			reply("250 OK")
+		case "DATA":
+			if len(to) == 0 {
+				reply("503 need RCPT first")
+				continue
+			}
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			data, err := readSMTPData(reader)
+			if err != nil {
+				return
+			}
+			path, err := saveMessage(dir, data)
+			if err != nil {
+				reply("451 " + err.Error())
+				continue
+			}
+			subject := ""
+			if message, err := mail.ReadMessage(bytes.NewReader(data)); err == nil {
+				subject, _ = new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+			}
+			fmt.Printf("%s  %s -> %s  %q  %s\n", time.Now().Format("15:04:05"), from, strings.Join(to, ", "), subject, path)
+			reply("250 OK")
+		case "RSET":
+			from, to = "", nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// smtpAddress pulls the address out of "FROM:<a@b>" or "TO:<a@b>".
+func smtpAddress(arg string) string {
+	_, address, _ := strings.Cut(arg, ":")
+	address, _, _ = strings.Cut(strings.TrimSpace(address), " ")
+	return strings.Trim(address, "<>")
+}
+
+// readSMTPData reads up to the lone "." line, undoing dot-stuffing.
+func readSMTPData(reader *bufio.Reader) ([]byte, error) {
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimRight(line, "\r\n") == "." {
+			return data.Bytes(), nil
+		}
+		data.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+func saveMessage(dir string, data []byte) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("%s.eml", time.Now().Format("20060102-150405.000000000")))
+	return path, os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,33 @@
+package configuration
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetEnvInt reads an integer from the environment and falls back to def
+// when the variable is unset or not a valid number.
+func GetEnvInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+// GetEnvFloat is GetEnvInt for decimal numbers.
+func GetEnvFloat(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
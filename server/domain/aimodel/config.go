@@ -0,0 +1,107 @@
+package aimodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Features that can be configured with their own models. LLM usage is
+// recorded under the same names.
+const (
+	FeaturePlan    = "plan"
+	FeatureChat    = "chat"
+	FeatureSummary = "summary"
+	FeatureReview  = "review"
+	FeatureNotes   = "notes"
+)
+
+// Providers a model can come from.
+const (
+	ProviderGemini = "gemini"
+	ProviderFake   = "fake"
+)
+
+// ModelSettings configures one model in a feature's chain. Unset generation
+// parameters are left to the provider's defaults. SafetyThreshold is a Gemini
+// HarmBlockThreshold such as BLOCK_MEDIUM_AND_ABOVE, applied to every harm
+// category.
+type ModelSettings struct {
+	Provider        string   `json:"provider"`
+	Model           string   `json:"model"`
+	Temperature     *float32 `json:"temperature,omitempty"`
+	TopP            *float32 `json:"top_p,omitempty"`
+	MaxOutputTokens int32    `json:"max_output_tokens,omitempty"`
+	SafetyThreshold string   `json:"safety_threshold,omitempty"`
+}
+
+// ModelConfig is the ordered fallback chain for each feature. The first
+// model is the primary; the next one is tried when it fails.
+type ModelConfig map[string][]ModelSettings
+
+func float32Ptr(v float32) *float32 {
+	return &v
+}
+
+func DefaultModelConfig() ModelConfig {
+	return ModelConfig{
+		FeaturePlan: {
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash", Temperature: float32Ptr(0.7), MaxOutputTokens: 8192},
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash-lite", Temperature: float32Ptr(0.7), MaxOutputTokens: 8192},
+		},
+		FeatureChat: {
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash", Temperature: float32Ptr(0.8), MaxOutputTokens: 2048},
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash-lite", Temperature: float32Ptr(0.8), MaxOutputTokens: 2048},
+		},
+		FeatureSummary: {
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash-lite", Temperature: float32Ptr(0.2), MaxOutputTokens: 1024},
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash", Temperature: float32Ptr(0.2), MaxOutputTokens: 1024},
+		},
+		FeatureReview: {
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash", Temperature: float32Ptr(0.4), MaxOutputTokens: 2048},
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash-lite", Temperature: float32Ptr(0.4), MaxOutputTokens: 2048},
+		},
+		FeatureNotes: {
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash-lite", Temperature: float32Ptr(0.1), MaxOutputTokens: 512},
+			{Provider: ProviderGemini, Model: "gemini-2.0-flash", Temperature: float32Ptr(0.1), MaxOutputTokens: 512},
+		},
+	}
+}
+
+// NewModelConfigFromEnv starts from DefaultModelConfig and replaces the chain
+// of every feature present in LLM_MODELS, e.g.
+// {"chat":[{"provider":"gemini","model":"gemini-2.5-flash","temperature":0.9},{"provider":"gemini","model":"gemini-2.0-flash"}]}.
+// LLM_PROVIDER=fake swaps every model for the fake provider.
+func NewModelConfigFromEnv() (ModelConfig, error) {
+	config := DefaultModelConfig()
+	if raw := os.Getenv("LLM_MODELS"); raw != "" {
+		var overrides ModelConfig
+		if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+			return nil, fmt.Errorf("invalid LLM_MODELS: %w", err)
+		}
+		for feature, chain := range overrides {
+			config[feature] = chain
+		}
+	}
+	if os.Getenv("LLM_PROVIDER") == ProviderFake {
+		for feature := range config {
+			config[feature] = []ModelSettings{{Provider: ProviderFake, Model: "fake"}}
+		}
+	}
+	for feature, chain := range config {
+		if len(chain) == 0 {
+			return nil, fmt.Errorf("no models configured for %s", feature)
+		}
+		for _, settings := range chain {
+			switch settings.Provider {
+			case ProviderGemini, ProviderFake:
+			default:
+				return nil, fmt.Errorf("%s: unknown provider %q", feature, settings.Provider)
+			}
+			if settings.Provider == ProviderGemini && settings.Model == "" {
+				return nil, fmt.Errorf("%s: gemini model name is required", feature)
+			}
+		}
+	}
+	return config, nil
+}
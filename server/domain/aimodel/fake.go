@@ -0,0 +1,83 @@
+package aimodel
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"sync"
+)
+
+// FakeLLM is a scripted ILLM for local runs and tests. Each call returns the
+// next queued response; when the queue is empty it returns Default, or an
+// error if Default is empty. A call first takes the next of Errors, and fails
+// with it unless it is nil. AIChat runs ToolCalls through the executor before
+// answering. Every prompt it receives is kept in Prompts.
+type FakeLLM struct {
+	mu        sync.Mutex
+	Responses []string
+	Default   string
+	Errors    []error
+	ToolCalls []ToolCall
+	Prompts   []string
+}
+
+const fakeModel = "fake"
+
+func NewFakeLLM(responses ...string) *FakeLLM {
+	return &FakeLLM{Responses: responses}
+}
+
+func (f *FakeLLM) GenerateText(prompt string) (string, entities.LLMUsage, error) {
+	return f.next(prompt)
+}
+
+// AIChat ignores the tool declarations; the system instruction is recorded
+// in front of the prompt so callers can assert on it.
+func (f *FakeLLM) AIChat(systemInstruction string, history []entities.AIChat, prompt string, tools []Tool, execute ToolExecutor) (string, entities.LLMUsage, error) {
+	if systemInstruction != "" {
+		prompt = systemInstruction + "\n\n" + prompt
+	}
+	if execute != nil {
+		for _, call := range f.ToolCalls {
+			if _, err := execute(call); err != nil {
+				return "", entities.LLMUsage{}, err
+			}
+		}
+	}
+	return f.next(prompt)
+}
+
+// Push queues more responses.
+func (f *FakeLLM) Push(responses ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Responses = append(f.Responses, responses...)
+}
+
+func (f *FakeLLM) next(prompt string) (string, entities.LLMUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Prompts = append(f.Prompts, prompt)
+	if len(f.Errors) > 0 {
+		err := f.Errors[0]
+		f.Errors = f.Errors[1:]
+		if err != nil {
+			return "", entities.LLMUsage{}, err
+		}
+	}
+	var response string
+	if len(f.Responses) > 0 {
+		response = f.Responses[0]
+		f.Responses = f.Responses[1:]
+	} else if f.Default != "" {
+		response = f.Default
+	} else {
+		return "", entities.LLMUsage{}, fmt.Errorf("fake llm: no response queued")
+	}
+	usage := entities.LLMUsage{
+		Model:            fakeModel,
+		PromptTokens:     len(prompt) / 4,
+		CompletionTokens: len(response) / 4,
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return response, usage, nil
+}
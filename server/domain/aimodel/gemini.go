@@ -2,43 +2,79 @@ package aimodel
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go-fiber-template/domain/entities"
-	"log"
 	"os"
+	"time"
 
 	"google.golang.org/genai"
 )
 
-type GeminiRest struct {
+// GeminiClient is the shared connection to the Gemini API.
+type GeminiClient struct {
 	Context context.Context
-	Client *genai.Client
+	Client  *genai.Client
 }
 
-func NewGeminiRest() *GeminiRest {
+func NewGeminiClient() (*GeminiClient, error) {
 	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
 		APIKey: os.Getenv("GEMINI_API_KEY"),
 		Backend: genai.BackendGeminiAPI,
 	})
 	if err != nil {
-		log.Fatal("Failed to create Gemini client: " + err.Error())
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
-	return &GeminiRest{
+	return &GeminiClient{
 		Context: context.Background(),
 		Client:  client,
+	}, nil
+}
+
+// GeminiRest is one Gemini model with its generation settings.
+type GeminiRest struct {
+	*GeminiClient
+	Settings ModelSettings
+}
+
+func NewGeminiRest(client *GeminiClient, settings ModelSettings) *GeminiRest {
+	return &GeminiRest{
+		GeminiClient: client,
+		Settings:     settings,
 	}
 }
 
+func (g *GeminiRest) generationConfig() *genai.GenerateContentConfig {
+	config := &genai.GenerateContentConfig{
+		Temperature:     g.Settings.Temperature,
+		TopP:            g.Settings.TopP,
+		MaxOutputTokens: g.Settings.MaxOutputTokens,
+	}
+	if g.Settings.SafetyThreshold != "" {
+		threshold := genai.HarmBlockThreshold(g.Settings.SafetyThreshold)
+		for _, category := range []genai.HarmCategory{genai.HarmCategoryHarassment, genai.HarmCategoryHateSpeech, genai.HarmCategorySexuallyExplicit, genai.HarmCategoryDangerousContent} {
+			config.SafetySettings = append(config.SafetySettings, &genai.SafetySetting{Category: category, Threshold: threshold})
+		}
+	}
+	return config
+}
 
-func (g *GeminiRest) GenerateText(prompt string) (string, error) {
-	req, err := g.Client.Models.GenerateContent(g.Context, "gemini-2.0-flash", genai.Text(prompt), nil)
+func (g *GeminiRest) GenerateText(prompt string) (string, entities.LLMUsage, error) {
+	model := g.Settings.Model
+	start := time.Now()
+	req, err := g.Client.Models.GenerateContent(g.Context, model, genai.Text(prompt), g.generationConfig())
 	if err != nil {
-		return "", err
+		return "", entities.LLMUsage{}, err
 	}
 	response := req.Text()
-	return response , nil
+	if response == "" {
+		return "", usageFromResponse(model, req, time.Since(start)), fmt.Errorf("gemini returned an empty response")
+	}
+	return response , usageFromResponse(model, req, time.Since(start)), nil
 }
 
-func (g *GeminiRest) AIChat(historychat []entities.AIChat, prompt string) (string, error) {
+func (g *GeminiRest) AIChat(systemInstruction string, historychat []entities.AIChat, prompt string, tools []Tool, execute ToolExecutor) (string, entities.LLMUsage, error) {
+	model := g.Settings.Model
 	history := []*genai.Content{}
 	for _, chat := range historychat {
 		if chat.Sender == "user" {
@@ -47,14 +83,131 @@ func (g *GeminiRest) AIChat(historychat []entities.AIChat, prompt string) (strin
 			history = append(history, genai.NewContentFromText(chat.Message, genai.RoleModel))
 		}
 	}
-	req, err := g.Client.Chats.Create(g.Context, "gemini-2.0-flash", nil, history)
+	config := g.generationConfig()
+	if systemInstruction != "" {
+		config.SystemInstruction = genai.NewContentFromText(systemInstruction, genai.RoleUser)
+	}
+	if len(tools) > 0 && execute != nil {
+		config.Tools = []*genai.Tool{{FunctionDeclarations: functionDeclarations(tools)}}
+	}
+	start := time.Now()
+	req, err := g.Client.Chats.Create(g.Context, model, config, history)
 	if err != nil {
-		return "", err
+		return "", entities.LLMUsage{}, err
+	}
+	usage := entities.LLMUsage{Model: model}
+	parts := []*genai.Part{genai.NewPartFromText(prompt)}
+	for round := 0; ; round++ {
+		res, err := req.Send(g.Context, parts...)
+		if err != nil {
+			return "", usage, err
+		}
+		usage = addUsage(usage, usageFromResponse(model, res, 0))
+		usage.LatencyMs = time.Since(start).Milliseconds()
+		calls := res.FunctionCalls()
+		if len(calls) == 0 {
+			text := res.Text()
+			if text == "" {
+				return "", usage, fmt.Errorf("gemini returned an empty chat response")
+			}
+			return text, usage, nil
+		}
+		if round >= MaxToolRounds {
+			return "", usage, fmt.Errorf("gemini kept calling tools after %d rounds", MaxToolRounds)
+		}
+		parts = parts[:0]
+		for _, call := range calls {
+			result, err := execute(ToolCall{Name: call.Name, Args: call.Args})
+			if err != nil {
+				result = map[string]any{"error": err.Error()}
+			}
+			part := genai.NewPartFromFunctionResponse(call.Name, result)
+			part.FunctionResponse.ID = call.ID
+			parts = append(parts, part)
+		}
 	}
-	res, err := req.SendMessage(g.Context,genai.Part{Text: prompt})
-	if len(res.Candidates) < 0 {
-		return "", err
-  }
-	return res.Candidates[0].Content.Parts[0].Text , nil
 }
 
+func functionDeclarations(tools []Tool) []*genai.FunctionDeclaration {
+	declarations := make([]*genai.FunctionDeclaration, 0, len(tools))
+	for _, tool := range tools {
+		schema := &genai.Schema{
+			Type:       genai.TypeObject,
+			Properties: map[string]*genai.Schema{},
+		}
+		for _, param := range tool.Parameters {
+			property := &genai.Schema{
+				Type:        schemaType(param.Type),
+				Description: param.Description,
+				Enum:        param.Enum,
+			}
+			if property.Type == genai.TypeArray {
+				property.Items = &genai.Schema{Type: genai.TypeString}
+			}
+			schema.Properties[param.Name] = property
+			if param.Required {
+				schema.Required = append(schema.Required, param.Name)
+			}
+		}
+		declaration := &genai.FunctionDeclaration{
+			Name:        tool.Name,
+			Description: tool.Description,
+		}
+		if len(tool.Parameters) > 0 {
+			declaration.Parameters = schema
+		}
+		declarations = append(declarations, declaration)
+	}
+	return declarations
+}
+
+func schemaType(name string) genai.Type {
+	switch name {
+	case "integer":
+		return genai.TypeInteger
+	case "number":
+		return genai.TypeNumber
+	case "boolean":
+		return genai.TypeBoolean
+	case "array":
+		return genai.TypeArray
+	default:
+		return genai.TypeString
+	}
+}
+
+func addUsage(total entities.LLMUsage, next entities.LLMUsage) entities.LLMUsage {
+	total.PromptTokens += next.PromptTokens
+	total.CompletionTokens += next.CompletionTokens
+	total.TotalTokens += next.TotalTokens
+	return total
+}
+
+func usageFromResponse(model string, res *genai.GenerateContentResponse, latency time.Duration) entities.LLMUsage {
+	usage := entities.LLMUsage{
+		Model:     model,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if res == nil || res.UsageMetadata == nil {
+		return usage
+	}
+	usage.PromptTokens = int(res.UsageMetadata.PromptTokenCount)
+	usage.CompletionTokens = int(res.UsageMetadata.CandidatesTokenCount + res.UsageMetadata.ThoughtsTokenCount)
+	usage.TotalTokens = int(res.UsageMetadata.TotalTokenCount)
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	return usage
+}
+
+func isGeminiRateLimit(err error) bool {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == 429 || apiErr.Status == "RESOURCE_EXHAUSTED"
+	}
+	var apiErrPtr *genai.APIError
+	if errors.As(err, &apiErrPtr) {
+		return apiErrPtr.Code == 429 || apiErrPtr.Status == "RESOURCE_EXHAUSTED"
+	}
+	return false
+}
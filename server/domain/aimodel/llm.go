@@ -0,0 +1,43 @@
+package aimodel
+
+import (
+	"go-fiber-template/domain/entities"
+)
+
+// ILLM is what the repositories need from a language model. GeminiRest is the
+// production implementation.
+type ILLM interface {
+	GenerateText(prompt string) (string, entities.LLMUsage, error)
+	// AIChat answers prompt given the earlier turns. When tools are passed the
+	// model may call them, and execute is invoked for each call until the model
+	// produces a plain text answer.
+	AIChat(systemInstruction string, history []entities.AIChat, prompt string, tools []Tool, execute ToolExecutor) (string, entities.LLMUsage, error)
+}
+
+// ToolParameter describes one argument of a tool. Type is one of string,
+// integer, number, boolean or array (an array of strings).
+type ToolParameter struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	Enum        []string
+}
+
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  []ToolParameter
+}
+
+type ToolCall struct {
+	Name string
+	Args map[string]any
+}
+
+// ToolExecutor runs a tool call and returns a JSON-serialisable result.
+type ToolExecutor func(call ToolCall) (map[string]any, error)
+
+// MaxToolRounds bounds how many times the model may call tools while
+// answering a single message.
+const MaxToolRounds = 5
@@ -0,0 +1,168 @@
+package aimodel
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"os"
+	"sync"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// IModelRouter hands out the model chain configured for a feature.
+type IModelRouter interface {
+	For(feature string) ILLM
+}
+
+type ModelRouter struct {
+	chains map[string]ILLM
+}
+
+// NewModelRouter builds one fallback chain per feature. Gemini models share
+// a single client.
+func NewModelRouter(config ModelConfig) (*ModelRouter, error) {
+	var client *GeminiClient
+	router := &ModelRouter{chains: map[string]ILLM{}}
+	for feature, chain := range config {
+		models := []ILLM{}
+		for _, settings := range chain {
+			switch settings.Provider {
+			case ProviderGemini:
+				if client == nil {
+					created, err := NewGeminiClient()
+					if err != nil {
+						return nil, err
+					}
+					client = created
+				}
+				models = append(models, NewGeminiRest(client, settings))
+			case ProviderFake:
+				fake := NewFakeLLM()
+				fake.Default = os.Getenv("FAKE_LLM_RESPONSE")
+				if fake.Default == "" {
+					fake.Default = "This is a placeholder response from the fake language model."
+				}
+				models = append(models, fake)
+			default:
+				return nil, fmt.Errorf("%s: unknown provider %q", feature, settings.Provider)
+			}
+		}
+		router.chains[feature] = NewFallbackLLM(feature, models...)
+	}
+	return router, nil
+}
+
+// For returns the chain for feature, or the plan chain for features that
+// are not configured.
+func (r *ModelRouter) For(feature string) ILLM {
+	if llm, ok := r.chains[feature]; ok {
+		return llm
+	}
+	return r.chains[FeaturePlan]
+}
+
+// SingleModel routes every feature to the same model.
+type SingleModel struct {
+	LLM ILLM
+}
+
+func (s SingleModel) For(feature string) ILLM {
+	return s.LLM
+}
+
+// RateLimitCooldown is how long a rate-limited model is skipped before the
+// chain tries it again.
+const RateLimitCooldown = 30 * time.Second
+
+// FallbackLLM tries its models in order and returns the first answer. A
+// model that reports a rate limit is skipped for RateLimitCooldown. Chat calls
+// only fall back while no tool has run, so tool side effects never repeat.
+type FallbackLLM struct {
+	Feature string
+	Models  []ILLM
+
+	mu            sync.Mutex
+	cooldownUntil map[int]time.Time
+}
+
+func NewFallbackLLM(feature string, models ...ILLM) *FallbackLLM {
+	return &FallbackLLM{
+		Feature:       feature,
+		Models:        models,
+		cooldownUntil: map[int]time.Time{},
+	}
+}
+
+func (f *FallbackLLM) GenerateText(prompt string) (string, entities.LLMUsage, error) {
+	var lastErr error
+	for i, model := range f.available() {
+		text, usage, err := f.Models[model].GenerateText(prompt)
+		if err == nil {
+			return text, usage, nil
+		}
+		lastErr = f.failed(model, i, err)
+	}
+	return "", entities.LLMUsage{}, lastErr
+}
+
+func (f *FallbackLLM) AIChat(systemInstruction string, history []entities.AIChat, prompt string, tools []Tool, execute ToolExecutor) (string, entities.LLMUsage, error) {
+	var lastErr error
+	var lastUsage entities.LLMUsage
+	toolsRan := false
+	tracked := execute
+	if execute != nil {
+		tracked = func(call ToolCall) (map[string]any, error) {
+			toolsRan = true
+			return execute(call)
+		}
+	}
+	for i, model := range f.available() {
+		text, usage, err := f.Models[model].AIChat(systemInstruction, history, prompt, tools, tracked)
+		if err == nil {
+			return text, usage, nil
+		}
+		lastErr, lastUsage = f.failed(model, i, err), usage
+		if toolsRan {
+			break
+		}
+	}
+	return "", lastUsage, lastErr
+}
+
+// available lists the models to try now. When every model is cooling down
+// the whole chain is tried anyway rather than failing without a request.
+func (f *FallbackLLM) available() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	models := []int{}
+	for i := range f.Models {
+		if f.cooldownUntil[i].Before(now) {
+			models = append(models, i)
+		}
+	}
+	if len(models) == 0 {
+		for i := range f.Models {
+			models = append(models, i)
+		}
+	}
+	return models
+}
+
+func (f *FallbackLLM) failed(model int, attempt int, err error) error {
+	if IsRateLimited(err) {
+		f.mu.Lock()
+		f.cooldownUntil[model] = time.Now().Add(RateLimitCooldown)
+		f.mu.Unlock()
+	}
+	if model < len(f.Models)-1 {
+		fiberlog.Warnf("FallbackLLM -> %s: model %d failed, trying the next one: %s \n", f.Feature, model, err)
+	}
+	return fmt.Errorf("%s model %d (attempt %d): %w", f.Feature, model, attempt+1, err)
+}
+
+// IsRateLimited reports whether err is a provider rate-limit or quota error.
+func IsRateLimited(err error) bool {
+	return isGeminiRateLimit(err)
+}
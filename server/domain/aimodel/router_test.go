@@ -0,0 +1,121 @@
+package aimodel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+var errRateLimited = genai.APIError{Code: 429, Status: "RESOURCE_EXHAUSTED"}
+
+func fakeAnswering(answer string, errs ...error) *FakeLLM {
+	fake := NewFakeLLM()
+	fake.Default = answer
+	fake.Errors = errs
+	return fake
+}
+
+func TestFallbackLLMTriesModelsInOrder(t *testing.T) {
+	first := fakeAnswering("first", errors.New("server error"))
+	second := fakeAnswering("second", errRateLimited)
+	third := fakeAnswering("third")
+	chain := NewFallbackLLM(FeaturePlan, first, second, third)
+
+	text, _, err := chain.GenerateText("plan")
+	if err != nil || text != "third" {
+		t.Fatalf("GenerateText() = %q, %v, want third", text, err)
+	}
+	for i, model := range []*FakeLLM{first, second, third} {
+		if len(model.Prompts) != 1 {
+			t.Errorf("model %d called %d times, want 1", i, len(model.Prompts))
+		}
+	}
+
+	// The failed first model is tried first again; the rate-limited second
+	// one is skipped.
+	text, _, err = chain.GenerateText("plan")
+	if err != nil || text != "first" {
+		t.Fatalf("GenerateText() = %q, %v, want first", text, err)
+	}
+	if len(first.Prompts) != 2 || len(second.Prompts) != 1 || len(third.Prompts) != 1 {
+		t.Errorf("calls = %d, %d, %d, want 2, 1, 1", len(first.Prompts), len(second.Prompts), len(third.Prompts))
+	}
+}
+
+func TestFallbackLLMRateLimitCooldown(t *testing.T) {
+	primary := fakeAnswering("primary", errRateLimited)
+	backup := fakeAnswering("backup")
+	chain := NewFallbackLLM(FeatureChat, primary, backup)
+
+	for i := 0; i < 2; i++ {
+		text, _, err := chain.GenerateText("hello")
+		if err != nil || text != "backup" {
+			t.Fatalf("call %d: GenerateText() = %q, %v, want backup", i, text, err)
+		}
+	}
+	if len(primary.Prompts) != 1 {
+		t.Errorf("primary called %d times during its cooldown, want 1", len(primary.Prompts))
+	}
+	if until := chain.cooldownUntil[0]; until.Before(time.Now().Add(RateLimitCooldown - time.Second)) {
+		t.Errorf("cooldown until %v, want about %v from now", until, RateLimitCooldown)
+	}
+
+	chain.cooldownUntil[0] = time.Now().Add(-time.Second)
+	text, _, err := chain.GenerateText("hello")
+	if err != nil || text != "primary" {
+		t.Errorf("after the cooldown GenerateText() = %q, %v, want primary", text, err)
+	}
+}
+
+func TestFallbackLLMTriesEveryModelWhenAllCoolDown(t *testing.T) {
+	primary := fakeAnswering("primary", errRateLimited)
+	backup := fakeAnswering("backup", errRateLimited)
+	chain := NewFallbackLLM(FeaturePlan, primary, backup)
+
+	_, _, err := chain.GenerateText("plan")
+	if err == nil || !IsRateLimited(err) {
+		t.Fatalf("GenerateText() error = %v, want the rate limit", err)
+	}
+	text, _, err := chain.GenerateText("plan")
+	if err != nil || text != "primary" {
+		t.Errorf("GenerateText() = %q, %v, want primary", text, err)
+	}
+}
+
+func TestFallbackLLMChatStopsFallingBackOnceAToolRan(t *testing.T) {
+	tests := []struct {
+		name      string
+		toolCalls []ToolCall
+		want      string
+		wantErr   bool
+	}{
+		{"no tool", nil, "backup", false},
+		{"tool ran", []ToolCall{{Name: "log_habit", Args: map[string]any{"habit_id": 1}}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := fakeAnswering("primary", errRateLimited)
+			primary.ToolCalls = tt.toolCalls
+			backup := fakeAnswering("backup")
+			chain := NewFallbackLLM(FeatureChat, primary, backup)
+			executed := 0
+			execute := func(call ToolCall) (map[string]any, error) {
+				executed++
+				return map[string]any{"ok": true}, nil
+			}
+
+			text, _, err := chain.AIChat("", nil, "I walked today", nil, execute)
+			if text != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("AIChat() = %q, %v, want %q", text, err, tt.want)
+			}
+			if executed != len(tt.toolCalls) {
+				t.Errorf("tools ran %d times, want %d", executed, len(tt.toolCalls))
+			}
+			if tt.wantErr && len(backup.Prompts) != 0 {
+				t.Errorf("backup called after a tool ran")
+			}
+		})
+	}
+}
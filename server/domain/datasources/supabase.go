@@ -57,7 +57,7 @@ func (s *SupabaseREST) Query(table string, method string, queryParams string, bo
 	if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
 		req.Header.Set("Content-Type", "application/json")
 		if method == http.MethodPost {
-			req.Header.Set("Prefer", "resolution=merge-duplicates")
+			req.Header.Set("Prefer", "resolution=merge-duplicates,return=representation")
 		}else {
 			req.Header.Set("Prefer", "return=representation")
 		}
@@ -2,6 +2,7 @@ package entities
 
 import (
 	// "time"
+	"go-fiber-template/domain/money"
 )
 
 type BodyData struct{
@@ -21,9 +22,9 @@ type BodyData struct{
 	Busy_Days      []string  `json:"busy_days"`
 	Preferred_Times []string  `json:"preferred_times"`
 	Currency string  `json:"currency"`
-	Income      float64 `json:"income"`
-	Expenses    float64 `json:"expenses"`
-	Savings_Goal float64 `json:"savings_goal"`
+	Income      money.Amount `json:"income"`
+	Expenses    money.Amount `json:"expenses"`
+	Savings_Goal money.Amount `json:"savings_goal"`
 	Risk_Tolerance       string  `json:"risk_tolerance"`
 	ShortTerm  []string    `json:"short_term"`
 	LongTerm   []string    `json:"long_term"`
@@ -18,3 +18,22 @@ type AIChatResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+
+type AIChatSummary struct {
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	Summary         string    `json:"summary"`
+	SummarizedUntil time.Time `json:"summarized_until"`
+	MessageCount    int       `json:"message_count"`
+	TokenEstimate   int       `json:"token_estimate"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type AIChatSummaryResponse struct {
+	UserID          string    `json:"user_id"`
+	Summary         string    `json:"summary"`
+	SummarizedUntil time.Time `json:"summarized_until"`
+	MessageCount    int       `json:"message_count"`
+	TokenEstimate   int       `json:"token_estimate"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AssistantActionModel struct {
+	ID         string          `json:"id"`
+	UserID     string          `json:"user_id"`
+	ActionType string          `json:"action_type"`
+	Payload    json.RawMessage `json:"payload"`
+	Summary    string          `json:"summary"`
+	Status     string          `json:"status"`
+	Error      string          `json:"error"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ResolvedAt *time.Time      `json:"resolved_at"`
+}
+
+type AssistantActionResponse struct {
+	UserID     string          `json:"user_id"`
+	ActionType string          `json:"action_type"`
+	Payload    json.RawMessage `json:"payload"`
+	Summary    string          `json:"summary"`
+	Status     string          `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AssistantActionStatusUpdate struct {
+	Status     string     `json:"status"`
+	Error      string     `json:"error"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
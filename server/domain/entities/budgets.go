@@ -0,0 +1,116 @@
+package entities
+
+import (
+	"go-fiber-template/domain/money"
+	"time"
+)
+
+// BudgetModel is a monthly spending limit for one expense category, from
+// StartMonth (YYYY-MM) on. Rollover is none, unspent (what is left over is
+// added to the next month) or full (overspending is taken off it too).
+// Alerts go out on Channels; AlertMonth and AlertLevel are the month and the
+// highest threshold, in percent, already alerted on.
+type BudgetModel struct {
+	ID               string            `json:"id"`
+	UserID           string            `json:"user_id"`
+	Category         string            `json:"category"`
+	Amount           money.Amount      `json:"amount"`
+	Currency         string            `json:"currency"`
+	Rollover         string            `json:"rollover"`
+	StartMonth       string            `json:"start_month"`
+	Channels         []string          `json:"channels"`
+	Email            string            `json:"email"`
+	WebhookURL       string            `json:"webhook_url"`
+	PushSubscription *PushSubscription `json:"push_subscription"`
+	AlertMonth       string            `json:"alert_month"`
+	AlertLevel       int               `json:"alert_level"`
+	LastAlertAt      *time.Time        `json:"last_alert_at"`
+	LastAlertError   string            `json:"last_alert_error"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// BudgetResponse is the body of a create or update and the row inserted.
+// Channels set to an empty list turn alerts off.
+type BudgetResponse struct {
+	UserID           string            `json:"user_id"`
+	Category         string            `json:"category"`
+	Amount           money.Amount      `json:"amount"`
+	Currency         string            `json:"currency"`
+	Rollover         string            `json:"rollover"`
+	StartMonth       string            `json:"start_month"`
+	Channels         []string          `json:"channels"`
+	Email            string            `json:"email"`
+	WebhookURL       string            `json:"webhook_url"`
+	PushSubscription *PushSubscription `json:"push_subscription"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// BudgetUpdate is sent as a PATCH with every editable field; the service
+// fills unchanged fields from the stored budget.
+type BudgetUpdate struct {
+	Category         string            `json:"category"`
+	Amount           money.Amount      `json:"amount"`
+	Currency         string            `json:"currency"`
+	Rollover         string            `json:"rollover"`
+	StartMonth       string            `json:"start_month"`
+	Channels         []string          `json:"channels"`
+	Email            string            `json:"email"`
+	WebhookURL       string            `json:"webhook_url"`
+	PushSubscription *PushSubscription `json:"push_subscription"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// BudgetAlertUpdate raises the threshold alerted on, which claims the alert.
+type BudgetAlertUpdate struct {
+	AlertMonth string    `json:"alert_month"`
+	AlertLevel int       `json:"alert_level"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// BudgetAlertResult records an alert delivery. LastAlertError lists the
+// channels that failed and is empty when all of them succeeded.
+type BudgetAlertResult struct {
+	LastAlertAt    *time.Time `json:"last_alert_at"`
+	LastAlertError string     `json:"last_alert_error"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// BudgetStatus compares a budget with the month's spending in its currency,
+// converting expenses in other currencies at the exchange rate of their date;
+// the currencies without a rate are listed in OtherCurrencies. Available is
+// Amount plus CarriedOver from earlier months. Spent counts the expenses
+// dated up to today; Projected adds the ones still to come this month and
+// extends one-off spending so far to the whole month. Used is Spent over
+// Available, and Status is under, warning or over by the alert thresholds.
+type BudgetStatus struct {
+	BudgetID           string       `json:"budget_id"`
+	Category           string       `json:"category"`
+	Currency           string       `json:"currency"`
+	Rollover           string       `json:"rollover"`
+	Amount             money.Amount `json:"amount"`
+	CarriedOver        money.Amount `json:"carried_over"`
+	Available          money.Amount `json:"available"`
+	Spent              money.Amount `json:"spent"`
+	Remaining          money.Amount `json:"remaining"`
+	Used               float64      `json:"used"`
+	Projected          money.Amount `json:"projected"`
+	ProjectedRemaining money.Amount `json:"projected_remaining"`
+	Status             string       `json:"status"`
+	OtherCurrencies    []string     `json:"other_currencies"`
+}
+
+// BudgetReport is budget against actual for a month. Unbudgeted lists the
+// expense categories spent on without a budget in Currency, the user's base
+// currency; OtherCurrencies are those of unbudgeted expenses without an
+// exchange rate.
+type BudgetReport struct {
+	Month           string                   `json:"month"`
+	DaysElapsed     int                      `json:"days_elapsed"`
+	DaysInMonth     int                      `json:"days_in_month"`
+	Currency        string                   `json:"currency"`
+	Budgets         []BudgetStatus           `json:"budgets"`
+	Unbudgeted      []FinanceCategorySummary `json:"unbudgeted"`
+	OtherCurrencies []string                 `json:"other_currencies"`
+}
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"go-fiber-template/domain/money"
+	"time"
+)
+
+// ExchangeRateModel is what one unit of Base was worth in Quote on Date
+// (YYYY-MM-DD). A rate applies from its date until the next one for the same
+// pair. Source says where it came from, such as the file it was loaded from
+// or admin.
+type ExchangeRateModel struct {
+	ID        string     `json:"id"`
+	Base      string     `json:"base"`
+	Quote     string     `json:"quote"`
+	Rate      money.Rate `json:"rate"`
+	Date      string     `json:"date"`
+	Source    string     `json:"source"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ExchangeRateResponse struct {
+	Base      string     `json:"base"`
+	Quote     string     `json:"quote"`
+	Rate      money.Rate `json:"rate"`
+	Date      string     `json:"date"`
+	Source    string     `json:"source"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ExchangeRateBody loads rates. Rates repeated for a pair and date replace
+// the stored ones.
+type ExchangeRateBody struct {
+	Rates []ExchangeRateResponse `json:"rates"`
+}
+
+// ExchangeRateImport is the outcome of loading rates.
+type ExchangeRateImport struct {
+	Imported int      `json:"imported"`
+	Pairs    []string `json:"pairs"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+}
@@ -13,6 +13,10 @@ type GeneratedPlan struct {
 	FinanceID  string    `json:"finance_id"`
 	HealthID  string    `json:"health_id"`
 	ScheduleID  string    `json:"schedule_id"`
+	ParentID  *string   `json:"parent_id"`
+	RootID    *string   `json:"root_id"`
+	Version   int       `json:"version"`
+	Feedback  string    `json:"feedback"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -24,5 +28,38 @@ type GeneratedPlanResponse struct {
 	FinanceID  string    `json:"finance_id"`
 	HealthID  string    `json:"health_id"`
 	ScheduleID  string    `json:"schedule_id"`
+	ParentID  *string   `json:"parent_id"`
+	RootID    *string   `json:"root_id"`
+	Version   int       `json:"version"`
+	Feedback  string    `json:"feedback"`
 	CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+}
+
+type PlanFeedbackBody struct {
+	Feedback string `json:"feedback"`
+}
+
+type PlanLineChange struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type PlanSectionDiff struct {
+	Title   string           `json:"title"`
+	Status  string           `json:"status"`
+	Before  string           `json:"before,omitempty"`
+	After   string           `json:"after,omitempty"`
+	Changes []PlanLineChange `json:"changes,omitempty"`
+}
+
+type PlanDiff struct {
+	FromID      string            `json:"from_id"`
+	ToID        string            `json:"to_id"`
+	FromVersion int               `json:"from_version"`
+	ToVersion   int               `json:"to_version"`
+	Added       int               `json:"added"`
+	Removed     int               `json:"removed"`
+	Changed     int               `json:"changed"`
+	Unchanged   int               `json:"unchanged"`
+	Sections    []PlanSectionDiff `json:"sections"`
+}
@@ -10,11 +10,17 @@ type HabitModel struct {
 	UserID    string    `json:"user_id"`
 	Name string    `json:"name"`
 	Frequency string    `json:"frequency"`
+	Recurrence string    `json:"recurrence"`
 	Description string    `json:"description"`
 	TargetCount int    `json:"target_count"`
 	CurrentStreak int    `json:"current_streak"`
+	BestStreak int    `json:"best_streak"`
 	CompletedDate []string    `json:"completed_dates"`
 	Category string    `json:"category"`
+	Status string    `json:"status"`
+	Pauses []HabitPause    `json:"pauses"`
+	PlanID *string    `json:"plan_id"`
+	PlanItemID string    `json:"plan_item_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -24,11 +30,150 @@ type HabitResponse struct{
 	UserID    string    `json:"user_id"`
 	Name string    `json:"name"`
 	Frequency string    `json:"frequency"`
+	Recurrence string    `json:"recurrence"`
 	Description string    `json:"description"`
 	TargetCount int    `json:"target_count"`
 	CurrentStreak int    `json:"current_streak"`
 	CompletedDate []string    `json:"completed_dates"`
 	Category string    `json:"category"`
+	Status string    `json:"status"`
+	PlanID *string    `json:"plan_id"`
+	PlanItemID string    `json:"plan_item_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// HabitPause is a stretch of days, from Start up to but not including End,
+// during which the habit was paused. End is nil while the pause lasts.
+type HabitPause struct {
+	Start string  `json:"start"`
+	End   *string `json:"end"`
+}
+
+// HabitUpdate is sent as a PATCH with every editable field; the service
+// fills unchanged fields from the stored habit.
+type HabitUpdate struct {
+	Name        string    `json:"name"`
+	Frequency   string    `json:"frequency"`
+	Recurrence  string    `json:"recurrence"`
+	Description string    `json:"description"`
+	TargetCount int       `json:"target_count"`
+	Category    string    `json:"category"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type HabitStatusUpdate struct {
+	Status    string       `json:"status"`
+	Pauses    []HabitPause `json:"pauses"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// HabitProgressUpdate is written by the server after every check-in change.
+// CompletedDate lists the distinct dates with at least one check-in.
+type HabitProgressUpdate struct {
+	CurrentStreak int       `json:"current_streak"`
+	BestStreak    int       `json:"best_streak"`
+	CompletedDate []string  `json:"completed_dates"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// HabitCheckInModel is one completion of a habit on a calendar date in the
+// user's timezone. A habit with a TargetCount above one needs that many
+// check-ins in a period for the period to count.
+type HabitCheckInModel struct {
+	ID        int       `json:"id"`
+	HabitID   int       `json:"habit_id"`
+	UserID    string    `json:"user_id"`
+	Date      string    `json:"date"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type HabitCheckInResponse struct {
+	HabitID   int       `json:"habit_id"`
+	UserID    string    `json:"user_id"`
+	Date      string    `json:"date"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HabitOccurrence is one due window of a habit, from Start to End
+// inclusive. It is completed once it has Target check-ins.
+type HabitOccurrence struct {
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Target    int    `json:"target"`
+	CheckIns  int    `json:"check_ins"`
+	Completed bool   `json:"completed"`
+	Paused    bool   `json:"paused"`
+}
+
+// HabitCheckInBody defaults to today in the user's timezone. Timezone
+// overrides the profile's, e.g. while travelling.
+type HabitCheckInBody struct {
+	Date     string `json:"date"`
+	Timezone string `json:"timezone"`
+}
+
+// HabitCompletionWindow is the completion rate over the last Days days up to
+// and including To. Expected counts the occurrences that lie wholly in the
+// window and were due: paused ones and today's unfinished one are left out.
+type HabitCompletionWindow struct {
+	Days      int     `json:"days"`
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Expected  int     `json:"expected"`
+	Completed int     `json:"completed"`
+	Rate      float64 `json:"rate"`
+}
+
+// HabitHeatmapCell is one day of the heatmap. Due is set when the day falls
+// in an occurrence that was not paused.
+type HabitHeatmapCell struct {
+	Date     string `json:"date"`
+	CheckIns int    `json:"check_ins"`
+	Due      bool   `json:"due"`
+}
+
+// HabitHeatmap has one row per week, Monday to Sunday. Days outside From to
+// To are null.
+type HabitHeatmap struct {
+	From  string                `json:"from"`
+	To    string                `json:"to"`
+	Weeks [][]*HabitHeatmapCell `json:"weeks"`
+}
+
+// HabitWeekdayStat is how a habit fares on one weekday over the heatmap
+// range. Due and Completed count single-day occurrences only, since a habit
+// that may be done any day of the week has no due weekday; Share is the part
+// of all check-ins made on the weekday.
+type HabitWeekdayStat struct {
+	Weekday   string  `json:"weekday"`
+	CheckIns  int     `json:"check_ins"`
+	Due       int     `json:"due"`
+	Completed int     `json:"completed"`
+	Rate      float64 `json:"rate"`
+	Share     float64 `json:"share"`
+}
+
+type HabitStats struct {
+	HabitID       int                     `json:"habit_id"`
+	Name          string                  `json:"name"`
+	Recurrence    string                  `json:"recurrence"`
+	Status        string                  `json:"status"`
+	CurrentStreak int                     `json:"current_streak"`
+	BestStreak    int                     `json:"best_streak"`
+	TotalCheckIns int                     `json:"total_check_ins"`
+	Windows       []HabitCompletionWindow `json:"windows"`
+	Heatmap       HabitHeatmap            `json:"heatmap"`
+	Weekdays      []HabitWeekdayStat      `json:"weekdays"`
+}
+
+// UserHabitStats adds up the stats of the user's habits that are not
+// archived.
+type UserHabitStats struct {
+	Habits        []HabitStats            `json:"habits"`
+	BestStreak    int                     `json:"best_streak"`
+	TotalCheckIns int                     `json:"total_check_ins"`
+	Windows       []HabitCompletionWindow `json:"windows"`
+	Heatmap       HabitHeatmap            `json:"heatmap"`
+	Weekdays      []HabitWeekdayStat      `json:"weekdays"`
+}
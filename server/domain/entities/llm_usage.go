@@ -0,0 +1,64 @@
+package entities
+
+import (
+	"time"
+)
+
+// LLMUsage is what a single model call reports back about itself.
+type LLMUsage struct {
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TotalTokens      int    `json:"total_tokens"`
+	LatencyMs        int64  `json:"latency_ms"`
+}
+
+type LLMUsageModel struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"user_id"`
+	Feature          string    `json:"feature"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	LatencyMs        int64     `json:"latency_ms"`
+	EstimatedCost    float64   `json:"estimated_cost"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type LLMUsageResponse struct {
+	UserID           string    `json:"user_id"`
+	Feature          string    `json:"feature"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	LatencyMs        int64     `json:"latency_ms"`
+	EstimatedCost    float64   `json:"estimated_cost"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type LLMUsageTotals struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost"`
+	AvgLatencyMs     int64   `json:"avg_latency_ms"`
+}
+
+type LLMUserUsageSummary struct {
+	UserID    string                    `json:"user_id"`
+	From      string                    `json:"from"`
+	To        string                    `json:"to"`
+	Total     LLMUsageTotals            `json:"total"`
+	ByFeature map[string]LLMUsageTotals `json:"by_feature"`
+	ByModel   map[string]LLMUsageTotals `json:"by_model"`
+}
+
+type LLMDailyUsage struct {
+	Date      string                    `json:"date"`
+	Users     int                       `json:"users"`
+	Total     LLMUsageTotals            `json:"total"`
+	ByFeature map[string]LLMUsageTotals `json:"by_feature"`
+}
@@ -4,17 +4,121 @@ import (
 	"time"
 )
 
+// MoodModel is one mood entry. Score, Energy and Stress are on a 1 to 5
+// scale; entries logged before scores existed only have Mood, a label such
+// as "Good". Date is the calendar day of the entry in the user's timezone
+// and SleepHours how long the user slept the night before it. Sentiment,
+// from -1 to 1, and Themes are read from Note, by the LLM or the local
+// lexicon as AnalysisSource says. Flagged marks notes with signs of a crisis.
 type MoodModel struct {
-	ID        int   `json:"id"`
-	UserID    string    `json:"user_id"`
-	Mood      string    `json:"mood"`
-	Note      string    `json:"note"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             int       `json:"id"`
+	UserID         string    `json:"user_id"`
+	Mood           string    `json:"mood"`
+	Note           string    `json:"note"`
+	Score          *int      `json:"score"`
+	Energy         *int      `json:"energy"`
+	Stress         *int      `json:"stress"`
+	SleepHours     *float64  `json:"sleep_hours"`
+	Tags           []string  `json:"tags"`
+	Date           string    `json:"date"`
+	Sentiment      *float64  `json:"sentiment"`
+	SentimentLabel string    `json:"sentiment_label"`
+	Themes         []string  `json:"themes"`
+	AnalysisSource string    `json:"analysis_source"`
+	Flagged        bool      `json:"flagged"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type MoodResponse struct {
-	UserID    string    `json:"user_id"`
-	Mood      string    `json:"mood"`
-	Note      string    `json:"note"`
-	CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+	UserID         string    `json:"user_id"`
+	Mood           string    `json:"mood"`
+	Note           string    `json:"note"`
+	Score          *int      `json:"score"`
+	Energy         *int      `json:"energy"`
+	Stress         *int      `json:"stress"`
+	SleepHours     *float64  `json:"sleep_hours"`
+	Tags           []string  `json:"tags"`
+	Date           string    `json:"date"`
+	Sentiment      *float64  `json:"sentiment"`
+	SentimentLabel string    `json:"sentiment_label"`
+	Themes         []string  `json:"themes"`
+	AnalysisSource string    `json:"analysis_source"`
+	Flagged        bool      `json:"flagged"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MoodBody is a new mood entry. Either Score or a Mood label is needed, and
+// each fills in the other. Date defaults to today in the user's timezone, or
+// in Timezone when given.
+type MoodBody struct {
+	Mood       string   `json:"mood"`
+	Note       string   `json:"note"`
+	Score      *int     `json:"score"`
+	Energy     *int     `json:"energy"`
+	Stress     *int     `json:"stress"`
+	SleepHours *float64 `json:"sleep_hours"`
+	Tags       []string `json:"tags"`
+	Date       string   `json:"date"`
+	Timezone   string   `json:"timezone"`
+}
+
+// MoodDimension summarises one 1 to 5 dimension over the entries that have
+// it. StdDev is the population standard deviation and MeanChange the mean
+// absolute change between consecutive days with entries.
+type MoodDimension struct {
+	Entries    int     `json:"entries"`
+	Average    float64 `json:"average"`
+	StdDev     float64 `json:"std_dev"`
+	MeanChange float64 `json:"mean_change"`
+	Min        int     `json:"min"`
+	Max        int     `json:"max"`
+}
+
+// MoodPeriodStat averages the entries of a day or of a week starting on
+// Monday. A dimension without entries in the period is null.
+type MoodPeriodStat struct {
+	Start   string   `json:"start"`
+	Entries int      `json:"entries"`
+	Score   *float64 `json:"score"`
+	Energy  *float64 `json:"energy"`
+	Stress  *float64 `json:"stress"`
+}
+
+type MoodTagStat struct {
+	Tag          string   `json:"tag"`
+	Count        int      `json:"count"`
+	Share        float64  `json:"share"`
+	AverageScore *float64 `json:"average_score"`
+}
+
+// MoodStreak is a run of consecutive days, both inclusive.
+type MoodStreak struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Days  int    `json:"days"`
+}
+
+// MoodLowStreaks lists the runs of consecutive days whose average score was
+// at most Threshold. A day without entries ends a run. Current is the run
+// that reaches the last day of the range, or the day before while the last
+// day has no entry yet.
+type MoodLowStreaks struct {
+	Threshold int          `json:"threshold"`
+	LowDays   int          `json:"low_days"`
+	Current   int          `json:"current"`
+	Longest   int          `json:"longest"`
+	Streaks   []MoodStreak `json:"streaks"`
+}
+
+type MoodAnalytics struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Entries int              `json:"entries"`
+	Score   MoodDimension    `json:"score"`
+	Energy  MoodDimension    `json:"energy"`
+	Stress  MoodDimension    `json:"stress"`
+	Daily   []MoodPeriodStat `json:"daily"`
+	Weekly  []MoodPeriodStat `json:"weekly"`
+	Tags    []MoodTagStat    `json:"tags"`
+	Low     MoodLowStreaks   `json:"low"`
+}
@@ -0,0 +1,31 @@
+package entities
+
+// MoodInsight compares the average daily mood score on days with a factor,
+// such as a habit being done, against days without it. Difference is
+// MeanWith minus MeanWithout. Correlation is Pearson's r for factors measured
+// in amounts, sleep hours and scheduled minutes, and null for the others.
+// Confidence is low, medium or high and follows from the sample sizes and
+// PValue, the two-tailed p-value of Welch's t-test.
+type MoodInsight struct {
+	Factor      string   `json:"factor"`
+	Kind        string   `json:"kind"`
+	HabitID     *int     `json:"habit_id"`
+	Finding     string   `json:"finding"`
+	Difference  float64  `json:"difference"`
+	MeanWith    float64  `json:"mean_with"`
+	MeanWithout float64  `json:"mean_without"`
+	DaysWith    int      `json:"days_with"`
+	DaysWithout int      `json:"days_without"`
+	Correlation *float64 `json:"correlation"`
+	PValue      float64  `json:"p_value"`
+	Confidence  string   `json:"confidence"`
+}
+
+// MoodInsights lists the findings over the days from From to To that have a
+// mood score, strongest evidence first.
+type MoodInsights struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	MoodDays int           `json:"mood_days"`
+	Insights []MoodInsight `json:"insights"`
+}
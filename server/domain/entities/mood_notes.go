@@ -0,0 +1,39 @@
+package entities
+
+// MoodNoteAnalysis is what was read from a mood note. Sentiment is nil when
+// the note is empty or sentiment analysis is turned off. Support is only set
+// for flagged notes.
+type MoodNoteAnalysis struct {
+	Sentiment   *float64       `json:"sentiment"`
+	Label       string         `json:"label"`
+	Themes      []string       `json:"themes"`
+	Source      string         `json:"source"`
+	CrisisScore float64        `json:"crisis_score"`
+	Flagged     bool           `json:"flagged"`
+	Support     *CrisisSupport `json:"support"`
+}
+
+// CrisisResource is a service someone in distress can contact. Phone and URL
+// are optional, but at least one is set.
+type CrisisResource struct {
+	Name        string `json:"name"`
+	Phone       string `json:"phone"`
+	URL         string `json:"url"`
+	Hours       string `json:"hours"`
+	Description string `json:"description"`
+}
+
+// CrisisSupport is returned with a flagged mood entry: a short message in the
+// user's language and the resources for their region.
+type CrisisSupport struct {
+	Region    string           `json:"region"`
+	Message   string           `json:"message"`
+	Resources []CrisisResource `json:"resources"`
+}
+
+// MoodEntry is a saved mood entry as returned by the API, with Support when
+// the note was flagged.
+type MoodEntry struct {
+	MoodResponse
+	Support *CrisisSupport `json:"support,omitempty"`
+}
@@ -0,0 +1,123 @@
+package entities
+
+import (
+	"time"
+)
+
+// PlanHabitSuggestion is a recurring activity found in a generated plan. ID
+// is stable for the same plan text, so it can be sent back to adopt it.
+type PlanHabitSuggestion struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Section     string `json:"section"`
+	Category    string `json:"category"`
+	Frequency   string `json:"frequency"`
+	Recurrence  string `json:"recurrence"`
+	TargetCount int    `json:"target_count"`
+	Adopted     bool   `json:"adopted"`
+	HabitID     *int   `json:"habit_id"`
+}
+
+// PlanTaskSuggestion is a one-off step found in a generated plan.
+type PlanTaskSuggestion struct {
+	ID      string  `json:"id"`
+	Title   string  `json:"title"`
+	Section string  `json:"section"`
+	Adopted bool    `json:"adopted"`
+	TaskID  *string `json:"task_id"`
+}
+
+type PlanSuggestions struct {
+	PlanID string                `json:"plan_id"`
+	Habits []PlanHabitSuggestion `json:"habits"`
+	Tasks  []PlanTaskSuggestion  `json:"tasks"`
+}
+
+// PlanAdoptItem picks a suggestion by ID. The other fields are optional and
+// override the suggestion: Name and Recurrence or TargetCount for habits,
+// Name and DueDate (YYYY-MM-DD) for tasks.
+type PlanAdoptItem struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Recurrence  string `json:"recurrence"`
+	TargetCount int    `json:"target_count"`
+	DueDate     string `json:"due_date"`
+}
+
+type PlanAdoptBody struct {
+	Habits []PlanAdoptItem `json:"habits"`
+	Tasks  []PlanAdoptItem `json:"tasks"`
+}
+
+// PlanAdoption lists what was created. Suggestions that had already been
+// adopted are returned as they are rather than created twice.
+type PlanAdoption struct {
+	PlanID string          `json:"plan_id"`
+	Habits []HabitModel    `json:"habits"`
+	Tasks  []PlanTaskModel `json:"tasks"`
+}
+
+// PlanTaskModel is a one-off task adopted from a plan. Status is todo or
+// done.
+type PlanTaskModel struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	PlanID      string     `json:"plan_id"`
+	PlanItemID  string     `json:"plan_item_id"`
+	Title       string     `json:"title"`
+	Section     string     `json:"section"`
+	DueDate     *string    `json:"due_date"`
+	Status      string     `json:"status"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type PlanTaskResponse struct {
+	UserID     string    `json:"user_id"`
+	PlanID     string    `json:"plan_id"`
+	PlanItemID string    `json:"plan_item_id"`
+	Title      string    `json:"title"`
+	Section    string    `json:"section"`
+	DueDate    *string   `json:"due_date"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type PlanTaskStatusBody struct {
+	Status string `json:"status"`
+}
+
+type PlanTaskStatusUpdate struct {
+	Status      string     `json:"status"`
+	CompletedAt *time.Time `json:"completed_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// PlanHabitAdherence counts a habit's occurrences since it was adopted.
+type PlanHabitAdherence struct {
+	HabitID   int     `json:"habit_id"`
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Expected  int     `json:"expected"`
+	Completed int     `json:"completed"`
+	Rate      float64 `json:"rate"`
+}
+
+// PlanAdherence is how much of a plan's adopted habits and tasks the user has
+// done. Adherence weighs every finished habit occurrence and every task
+// equally.
+type PlanAdherence struct {
+	PlanID             string               `json:"plan_id"`
+	Habits             []PlanHabitAdherence `json:"habits"`
+	HabitRate          float64              `json:"habit_rate"`
+	TasksTotal         int                  `json:"tasks_total"`
+	TasksDone          int                  `json:"tasks_done"`
+	TasksOverdue       int                  `json:"tasks_overdue"`
+	TaskRate           float64              `json:"task_rate"`
+	Adherence          float64              `json:"adherence"`
+	SuggestionsTotal   int                  `json:"suggestions_total"`
+	SuggestionsAdopted int                  `json:"suggestions_adopted"`
+}
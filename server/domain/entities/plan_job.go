@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"time"
+)
+
+type PlanJobModel struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	PromptID    string     `json:"prompt_id"`
+	Status      string     `json:"status"`
+	Progress    int        `json:"progress"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	PlanID      *string    `json:"plan_id"`
+	Error       string     `json:"error"`
+	NextRunAt   time.Time  `json:"next_run_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+type PlanJobResponse struct {
+	UserID      string    `json:"user_id"`
+	PromptID    string    `json:"prompt_id"`
+	Status      string    `json:"status"`
+	Progress    int       `json:"progress"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"max_attempts"`
+	NextRunAt   time.Time `json:"next_run_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PlanJobUpdate is sent as a PATCH, so every field is written; callers start
+// from the current job and change what they need.
+type PlanJobUpdate struct {
+	Status     string     `json:"status"`
+	Progress   int        `json:"progress"`
+	Attempts   int        `json:"attempts"`
+	PlanID     *string    `json:"plan_id"`
+	Error      string     `json:"error"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
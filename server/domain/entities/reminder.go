@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"time"
+)
+
+// ReminderModel fires at Time (HH:MM) on Days in Timezone, or the user's
+// timezone when empty, through each of Channels. Quiet hours, from
+// QuietStart up to QuietEnd, may wrap past midnight; a reminder that falls in
+// them is moved to QuietEnd. NextRunAt and LastSentAt are server time plus
+// seven hours, like every other timestamp.
+type ReminderModel struct {
+	ID               string            `json:"id"`
+	UserID           string            `json:"user_id"`
+	Kind             string            `json:"kind"`
+	HabitID          *int              `json:"habit_id"`
+	Time             string            `json:"time"`
+	Days             []string          `json:"days"`
+	Timezone         string            `json:"timezone"`
+	QuietStart       string            `json:"quiet_start"`
+	QuietEnd         string            `json:"quiet_end"`
+	Channels         []string          `json:"channels"`
+	Email            string            `json:"email"`
+	WebhookURL       string            `json:"webhook_url"`
+	PushSubscription *PushSubscription `json:"push_subscription"`
+	Enabled          bool              `json:"enabled"`
+	NextRunAt        time.Time         `json:"next_run_at"`
+	LastSentAt       *time.Time        `json:"last_sent_at"`
+	LastError        string            `json:"last_error"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// ReminderResponse is the body of a create or update and the row inserted.
+// Enabled defaults to true on create and keeps its value on update.
+type ReminderResponse struct {
+	UserID           string            `json:"user_id"`
+	Kind             string            `json:"kind"`
+	HabitID          *int              `json:"habit_id"`
+	Time             string            `json:"time"`
+	Days             []string          `json:"days"`
+	Timezone         string            `json:"timezone"`
+	QuietStart       string            `json:"quiet_start"`
+	QuietEnd         string            `json:"quiet_end"`
+	Channels         []string          `json:"channels"`
+	Email            string            `json:"email"`
+	WebhookURL       string            `json:"webhook_url"`
+	PushSubscription *PushSubscription `json:"push_subscription"`
+	Enabled          *bool             `json:"enabled"`
+	NextRunAt        time.Time         `json:"next_run_at"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// ReminderUpdate is sent as a PATCH with every editable field; the service
+// fills unchanged fields from the stored reminder.
+type ReminderUpdate struct {
+	Kind             string            `json:"kind"`
+	HabitID          *int              `json:"habit_id"`
+	Time             string            `json:"time"`
+	Days             []string          `json:"days"`
+	Timezone         string            `json:"timezone"`
+	QuietStart       string            `json:"quiet_start"`
+	QuietEnd         string            `json:"quiet_end"`
+	Channels         []string          `json:"channels"`
+	Email            string            `json:"email"`
+	WebhookURL       string            `json:"webhook_url"`
+	PushSubscription *PushSubscription `json:"push_subscription"`
+	Enabled          bool              `json:"enabled"`
+	NextRunAt        time.Time         `json:"next_run_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// PushSubscription is the PushSubscription a browser returns from
+// pushManager.subscribe, serialised with toJSON().
+type PushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// ReminderRunUpdate moves a due reminder to its next run, which claims it.
+type ReminderRunUpdate struct {
+	NextRunAt time.Time `json:"next_run_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReminderResultUpdate records a delivery. LastError lists the channels that
+// failed and is empty when all of them succeeded.
+type ReminderResultUpdate struct {
+	LastSentAt *time.Time `json:"last_sent_at"`
+	LastError  string     `json:"last_error"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"go-fiber-template/domain/money"
+	"time"
+)
+
+// SavingsGoalModel is an amount to save by Deadline (YYYY-MM-DD, optional),
+// counted from StartDate. LifeGoalID optionally links it to the life goal it
+// serves. What has been saved is the sum of its contributions.
+type SavingsGoalModel struct {
+	ID         string       `json:"id"`
+	UserID     string       `json:"user_id"`
+	Name       string       `json:"name"`
+	Target     money.Amount `json:"target"`
+	Currency   string       `json:"currency"`
+	StartDate  string       `json:"start_date"`
+	Deadline   *string      `json:"deadline"`
+	LifeGoalID *string      `json:"lifegoal_id"`
+	Notes      string       `json:"notes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+type SavingsGoalResponse struct {
+	UserID     string       `json:"user_id"`
+	Name       string       `json:"name"`
+	Target     money.Amount `json:"target"`
+	Currency   string       `json:"currency"`
+	StartDate  string       `json:"start_date"`
+	Deadline   *string      `json:"deadline"`
+	LifeGoalID *string      `json:"lifegoal_id"`
+	Notes      string       `json:"notes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// SavingsGoalBody creates a savings goal or edits one. Current is what is
+// already saved and is recorded as a first contribution on create. When
+// editing, fields left out keep their value and an empty Deadline or
+// LifeGoalID removes it.
+type SavingsGoalBody struct {
+	Name       string        `json:"name"`
+	Target     *money.Amount `json:"target"`
+	Current    *money.Amount `json:"current"`
+	Currency   string        `json:"currency"`
+	StartDate  string        `json:"start_date"`
+	Deadline   *string       `json:"deadline"`
+	LifeGoalID *string       `json:"lifegoal_id"`
+	Notes      *string       `json:"notes"`
+}
+
+// SavingsGoalUpdate is sent as a PATCH with every editable field.
+type SavingsGoalUpdate struct {
+	Name       string       `json:"name"`
+	Target     money.Amount `json:"target"`
+	Currency   string       `json:"currency"`
+	StartDate  string       `json:"start_date"`
+	Deadline   *string      `json:"deadline"`
+	LifeGoalID *string      `json:"lifegoal_id"`
+	Notes      string       `json:"notes"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// SavingsContributionModel is money put towards a goal on Date, or taken
+// out of it when Amount is negative.
+type SavingsContributionModel struct {
+	ID        string       `json:"id"`
+	GoalID    string       `json:"goal_id"`
+	UserID    string       `json:"user_id"`
+	Amount    money.Amount `json:"amount"`
+	Date      string       `json:"date"`
+	Notes     string       `json:"notes"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type SavingsContributionResponse struct {
+	GoalID    string       `json:"goal_id"`
+	UserID    string       `json:"user_id"`
+	Amount    money.Amount `json:"amount"`
+	Date      string       `json:"date"`
+	Notes     string       `json:"notes"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// SavingsGoalProgress is a goal with what has been saved towards it.
+// Progress is Current over Target in percent. RequiredMonthly is what has
+// to be saved each month from today to reach Target by the deadline, and
+// AverageMonthly what has been saved per month since StartDate; at that pace
+// the goal is reached on ProjectedDate. Status is achieved, on_track,
+// off_track (less saved than an even pace from StartDate to the deadline
+// would have), overdue or no_deadline. CurrentInBase and TargetInBase are
+// Current and Target in the user's base currency at today's exchange rate,
+// nil when there is no rate.
+type SavingsGoalProgress struct {
+	SavingsGoalModel
+	Current         money.Amount                `json:"current"`
+	Remaining       money.Amount                `json:"remaining"`
+	Progress        float64                     `json:"progress"`
+	Expected        *money.Amount               `json:"expected"`
+	MonthsLeft      *float64                    `json:"months_left"`
+	RequiredMonthly *money.Amount               `json:"required_monthly"`
+	AverageMonthly  money.Amount                `json:"average_monthly"`
+	ProjectedDate   *string                     `json:"projected_date"`
+	Status          string                      `json:"status"`
+	BaseCurrency    string                      `json:"base_currency"`
+	CurrentInBase   *money.Amount               `json:"current_in_base"`
+	TargetInBase    *money.Amount               `json:"target_in_base"`
+	Contributions   *[]SavingsContributionModel `json:"contributions,omitempty"`
+}
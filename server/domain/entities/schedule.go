@@ -27,3 +27,38 @@ type ScheduleResponse struct {
 
 //  query schedule
 // create prompt
+
+type ScheduleBlockModel struct {
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	Title           string    `json:"title"`
+	Days            []string  `json:"days"`
+	Recurrence      string    `json:"recurrence"`
+	StartTime       string    `json:"start_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Notes           string    `json:"notes"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type ScheduleBlockResponse struct {
+	UserID          string    `json:"user_id"`
+	Title           string    `json:"title"`
+	Days            []string  `json:"days"`
+	Recurrence      string    `json:"recurrence"`
+	StartTime       string    `json:"start_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Notes           string    `json:"notes"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ScheduleOccurrence is one dated instance of a recurring schedule block.
+type ScheduleOccurrence struct {
+	BlockID         string `json:"block_id"`
+	Title           string `json:"title"`
+	Date            string `json:"date"`
+	StartTime       string `json:"start_time"`
+	DurationMinutes int    `json:"duration_minutes"`
+	Notes           string `json:"notes"`
+}
@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"go-fiber-template/domain/money"
+	"time"
+)
+
+// TransactionModel is one income or expense. Date is the calendar day it
+// happened on. ExpenseType is fixed or flexible for expenses and empty for
+// income. A recurring transaction repeats every month on the same day, or
+// the month's last day when it is shorter, up to RecurringEnd when set.
+type TransactionModel struct {
+	ID           string       `json:"id"`
+	UserID       string       `json:"user_id"`
+	Type         string       `json:"type"`
+	Amount       money.Amount `json:"amount"`
+	Currency     string       `json:"currency"`
+	Category     string       `json:"category"`
+	ExpenseType  string       `json:"expense_type"`
+	Date         string       `json:"date"`
+	Notes        string       `json:"notes"`
+	Recurring    bool         `json:"recurring"`
+	RecurringEnd *string      `json:"recurring_end"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+type TransactionResponse struct {
+	UserID       string       `json:"user_id"`
+	Type         string       `json:"type"`
+	Amount       money.Amount `json:"amount"`
+	Currency     string       `json:"currency"`
+	Category     string       `json:"category"`
+	ExpenseType  string       `json:"expense_type"`
+	Date         string       `json:"date"`
+	Notes        string       `json:"notes"`
+	Recurring    bool         `json:"recurring"`
+	RecurringEnd *string      `json:"recurring_end"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// TransactionBody creates a transaction or edits one. When editing, fields
+// left out keep their stored value; an empty RecurringEnd clears it.
+type TransactionBody struct {
+	Type         string        `json:"type"`
+	Amount       *money.Amount `json:"amount"`
+	Currency     string        `json:"currency"`
+	Category     string        `json:"category"`
+	ExpenseType  string        `json:"expense_type"`
+	Date         string        `json:"date"`
+	Notes        *string       `json:"notes"`
+	Recurring    *bool         `json:"recurring"`
+	RecurringEnd *string       `json:"recurring_end"`
+}
+
+// TransactionUpdate is sent as a PATCH with every editable field.
+type TransactionUpdate struct {
+	Type         string       `json:"type"`
+	Amount       money.Amount `json:"amount"`
+	Currency     string       `json:"currency"`
+	Category     string       `json:"category"`
+	ExpenseType  string       `json:"expense_type"`
+	Date         string       `json:"date"`
+	Notes        string       `json:"notes"`
+	Recurring    bool         `json:"recurring"`
+	RecurringEnd *string      `json:"recurring_end"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// FinanceCategorySummary totals one category in a month. Share is its part
+// of the month's income or expenses.
+type FinanceCategorySummary struct {
+	Category    string       `json:"category"`
+	ExpenseType string       `json:"expense_type"`
+	Amount      money.Amount `json:"amount"`
+	Share       float64      `json:"share"`
+	Count       int          `json:"count"`
+}
+
+// FinanceMonthSummary totals a month's transactions in Currency, counting
+// each recurring transaction once in every month it repeats in. Transactions
+// in other currencies are converted at the exchange rate of their date; the
+// ones without a rate are left out and their currencies listed in
+// OtherCurrencies. SavingsRate is Net over Income, 0 without income.
+type FinanceMonthSummary struct {
+	Month            string                   `json:"month"`
+	Currency         string                   `json:"currency"`
+	Income           money.Amount             `json:"income"`
+	Expenses         money.Amount             `json:"expenses"`
+	FixedExpenses    money.Amount             `json:"fixed_expenses"`
+	FlexibleExpenses money.Amount             `json:"flexible_expenses"`
+	Net              money.Amount             `json:"net"`
+	SavingsRate      float64                  `json:"savings_rate"`
+	Transactions     int                      `json:"transactions"`
+	Expense          []FinanceCategorySummary `json:"expense_categories"`
+	IncomeSources    []FinanceCategorySummary `json:"income_categories"`
+	OtherCurrencies  []string                 `json:"other_currencies"`
+}
@@ -12,6 +12,9 @@ type UserProfileModel struct {
 	Weight   float32    `json:"weight"`
 	Height   float32    `json:"height"`
 	Gender   string    `json:"gender"`
+	Language string    `json:"language"`
+	TenantID string    `json:"tenant_id"`
+	Timezone string    `json:"timezone"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -23,6 +26,12 @@ type UserProfileResponse struct {
 	Weight    float32   `json:"weight"`
 	Height    float32   `json:"height"`
 	Gender    string    `json:"gender"`
+	Language  string    `json:"language"`
+	// TenantID is cleared on insert and an empty one is left out of the body,
+	// so posting a profile again merges into the row without resetting the
+	// tenant an administrator set.
+	TenantID  string    `json:"tenant_id,omitempty"`
+	Timezone  string    `json:"timezone"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"time"
+)
+
+// WeeklyHabitStat is how often a habit was completed in the reviewed week.
+// Expected is zero when no occurrence of the habit falls wholly in the week,
+// as with monthly habits.
+type WeeklyHabitStat struct {
+	HabitID    int     `json:"habit_id"`
+	Name       string  `json:"name"`
+	Category   string  `json:"category"`
+	Frequency  string  `json:"frequency"`
+	Recurrence string  `json:"recurrence"`
+	Completed  int     `json:"completed"`
+	Expected   int     `json:"expected"`
+	Rate       float64 `json:"rate"`
+}
+
+type WeeklyMoodStat struct {
+	Entries    int            `json:"entries"`
+	Counts     map[string]int `json:"counts"`
+	MostCommon string         `json:"most_common"`
+	// AverageScore is the mean 1 to 5 score, null without scored entries.
+	AverageScore *float64 `json:"average_score"`
+}
+
+// WeeklyReviewContent is the part of a review written by the model.
+type WeeklyReviewContent struct {
+	Summary     string   `json:"summary"`
+	Wins        []string `json:"wins"`
+	Misses      []string `json:"misses"`
+	Patterns    []string `json:"patterns"`
+	Adjustments []string `json:"adjustments"`
+}
+
+type WeeklyReviewModel struct {
+	ID          string            `json:"id"`
+	UserID      string            `json:"user_id"`
+	WeekStart   string            `json:"week_start"`
+	WeekEnd     string            `json:"week_end"`
+	PlanID      *string           `json:"plan_id"`
+	Summary     string            `json:"summary"`
+	Wins        []string          `json:"wins"`
+	Misses      []string          `json:"misses"`
+	Patterns    []string          `json:"patterns"`
+	Adjustments []string          `json:"adjustments"`
+	Habits      []WeeklyHabitStat `json:"habits"`
+	Mood        WeeklyMoodStat    `json:"mood"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+type WeeklyReviewResponse struct {
+	UserID      string            `json:"user_id"`
+	WeekStart   string            `json:"week_start"`
+	WeekEnd     string            `json:"week_end"`
+	PlanID      *string           `json:"plan_id"`
+	Summary     string            `json:"summary"`
+	Wins        []string          `json:"wins"`
+	Misses      []string          `json:"misses"`
+	Patterns    []string          `json:"patterns"`
+	Adjustments []string          `json:"adjustments"`
+	Habits      []WeeklyHabitStat `json:"habits"`
+	Mood        WeeklyMoodStat    `json:"mood"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
@@ -0,0 +1,192 @@
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places an Amount keeps, enough for every
+// ISO 4217 minor unit.
+const Scale = 4
+
+const unit = 10000
+
+// Amount is a sum of money in ten-thousandths of the currency's major unit.
+// It is read from and written to JSON as an exact decimal number, so amounts
+// never pass through float64 on their way to or from the database, where the
+// columns are numeric.
+type Amount int64
+
+// ParseAmount reads a decimal number such as "-12.5" or "1e3". Digits past
+// the fourth decimal place are rounded half away from zero.
+func ParseAmount(value string) (Amount, error) {
+	rat, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return fromRat(rat)
+}
+
+// FromMinor is minor units of code, e.g. cents for USD, as an Amount.
+func FromMinor(minor int64, code string) Amount {
+	return Amount(minor * pow10(Scale-MinorUnits(code)))
+}
+
+// FromFloat converts a float, rounding to Scale. It is for figures that were
+// computed rather than entered, such as averages.
+func FromFloat(value float64) Amount {
+	return Amount(math.Round(value * unit))
+}
+
+func fromRat(rat *big.Rat) (Amount, error) {
+	scaled := new(big.Rat).Mul(rat, big.NewRat(unit, 1))
+	rounded := roundRat(scaled)
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("amount %s is too large", rat.FloatString(Scale))
+	}
+	return Amount(rounded.Int64()), nil
+}
+
+// roundRat rounds to the nearest integer, halves away from zero.
+func roundRat(rat *big.Rat) *big.Int {
+	num := new(big.Int).Abs(rat.Num())
+	den := rat.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(remainder, big.NewInt(2)).Cmp(den) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if rat.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+	return quotient
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
+
+// Round rounds to the minor unit of code, halves away from zero.
+func (a Amount) Round(code string) Amount {
+	step := pow10(Scale - MinorUnits(code))
+	if step == 1 {
+		return a
+	}
+	value := int64(a)
+	remainder := value % step
+	value -= remainder
+	if remainder*2 >= step {
+		value += step
+	} else if remainder*2 <= -step {
+		value -= step
+	}
+	return Amount(value)
+}
+
+// Fits reports whether a has no digits beyond the minor unit of code.
+func (a Amount) Fits(code string) bool {
+	return int64(a)%pow10(Scale-MinorUnits(code)) == 0
+}
+
+// CheckFits returns an error naming the field when a has more decimal places
+// than code allows.
+func (a Amount) CheckFits(field string, code string) error {
+	if a.Fits(code) {
+		return nil
+	}
+	return fmt.Errorf("%s in %s can have at most %d decimal places", field, code, MinorUnits(code))
+}
+
+// Minor is a in minor units of code, rounded.
+func (a Amount) Minor(code string) int64 {
+	return int64(a.Round(code)) / pow10(Scale-MinorUnits(code))
+}
+
+// Div divides by n, rounding to Scale.
+func (a Amount) Div(n int64) (Amount, error) {
+	if n == 0 {
+		return 0, fmt.Errorf("cannot divide amount %s by 0", a)
+	}
+	return fromRat(new(big.Rat).SetFrac(big.NewInt(int64(a)), new(big.Int).Mul(big.NewInt(n), big.NewInt(unit))))
+}
+
+// Mul multiplies by rate, rounding to Scale. The error reports a product
+// too large for an Amount.
+func (a Amount) Mul(rate *big.Rat) (Amount, error) {
+	return fromRat(new(big.Rat).Mul(big.NewRat(int64(a), unit), rate))
+}
+
+// Float is a as a float64, for ratios and statistics only.
+func (a Amount) Float() float64 {
+	return float64(a) / unit
+}
+
+// Ratio is a over b, 0 when b is 0.
+func Ratio(a Amount, b Amount) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// String writes a with as few decimal places as it needs.
+func (a Amount) String() string {
+	return strings.TrimSuffix(strings.TrimRight(a.decimal(Scale), "0"), ".")
+}
+
+// Format writes a rounded to the minor unit of code, with all its decimal
+// places, e.g. 12.50 for USD and 1250 for JPY.
+func (a Amount) Format(code string) string {
+	return a.Round(code).decimal(MinorUnits(code))
+}
+
+func (a Amount) decimal(places int) string {
+	value := int64(a)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+	}
+	whole := value / unit
+	fraction := value % unit
+	if whole < 0 {
+		whole = -whole
+	}
+	if fraction < 0 {
+		fraction = -fraction
+	}
+	text := sign + strconv.FormatInt(whole, 10)
+	if places == 0 {
+		return text
+	}
+	return text + "." + fmt.Sprintf("%04d", fraction)[:places]
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a string holding one; null is 0.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		*a = 0
+		return nil
+	}
+	text := string(data)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	amount, err := ParseAmount(text)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
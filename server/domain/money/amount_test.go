@@ -0,0 +1,28 @@
+package money
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestMulAndDiv(t *testing.T) {
+	amount, _ := ParseAmount("12.5")
+	if got, err := amount.Mul(big.NewRat(3, 2)); err != nil || got.String() != "18.75" {
+		t.Errorf("12.5 * 1.5 = %s, %v, want 18.75", got, err)
+	}
+	if got, err := amount.Div(3); err != nil || got.String() != "4.1667" {
+		t.Errorf("12.5 / 3 = %s, %v, want 4.1667", got, err)
+	}
+	if _, err := amount.Div(0); err == nil {
+		t.Error("12.5 / 0 did not fail")
+	}
+	large := Amount(math.MaxInt64 / 2)
+	if got, err := large.Mul(big.NewRat(3, 1)); err == nil {
+		t.Errorf("overflowing product = %s, want an error", got)
+	}
+	rate, _ := ParseRate("1000")
+	if got, err := rate.Convert(large, "USD"); err == nil {
+		t.Errorf("overflowing conversion = %s, want an error", got)
+	}
+}
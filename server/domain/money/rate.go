@@ -0,0 +1,129 @@
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// rateDigits is how many decimal places a rate that is not a finite decimal,
+// such as an inverse or a cross rate, is written with.
+const rateDigits = 10
+
+// Rate is how many units of a quote currency one unit of a base currency
+// buys. It is kept as an exact fraction so that inverse and cross rates do
+// not pick up floating point error.
+type Rate struct {
+	value *big.Rat
+}
+
+// ParseRate reads a positive decimal number such as "35.42".
+func ParseRate(value string) (Rate, error) {
+	rat, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q", value)
+	}
+	if rat.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("rate must be greater than 0")
+	}
+	return Rate{value: rat}, nil
+}
+
+// IsZero reports whether the rate is unset.
+func (r Rate) IsZero() bool {
+	return r.value == nil || r.value.Sign() == 0
+}
+
+// Rat is a copy of the rate as a fraction.
+func (r Rate) Rat() *big.Rat {
+	if r.value == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(r.value)
+}
+
+// Inverse is the rate the other way round.
+func (r Rate) Inverse() Rate {
+	if r.IsZero() {
+		return Rate{}
+	}
+	return Rate{value: new(big.Rat).Inv(r.value)}
+}
+
+// Times chains two rates, A→B then B→C giving A→C.
+func (r Rate) Times(other Rate) Rate {
+	return Rate{value: new(big.Rat).Mul(r.Rat(), other.Rat())}
+}
+
+// Convert converts an amount at this rate and rounds it to the minor unit of
+// the target currency.
+func (r Rate) Convert(amount Amount, to string) (Amount, error) {
+	converted, err := amount.Mul(r.Rat())
+	if err != nil {
+		return 0, err
+	}
+	return converted.Round(to), nil
+}
+
+// String writes the rate exactly when it is a finite decimal and to
+// rateDigits decimal places otherwise.
+func (r Rate) String() string {
+	rat := r.Rat()
+	if exact, ok := exactDecimal(rat); ok {
+		return exact
+	}
+	text := strings.TrimRight(rat.FloatString(rateDigits), "0")
+	return strings.TrimSuffix(text, ".")
+}
+
+// exactDecimal writes rat without rounding if its denominator only has the
+// factors 2 and 5.
+func exactDecimal(rat *big.Rat) (string, bool) {
+	den := new(big.Int).Set(rat.Denom())
+	places := 0
+	for _, factor := range []int64{2, 5} {
+		divisor := big.NewInt(factor)
+		count := 0
+		for new(big.Int).Mod(den, divisor).Sign() == 0 {
+			den.Quo(den, divisor)
+			count++
+		}
+		places = max(places, count)
+	}
+	if den.Cmp(big.NewInt(1)) != 0 {
+		return "", false
+	}
+	text := rat.FloatString(places)
+	if places > 0 {
+		text = strings.TrimSuffix(strings.TrimRight(text, "0"), ".")
+	}
+	return text, true
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	if r.value == nil {
+		return []byte("null"), nil
+	}
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a string holding one.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		*r = Rate{}
+		return nil
+	}
+	text := string(data)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	rate, err := ParseRate(text)
+	if err != nil {
+		return err
+	}
+	*r = rate
+	return nil
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"os"
+	"time"
+)
+
+// Channels a notification can be delivered through.
+const (
+	ChannelEmail   = "email"
+	ChannelWebPush = "web_push"
+	ChannelWebhook = "webhook"
+)
+
+// Message is what a channel delivers. Kind tells clients what it is about,
+// e.g. habit_due, and Data carries the ids they need to act on it.
+type Message struct {
+	Kind   string         `json:"kind"`
+	Title  string         `json:"title"`
+	Body   string         `json:"body"`
+	Data   map[string]any `json:"data,omitempty"`
+	SentAt time.Time      `json:"sent_at"`
+}
+
+// Target is where to deliver; each channel reads its own field.
+type Target struct {
+	Email            string
+	WebhookURL       string
+	PushSubscription *entities.PushSubscription
+}
+
+type IChannel interface {
+	Name() string
+	Send(target Target, message Message) error
+}
+
+// NewChannelsFromEnv returns the channels that are configured, keyed by
+// name. Email needs SMTP_HOST and web push WEB_PUSH_VAPID_PRIVATE_KEY; the
+// webhook channel is always available. WEBHOOK_ALLOW_PRIVATE lets webhooks
+// and push endpoints reach private addresses.
+func NewChannelsFromEnv() (map[string]IChannel, error) {
+	allowPrivate := os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "1"
+	channels := map[string]IChannel{
+		ChannelWebhook: NewWebhookChannel(os.Getenv("WEBHOOK_SIGNING_SECRET"), allowPrivate),
+	}
+	if smtp := NewSMTPConfigFromEnv(); smtp.Host != "" {
+		channels[ChannelEmail] = NewSMTPChannel(smtp)
+	}
+	if key := os.Getenv("WEB_PUSH_VAPID_PRIVATE_KEY"); key != "" {
+		push, err := NewWebPushChannel(key, os.Getenv("WEB_PUSH_SUBJECT"), allowPrivate)
+		if err != nil {
+			return nil, fmt.Errorf("WEB_PUSH_VAPID_PRIVATE_KEY: %w", err)
+		}
+		channels[ChannelWebPush] = push
+	}
+	return channels, nil
+}
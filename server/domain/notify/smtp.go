@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPConfigFromEnv() SMTPConfig {
+	port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil || port <= 0 {
+		port = 587
+	}
+	return SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+}
+
+// SMTPChannel sends plain text mail. STARTTLS is used when the server offers
+// it, and credentials are only sent over TLS or to localhost.
+type SMTPChannel struct {
+	Config SMTPConfig
+}
+
+func NewSMTPChannel(config SMTPConfig) *SMTPChannel {
+	if config.From == "" {
+		config.From = "planner@localhost"
+	}
+	return &SMTPChannel{Config: config}
+}
+
+func (c *SMTPChannel) Name() string {
+	return ChannelEmail
+}
+
+func (c *SMTPChannel) Send(target Target, message Message) error {
+	to, err := mail.ParseAddress(target.Email)
+	if err != nil {
+		return fmt.Errorf("invalid email address %q", target.Email)
+	}
+	from, err := mail.ParseAddress(c.Config.From)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM %q", c.Config.From)
+	}
+	var auth smtp.Auth
+	if c.Config.Username != "" {
+		auth = smtp.PlainAuth("", c.Config.Username, c.Config.Password, c.Config.Host)
+	}
+	addr := net.JoinHostPort(c.Config.Host, strconv.Itoa(c.Config.Port))
+	return smtp.SendMail(addr, auth, from.Address, []string{to.Address}, buildMail(from, to, message))
+}
+
+func buildMail(from *mail.Address, to *mail.Address, message Message) []byte {
+	var body bytes.Buffer
+	writer := quotedprintable.NewWriter(&body)
+	writer.Write([]byte(message.Body))
+	writer.Close()
+
+	var raw bytes.Buffer
+	fmt.Fprintf(&raw, "From: %s\r\n", from.String())
+	fmt.Fprintf(&raw, "To: %s\r\n", to.String())
+	fmt.Fprintf(&raw, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Title))
+	fmt.Fprintf(&raw, "Date: %s\r\n", message.SentAt.Format(time.RFC1123Z))
+	fmt.Fprintf(&raw, "X-Planner-Kind: %s\r\n", message.Kind)
+	raw.WriteString("MIME-Version: 1.0\r\n")
+	raw.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	raw.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	raw.Write(body.Bytes())
+	raw.WriteString("\r\n")
+	return raw.Bytes()
+}
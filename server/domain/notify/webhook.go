@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// WebhookChannel POSTs the message as JSON. With a secret the body is signed
+// in the X-Planner-Signature header as sha256=<hex HMAC-SHA256>. Unless
+// allowPrivate is set, URLs that resolve to loopback, private or link-local
+// addresses are refused so users cannot reach the server's own network.
+type WebhookChannel struct {
+	Secret string
+	client *http.Client
+}
+
+func NewWebhookChannel(secret string, allowPrivate bool) *WebhookChannel {
+	return &WebhookChannel{
+		Secret: secret,
+		client: newGuardedClient("webhook", allowPrivate),
+	}
+}
+
+// newGuardedClient is an HTTP client for URLs users give us. It does not
+// follow redirects and, unless allowPrivate is set, refuses to connect to
+// addresses that are not public, checked on the resolved IP so DNS cannot
+// point it inside.
+func newGuardedClient(purpose string, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network string, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%s address %s is not public", purpose, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast())
+}
+
+func (c *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// ValidateWebhookURL checks that target is an absolute http or https URL.
+func ValidateWebhookURL(target string) error {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func (c *WebhookChannel) Send(target Target, message Message) error {
+	if err := ValidateWebhookURL(target.WebhookURL); err != nil {
+		return err
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, target.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.Secret))
+		mac.Write(body)
+		request.Header.Set("X-Planner-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
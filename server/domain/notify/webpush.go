@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// WebPushChannel delivers to browser push subscriptions with VAPID (RFC
+// 8292) and aes128gcm payload encryption (RFC 8291). The VAPID private key
+// is the raw P-256 scalar in unpadded base64url, as printed by most web push
+// key generators. Subscription endpoints come from the browser, so like
+// webhooks they may not resolve to private addresses unless allowPrivate is
+// set.
+type WebPushChannel struct {
+	Subject   string
+	PublicKey string
+	key       *ecdsa.PrivateKey
+	client    *http.Client
+}
+
+func NewWebPushChannel(privateKey string, subject string, allowPrivate bool) (*WebPushChannel, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	private, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	public := private.PublicKey().Bytes()
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+	if subject == "" {
+		subject = "mailto:planner@localhost"
+	}
+	return &WebPushChannel{
+		Subject:   subject,
+		PublicKey: base64.RawURLEncoding.EncodeToString(public),
+		key:       key,
+		client:    newGuardedClient("push endpoint", allowPrivate),
+	}, nil
+}
+
+func (c *WebPushChannel) Name() string {
+	return ChannelWebPush
+}
+
+// ValidatePushSubscription checks that the subscription has an https
+// endpoint and keys of the right length.
+func ValidatePushSubscription(subscription *entities.PushSubscription) error {
+	if subscription == nil {
+		return fmt.Errorf("push subscription is required")
+	}
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("push subscription endpoint must be an https URL")
+	}
+	if key, err := decodeBase64URL(subscription.Keys.P256dh); err != nil || len(key) != 65 {
+		return fmt.Errorf("push subscription p256dh key is invalid")
+	}
+	if auth, err := decodeBase64URL(subscription.Keys.Auth); err != nil || len(auth) != 16 {
+		return fmt.Errorf("push subscription auth secret is invalid")
+	}
+	return nil
+}
+
+func (c *WebPushChannel) Send(target Target, message Message) error {
+	subscription := target.PushSubscription
+	if err := ValidatePushSubscription(subscription); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	body, err := encryptPushPayload(subscription, payload, serverKey, salt)
+	if err != nil {
+		return err
+	}
+	endpoint, _ := url.Parse(subscription.Endpoint)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": c.Subject,
+	}).SignedString(c.key)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("Content-Encoding", "aes128gcm")
+	request.Header.Set("TTL", "86400")
+	request.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, c.PublicKey))
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone {
+		return fmt.Errorf("push subscription has expired")
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("push service responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// encryptPushPayload builds a single aes128gcm record as described in RFC
+// 8291 section 3, with a fresh server key pair and salt for every message.
+func encryptPushPayload(subscription *entities.PushSubscription, payload []byte, serverPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	userAgentKey, _ := decodeBase64URL(subscription.Keys.P256dh)
+	authSecret, _ := decodeBase64URL(subscription.Keys.Auth)
+	userAgentPublic, err := ecdh.P256().NewPublicKey(userAgentKey)
+	if err != nil {
+		return nil, err
+	}
+	serverPublic := serverPrivate.PublicKey().Bytes()
+	shared, err := serverPrivate.ECDH(userAgentPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), userAgentKey...)
+	keyInfo = append(keyInfo, serverPublic...)
+	ikm := hkdfExpand(hkdfExtract(authSecret, shared), keyInfo, 32)
+	prk := hkdfExtract(salt, ikm)
+	contentKey := hkdfExpand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfExpand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	record := gcm.Seal(nil, nonce, append(payload, 0x02), nil)
+
+	header := make([]byte, 0, 16+4+1+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, 4096)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+	return append(header, record...), nil
+}
+
+func hkdfExtract(salt []byte, secret []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
+
+// hkdfExpand only needs one block, since every key here is at most 32 bytes.
+func hkdfExpand(prk []byte, info []byte, length int) []byte {
+	mac := hmac.New(sha256.New, prk)
+	mac.Write(info)
+	mac.Write([]byte{0x01})
+	return mac.Sum(nil)[:length]
+}
+
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(value), "="))
+}
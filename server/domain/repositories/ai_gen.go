@@ -16,53 +16,72 @@ import (
 )
 type aiGenRepository struct {
 	SupabaseClient *datasources.SupabaseREST
-	GeminiClient   *aimodel.GeminiRest
+	Models         aimodel.IModelRouter
 }
 
 type IAiGenRepository interface {
-	GenerateLifeGoal(prompt string) (string, error)
-	InsertGoal(data entities.GeneratedPlanResponse) error
+	GenerateLifeGoal(prompt string) (string, entities.LLMUsage, error)
+	InsertGoal(data entities.GeneratedPlanResponse) (*entities.GeneratedPlan, error)
 	GetAllGenGoal() (*[]entities.GeneratedPlan,error)
 	GetGenGoalByUserID(id string) (*[]entities.GeneratedPlan,error)
-	GenerateAiAssitant(prompt string) (string, error)
+	GenerateAiAssitant(prompt string) (string, entities.LLMUsage, error)
 	InsertChat(data entities.AIChatResponse) error
 	// InsertGenMessage(data entities.AIChatResponse) error 
 	GetGenAiChatByUserID(id string) (*[]entities.AIChat, error)
-	GenerateAiChat(history []entities.AIChat,prompt string) (string, error)
+	GenerateAiChat(systemInstruction string, history []entities.AIChat,prompt string, tools []aimodel.Tool, execute aimodel.ToolExecutor) (string, entities.LLMUsage, error)
+	GenerateChatSummary(prompt string) (string, entities.LLMUsage, error)
+	GenerateWeeklyReview(prompt string) (string, entities.LLMUsage, error)
+	GenerateNoteAnalysis(prompt string) (string, entities.LLMUsage, error)
+	GetChatSummaryByUserID(id string) (*entities.AIChatSummary, error)
+	UpsertChatSummary(data entities.AIChatSummaryResponse) error
 	DeleteChat(id string) error
 	DeleteGoal(id string) error
 	GetGenGoalByID(id string) (*entities.GeneratedPlan,error)
+	GetPlanLineage(rootID string) (*[]entities.GeneratedPlan, error)
+	// DeletePlanRevisions deletes every revision of the root plan, leaving the
+	// root itself.
+	DeletePlanRevisions(rootID string) error
+	// ReparentPlans moves the revisions made from parentID onto newParentID.
+	ReparentPlans(parentID string, newParentID string) error
 }
 
-func NewAiGenRepository(client *datasources.SupabaseREST, geminiClient *aimodel.GeminiRest) IAiGenRepository {
+func NewAiGenRepository(client *datasources.SupabaseREST, models aimodel.IModelRouter) IAiGenRepository {
 	return &aiGenRepository{
 		SupabaseClient: client,
-		GeminiClient:   geminiClient,
+		Models:         models,
 	}
 }
 
-func (repo *aiGenRepository) GenerateLifeGoal(prompt string) (string, error) {
+func (repo *aiGenRepository) GenerateLifeGoal(prompt string) (string, entities.LLMUsage, error) {
 	if prompt == "" {
-		return "", fmt.Errorf("prompt cannot be empty")
+		return "", entities.LLMUsage{}, fmt.Errorf("prompt cannot be empty")
 	}
 
-	response, err := repo.GeminiClient.GenerateText(prompt)
+	response, usage, err := repo.Models.For(aimodel.FeaturePlan).GenerateText(prompt)
 	if err != nil {
 		fiberlog.Errorf("AiGenRepository -> GenerateLifeGoal: %s \n", err)
 		fmt.Println("Error generating life goal:", err)
-		return "", err
+		return "", usage, err
 	}
-	return response, nil
+	return response, usage, nil
 }
 
-func (repo *aiGenRepository) InsertGoal(data entities.GeneratedPlanResponse) error {
-	_, err := repo.SupabaseClient.Query("goals", http.MethodPost, "", data)
+func (repo *aiGenRepository) InsertGoal(data entities.GeneratedPlanResponse) (*entities.GeneratedPlan, error) {
+	respond, err := repo.SupabaseClient.Query("goals", http.MethodPost, "", data)
 	if err != nil {
 		fiberlog.Errorf("AiGenRepository -> InsertGoal: %s \n", err)
 		fmt.Println("Error inserting life goal:", err)
-		return err
+		return nil, err
 	}
-	return nil
+	var plans []entities.GeneratedPlan
+	if err = json.Unmarshal(respond, &plans); err != nil {
+		fiberlog.Errorf("AiGenRepository -> InsertGoal: %s \n", err)
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("generated plan was not returned after insert")
+	}
+	return &plans[0], nil
 }
 
 
@@ -99,18 +118,18 @@ func (repo *aiGenRepository) GetGenGoalByUserID(id string) (*[]entities.Generate
 	}
 	return &data , nil 
 }
-func (repo *aiGenRepository) GenerateAiAssitant(prompt string) (string, error) {
+func (repo *aiGenRepository) GenerateAiAssitant(prompt string) (string, entities.LLMUsage, error) {
 	if prompt == "" {
-		return "", fmt.Errorf("prompt cannot be empty")
+		return "", entities.LLMUsage{}, fmt.Errorf("prompt cannot be empty")
 	}
 
-	response, err := repo.GeminiClient.GenerateText(prompt)
+	response, usage, err := repo.Models.For(aimodel.FeatureChat).GenerateText(prompt)
 	if err != nil {
 		fiberlog.Errorf("AiGenRepository -> GenerateLifeGoal: %s \n", err)
 		fmt.Println("Error generating life goal:", err)
-		return "", err
+		return "", usage, err
 	}
-	return response, nil
+	return response, usage, nil
 }
 
 func (repo *aiGenRepository) InsertChat(data entities.AIChatResponse) error {
@@ -135,7 +154,7 @@ func (repo *aiGenRepository) InsertChat(data entities.AIChatResponse) error {
 // }
 
 func (repo *aiGenRepository) GetGenAiChatByUserID(id string) (*[]entities.AIChat,error){
-	queryParams := fmt.Sprintf("?user_id=eq.%s", id)
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=created_at.asc", id)
 	respond, err := repo.SupabaseClient.Query("ai_chats", http.MethodGet, queryParams, nil)
 	if err != nil {
 		fiberlog.Errorf("AiGenRepository -> GetGenGoalByUserID: %s \n", err)
@@ -153,21 +172,94 @@ func (repo *aiGenRepository) GetGenAiChatByUserID(id string) (*[]entities.AIChat
 	return &data , nil 
 }
 
-func (repo *aiGenRepository) GenerateAiChat(history []entities.AIChat,prompt string) (string, error) {
+func (repo *aiGenRepository) GenerateAiChat(systemInstruction string, history []entities.AIChat,prompt string, tools []aimodel.Tool, execute aimodel.ToolExecutor) (string, entities.LLMUsage, error) {
 	if prompt == "" {
-		return "", fmt.Errorf("prompt cannot be empty")
+		return "", entities.LLMUsage{}, fmt.Errorf("prompt cannot be empty")
 	}
 
-	response, err := repo.GeminiClient.AIChat(history,prompt)
+	response, usage, err := repo.Models.For(aimodel.FeatureChat).AIChat(systemInstruction, history,prompt, tools, execute)
 	if err != nil {
 		fiberlog.Errorf("AiGenRepository -> GenerateLifeGoal: %s \n", err)
 		fmt.Println("Error generating life goal:", err)
-		return "", err
+		return "", usage, err
 	}
-	return response, nil
+	return response, usage, nil
 }
 
 
+func (repo *aiGenRepository) GenerateChatSummary(prompt string) (string, entities.LLMUsage, error) {
+	if prompt == "" {
+		return "", entities.LLMUsage{}, fmt.Errorf("prompt cannot be empty")
+	}
+
+	response, usage, err := repo.Models.For(aimodel.FeatureSummary).GenerateText(prompt)
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> GenerateChatSummary: %s \n", err)
+		fmt.Println("Error generating chat summary:", err)
+		return "", usage, err
+	}
+	return response, usage, nil
+}
+
+func (repo *aiGenRepository) GenerateWeeklyReview(prompt string) (string, entities.LLMUsage, error) {
+	if prompt == "" {
+		return "", entities.LLMUsage{}, fmt.Errorf("prompt cannot be empty")
+	}
+
+	response, usage, err := repo.Models.For(aimodel.FeatureReview).GenerateText(prompt)
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> GenerateWeeklyReview: %s \n", err)
+		fmt.Println("Error generating weekly review:", err)
+		return "", usage, err
+	}
+	return response, usage, nil
+}
+
+func (repo *aiGenRepository) GenerateNoteAnalysis(prompt string) (string, entities.LLMUsage, error) {
+	if prompt == "" {
+		return "", entities.LLMUsage{}, fmt.Errorf("prompt cannot be empty")
+	}
+
+	response, usage, err := repo.Models.For(aimodel.FeatureNotes).GenerateText(prompt)
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> GenerateNoteAnalysis: %s \n", err)
+		fmt.Println("Error analysing mood note:", err)
+		return "", usage, err
+	}
+	return response, usage, nil
+}
+
+// GetChatSummaryByUserID returns nil without an error when the user has no
+// summary yet, which is the normal state for short conversations.
+func (repo *aiGenRepository) GetChatSummaryByUserID(id string) (*entities.AIChatSummary, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("ai_chat_summaries", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> GetChatSummaryByUserID: %s \n", err)
+		fmt.Println("Error fetching chat summary:", err)
+		return nil, err
+	}
+	var data []entities.AIChatSummary
+	if err = json.Unmarshal(respond, &data); err != nil {
+		fiberlog.Errorf("AiGenRepository -> GetChatSummaryByUserID: %s \n", err)
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return &data[0], nil
+}
+
+func (repo *aiGenRepository) UpsertChatSummary(data entities.AIChatSummaryResponse) error {
+	_, err := repo.SupabaseClient.Query("ai_chat_summaries", http.MethodPost, "?on_conflict=user_id", data)
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> UpsertChatSummary: %s \n", err)
+		fmt.Println("Error saving chat summary:", err)
+		return err
+	}
+	return nil
+}
+
 func (repo *aiGenRepository) DeleteChat(id string) error {
 	queryParams := fmt.Sprintf("?user_id=eq.%s", id)
 	_, err := repo.SupabaseClient.Query("ai_chats", http.MethodDelete, queryParams, nil)
@@ -176,6 +268,12 @@ func (repo *aiGenRepository) DeleteChat(id string) error {
 		fmt.Println("Error Deleteting life goal:", err)
 		return err
 	}
+	_, err = repo.SupabaseClient.Query("ai_chat_summaries", http.MethodDelete, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> DeleteChat: %s \n", err)
+		fmt.Println("Error deleting chat summary:", err)
+		return err
+	}
 	return nil
 }
 
@@ -206,4 +304,44 @@ func (repo *aiGenRepository) GetGenGoalByID(id string) (*entities.GeneratedPlan,
 		return nil, fmt.Errorf("goal with ID %s not found", id)
 	}
 	return &data[0] , nil 
-}
\ No newline at end of file
+}
+
+// GetPlanLineage returns the root plan and every revision of it, oldest
+// version first. Root plans have no root_id of their own.
+func (repo *aiGenRepository) GetPlanLineage(rootID string) (*[]entities.GeneratedPlan, error) {
+	queryParams := fmt.Sprintf("?or=(id.eq.%s,root_id.eq.%s)&order=version.asc,created_at.asc", rootID, rootID)
+	respond, err := repo.SupabaseClient.Query("goals", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> GetPlanLineage: %s \n", err)
+		fmt.Println("Error fetching plan lineage:", err)
+		return nil, err
+	}
+	var data []entities.GeneratedPlan
+	if err = json.Unmarshal(respond, &data); err != nil {
+		fiberlog.Errorf("AiGenRepository -> GetPlanLineage: %s \n", err)
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (repo *aiGenRepository) DeletePlanRevisions(rootID string) error {
+	queryParams := fmt.Sprintf("?root_id=eq.%s", rootID)
+	_, err := repo.SupabaseClient.Query("goals", http.MethodDelete, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> DeletePlanRevisions: %s \n", err)
+		fmt.Println("Error deleting plan revisions:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *aiGenRepository) ReparentPlans(parentID string, newParentID string) error {
+	queryParams := fmt.Sprintf("?parent_id=eq.%s", parentID)
+	_, err := repo.SupabaseClient.Query("goals", http.MethodPatch, queryParams, map[string]string{"parent_id": newParentID})
+	if err != nil {
+		fiberlog.Errorf("AiGenRepository -> ReparentPlans: %s \n", err)
+		fmt.Println("Error reparenting plan revisions:", err)
+		return err
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ type IAipromptRepository interface {
 	InsertAIPrompt(data entities.AiPromptResponse) error
 	GetPromptByUserID(id string)(*entities.AiPromptModel,error)
 	DeletePromptByID(id string) error
+	GetPromptByID(id string) (*entities.AiPromptModel, error)
 }
 
 func NewAiPromptRepository(client *datasources.SupabaseREST) IAipromptRepository {
@@ -39,7 +40,8 @@ func (repo *aiPromptRepository) InsertAIPrompt(data entities.AiPromptResponse) e
 }
 
 func (repo *aiPromptRepository) GetPromptByUserID(id string)(*entities.AiPromptModel,error) {
-	queryParams := fmt.Sprintf("?user_id=eq.%s", id)
+	// Plans are generated from the user's latest prompt.
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=created_at.desc&limit=1", id)
 	respond, err := repo.SupabaseClient.Query("ai_prompt", http.MethodGet, queryParams, nil)
 	if err != nil {
 		fmt.Println("Error fetching AI prompt:", err)
@@ -50,6 +52,9 @@ func (repo *aiPromptRepository) GetPromptByUserID(id string)(*entities.AiPromptM
 		fmt.Println("Error unmarshalling AI prompt:", err)
 		return nil, err
 	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no AI prompt found for user ID: %s", id)
+	}
 	return &data[0], nil
 }
 func (repo *aiPromptRepository) DeletePromptByID(id string) error {
@@ -60,4 +65,22 @@ func (repo *aiPromptRepository) DeletePromptByID(id string) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (repo *aiPromptRepository) GetPromptByID(id string) (*entities.AiPromptModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("ai_prompt", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fmt.Println("Error fetching AI prompt:", err)
+		return nil, err
+	}
+	var data []entities.AiPromptModel
+	if err := json.Unmarshal(respond, &data); err != nil {
+		fmt.Println("Error unmarshalling AI prompt:", err)
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("AI prompt with ID %s not found", id)
+	}
+	return &data[0], nil
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+	"net/url"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type assistantActionRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type IAssistantActionRepository interface {
+	InsertAction(data entities.AssistantActionResponse) (*entities.AssistantActionModel, error)
+	GetActionsByUserID(userID string, status string) (*[]entities.AssistantActionModel, error)
+	GetActionByID(id string) (*entities.AssistantActionModel, error)
+	// UpdateActionStatus only updates the row while it is still in
+	// fromStatus and reports whether it did, so two confirmations of the same
+	// action cannot both run it.
+	UpdateActionStatus(id string, fromStatus string, data entities.AssistantActionStatusUpdate) (bool, error)
+}
+
+func NewAssistantActionRepository(client *datasources.SupabaseREST) IAssistantActionRepository {
+	return &assistantActionRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *assistantActionRepository) InsertAction(data entities.AssistantActionResponse) (*entities.AssistantActionModel, error) {
+	respond, err := repo.SupabaseClient.Query("assistant_actions", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("AssistantActionRepository -> InsertAction: %s \n", err)
+		fmt.Println("Error inserting assistant action:", err)
+		return nil, err
+	}
+	var actions []entities.AssistantActionModel
+	if err := json.Unmarshal(respond, &actions); err != nil {
+		fiberlog.Errorf("AssistantActionRepository -> InsertAction: %s \n", err)
+		return nil, err
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("assistant action was not returned after insert")
+	}
+	return &actions[0], nil
+}
+
+func (repo *assistantActionRepository) GetActionsByUserID(userID string, status string) (*[]entities.AssistantActionModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=created_at.desc", userID)
+	if status != "" {
+		queryParams += fmt.Sprintf("&status=eq.%s", url.QueryEscape(status))
+	}
+	respond, err := repo.SupabaseClient.Query("assistant_actions", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("AssistantActionRepository -> GetActionsByUserID: %s \n", err)
+		fmt.Println("Error fetching assistant actions:", err)
+		return nil, err
+	}
+	var actions []entities.AssistantActionModel
+	if err := json.Unmarshal(respond, &actions); err != nil {
+		fiberlog.Errorf("AssistantActionRepository -> GetActionsByUserID: %s \n", err)
+		return nil, err
+	}
+	return &actions, nil
+}
+
+func (repo *assistantActionRepository) GetActionByID(id string) (*entities.AssistantActionModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("assistant_actions", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("AssistantActionRepository -> GetActionByID: %s \n", err)
+		fmt.Println("Error fetching assistant action:", err)
+		return nil, err
+	}
+	var actions []entities.AssistantActionModel
+	if err := json.Unmarshal(respond, &actions); err != nil {
+		fiberlog.Errorf("AssistantActionRepository -> GetActionByID: %s \n", err)
+		return nil, err
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("assistant action with ID %s not found", id)
+	}
+	return &actions[0], nil
+}
+
+func (repo *assistantActionRepository) UpdateActionStatus(id string, fromStatus string, data entities.AssistantActionStatusUpdate) (bool, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s&status=eq.%s", id, fromStatus)
+	respond, err := repo.SupabaseClient.Query("assistant_actions", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("AssistantActionRepository -> UpdateActionStatus: %s \n", err)
+		fmt.Println("Error updating assistant action:", err)
+		return false, err
+	}
+	var actions []entities.AssistantActionModel
+	if err := json.Unmarshal(respond, &actions); err != nil {
+		fiberlog.Errorf("AssistantActionRepository -> UpdateActionStatus: %s \n", err)
+		return false, err
+	}
+	return len(actions) > 0, nil
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type budgetRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type IBudgetRepository interface {
+	InsertBudget(data entities.BudgetResponse) (*entities.BudgetModel, error)
+	GetBudgetByID(id string) (*entities.BudgetModel, error)
+	GetBudgetsByUserID(userID string) (*[]entities.BudgetModel, error)
+	// GetAlertingBudgets returns the budgets with at least one alert channel.
+	GetAlertingBudgets() (*[]entities.BudgetModel, error)
+	UpdateBudget(id string, data entities.BudgetUpdate) (*entities.BudgetModel, error)
+	// ClaimBudgetAlert only updates the row while it has not been alerted on
+	// at data.AlertLevel or above in data.AlertMonth, and reports whether it
+	// did, so an alert is sent once even with several servers.
+	ClaimBudgetAlert(id string, data entities.BudgetAlertUpdate) (bool, error)
+	// RecordBudgetAlert stores the outcome of a claimed alert.
+	RecordBudgetAlert(id string, data entities.BudgetAlertResult) error
+	DeleteBudget(id string) error
+}
+
+func NewBudgetRepository(client *datasources.SupabaseREST) IBudgetRepository {
+	return &budgetRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *budgetRepository) InsertBudget(data entities.BudgetResponse) (*entities.BudgetModel, error) {
+	respond, err := repo.SupabaseClient.Query("budgets", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("BudgetRepository -> InsertBudget: %s \n", err)
+		fmt.Println("Error inserting budget:", err)
+		return nil, err
+	}
+	var budgets []entities.BudgetModel
+	if err := json.Unmarshal(respond, &budgets); err != nil {
+		fiberlog.Errorf("BudgetRepository -> InsertBudget: %s \n", err)
+		return nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, fmt.Errorf("budget was not returned after insert")
+	}
+	return &budgets[0], nil
+}
+
+func (repo *budgetRepository) GetBudgetByID(id string) (*entities.BudgetModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("budgets", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("BudgetRepository -> GetBudgetByID: %s \n", err)
+		fmt.Println("Error fetching budget:", err)
+		return nil, err
+	}
+	var budgets []entities.BudgetModel
+	if err := json.Unmarshal(respond, &budgets); err != nil {
+		fiberlog.Errorf("BudgetRepository -> GetBudgetByID: %s \n", err)
+		return nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, fmt.Errorf("budget with ID %s not found", id)
+	}
+	return &budgets[0], nil
+}
+
+func (repo *budgetRepository) GetBudgetsByUserID(userID string) (*[]entities.BudgetModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=category.asc", userID)
+	respond, err := repo.SupabaseClient.Query("budgets", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("BudgetRepository -> GetBudgetsByUserID: %s \n", err)
+		fmt.Println("Error fetching budgets:", err)
+		return nil, err
+	}
+	budgets := []entities.BudgetModel{}
+	if err := json.Unmarshal(respond, &budgets); err != nil {
+		fiberlog.Errorf("BudgetRepository -> GetBudgetsByUserID: %s \n", err)
+		return nil, err
+	}
+	return &budgets, nil
+}
+
+func (repo *budgetRepository) GetAlertingBudgets() (*[]entities.BudgetModel, error) {
+	respond, err := repo.SupabaseClient.Query("budgets", http.MethodGet, "?channels=neq.{}&order=user_id.asc", nil)
+	if err != nil {
+		fiberlog.Errorf("BudgetRepository -> GetAlertingBudgets: %s \n", err)
+		fmt.Println("Error fetching alerting budgets:", err)
+		return nil, err
+	}
+	budgets := []entities.BudgetModel{}
+	if err := json.Unmarshal(respond, &budgets); err != nil {
+		fiberlog.Errorf("BudgetRepository -> GetAlertingBudgets: %s \n", err)
+		return nil, err
+	}
+	return &budgets, nil
+}
+
+func (repo *budgetRepository) UpdateBudget(id string, data entities.BudgetUpdate) (*entities.BudgetModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("budgets", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("BudgetRepository -> UpdateBudget: %s \n", err)
+		fmt.Println("Error updating budget:", err)
+		return nil, err
+	}
+	var budgets []entities.BudgetModel
+	if err := json.Unmarshal(respond, &budgets); err != nil {
+		fiberlog.Errorf("BudgetRepository -> UpdateBudget: %s \n", err)
+		return nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, fmt.Errorf("budget with ID %s not found", id)
+	}
+	return &budgets[0], nil
+}
+
+func (repo *budgetRepository) ClaimBudgetAlert(id string, data entities.BudgetAlertUpdate) (bool, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s&or=(alert_month.is.null,alert_month.neq.%s,alert_level.lt.%d)", id, data.AlertMonth, data.AlertLevel)
+	respond, err := repo.SupabaseClient.Query("budgets", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("BudgetRepository -> ClaimBudgetAlert: %s \n", err)
+		fmt.Println("Error claiming budget alert:", err)
+		return false, err
+	}
+	var budgets []entities.BudgetModel
+	if err := json.Unmarshal(respond, &budgets); err != nil {
+		fiberlog.Errorf("BudgetRepository -> ClaimBudgetAlert: %s \n", err)
+		return false, err
+	}
+	return len(budgets) > 0, nil
+}
+
+func (repo *budgetRepository) RecordBudgetAlert(id string, data entities.BudgetAlertResult) error {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("budgets", http.MethodPatch, queryParams, data); err != nil {
+		fiberlog.Errorf("BudgetRepository -> RecordBudgetAlert: %s \n", err)
+		fmt.Println("Error recording budget alert:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *budgetRepository) DeleteBudget(id string) error {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("budgets", http.MethodDelete, queryParams, nil); err != nil {
+		fiberlog.Errorf("BudgetRepository -> DeleteBudget: %s \n", err)
+		fmt.Println("Error deleting budget:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+const exchangeRatePage = 1000
+
+type exchangeRateRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type IExchangeRateRepository interface {
+	// UpsertRates inserts rates, replacing any stored for the same base,
+	// quote and date.
+	UpsertRates(data []entities.ExchangeRateResponse) (*[]entities.ExchangeRateModel, error)
+	GetAllRates() (*[]entities.ExchangeRateModel, error)
+}
+
+func NewExchangeRateRepository(client *datasources.SupabaseREST) IExchangeRateRepository {
+	return &exchangeRateRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *exchangeRateRepository) UpsertRates(data []entities.ExchangeRateResponse) (*[]entities.ExchangeRateModel, error) {
+	respond, err := repo.SupabaseClient.Query("exchange_rates", http.MethodPost, "?on_conflict=base,quote,date", data)
+	if err != nil {
+		fiberlog.Errorf("ExchangeRateRepository -> UpsertRates: %s \n", err)
+		fmt.Println("Error upserting exchange rates:", err)
+		return nil, err
+	}
+	rates := []entities.ExchangeRateModel{}
+	if err := json.Unmarshal(respond, &rates); err != nil {
+		fiberlog.Errorf("ExchangeRateRepository -> UpsertRates: %s \n", err)
+		return nil, err
+	}
+	return &rates, nil
+}
+
+// GetAllRates pages through the table, since PostgREST caps how many rows
+// one request returns.
+func (repo *exchangeRateRepository) GetAllRates() (*[]entities.ExchangeRateModel, error) {
+	rates := []entities.ExchangeRateModel{}
+	for offset := 0; ; offset += exchangeRatePage {
+		queryParams := fmt.Sprintf("?order=date.asc,base.asc,quote.asc&limit=%d&offset=%d", exchangeRatePage, offset)
+		respond, err := repo.SupabaseClient.Query("exchange_rates", http.MethodGet, queryParams, nil)
+		if err != nil {
+			fiberlog.Errorf("ExchangeRateRepository -> GetAllRates: %s \n", err)
+			fmt.Println("Error fetching exchange rates:", err)
+			return nil, err
+		}
+		var page []entities.ExchangeRateModel
+		if err := json.Unmarshal(respond, &page); err != nil {
+			fiberlog.Errorf("ExchangeRateRepository -> GetAllRates: %s \n", err)
+			return nil, err
+		}
+		rates = append(rates, page...)
+		if len(page) < exchangeRatePage {
+			return &rates, nil
+		}
+	}
+}
@@ -16,6 +16,16 @@ type HabitRepository struct {
 type IHabitRepository interface {
 	CreateHabit(habit *entities.HabitResponse) error
 	GetHabitsByUserID(userId string) (*[]entities.HabitModel, error)
+	GetHabitByID(id int) (*entities.HabitModel, error)
+	UpdateHabit(id int, data entities.HabitUpdate) error
+	UpdateHabitStatus(id int, data entities.HabitStatusUpdate) error
+	UpdateHabitProgress(id int, data entities.HabitProgressUpdate) error
+	DeleteHabit(id int) error
+	InsertCheckIn(data entities.HabitCheckInResponse) (*entities.HabitCheckInModel, error)
+	GetCheckInsByHabitID(habitID int) (*[]entities.HabitCheckInModel, error)
+	GetCheckInsByUserID(userID string) (*[]entities.HabitCheckInModel, error)
+	DeleteCheckIn(id int) error
+	DeleteCheckInsByHabitID(habitID int) error
 }
 
 func NewHabitRepository(supabaseREST *datasources.SupabaseREST) *HabitRepository {
@@ -48,8 +58,133 @@ func (repo *HabitRepository) GetHabitsByUserID(userId string) (*[]entities.Habit
 		fmt.Println("Error unmarshalling habits:", err)
 		return nil, err
 	}
+	return &habits, nil
+}
+
+func (repo *HabitRepository) GetHabitByID(id int) (*entities.HabitModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%d", id)
+	respond, err := repo.SupabaseREST.Query("habits", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> GetHabitByID: %s \n", err)
+		fmt.Println("Error fetching habit:", err)
+		return nil, err
+	}
+	var habits []entities.HabitModel
+	if err := json.Unmarshal(respond, &habits); err != nil {
+		fiberlog.Errorf("HabitRepository -> GetHabitByID: %s \n", err)
+		return nil, err
+	}
 	if len(habits) == 0 {
-		return nil, fmt.Errorf("no habits found for user ID: %s", userId)
+		return nil, fmt.Errorf("habit with ID %d not found", id)
 	}
-	return &habits, nil
-}
\ No newline at end of file
+	return &habits[0], nil
+}
+
+func (repo *HabitRepository) UpdateHabit(id int, data entities.HabitUpdate) error {
+	queryParams := fmt.Sprintf("?id=eq.%d", id)
+	_, err := repo.SupabaseREST.Query("habits", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> UpdateHabit: %s \n", err)
+		fmt.Println("Error updating habit:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *HabitRepository) UpdateHabitStatus(id int, data entities.HabitStatusUpdate) error {
+	queryParams := fmt.Sprintf("?id=eq.%d", id)
+	_, err := repo.SupabaseREST.Query("habits", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> UpdateHabitStatus: %s \n", err)
+		fmt.Println("Error updating habit status:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *HabitRepository) DeleteHabit(id int) error {
+	queryParams := fmt.Sprintf("?id=eq.%d", id)
+	_, err := repo.SupabaseREST.Query("habits", http.MethodDelete, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> DeleteHabit: %s \n", err)
+		fmt.Println("Error deleting habit:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *HabitRepository) UpdateHabitProgress(id int, data entities.HabitProgressUpdate) error {
+	queryParams := fmt.Sprintf("?id=eq.%d", id)
+	_, err := repo.SupabaseREST.Query("habits", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> UpdateHabitProgress: %s \n", err)
+		fmt.Println("Error updating habit progress:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *HabitRepository) InsertCheckIn(data entities.HabitCheckInResponse) (*entities.HabitCheckInModel, error) {
+	respond, err := repo.SupabaseREST.Query("habit_checkins", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> InsertCheckIn: %s \n", err)
+		fmt.Println("Error inserting habit check-in:", err)
+		return nil, err
+	}
+	var checkIns []entities.HabitCheckInModel
+	if err := json.Unmarshal(respond, &checkIns); err != nil {
+		fiberlog.Errorf("HabitRepository -> InsertCheckIn: %s \n", err)
+		return nil, err
+	}
+	if len(checkIns) == 0 {
+		return nil, fmt.Errorf("habit check-in was not returned after insert")
+	}
+	return &checkIns[0], nil
+}
+
+func (repo *HabitRepository) GetCheckInsByHabitID(habitID int) (*[]entities.HabitCheckInModel, error) {
+	queryParams := fmt.Sprintf("?habit_id=eq.%d&order=date.asc,created_at.asc", habitID)
+	return repo.getCheckIns("GetCheckInsByHabitID", queryParams)
+}
+
+func (repo *HabitRepository) GetCheckInsByUserID(userID string) (*[]entities.HabitCheckInModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=date.asc,created_at.asc", userID)
+	return repo.getCheckIns("GetCheckInsByUserID", queryParams)
+}
+
+func (repo *HabitRepository) getCheckIns(caller string, queryParams string) (*[]entities.HabitCheckInModel, error) {
+	respond, err := repo.SupabaseREST.Query("habit_checkins", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> %s: %s \n", caller, err)
+		fmt.Println("Error fetching habit check-ins:", err)
+		return nil, err
+	}
+	var checkIns []entities.HabitCheckInModel
+	if err := json.Unmarshal(respond, &checkIns); err != nil {
+		fiberlog.Errorf("HabitRepository -> %s: %s \n", caller, err)
+		return nil, err
+	}
+	return &checkIns, nil
+}
+
+func (repo *HabitRepository) DeleteCheckIn(id int) error {
+	queryParams := fmt.Sprintf("?id=eq.%d", id)
+	_, err := repo.SupabaseREST.Query("habit_checkins", http.MethodDelete, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> DeleteCheckIn: %s \n", err)
+		fmt.Println("Error deleting habit check-in:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *HabitRepository) DeleteCheckInsByHabitID(habitID int) error {
+	queryParams := fmt.Sprintf("?habit_id=eq.%d", habitID)
+	_, err := repo.SupabaseREST.Query("habit_checkins", http.MethodDelete, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("HabitRepository -> DeleteCheckInsByHabitID: %s \n", err)
+		fmt.Println("Error deleting habit check-ins:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// usageTimeLayout matches how the rest of the app stores created_at: local
+// Bangkok wall time without a zone suffix, so it is safe inside a query string.
+const usageTimeLayout = "2006-01-02T15:04:05"
+
+type llmUsageRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type ILLMUsageRepository interface {
+	InsertUsage(data entities.LLMUsageResponse) error
+	GetUsageByUserID(userID string, from time.Time, to time.Time) (*[]entities.LLMUsageModel, error)
+	GetUsageBetween(from time.Time, to time.Time) (*[]entities.LLMUsageModel, error)
+}
+
+func NewLLMUsageRepository(client *datasources.SupabaseREST) ILLMUsageRepository {
+	return &llmUsageRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *llmUsageRepository) InsertUsage(data entities.LLMUsageResponse) error {
+	_, err := repo.SupabaseClient.Query("llm_usage", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("LLMUsageRepository -> InsertUsage: %s \n", err)
+		fmt.Println("Error inserting llm usage:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *llmUsageRepository) GetUsageByUserID(userID string, from time.Time, to time.Time) (*[]entities.LLMUsageModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&created_at=gte.%s&created_at=lt.%s&order=created_at.asc", userID, from.Format(usageTimeLayout), to.Format(usageTimeLayout))
+	return repo.queryUsage(queryParams)
+}
+
+func (repo *llmUsageRepository) GetUsageBetween(from time.Time, to time.Time) (*[]entities.LLMUsageModel, error) {
+	queryParams := fmt.Sprintf("?created_at=gte.%s&created_at=lt.%s&order=created_at.asc", from.Format(usageTimeLayout), to.Format(usageTimeLayout))
+	return repo.queryUsage(queryParams)
+}
+
+func (repo *llmUsageRepository) queryUsage(queryParams string) (*[]entities.LLMUsageModel, error) {
+	respond, err := repo.SupabaseClient.Query("llm_usage", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("LLMUsageRepository -> queryUsage: %s \n", err)
+		fmt.Println("Error fetching llm usage:", err)
+		return nil, err
+	}
+	var data []entities.LLMUsageModel
+	if err := json.Unmarshal(respond, &data); err != nil {
+		fiberlog.Errorf("LLMUsageRepository -> queryUsage: %s \n", err)
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+	"strings"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type planJobRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type IPlanJobRepository interface {
+	InsertJob(data entities.PlanJobResponse) (*entities.PlanJobModel, error)
+	GetJobByID(id string) (*entities.PlanJobModel, error)
+	GetJobsByUserID(userID string) (*[]entities.PlanJobModel, error)
+	// GetJobsByStatus returns jobs in any of statuses whose next_run_at is not
+	// after dueBefore, oldest first.
+	GetJobsByStatus(statuses []string, dueBefore time.Time) (*[]entities.PlanJobModel, error)
+	// UpdateJob only updates the row while it is still in fromStatus and
+	// reports whether it did, so two workers cannot claim the same job.
+	UpdateJob(id string, fromStatus string, data entities.PlanJobUpdate) (bool, error)
+}
+
+func NewPlanJobRepository(client *datasources.SupabaseREST) IPlanJobRepository {
+	return &planJobRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *planJobRepository) InsertJob(data entities.PlanJobResponse) (*entities.PlanJobModel, error) {
+	respond, err := repo.SupabaseClient.Query("plan_jobs", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("PlanJobRepository -> InsertJob: %s \n", err)
+		fmt.Println("Error inserting plan job:", err)
+		return nil, err
+	}
+	var jobs []entities.PlanJobModel
+	if err := json.Unmarshal(respond, &jobs); err != nil {
+		fiberlog.Errorf("PlanJobRepository -> InsertJob: %s \n", err)
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("plan job was not returned after insert")
+	}
+	return &jobs[0], nil
+}
+
+func (repo *planJobRepository) GetJobByID(id string) (*entities.PlanJobModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("plan_jobs", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("PlanJobRepository -> GetJobByID: %s \n", err)
+		fmt.Println("Error fetching plan job:", err)
+		return nil, err
+	}
+	var jobs []entities.PlanJobModel
+	if err := json.Unmarshal(respond, &jobs); err != nil {
+		fiberlog.Errorf("PlanJobRepository -> GetJobByID: %s \n", err)
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("plan job with ID %s not found", id)
+	}
+	return &jobs[0], nil
+}
+
+func (repo *planJobRepository) GetJobsByUserID(userID string) (*[]entities.PlanJobModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=created_at.desc", userID)
+	respond, err := repo.SupabaseClient.Query("plan_jobs", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("PlanJobRepository -> GetJobsByUserID: %s \n", err)
+		fmt.Println("Error fetching plan jobs:", err)
+		return nil, err
+	}
+	var jobs []entities.PlanJobModel
+	if err := json.Unmarshal(respond, &jobs); err != nil {
+		fiberlog.Errorf("PlanJobRepository -> GetJobsByUserID: %s \n", err)
+		return nil, err
+	}
+	return &jobs, nil
+}
+
+func (repo *planJobRepository) GetJobsByStatus(statuses []string, dueBefore time.Time) (*[]entities.PlanJobModel, error) {
+	queryParams := fmt.Sprintf("?status=in.(%s)&next_run_at=lte.%s&order=created_at.asc", strings.Join(statuses, ","), dueBefore.Format("2006-01-02T15:04:05"))
+	respond, err := repo.SupabaseClient.Query("plan_jobs", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("PlanJobRepository -> GetJobsByStatus: %s \n", err)
+		fmt.Println("Error fetching plan jobs:", err)
+		return nil, err
+	}
+	var jobs []entities.PlanJobModel
+	if err := json.Unmarshal(respond, &jobs); err != nil {
+		fiberlog.Errorf("PlanJobRepository -> GetJobsByStatus: %s \n", err)
+		return nil, err
+	}
+	return &jobs, nil
+}
+
+func (repo *planJobRepository) UpdateJob(id string, fromStatus string, data entities.PlanJobUpdate) (bool, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s&status=eq.%s", id, fromStatus)
+	respond, err := repo.SupabaseClient.Query("plan_jobs", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("PlanJobRepository -> UpdateJob: %s \n", err)
+		fmt.Println("Error updating plan job:", err)
+		return false, err
+	}
+	var jobs []entities.PlanJobModel
+	if err := json.Unmarshal(respond, &jobs); err != nil {
+		fiberlog.Errorf("PlanJobRepository -> UpdateJob: %s \n", err)
+		return false, err
+	}
+	return len(jobs) > 0, nil
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type planTaskRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type IPlanTaskRepository interface {
+	InsertTask(data entities.PlanTaskResponse) (*entities.PlanTaskModel, error)
+	GetTaskByID(id string) (*entities.PlanTaskModel, error)
+	GetTasksByUserID(userID string) (*[]entities.PlanTaskModel, error)
+	GetTasksByPlanID(planID string) (*[]entities.PlanTaskModel, error)
+	UpdateTaskStatus(id string, data entities.PlanTaskStatusUpdate) (*entities.PlanTaskModel, error)
+	DeleteTask(id string) error
+}
+
+func NewPlanTaskRepository(client *datasources.SupabaseREST) IPlanTaskRepository {
+	return &planTaskRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *planTaskRepository) InsertTask(data entities.PlanTaskResponse) (*entities.PlanTaskModel, error) {
+	respond, err := repo.SupabaseClient.Query("plan_tasks", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> InsertTask: %s \n", err)
+		fmt.Println("Error inserting plan task:", err)
+		return nil, err
+	}
+	var tasks []entities.PlanTaskModel
+	if err := json.Unmarshal(respond, &tasks); err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> InsertTask: %s \n", err)
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("plan task was not returned after insert")
+	}
+	return &tasks[0], nil
+}
+
+func (repo *planTaskRepository) GetTaskByID(id string) (*entities.PlanTaskModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("plan_tasks", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> GetTaskByID: %s \n", err)
+		fmt.Println("Error fetching plan task:", err)
+		return nil, err
+	}
+	var tasks []entities.PlanTaskModel
+	if err := json.Unmarshal(respond, &tasks); err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> GetTaskByID: %s \n", err)
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task with ID %s not found", id)
+	}
+	return &tasks[0], nil
+}
+
+func (repo *planTaskRepository) GetTasksByUserID(userID string) (*[]entities.PlanTaskModel, error) {
+	return repo.getTasks("GetTasksByUserID", fmt.Sprintf("?user_id=eq.%s&order=created_at.asc", userID))
+}
+
+func (repo *planTaskRepository) GetTasksByPlanID(planID string) (*[]entities.PlanTaskModel, error) {
+	return repo.getTasks("GetTasksByPlanID", fmt.Sprintf("?plan_id=eq.%s&order=created_at.asc", planID))
+}
+
+func (repo *planTaskRepository) getTasks(caller string, queryParams string) (*[]entities.PlanTaskModel, error) {
+	respond, err := repo.SupabaseClient.Query("plan_tasks", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> %s: %s \n", caller, err)
+		fmt.Println("Error fetching plan tasks:", err)
+		return nil, err
+	}
+	tasks := []entities.PlanTaskModel{}
+	if err := json.Unmarshal(respond, &tasks); err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> %s: %s \n", caller, err)
+		return nil, err
+	}
+	return &tasks, nil
+}
+
+func (repo *planTaskRepository) UpdateTaskStatus(id string, data entities.PlanTaskStatusUpdate) (*entities.PlanTaskModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("plan_tasks", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> UpdateTaskStatus: %s \n", err)
+		fmt.Println("Error updating plan task:", err)
+		return nil, err
+	}
+	var tasks []entities.PlanTaskModel
+	if err := json.Unmarshal(respond, &tasks); err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> UpdateTaskStatus: %s \n", err)
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task with ID %s not found", id)
+	}
+	return &tasks[0], nil
+}
+
+func (repo *planTaskRepository) DeleteTask(id string) error {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("plan_tasks", http.MethodDelete, queryParams, nil); err != nil {
+		fiberlog.Errorf("PlanTaskRepository -> DeleteTask: %s \n", err)
+		fmt.Println("Error deleting plan task:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type reminderRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type IReminderRepository interface {
+	InsertReminder(data entities.ReminderResponse) (*entities.ReminderModel, error)
+	GetReminderByID(id string) (*entities.ReminderModel, error)
+	GetRemindersByUserID(userID string) (*[]entities.ReminderModel, error)
+	// GetDueReminders returns enabled reminders whose next_run_at is not
+	// after dueBefore, oldest first.
+	GetDueReminders(dueBefore time.Time) (*[]entities.ReminderModel, error)
+	UpdateReminder(id string, data entities.ReminderUpdate) (*entities.ReminderModel, error)
+	// ClaimReminder only updates the row while its next_run_at is still not
+	// after dueBefore and reports whether it did. Moving next_run_at forward
+	// is the claim, so two schedulers cannot send the same reminder.
+	ClaimReminder(id string, dueBefore time.Time, data entities.ReminderRunUpdate) (bool, error)
+	// RecordReminderRun stores the outcome of a claimed run.
+	RecordReminderRun(id string, data entities.ReminderResultUpdate) error
+	DeleteReminder(id string) error
+	DeleteRemindersByHabitID(habitID int) error
+}
+
+func NewReminderRepository(client *datasources.SupabaseREST) IReminderRepository {
+	return &reminderRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *reminderRepository) InsertReminder(data entities.ReminderResponse) (*entities.ReminderModel, error) {
+	respond, err := repo.SupabaseClient.Query("reminders", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("ReminderRepository -> InsertReminder: %s \n", err)
+		fmt.Println("Error inserting reminder:", err)
+		return nil, err
+	}
+	var reminders []entities.ReminderModel
+	if err := json.Unmarshal(respond, &reminders); err != nil {
+		fiberlog.Errorf("ReminderRepository -> InsertReminder: %s \n", err)
+		return nil, err
+	}
+	if len(reminders) == 0 {
+		return nil, fmt.Errorf("reminder was not returned after insert")
+	}
+	return &reminders[0], nil
+}
+
+func (repo *reminderRepository) GetReminderByID(id string) (*entities.ReminderModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("reminders", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("ReminderRepository -> GetReminderByID: %s \n", err)
+		fmt.Println("Error fetching reminder:", err)
+		return nil, err
+	}
+	var reminders []entities.ReminderModel
+	if err := json.Unmarshal(respond, &reminders); err != nil {
+		fiberlog.Errorf("ReminderRepository -> GetReminderByID: %s \n", err)
+		return nil, err
+	}
+	if len(reminders) == 0 {
+		return nil, fmt.Errorf("reminder with ID %s not found", id)
+	}
+	return &reminders[0], nil
+}
+
+func (repo *reminderRepository) GetRemindersByUserID(userID string) (*[]entities.ReminderModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=created_at.asc", userID)
+	respond, err := repo.SupabaseClient.Query("reminders", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("ReminderRepository -> GetRemindersByUserID: %s \n", err)
+		fmt.Println("Error fetching reminders:", err)
+		return nil, err
+	}
+	reminders := []entities.ReminderModel{}
+	if err := json.Unmarshal(respond, &reminders); err != nil {
+		fiberlog.Errorf("ReminderRepository -> GetRemindersByUserID: %s \n", err)
+		return nil, err
+	}
+	return &reminders, nil
+}
+
+func (repo *reminderRepository) GetDueReminders(dueBefore time.Time) (*[]entities.ReminderModel, error) {
+	queryParams := fmt.Sprintf("?enabled=eq.true&next_run_at=lte.%s&order=next_run_at.asc", dueBefore.Format("2006-01-02T15:04:05"))
+	respond, err := repo.SupabaseClient.Query("reminders", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("ReminderRepository -> GetDueReminders: %s \n", err)
+		fmt.Println("Error fetching due reminders:", err)
+		return nil, err
+	}
+	var reminders []entities.ReminderModel
+	if err := json.Unmarshal(respond, &reminders); err != nil {
+		fiberlog.Errorf("ReminderRepository -> GetDueReminders: %s \n", err)
+		return nil, err
+	}
+	return &reminders, nil
+}
+
+func (repo *reminderRepository) UpdateReminder(id string, data entities.ReminderUpdate) (*entities.ReminderModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("reminders", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("ReminderRepository -> UpdateReminder: %s \n", err)
+		fmt.Println("Error updating reminder:", err)
+		return nil, err
+	}
+	var reminders []entities.ReminderModel
+	if err := json.Unmarshal(respond, &reminders); err != nil {
+		fiberlog.Errorf("ReminderRepository -> UpdateReminder: %s \n", err)
+		return nil, err
+	}
+	if len(reminders) == 0 {
+		return nil, fmt.Errorf("reminder with ID %s not found", id)
+	}
+	return &reminders[0], nil
+}
+
+func (repo *reminderRepository) ClaimReminder(id string, dueBefore time.Time, data entities.ReminderRunUpdate) (bool, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s&next_run_at=lte.%s", id, dueBefore.Format("2006-01-02T15:04:05"))
+	respond, err := repo.SupabaseClient.Query("reminders", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("ReminderRepository -> ClaimReminder: %s \n", err)
+		fmt.Println("Error claiming reminder:", err)
+		return false, err
+	}
+	var reminders []entities.ReminderModel
+	if err := json.Unmarshal(respond, &reminders); err != nil {
+		fiberlog.Errorf("ReminderRepository -> ClaimReminder: %s \n", err)
+		return false, err
+	}
+	return len(reminders) > 0, nil
+}
+
+func (repo *reminderRepository) RecordReminderRun(id string, data entities.ReminderResultUpdate) error {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("reminders", http.MethodPatch, queryParams, data); err != nil {
+		fiberlog.Errorf("ReminderRepository -> RecordReminderRun: %s \n", err)
+		fmt.Println("Error recording reminder run:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *reminderRepository) DeleteReminder(id string) error {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("reminders", http.MethodDelete, queryParams, nil); err != nil {
+		fiberlog.Errorf("ReminderRepository -> DeleteReminder: %s \n", err)
+		fmt.Println("Error deleting reminder:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *reminderRepository) DeleteRemindersByHabitID(habitID int) error {
+	queryParams := fmt.Sprintf("?habit_id=eq.%d", habitID)
+	if _, err := repo.SupabaseClient.Query("reminders", http.MethodDelete, queryParams, nil); err != nil {
+		fiberlog.Errorf("ReminderRepository -> DeleteRemindersByHabitID: %s \n", err)
+		fmt.Println("Error deleting habit reminders:", err)
+		return err
+	}
+	return nil
+}
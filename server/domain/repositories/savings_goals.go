@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type savingsGoalRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type ISavingsGoalRepository interface {
+	InsertSavingsGoal(data entities.SavingsGoalResponse) (*entities.SavingsGoalModel, error)
+	GetSavingsGoalByID(id string) (*entities.SavingsGoalModel, error)
+	GetSavingsGoalsByUserID(userID string) (*[]entities.SavingsGoalModel, error)
+	UpdateSavingsGoal(id string, data entities.SavingsGoalUpdate) (*entities.SavingsGoalModel, error)
+	// DeleteSavingsGoal deletes the goal with its contributions.
+	DeleteSavingsGoal(id string) error
+	InsertContribution(data entities.SavingsContributionResponse) (*entities.SavingsContributionModel, error)
+	GetContributionByID(id string) (*entities.SavingsContributionModel, error)
+	GetContributionsByGoalID(goalID string) (*[]entities.SavingsContributionModel, error)
+	GetContributionsByUserID(userID string) (*[]entities.SavingsContributionModel, error)
+	DeleteContribution(id string) error
+}
+
+func NewSavingsGoalRepository(client *datasources.SupabaseREST) ISavingsGoalRepository {
+	return &savingsGoalRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *savingsGoalRepository) InsertSavingsGoal(data entities.SavingsGoalResponse) (*entities.SavingsGoalModel, error) {
+	respond, err := repo.SupabaseClient.Query("savings_goals", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> InsertSavingsGoal: %s \n", err)
+		fmt.Println("Error inserting savings goal:", err)
+		return nil, err
+	}
+	var goals []entities.SavingsGoalModel
+	if err := json.Unmarshal(respond, &goals); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> InsertSavingsGoal: %s \n", err)
+		return nil, err
+	}
+	if len(goals) == 0 {
+		return nil, fmt.Errorf("savings goal was not returned after insert")
+	}
+	return &goals[0], nil
+}
+
+func (repo *savingsGoalRepository) GetSavingsGoalByID(id string) (*entities.SavingsGoalModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("savings_goals", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> GetSavingsGoalByID: %s \n", err)
+		fmt.Println("Error fetching savings goal:", err)
+		return nil, err
+	}
+	var goals []entities.SavingsGoalModel
+	if err := json.Unmarshal(respond, &goals); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> GetSavingsGoalByID: %s \n", err)
+		return nil, err
+	}
+	if len(goals) == 0 {
+		return nil, fmt.Errorf("savings goal with ID %s not found", id)
+	}
+	return &goals[0], nil
+}
+
+func (repo *savingsGoalRepository) GetSavingsGoalsByUserID(userID string) (*[]entities.SavingsGoalModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=created_at.asc", userID)
+	respond, err := repo.SupabaseClient.Query("savings_goals", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> GetSavingsGoalsByUserID: %s \n", err)
+		fmt.Println("Error fetching savings goals:", err)
+		return nil, err
+	}
+	goals := []entities.SavingsGoalModel{}
+	if err := json.Unmarshal(respond, &goals); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> GetSavingsGoalsByUserID: %s \n", err)
+		return nil, err
+	}
+	return &goals, nil
+}
+
+func (repo *savingsGoalRepository) UpdateSavingsGoal(id string, data entities.SavingsGoalUpdate) (*entities.SavingsGoalModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("savings_goals", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> UpdateSavingsGoal: %s \n", err)
+		fmt.Println("Error updating savings goal:", err)
+		return nil, err
+	}
+	var goals []entities.SavingsGoalModel
+	if err := json.Unmarshal(respond, &goals); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> UpdateSavingsGoal: %s \n", err)
+		return nil, err
+	}
+	if len(goals) == 0 {
+		return nil, fmt.Errorf("savings goal with ID %s not found", id)
+	}
+	return &goals[0], nil
+}
+
+func (repo *savingsGoalRepository) DeleteSavingsGoal(id string) error {
+	queryParams := fmt.Sprintf("?goal_id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("savings_contributions", http.MethodDelete, queryParams, nil); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> DeleteSavingsGoal: %s \n", err)
+		fmt.Println("Error deleting savings contributions:", err)
+		return err
+	}
+	queryParams = fmt.Sprintf("?id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("savings_goals", http.MethodDelete, queryParams, nil); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> DeleteSavingsGoal: %s \n", err)
+		fmt.Println("Error deleting savings goal:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *savingsGoalRepository) InsertContribution(data entities.SavingsContributionResponse) (*entities.SavingsContributionModel, error) {
+	respond, err := repo.SupabaseClient.Query("savings_contributions", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> InsertContribution: %s \n", err)
+		fmt.Println("Error inserting savings contribution:", err)
+		return nil, err
+	}
+	var contributions []entities.SavingsContributionModel
+	if err := json.Unmarshal(respond, &contributions); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> InsertContribution: %s \n", err)
+		return nil, err
+	}
+	if len(contributions) == 0 {
+		return nil, fmt.Errorf("savings contribution was not returned after insert")
+	}
+	return &contributions[0], nil
+}
+
+func (repo *savingsGoalRepository) GetContributionByID(id string) (*entities.SavingsContributionModel, error) {
+	contributions, err := repo.getContributions("GetContributionByID", fmt.Sprintf("?id=eq.%s", id))
+	if err != nil {
+		return nil, err
+	}
+	if len(*contributions) == 0 {
+		return nil, fmt.Errorf("savings contribution with ID %s not found", id)
+	}
+	return &(*contributions)[0], nil
+}
+
+func (repo *savingsGoalRepository) GetContributionsByGoalID(goalID string) (*[]entities.SavingsContributionModel, error) {
+	return repo.getContributions("GetContributionsByGoalID", fmt.Sprintf("?goal_id=eq.%s&order=date.asc,created_at.asc", goalID))
+}
+
+func (repo *savingsGoalRepository) GetContributionsByUserID(userID string) (*[]entities.SavingsContributionModel, error) {
+	return repo.getContributions("GetContributionsByUserID", fmt.Sprintf("?user_id=eq.%s&order=date.asc,created_at.asc", userID))
+}
+
+func (repo *savingsGoalRepository) getContributions(caller string, queryParams string) (*[]entities.SavingsContributionModel, error) {
+	respond, err := repo.SupabaseClient.Query("savings_contributions", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> %s: %s \n", caller, err)
+		fmt.Println("Error fetching savings contributions:", err)
+		return nil, err
+	}
+	contributions := []entities.SavingsContributionModel{}
+	if err := json.Unmarshal(respond, &contributions); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> %s: %s \n", caller, err)
+		return nil, err
+	}
+	return &contributions, nil
+}
+
+func (repo *savingsGoalRepository) DeleteContribution(id string) error {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("savings_contributions", http.MethodDelete, queryParams, nil); err != nil {
+		fiberlog.Errorf("SavingsGoalRepository -> DeleteContribution: %s \n", err)
+		fmt.Println("Error deleting savings contribution:", err)
+		return err
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ type IScheduleRepository interface {
 	GetScheduleByUserID(id string) (*entities.ScheduleModel, error)
 	UpdateSchedule(id string, schedule entities.ScheduleResponse) error
 	DeleteSchedule(id string) error
+	CreateScheduleBlock(block entities.ScheduleBlockResponse) error
+	GetScheduleBlocksByUserID(id string) (*[]entities.ScheduleBlockModel, error)
 }
 
 type ScheduleRepository struct {
@@ -117,4 +119,30 @@ func (repo *ScheduleRepository) DeleteSchedule(id string) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+func (repo *ScheduleRepository) CreateScheduleBlock(block entities.ScheduleBlockResponse) error {
+	_, err := repo.SupabaseRest.Query("schedule_blocks", http.MethodPost, "", block)
+	if err != nil {
+		fiberlog.Errorf("ScheduleRepository -> CreateScheduleBlock: %s \n", err)
+		fmt.Println("Error inserting schedule block:", err)
+		return err
+	}
+	return nil
+}
+
+func (repo *ScheduleRepository) GetScheduleBlocksByUserID(id string) (*[]entities.ScheduleBlockModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=start_time.asc", id)
+	respond, err := repo.SupabaseRest.Query("schedule_blocks", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("ScheduleRepository -> GetScheduleBlocksByUserID: %s \n", err)
+		fmt.Println("Error fetching schedule blocks by UserID:", err)
+		return nil, err
+	}
+	var blocks []entities.ScheduleBlockModel
+	if err := json.Unmarshal(respond, &blocks); err != nil {
+		fiberlog.Errorf("ScheduleRepository -> GetScheduleBlocksByUserID: %s \n", err)
+		fmt.Println("Error unmarshalling schedule blocks:", err)
+		return nil, err
+	}
+	return &blocks, nil
+}
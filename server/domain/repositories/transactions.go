@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type transactionRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type ITransactionRepository interface {
+	InsertTransaction(data entities.TransactionResponse) (*entities.TransactionModel, error)
+	GetTransactionByID(id string) (*entities.TransactionModel, error)
+	GetTransactionsByUserID(userID string) (*[]entities.TransactionModel, error)
+	UpdateTransaction(id string, data entities.TransactionUpdate) (*entities.TransactionModel, error)
+	DeleteTransaction(id string) error
+}
+
+func NewTransactionRepository(client *datasources.SupabaseREST) ITransactionRepository {
+	return &transactionRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *transactionRepository) InsertTransaction(data entities.TransactionResponse) (*entities.TransactionModel, error) {
+	respond, err := repo.SupabaseClient.Query("transactions", http.MethodPost, "", data)
+	if err != nil {
+		fiberlog.Errorf("TransactionRepository -> InsertTransaction: %s \n", err)
+		fmt.Println("Error inserting transaction:", err)
+		return nil, err
+	}
+	var transactions []entities.TransactionModel
+	if err := json.Unmarshal(respond, &transactions); err != nil {
+		fiberlog.Errorf("TransactionRepository -> InsertTransaction: %s \n", err)
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("transaction was not returned after insert")
+	}
+	return &transactions[0], nil
+}
+
+func (repo *transactionRepository) GetTransactionByID(id string) (*entities.TransactionModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("transactions", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("TransactionRepository -> GetTransactionByID: %s \n", err)
+		fmt.Println("Error fetching transaction:", err)
+		return nil, err
+	}
+	var transactions []entities.TransactionModel
+	if err := json.Unmarshal(respond, &transactions); err != nil {
+		fiberlog.Errorf("TransactionRepository -> GetTransactionByID: %s \n", err)
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("transaction with ID %s not found", id)
+	}
+	return &transactions[0], nil
+}
+
+func (repo *transactionRepository) GetTransactionsByUserID(userID string) (*[]entities.TransactionModel, error) {
+	return repo.getTransactions("GetTransactionsByUserID", fmt.Sprintf("?user_id=eq.%s&order=date.asc,created_at.asc", userID))
+}
+
+func (repo *transactionRepository) getTransactions(caller string, queryParams string) (*[]entities.TransactionModel, error) {
+	respond, err := repo.SupabaseClient.Query("transactions", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("TransactionRepository -> %s: %s \n", caller, err)
+		fmt.Println("Error fetching transactions:", err)
+		return nil, err
+	}
+	transactions := []entities.TransactionModel{}
+	if err := json.Unmarshal(respond, &transactions); err != nil {
+		fiberlog.Errorf("TransactionRepository -> %s: %s \n", caller, err)
+		return nil, err
+	}
+	return &transactions, nil
+}
+
+func (repo *transactionRepository) UpdateTransaction(id string, data entities.TransactionUpdate) (*entities.TransactionModel, error) {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	respond, err := repo.SupabaseClient.Query("transactions", http.MethodPatch, queryParams, data)
+	if err != nil {
+		fiberlog.Errorf("TransactionRepository -> UpdateTransaction: %s \n", err)
+		fmt.Println("Error updating transaction:", err)
+		return nil, err
+	}
+	var transactions []entities.TransactionModel
+	if err := json.Unmarshal(respond, &transactions); err != nil {
+		fiberlog.Errorf("TransactionRepository -> UpdateTransaction: %s \n", err)
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("transaction with ID %s not found", id)
+	}
+	return &transactions[0], nil
+}
+
+func (repo *transactionRepository) DeleteTransaction(id string) error {
+	queryParams := fmt.Sprintf("?id=eq.%s", id)
+	if _, err := repo.SupabaseClient.Query("transactions", http.MethodDelete, queryParams, nil); err != nil {
+		fiberlog.Errorf("TransactionRepository -> DeleteTransaction: %s \n", err)
+		fmt.Println("Error deleting transaction:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/entities"
+	"net/http"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type weeklyReviewRepository struct {
+	SupabaseClient *datasources.SupabaseREST
+}
+
+type IWeeklyReviewRepository interface {
+	// UpsertReview keeps one review per user and week_start.
+	UpsertReview(data entities.WeeklyReviewResponse) (*entities.WeeklyReviewModel, error)
+	GetReviewsByUserID(userID string) (*[]entities.WeeklyReviewModel, error)
+	// GetReviewByWeek returns nil without an error when the week has no review.
+	GetReviewByWeek(userID string, weekStart string) (*entities.WeeklyReviewModel, error)
+}
+
+func NewWeeklyReviewRepository(client *datasources.SupabaseREST) IWeeklyReviewRepository {
+	return &weeklyReviewRepository{
+		SupabaseClient: client,
+	}
+}
+
+func (repo *weeklyReviewRepository) UpsertReview(data entities.WeeklyReviewResponse) (*entities.WeeklyReviewModel, error) {
+	respond, err := repo.SupabaseClient.Query("weekly_reviews", http.MethodPost, "?on_conflict=user_id,week_start", data)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewRepository -> UpsertReview: %s \n", err)
+		fmt.Println("Error saving weekly review:", err)
+		return nil, err
+	}
+	var reviews []entities.WeeklyReviewModel
+	if err := json.Unmarshal(respond, &reviews); err != nil {
+		fiberlog.Errorf("WeeklyReviewRepository -> UpsertReview: %s \n", err)
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, fmt.Errorf("weekly review was not returned after insert")
+	}
+	return &reviews[0], nil
+}
+
+func (repo *weeklyReviewRepository) GetReviewsByUserID(userID string) (*[]entities.WeeklyReviewModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&order=week_start.desc", userID)
+	respond, err := repo.SupabaseClient.Query("weekly_reviews", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewRepository -> GetReviewsByUserID: %s \n", err)
+		fmt.Println("Error fetching weekly reviews:", err)
+		return nil, err
+	}
+	var reviews []entities.WeeklyReviewModel
+	if err := json.Unmarshal(respond, &reviews); err != nil {
+		fiberlog.Errorf("WeeklyReviewRepository -> GetReviewsByUserID: %s \n", err)
+		return nil, err
+	}
+	return &reviews, nil
+}
+
+func (repo *weeklyReviewRepository) GetReviewByWeek(userID string, weekStart string) (*entities.WeeklyReviewModel, error) {
+	queryParams := fmt.Sprintf("?user_id=eq.%s&week_start=eq.%s", userID, weekStart)
+	respond, err := repo.SupabaseClient.Query("weekly_reviews", http.MethodGet, queryParams, nil)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewRepository -> GetReviewByWeek: %s \n", err)
+		fmt.Println("Error fetching weekly review:", err)
+		return nil, err
+	}
+	var reviews []entities.WeeklyReviewModel
+	if err := json.Unmarshal(respond, &reviews); err != nil {
+		fiberlog.Errorf("WeeklyReviewRepository -> GetReviewByWeek: %s \n", err)
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, nil
+	}
+	return &reviews[0], nil
+}
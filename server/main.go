@@ -4,11 +4,12 @@ import (
 	"go-fiber-template/configuration"
 	ai "go-fiber-template/domain/aimodel"
 	ds "go-fiber-template/domain/datasources"
+	"go-fiber-template/domain/notify"
 	repo "go-fiber-template/domain/repositories"
 	gw "go-fiber-template/src/gateways"
 	"go-fiber-template/src/middlewares"
 	sv "go-fiber-template/src/services"
-	// "log"
+	"log"
 	"os"
 
 	"github.com/gofiber/fiber/v2"
@@ -37,33 +38,75 @@ func main() {
 	middlewares.Logger(app)
 	app.Use(recover.New())
 	app.Use(cors.New())
+	app.Use(middlewares.SetLocaleHandler())
 	// app.Get("/swagger/*", swagger.HandlerDefault)
 
 	supabasedb := ds.NewSupabaseREST()
-	gemini := ai.NewGeminiRest()
-
+	modelConfig, err := ai.NewModelConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	models, err := ai.NewModelRouter(modelConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	channels, err := notify.NewChannelsFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	userRepo := repo.NewUsersRepository(supabasedb)
 	lifeGoalRepo := repo.NewLifeGoalRepository(supabasedb)
 	aiPromptRepo := repo.NewAiPromptRepository(supabasedb)
 	financeRepo := repo.NewFinanceRepository(supabasedb)
+	transactionRepo := repo.NewTransactionRepository(supabasedb)
+	budgetRepo := repo.NewBudgetRepository(supabasedb)
+	savingsRepo := repo.NewSavingsGoalRepository(supabasedb)
+	exchangeRateRepo := repo.NewExchangeRateRepository(supabasedb)
 	healthBackgroundRepo := repo.NewHealthBackgroundRepository(supabasedb)
 	scheduleRepo :=repo.NewScheduleRepository(supabasedb)
-	aiGenRepo := repo.NewAiGenRepository(supabasedb, gemini)
+	aiGenRepo := repo.NewAiGenRepository(supabasedb, models)
 	habitsRepo := repo.NewHabitRepository(supabasedb)
 	moodRepo := repo.NewMoodRepository(supabasedb)
+	usageRepo := repo.NewLLMUsageRepository(supabasedb)
+	actionRepo := repo.NewAssistantActionRepository(supabasedb)
+	planJobRepo := repo.NewPlanJobRepository(supabasedb)
+	weeklyReviewRepo := repo.NewWeeklyReviewRepository(supabasedb)
+	reminderRepo := repo.NewReminderRepository(supabasedb)
+	planTaskRepo := repo.NewPlanTaskRepository(supabasedb)
 
 	sv0 := sv.NewUsersService(userRepo, lifeGoalRepo, userRepo, healthBackgroundRepo, financeRepo, scheduleRepo)
 	sv1 := sv.NewLifeGoalService(lifeGoalRepo, userRepo)
-	sv2 := sv.NewAiPromptService(lifeGoalRepo, userRepo, aiPromptRepo, healthBackgroundRepo, financeRepo, scheduleRepo)
-	sv3 := sv.NewAiGenService(aiGenRepo, aiPromptRepo, lifeGoalRepo, userRepo, healthBackgroundRepo, financeRepo, scheduleRepo)
-	sv4 := sv.NewFinanceService(financeRepo)
+	exchangeRates := sv.NewExchangeRateService(exchangeRateRepo, sv.NewExchangeRateConfigFromEnv())
+	if err := exchangeRates.Load(); err != nil {
+		log.Println("exchange rates not loaded:", err)
+	}
+	sv2 := sv.NewAiPromptService(lifeGoalRepo, userRepo, aiPromptRepo, healthBackgroundRepo, financeRepo, scheduleRepo, transactionRepo, exchangeRates)
+	sv4 := sv.NewFinanceService(financeRepo, transactionRepo, budgetRepo, savingsRepo, lifeGoalRepo, userRepo, exchangeRates, channels, sv.NewBudgetConfigFromEnv())
+	sv4.Start()
 	sv5 := sv.NewHealthBackgroundService(healthBackgroundRepo)
 	sv6 := sv.NewScheduleService(scheduleRepo)
-	sv7 := sv.NewHabitsService(habitsRepo)
-	sv8 := sv.NewMoodService(moodRepo)
+	sv7 := sv.NewHabitsService(habitsRepo, userRepo, reminderRepo)
+	usageService := sv.NewUsageService(usageRepo)
+	privacyService := sv.NewPrivacyService(userRepo, sv.NewPrivacyConfigFromEnv())
+	moodNotes := sv.NewMoodNoteService(aiGenRepo, userRepo, usageService, privacyService, sv.NewMoodNoteConfigFromEnv())
+	sv8 := sv.NewMoodService(moodRepo, userRepo, moodNotes)
+	chatHistory := sv.NewChatHistoryManager(aiGenRepo, usageService, privacyService, sv.NewChatHistoryConfigFromEnv())
+	actionService := sv.NewAssistantActionService(actionRepo, sv7, sv6, sv1)
+	assistantTools := sv.NewAssistantTools(sv7, sv8, sv4, sv6, aiGenRepo, actionService)
+	safetyService := sv.NewSafetyService(healthBackgroundRepo, userRepo, sv.NewSafetyConfigFromEnv())
+	sv3 := sv.NewAiGenService(aiGenRepo, aiPromptRepo, lifeGoalRepo, userRepo, healthBackgroundRepo, financeRepo, scheduleRepo, chatHistory, usageService, assistantTools, safetyService, privacyService)
+	planJobs := sv.NewPlanJobService(planJobRepo, aiPromptRepo, sv3, sv.NewPlanJobConfigFromEnv())
+	planJobs.Start()
+	weeklyReviews := sv.NewWeeklyReviewService(weeklyReviewRepo, aiGenRepo, habitsRepo, moodRepo, userRepo, usageService, safetyService, privacyService, sv.NewWeeklyReviewConfigFromEnv())
+	weeklyReviews.Start()
+	reminders := sv.NewReminderService(reminderRepo, habitsRepo, moodRepo, weeklyReviewRepo, userRepo, channels, sv.NewReminderConfigFromEnv())
+	reminders.Start()
+	planAdoption := sv.NewPlanAdoptionService(aiGenRepo, habitsRepo, planTaskRepo, userRepo, sv7)
+
+	moodInsights := sv.NewMoodInsightsService(moodRepo, habitsRepo, scheduleRepo, userRepo)
 
-	gw.NewHTTPGateway(app, sv0, sv1, sv2, sv3, sv4, sv5, sv6, sv7, sv8)
+	gw.NewHTTPGateway(app, sv0, sv1, sv2, sv3, sv4, sv5, sv6, sv7, sv8, usageService, actionService, planJobs, weeklyReviews, reminders, planAdoption, moodInsights, exchangeRates)
 
 	PORT := os.Getenv("PORT")
 
@@ -0,0 +1,243 @@
+package evaluation
+
+import (
+	"fmt"
+	"go-fiber-template/src/services"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// AssertionResult is the outcome of one assertion on one plan. Score is
+// between 0 and 1; Passed means nothing was wrong.
+type AssertionResult struct {
+	Name    string   `json:"name"`
+	Passed  bool     `json:"passed"`
+	Score   float64  `json:"score"`
+	Details []string `json:"details,omitempty"`
+}
+
+// Assertion checks a generated plan against the fixture it was generated for.
+type Assertion interface {
+	Name() string
+	Check(fixture Fixture, plan string) AssertionResult
+}
+
+// Assertions maps the names accepted on the command line to their checks.
+func Assertions(safety services.ISafetyService) map[string]Assertion {
+	return map[string]Assertion{
+		"must-mention-goal": mustMentionGoal{},
+		"no-allergen-food":  noAllergenFood{Safety: safety},
+		"budget-consistent": budgetConsistent{},
+	}
+}
+
+// DefaultAssertions is the order assertions run in when none are selected.
+var DefaultAssertions = []string{"must-mention-goal", "no-allergen-food", "budget-consistent"}
+
+// mustMentionGoal passes when every short and long term goal is addressed.
+// A goal counts as mentioned when the plan contains it verbatim or at least
+// half of its significant words.
+type mustMentionGoal struct{}
+
+func (mustMentionGoal) Name() string { return "must-mention-goal" }
+
+func (a mustMentionGoal) Check(fixture Fixture, plan string) AssertionResult {
+	result := AssertionResult{Name: a.Name(), Passed: true, Score: 1}
+	goals := append(append([]string{}, fixture.Body.ShortTerm...), fixture.Body.LongTerm...)
+	if len(goals) == 0 {
+		result.Details = []string{"fixture has no goals"}
+		return result
+	}
+	lowerPlan := strings.ToLower(plan)
+	mentioned := 0
+	for _, goal := range goals {
+		if goalMentioned(lowerPlan, goal) {
+			mentioned++
+			continue
+		}
+		result.Passed = false
+		result.Details = append(result.Details, fmt.Sprintf("goal not mentioned: %q", goal))
+	}
+	result.Score = float64(mentioned) / float64(len(goals))
+	return result
+}
+
+var goalStopwords = map[string]bool{
+	"about": true, "become": true, "being": true, "every": true, "from": true, "have": true,
+	"into": true, "less": true, "more": true, "able": true, "that": true, "this": true,
+	"want": true, "with": true, "would": true, "year": true, "years": true, "month": true,
+}
+
+func goalMentioned(lowerPlan string, goal string) bool {
+	goal = strings.ToLower(strings.TrimSpace(goal))
+	if goal == "" || strings.Contains(lowerPlan, goal) {
+		return true
+	}
+	words := strings.FieldsFunc(goal, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) })
+	keywords := []string{}
+	for _, word := range words {
+		if len([]rune(word)) >= 4 && !goalStopwords[word] {
+			keywords = append(keywords, word)
+		}
+	}
+	if len(keywords) == 0 {
+		return false
+	}
+	found := 0
+	for _, keyword := range keywords {
+		// Match the stem so "saving" also finds "save" and "savings".
+		stem := keyword
+		if runes := []rune(keyword); len(runes) > 5 {
+			stem = string(runes[:len(runes)-2])
+		}
+		if strings.Contains(lowerPlan, stem) {
+			found++
+		}
+	}
+	return found*2 >= len(keywords)
+}
+
+// noAllergenFood runs the allergen rules of the safety layer over the raw
+// model output, before any redaction.
+type noAllergenFood struct {
+	Safety services.ISafetyService
+}
+
+func (noAllergenFood) Name() string { return "no-allergen-food" }
+
+func (a noAllergenFood) Check(fixture Fixture, plan string) AssertionResult {
+	result := AssertionResult{Name: a.Name(), Passed: true, Score: 1}
+	health := fixture.Health()
+	for _, finding := range a.Safety.Check(plan, &health) {
+		if finding.Category != services.SafetyAllergen {
+			continue
+		}
+		result.Passed = false
+		result.Score = 0
+		result.Details = append(result.Details, fmt.Sprintf("%q mentions %s", finding.Sentence, finding.Match))
+	}
+	return result
+}
+
+// budgetConsistent passes when every monthly amount the plan tells the user
+// to save or invest fits into income minus expenses, and no monthly spending
+// amount exceeds income. Amounts only count when they carry the fixture's
+// currency. Plans without monthly amounts pass.
+type budgetConsistent struct{}
+
+func (budgetConsistent) Name() string { return "budget-consistent" }
+
+var (
+	amountPattern  = regexp.MustCompile(`(\d{1,3}(?:,\d{3})+|\d+)(\.\d+)?\s?([kK])?`)
+	monthlyMarkers = []string{"per month", "a month", "/month", "/mo", "monthly", "each month", "every month", "ต่อเดือน", "เดือนละ", "/เดือน"}
+	savingMarkers  = []string{"save", "saving", "set aside", "put aside", "invest", "emergency fund", "ออม", "เก็บเงิน", "ลงทุน"}
+	spendMarkers   = []string{"spend", "budget", "expense", "ใช้จ่าย", "งบ"}
+	// Markers that come before the amount, as in "monthly 5,000 THB".
+	monthlyPrefixes = []string{"monthly", "each month", "every month", "เดือนละ"}
+)
+
+// monthlyMarkerWindow is how many bytes around an amount are searched for a
+// monthly marker. Thai characters take three bytes each.
+const monthlyMarkerWindow = 40
+
+var currencyMarkers = map[string][]string{
+	"THB": {"฿", "thb", "baht", "บาท"},
+	"USD": {"$", "usd", "dollar"},
+	"EUR": {"€", "eur", "euro"},
+	"JPY": {"¥", "jpy", "yen"},
+	"GBP": {"£", "gbp", "pound"},
+}
+
+func (a budgetConsistent) Check(fixture Fixture, plan string) AssertionResult {
+	result := AssertionResult{Name: a.Name(), Passed: true, Score: 1}
+	finance := fixture.Finance()
+	capacity := (finance.Income - finance.Expenses).Float()
+	markers := currencyMarkers[strings.ToUpper(finance.Currency)]
+	if finance.Currency != "" {
+		markers = append(markers, strings.ToLower(finance.Currency))
+	}
+	checked, consistent := 0, 0
+	for _, sentence := range planSentences(plan) {
+		lower := strings.ToLower(sentence)
+		if !containsAnyOf(lower, monthlyMarkers) || !containsAnyOf(lower, markers) {
+			continue
+		}
+		saving := containsAnyOf(lower, savingMarkers)
+		spending := containsAnyOf(lower, spendMarkers)
+		if !saving && !spending {
+			continue
+		}
+		for _, amount := range monthlyAmounts(lower) {
+			checked++
+			limit := finance.Income.Float()
+			kind := "income"
+			if saving {
+				limit = capacity
+				kind = "income minus expenses"
+			}
+			// Allow 5% for rounding in the model's arithmetic.
+			if amount <= limit*1.05 && amount >= 0 {
+				consistent++
+				continue
+			}
+			result.Passed = false
+			result.Details = append(result.Details, fmt.Sprintf("%.2f %s exceeds %s (%.2f): %q", amount, finance.Currency, kind, limit, sentence))
+		}
+	}
+	if checked == 0 {
+		result.Details = []string{"no monthly amounts found"}
+		return result
+	}
+	result.Score = float64(consistent) / float64(checked)
+	return result
+}
+
+// monthlyAmounts returns the amounts that have a monthly marker next to
+// them, so a total target in the same sentence ("... to reach 100,000 THB")
+// is not mistaken for a monthly figure.
+func monthlyAmounts(lower string) []float64 {
+	amounts := []float64{}
+	for _, match := range amountPattern.FindAllStringSubmatchIndex(lower, -1) {
+		before := lower[max(0, match[0]-monthlyMarkerWindow):match[0]]
+		after := lower[match[1]:min(len(lower), match[1]+monthlyMarkerWindow)]
+		if !containsAnyOf(after, monthlyMarkers) && !containsAnyOf(before, monthlyPrefixes) {
+			continue
+		}
+		number := strings.ReplaceAll(lower[match[2]:match[3]], ",", "")
+		if match[4] >= 0 {
+			number += lower[match[4]:match[5]]
+		}
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			continue
+		}
+		if match[6] >= 0 {
+			value *= 1000
+		}
+		amounts = append(amounts, value)
+	}
+	return amounts
+}
+
+func planSentences(plan string) []string {
+	sentences := []string{}
+	for _, line := range strings.Split(plan, "\n") {
+		for _, part := range strings.Split(line, ". ") {
+			if part = strings.TrimSpace(part); part != "" {
+				sentences = append(sentences, part)
+			}
+		}
+	}
+	return sentences
+}
+
+func containsAnyOf(lower string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(lower, phrase) {
+			return true
+		}
+	}
+	return false
+}
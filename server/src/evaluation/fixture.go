@@ -0,0 +1,101 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Fixture is one user profile to evaluate. The file is an entities.BodyData
+// JSON document; "profile" and "language" are optional extras for the fields
+// BodyData does not carry.
+type Fixture struct {
+	Name     string
+	Body     entities.BodyData
+	Profile  entities.UserProfileModel
+	Language string
+}
+
+type fixtureFile struct {
+	entities.BodyData
+	Profile  *entities.UserProfileModel `json:"profile"`
+	Language string                     `json:"language"`
+}
+
+// LoadFixtures reads every *.json file in dir, sorted by name. The fixture
+// name is the file name without its extension.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	fixtures := []Fixture{}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var file fixtureFile
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", path, err)
+		}
+		fixture := Fixture{
+			Name:     strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Body:     file.BodyData,
+			Language: file.Language,
+		}
+		if file.Profile != nil {
+			fixture.Profile = *file.Profile
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %s", dir)
+	}
+	return fixtures, nil
+}
+
+// The records below mirror what PostAllInfomation stores for the same body.
+
+func (f Fixture) LifeGoal() entities.LifeGoalModel {
+	return entities.LifeGoalModel{
+		ShortTerm:  f.Body.ShortTerm,
+		LongTerm:   f.Body.LongTerm,
+		Priorities: f.Body.Priorities,
+		TimeFrame:  f.Body.TimeFrame,
+	}
+}
+
+func (f Fixture) Health() entities.HealthBackgroundModel {
+	return entities.HealthBackgroundModel{
+		Medical_Conditions: f.Body.Medical_Conditions,
+		Allergies:          f.Body.Allergies,
+		Medications:        f.Body.Medications,
+		Fitness_Level:      f.Body.Fitness_Level,
+		Sleep_Pattern:      f.Body.Sleep_Pattern,
+	}
+}
+
+func (f Fixture) Finance() entities.FinanceModel {
+	return entities.FinanceModel{
+		Currency:       f.Body.Currency,
+		Income:         f.Body.Income,
+		Expenses:       f.Body.Expenses,
+		SavingsGoal:    f.Body.Savings_Goal,
+		Risk_Tolerance: f.Body.Risk_Tolerance,
+	}
+}
+
+func (f Fixture) Schedule() entities.ScheduleModel {
+	return entities.ScheduleModel{
+		WorkHours:     f.Body.Work_Hours,
+		AvailableTime: f.Body.Available_Time,
+		BusyDays:      f.Body.Busy_Days,
+		PreferredTime: f.Body.Preferred_Times,
+	}
+}
@@ -0,0 +1,103 @@
+package evaluation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/aimodel"
+	"go-fiber-template/domain/entities"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Recording is a stored model response for one fixture. PromptSHA256 lets
+// the harness tell when the prompt has changed since it was recorded.
+type Recording struct {
+	Fixture      string            `json:"fixture"`
+	PromptSHA256 string            `json:"prompt_sha256"`
+	Response     string            `json:"response"`
+	Usage        entities.LLMUsage `json:"usage"`
+	RecordedAt   time.Time         `json:"recorded_at"`
+}
+
+func RecordingPath(dir string, fixture string) string {
+	return filepath.Join(dir, fixture+".json")
+}
+
+func PromptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// ReplayLLM answers from a recording instead of calling a model, so the
+// harness runs without network access. Stale reports whether the last prompt
+// differed from the recorded one.
+type ReplayLLM struct {
+	Dir     string
+	Fixture string
+	Stale   bool
+}
+
+func NewReplayLLM(dir string, fixture string) *ReplayLLM {
+	return &ReplayLLM{Dir: dir, Fixture: fixture}
+}
+
+func (r *ReplayLLM) GenerateText(prompt string) (string, entities.LLMUsage, error) {
+	raw, err := os.ReadFile(RecordingPath(r.Dir, r.Fixture))
+	if err != nil {
+		return "", entities.LLMUsage{}, fmt.Errorf("no recording for fixture %s (run with -record): %w", r.Fixture, err)
+	}
+	var recording Recording
+	if err := json.Unmarshal(raw, &recording); err != nil {
+		return "", entities.LLMUsage{}, fmt.Errorf("recording for fixture %s: %w", r.Fixture, err)
+	}
+	r.Stale = recording.PromptSHA256 != PromptHash(prompt)
+	return recording.Response, recording.Usage, nil
+}
+
+func (r *ReplayLLM) AIChat(systemInstruction string, history []entities.AIChat, prompt string, tools []aimodel.Tool, execute aimodel.ToolExecutor) (string, entities.LLMUsage, error) {
+	return "", entities.LLMUsage{}, fmt.Errorf("replay backend only records plan generation")
+}
+
+// RecordingLLM passes calls through to a live model and stores each plan
+// response as the fixture's recording.
+type RecordingLLM struct {
+	LLM     aimodel.ILLM
+	Dir     string
+	Fixture string
+}
+
+func NewRecordingLLM(llm aimodel.ILLM, dir string, fixture string) *RecordingLLM {
+	return &RecordingLLM{LLM: llm, Dir: dir, Fixture: fixture}
+}
+
+func (r *RecordingLLM) GenerateText(prompt string) (string, entities.LLMUsage, error) {
+	response, usage, err := r.LLM.GenerateText(prompt)
+	if err != nil {
+		return "", usage, err
+	}
+	recording := Recording{
+		Fixture:      r.Fixture,
+		PromptSHA256: PromptHash(prompt),
+		Response:     response,
+		Usage:        usage,
+		RecordedAt:   time.Now().UTC(),
+	}
+	raw, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return "", usage, err
+	}
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return "", usage, err
+	}
+	if err := os.WriteFile(RecordingPath(r.Dir, r.Fixture), append(raw, '\n'), 0o644); err != nil {
+		return "", usage, err
+	}
+	return response, usage, nil
+}
+
+func (r *RecordingLLM) AIChat(systemInstruction string, history []entities.AIChat, prompt string, tools []aimodel.Tool, execute aimodel.ToolExecutor) (string, entities.LLMUsage, error) {
+	return r.LLM.AIChat(systemInstruction, history, prompt, tools, execute)
+}
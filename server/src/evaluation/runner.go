@@ -0,0 +1,136 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/aimodel"
+	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/locale"
+	"go-fiber-template/src/services"
+	"os"
+	"time"
+)
+
+// Config selects what to run. When Live is set every fixture is generated
+// with it and the response is recorded into ReplayDir; otherwise responses
+// are replayed from ReplayDir.
+type Config struct {
+	FixturesDir string
+	ReplayDir   string
+	Assertions  []string
+	Language    string
+	Live        aimodel.ILLM
+}
+
+type FixtureResult struct {
+	Fixture        string                   `json:"fixture"`
+	Language       string                   `json:"language"`
+	Score          float64                  `json:"score"`
+	Passed         bool                     `json:"passed"`
+	StaleRecording bool                     `json:"stale_recording"`
+	SafetyFindings []services.SafetyFinding `json:"safety_findings"`
+	Assertions     []AssertionResult        `json:"assertions"`
+	Error          string                   `json:"error,omitempty"`
+}
+
+type Report struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Mode        string             `json:"mode"`
+	Fixtures    int                `json:"fixtures"`
+	Passed      int                `json:"passed"`
+	Score       float64            `json:"score"`
+	ByAssertion map[string]float64 `json:"by_assertion"`
+	Results     []FixtureResult    `json:"results"`
+}
+
+// Run generates a plan for every fixture through the same prompt builder and
+// repository the server uses, and scores it with the selected assertions.
+func Run(config Config) (*Report, error) {
+	fixtures, err := LoadFixtures(config.FixturesDir)
+	if err != nil {
+		return nil, err
+	}
+	safety := services.NewSafetyService(nil, nil, services.DefaultSafetyConfig())
+	available := Assertions(safety)
+	names := config.Assertions
+	if len(names) == 0 {
+		names = DefaultAssertions
+	}
+	selected := []Assertion{}
+	for _, name := range names {
+		assertion, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown assertion %q", name)
+		}
+		selected = append(selected, assertion)
+	}
+
+	report := Report{
+		GeneratedAt: time.Now().UTC(),
+		Mode:        "replay",
+		ByAssertion: map[string]float64{},
+		Results:     []FixtureResult{},
+	}
+	if config.Live != nil {
+		report.Mode = "record"
+	}
+	for _, fixture := range fixtures {
+		result := runFixture(config, fixture, selected, safety)
+		report.Results = append(report.Results, result)
+		report.Score += result.Score
+		if result.Passed {
+			report.Passed++
+		}
+		for _, assertion := range result.Assertions {
+			report.ByAssertion[assertion.Name] += assertion.Score
+		}
+	}
+	report.Fixtures = len(fixtures)
+	report.Score /= float64(len(fixtures))
+	for name := range report.ByAssertion {
+		report.ByAssertion[name] /= float64(len(fixtures))
+	}
+	return &report, nil
+}
+
+func runFixture(config Config, fixture Fixture, assertions []Assertion, safety services.ISafetyService) FixtureResult {
+	lang := locale.Resolve(fixture.Language, config.Language, fixture.Profile.Language)
+	result := FixtureResult{Fixture: fixture.Name, Language: lang, Assertions: []AssertionResult{}}
+	prompt := services.BuildPlanPrompt(lang, fixture.Profile, fixture.LifeGoal(), fixture.Health(), fixture.Finance(), fixture.Schedule())
+
+	var llm aimodel.ILLM
+	replay := NewReplayLLM(config.ReplayDir, fixture.Name)
+	if config.Live != nil {
+		llm = NewRecordingLLM(config.Live, config.ReplayDir, fixture.Name)
+	} else {
+		llm = replay
+	}
+	plan, _, err := repositories.NewAiGenRepository(nil, aimodel.SingleModel{LLM: llm}).GenerateLifeGoal(prompt)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.StaleRecording = replay.Stale
+
+	health := fixture.Health()
+	result.SafetyFindings = safety.Check(plan, &health)
+	result.Passed = true
+	for _, assertion := range assertions {
+		outcome := assertion.Check(fixture, plan)
+		result.Assertions = append(result.Assertions, outcome)
+		result.Score += outcome.Score
+		result.Passed = result.Passed && outcome.Passed
+	}
+	if len(assertions) > 0 {
+		result.Score /= float64(len(assertions))
+	}
+	return result
+}
+
+func WriteReport(report *Report, path string) error {
+	raw, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
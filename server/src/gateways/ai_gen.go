@@ -6,12 +6,12 @@ import (
 )
 
 //@Summary Create a new AI GenPlan
-// @Description generate a new AI GenPlan base ai prompt that we create base on data that user input 
+// @Description Queue a job that generates a new AI GenPlan from an ai prompt built from the data the user input. Poll the returned job for the result.
 // @Tags Ai Gen
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
-// @Success 200 {object} entities.ResponseModel
+// @Success 202 {object} entities.ResponseModel
 // @Failure 403 {object} entities.ResponseModel
 // @Router /api/v1/ai_gen/create_ai_gen/{id} [post]
 func (gateway *HTTPGateway) CreateAiGen(ctx *fiber.Ctx) error {
@@ -20,11 +20,47 @@ func (gateway *HTTPGateway) CreateAiGen(ctx *fiber.Ctx) error {
 	if err != nil {
 		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot create new AI prompt."})
 	}
-	respond, err := gateway.AiGenService.GenerateLifeGoal(id);
+	job, err := gateway.PlanJobService.Enqueue(id)
 	if  err != nil {
 		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot create new AI gen."})
 	}
-	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "successfully created new AI gen", Data: respond})
+	return ctx.Status(fiber.StatusAccepted).JSON(entities.ResponseModel{Message: "AI gen job queued", Data: job})
+}
+
+//@Summary Get an AI GenPlan job
+// @Description Get the status, progress and resulting plan id of a plan generation job
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 404 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/jobs/{job_id} [get]
+func (gateway *HTTPGateway) GetPlanJob(ctx *fiber.Ctx) error {
+	id := ctx.Params("job_id")
+	data, err := gateway.PlanJobService.GetJob(id)
+	if  err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseModel{Message: "cannot get AI gen job."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+//@Summary Get AI GenPlan jobs By userID
+// @Description Get all plan generation jobs of a user, newest first
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/jobs/user/{id} [get]
+func (gateway *HTTPGateway) GetPlanJobsByUserID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	data, err := gateway.PlanJobService.GetJobsByUserID(id)
+	if  err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get AI gen jobs."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
 }
 
 func (gateway *HTTPGateway) GetAllGenGoal(ctx *fiber.Ctx) error{
@@ -130,4 +166,67 @@ func (gateway *HTTPGateway) DeleteGenGoal(ctx *fiber.Ctx) error{
 		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get all gen goal."})
 	}
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
-}
\ No newline at end of file
+}
+//@Summary Give feedback on a GeneratedPlan
+// @Description Generate a revised version of a plan from the user's feedback. The new plan is linked to the one it revises.
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "GeneratedPlan ID"
+// @Param bodyFeedback body entities.PlanFeedbackBody true "Plan feedback"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/goal/{id}/feedback [post]
+func (gateway *HTTPGateway) RefineGenGoal(ctx *fiber.Ctx) error{
+	id := ctx.Params("id")
+	bodyData := entities.PlanFeedbackBody{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if bodyData.Feedback == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "feedback is required"})
+	}
+	data, err := gateway.AiGenService.RefinePlan(id, bodyData.Feedback)
+	if  err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot refine gen goal."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+//@Summary Get the versions of a GeneratedPlan
+// @Description Get every version in the lineage of a plan, oldest first
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "GeneratedPlan ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/goal/{id}/versions [get]
+func (gateway *HTTPGateway) GetGenGoalVersions(ctx *fiber.Ctx) error{
+	id := ctx.Params("id")
+	data, err := gateway.AiGenService.GetPlanVersions(id)
+	if  err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get gen goal versions."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+//@Summary Diff two GeneratedPlan versions
+// @Description Get a section-level diff from one plan version to another
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "GeneratedPlan ID to diff from"
+// @Param to_id path string true "GeneratedPlan ID to diff to"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/goal/{id}/diff/{to_id} [get]
+func (gateway *HTTPGateway) DiffGenGoal(ctx *fiber.Ctx) error{
+	id := ctx.Params("id")
+	toID := ctx.Params("to_id")
+	data, err := gateway.AiGenService.DiffPlans(id, toID)
+	if  err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot diff gen goal."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
@@ -0,0 +1,77 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get assistant actions by User ID
+// @Description Get the habits, schedule blocks and life goal changes the AI assistant proposed for a user
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param status query string false "pending, processing, confirmed, rejected or failed"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/actions/{id} [get]
+func (gateway *HTTPGateway) GetAssistantActions(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	data, err := gateway.AssistantActionService.GetActionsByUserID(id, ctx.Query("status"))
+	if err != nil {
+		return serviceError(ctx, "cannot get assistant actions.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Confirm an assistant action
+// @Description Apply a pending action proposed by the AI assistant through the regular habit, schedule or life goal services
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param action_id path string true "Action ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/actions/{id}/{action_id}/confirm [post]
+func (gateway *HTTPGateway) ConfirmAssistantAction(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	actionID := ctx.Params("action_id")
+	if id == "" || actionID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid action id"})
+	}
+	data, err := gateway.AssistantActionService.ConfirmAction(id, actionID)
+	if err != nil {
+		status, response := serviceErrorResponse(ctx, "cannot confirm assistant action.", err)
+		response.Data = data
+		return ctx.Status(status).JSON(response)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Reject an assistant action
+// @Description Discard a pending action proposed by the AI assistant
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param action_id path string true "Action ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/actions/{id}/{action_id}/reject [post]
+func (gateway *HTTPGateway) RejectAssistantAction(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	actionID := ctx.Params("action_id")
+	if id == "" || actionID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid action id"})
+	}
+	data, err := gateway.AssistantActionService.RejectAction(id, actionID)
+	if err != nil {
+		return serviceError(ctx, "cannot reject assistant action.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
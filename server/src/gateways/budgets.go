@@ -0,0 +1,131 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+	service "go-fiber-template/src/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get Budgets by User ID
+// @Description List a user's monthly category budgets
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/budget/{id} [get]
+func (h *HTTPGateway) GetBudgetsByUserID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	data, err := h.FinanceService.GetBudgetsByUserID(id)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get budgets"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Create a Budget
+// @Description Set a monthly budget for an expense category. The currency defaults to the finance profile's and start_month to the current month. rollover is none, unspent (what is left is added to the next month) or full (overspending is taken off it too). With channels (email, web_push, webhook) an alert is sent when the month's spending first reaches each alert threshold, 80% and 100% by default.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param bodyBudget body entities.BudgetResponse true "Budget Data"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/budget/{id} [post]
+func (h *HTTPGateway) CreateBudget(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	bodyData := entities.BudgetResponse{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.FinanceService.CreateBudget(id, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot create budget.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Update a Budget
+// @Description Edit a budget. Fields left out keep their value; an empty channels list turns alerts off.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param budget_id path string true "Budget ID"
+// @Param bodyBudget body entities.BudgetResponse true "Budget fields to change"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/budget/{id}/{budget_id} [patch]
+func (h *HTTPGateway) UpdateBudget(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	budgetID := ctx.Params("budget_id")
+	if id == "" || budgetID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid budget id"})
+	}
+	bodyData := entities.BudgetResponse{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.FinanceService.UpdateBudget(id, budgetID, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot update budget.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Delete a Budget
+// @Description Delete a budget
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param budget_id path string true "Budget ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/budget/{id}/{budget_id} [delete]
+func (h *HTTPGateway) DeleteBudget(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	budgetID := ctx.Params("budget_id")
+	if id == "" || budgetID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid budget id"})
+	}
+	if err := h.FinanceService.DeleteBudget(id, budgetID); err != nil {
+		return serviceError(ctx, "cannot delete budget.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
+}
+
+// @Summary Get budget versus actual
+// @Description For each budget: the amount, what was carried over from earlier months, spending so far, what is left, the share used, the projected month-end spending (expenses still scheduled this month plus one-off spending so far extended to the whole month) and a status of under, warning or over. Expense categories without a budget are listed in unbudgeted.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param month query string false "Month (YYYY-MM), default the current month"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/budget/{id}/status [get]
+func (h *HTTPGateway) GetBudgetStatus(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	month, err := service.ParseBudgetMonth(ctx.Query("month"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := h.FinanceService.GetBudgetReport(id, month)
+	if err != nil {
+		return serviceError(ctx, "cannot get budget status.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
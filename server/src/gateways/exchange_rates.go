@@ -0,0 +1,56 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+	service "go-fiber-template/src/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Import exchange rates
+// @Description Admin only. Load exchange rates as JSON ({"rates": [{"base", "quote", "rate", "date", "source"}]}) or, with Content-Type text/csv, as CSV rows of base,quote,rate,date[,source]. A rate is what one unit of base is worth in quote on date and applies until the next one for the pair; a pair and date already stored are replaced.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Admin API key"
+// @Param bodyRates body entities.ExchangeRateBody true "Exchange rates"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 401 {object} entities.ResponseMessage
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/admin/exchange_rates [post]
+func (gateway *HTTPGateway) ImportExchangeRates(ctx *fiber.Ctx) error {
+	format := "json"
+	if strings.Contains(strings.ToLower(ctx.Get(fiber.HeaderContentType)), "csv") {
+		format = "csv"
+	}
+	rates, err := service.ParseExchangeRates(ctx.Body(), format)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := gateway.ExchangeRateService.ImportRates(rates, service.ExchangeRateSourceAdmin)
+	if err != nil {
+		return serviceError(ctx, "cannot import exchange rates.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get exchange rates
+// @Description Admin only. The stored exchange rate history, oldest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Admin API key"
+// @Param base query string false "Only rates from this currency"
+// @Param quote query string false "Only rates into this currency"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 401 {object} entities.ResponseMessage
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/admin/exchange_rates [get]
+func (gateway *HTTPGateway) GetExchangeRates(ctx *fiber.Ctx) error {
+	data, err := gateway.ExchangeRateService.GetRates(ctx.Query("base"), ctx.Query("quote"))
+	if err != nil {
+		return serviceError(ctx, "cannot get exchange rates.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
@@ -65,7 +65,7 @@ func (gateway *HTTPGateway) CreateFinance(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
 	}
 	if err := gateway.FinanceService.CreateFinance(id, body); err != nil {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot create new finance."})
+		return serviceError(ctx, "cannot create new finance.", err)
 	}
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "successfully created new finance"})
 }
\ No newline at end of file
@@ -2,6 +2,9 @@ package gateways
 
 import (
 	"go-fiber-template/domain/entities"
+	service "go-fiber-template/src/services"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	fiberlog "github.com/gofiber/fiber/v2/log"
 )
@@ -32,11 +35,13 @@ func (h *HTTPGateway) CreateHabit(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
 }
 // @Summary Get Habit by UserId
-// @Description Get Habits by user ID
+// @Description Get Habits by user ID, optionally filtered by status and category. A user without habits gets an empty list.
 // @Tags Habits
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
+// @Param status query string false "active, paused or archived"
+// @Param category query string false "Habit category"
 // @Success 200 {object} entities.ResponseModel
 // @Failure 403 {object} entities.ResponseModel
 // @Router /api/v1/users/habit/{id} [get]
@@ -45,9 +50,267 @@ func (h *HTTPGateway) GetHabitByUserID(ctx *fiber.Ctx) error {
 	if id == "" {
 		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
 	}
-	data, err := h.HabitsService.GetHabitsByUserID(id)
+	data, err := h.HabitsService.ListHabits(id, ctx.Query("status"), ctx.Query("category"))
+	if err != nil {
+		return serviceError(ctx, "cannot get habits", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Check in a Habit
+// @Description Record a completion of a habit. The date defaults to today in the user's timezone (or the timezone in the body); streaks are recomputed from all check-ins.
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Param bodyCheckIn body entities.HabitCheckInBody false "Check-in date and timezone"
+// @Success 201 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id}/checkin [post]
+func (h *HTTPGateway) CheckInHabit(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	habitID, err := strconv.Atoi(ctx.Params("habit_id"))
+	if id == "" || err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid habit id"})
+	}
+	bodyData := entities.HabitCheckInBody{}
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&bodyData); err != nil {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+		}
+	}
+	data, err := h.HabitsService.CheckIn(id, habitID, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot check in habit.", err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Undo a Habit check-in
+// @Description Remove the latest check-in of a habit on a date and recompute its streaks
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Param date path string true "Check-in date (YYYY-MM-DD)"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id}/checkin/{date} [delete]
+func (h *HTTPGateway) UndoHabitCheckIn(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	habitID, err := strconv.Atoi(ctx.Params("habit_id"))
+	if id == "" || err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid habit id"})
+	}
+	data, err := h.HabitsService.UndoCheckIn(id, habitID, ctx.Params("date"))
 	if err != nil {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get habits"})
+		return serviceError(ctx, "cannot undo habit check-in.", err)
 	}
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
-}
\ No newline at end of file
+}
+
+// @Summary Update a Habit
+// @Description Edit a habit's name, description, frequency, target count or category. Empty fields keep their value.
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Param bodyHabit body entities.HabitUpdate true "Habit fields to change"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id} [patch]
+func (h *HTTPGateway) UpdateHabit(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	habitID, err := strconv.Atoi(ctx.Params("habit_id"))
+	if id == "" || err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid habit id"})
+	}
+	bodyData := entities.HabitUpdate{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.HabitsService.UpdateHabit(id, habitID, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot update habit.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Pause a Habit
+// @Description Pause an active habit. Days, weeks or months during the pause do not break its streak.
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id}/pause [post]
+func (h *HTTPGateway) PauseHabit(ctx *fiber.Ctx) error {
+	return h.changeHabitStatus(ctx, h.HabitsService.PauseHabit)
+}
+
+// @Summary Resume a Habit
+// @Description Resume a paused habit
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id}/resume [post]
+func (h *HTTPGateway) ResumeHabit(ctx *fiber.Ctx) error {
+	return h.changeHabitStatus(ctx, h.HabitsService.ResumeHabit)
+}
+
+// @Summary Archive a Habit
+// @Description Archive an active or paused habit
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id}/archive [post]
+func (h *HTTPGateway) ArchiveHabit(ctx *fiber.Ctx) error {
+	return h.changeHabitStatus(ctx, h.HabitsService.ArchiveHabit)
+}
+
+// @Summary Restore a Habit
+// @Description Make an archived habit active again
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id}/restore [post]
+func (h *HTTPGateway) RestoreHabit(ctx *fiber.Ctx) error {
+	return h.changeHabitStatus(ctx, h.HabitsService.RestoreHabit)
+}
+
+func (h *HTTPGateway) changeHabitStatus(ctx *fiber.Ctx, change func(userID string, habitID int) (*entities.HabitModel, error)) error {
+	id := ctx.Params("id")
+	habitID, err := strconv.Atoi(ctx.Params("habit_id"))
+	if id == "" || err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid habit id"})
+	}
+	data, err := change(id, habitID)
+	if err != nil {
+		return serviceError(ctx, "cannot change habit status.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Delete a Habit
+// @Description Delete a habit and all of its check-ins
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id} [delete]
+func (h *HTTPGateway) DeleteHabit(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	habitID, err := strconv.Atoi(ctx.Params("habit_id"))
+	if id == "" || err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid habit id"})
+	}
+	if err := h.HabitsService.DeleteHabit(id, habitID); err != nil {
+		return serviceError(ctx, "cannot delete habit.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
+}
+
+// @Summary Get Habit occurrences
+// @Description Expand the habit's recurrence rule into its due occurrences, with the check-ins counted against each
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Param from query string false "Start date (YYYY-MM-DD), default today"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), default six days after from"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id}/occurrences [get]
+func (h *HTTPGateway) GetHabitOccurrences(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	habitID, err := strconv.Atoi(ctx.Params("habit_id"))
+	if id == "" || err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid habit id"})
+	}
+	from, to, err := service.ParseOccurrenceRange(ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := h.HabitsService.GetHabitOccurrences(id, habitID, from, to)
+	if err != nil {
+		return serviceError(ctx, "cannot get habit occurrences.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get Habit stats
+// @Description Completion rates over the given windows, current and best streak, a day-by-day heatmap (one row per week, Monday first) and weekday performance, computed from the habit's check-ins
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param habit_id path int true "Habit ID"
+// @Param windows query string false "Comma separated window lengths in days, default 7,30,90"
+// @Param days query int false "Days covered by the heatmap and weekday stats, default 91"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/{habit_id}/stats [get]
+func (h *HTTPGateway) GetHabitStats(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	habitID, err := strconv.Atoi(ctx.Params("habit_id"))
+	if id == "" || err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid habit id"})
+	}
+	query, err := service.ParseHabitStatsQuery(ctx.Query("windows"), ctx.Query("days"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := h.HabitsService.GetHabitStats(id, habitID, query)
+	if err != nil {
+		return serviceError(ctx, "cannot get habit stats.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get Habit stats by UserId
+// @Description Stats for each habit that is not archived, together with their combined completion rates, heatmap and weekday performance
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param windows query string false "Comma separated window lengths in days, default 7,30,90"
+// @Param days query int false "Days covered by the heatmap and weekday stats, default 91"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/habit/{id}/stats [get]
+func (h *HTTPGateway) GetUserHabitStats(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	query, err := service.ParseHabitStatsQuery(ctx.Query("windows"), ctx.Query("days"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := h.HabitsService.GetUserHabitStats(id, query)
+	if err != nil {
+		return serviceError(ctx, "cannot get habit stats.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
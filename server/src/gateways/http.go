@@ -1,9 +1,12 @@
 package gateways
 
 import (
+	"go-fiber-template/domain/entities"
 	service "go-fiber-template/src/services"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	fiberlog "github.com/gofiber/fiber/v2/log"
 )
 
 type HTTPGateway struct {
@@ -16,9 +19,17 @@ type HTTPGateway struct {
 	ScheduleService service.IScheduleService
 	HabitsService service.IHabitsService
 	MoodService service.IMoodService
+	UsageService service.IUsageService
+	AssistantActionService service.IAssistantActionService
+	PlanJobService service.IPlanJobService
+	WeeklyReviewService service.IWeeklyReviewService
+	ReminderService service.IReminderService
+	PlanAdoptionService service.IPlanAdoptionService
+	MoodInsightsService service.IMoodInsightsService
+	ExchangeRateService service.IExchangeRateService
 }
 
-func NewHTTPGateway(app *fiber.App, users service.IUsersService, lifeGoals service.ILifeGoalService, aiPrompts service.IAiPromptService, aiGen service.IAiGenService, finance service.IFinanceService, healthBackground service.IHealthBackgroundService, schedule service.IScheduleService, habits service.IHabitsService, mood service.IMoodService) {
+func NewHTTPGateway(app *fiber.App, users service.IUsersService, lifeGoals service.ILifeGoalService, aiPrompts service.IAiPromptService, aiGen service.IAiGenService, finance service.IFinanceService, healthBackground service.IHealthBackgroundService, schedule service.IScheduleService, habits service.IHabitsService, mood service.IMoodService, usage service.IUsageService, assistantActions service.IAssistantActionService, planJobs service.IPlanJobService, weeklyReviews service.IWeeklyReviewService, reminders service.IReminderService, planAdoption service.IPlanAdoptionService, moodInsights service.IMoodInsightsService, exchangeRates service.IExchangeRateService) {
 	gateway := &HTTPGateway{
 		UserService: users,
 		LifeGoalService: lifeGoals,
@@ -29,6 +40,14 @@ func NewHTTPGateway(app *fiber.App, users service.IUsersService, lifeGoals servi
 		ScheduleService: schedule,
 		HabitsService: habits,
 		MoodService: mood,
+		UsageService: usage,
+		AssistantActionService: assistantActions,
+		PlanJobService: planJobs,
+		WeeklyReviewService: weeklyReviews,
+		ReminderService: reminders,
+		PlanAdoptionService: planAdoption,
+		MoodInsightsService: moodInsights,
+		ExchangeRateService: exchangeRates,
 	}
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("Welcome to the Go Fiber")
@@ -37,4 +56,24 @@ func NewHTTPGateway(app *fiber.App, users service.IUsersService, lifeGoals servi
 	GatewayUsers(*gateway, app)
 	GatewayLifeGoals(*gateway, app)
 	GatewayAiGen(*gateway, app)
+	GatewayAdmin(*gateway, app)
+}
+
+// serviceError answers a failed service call with message, such as
+// "cannot create new finance.". A validation error is the caller's to fix, so
+// it is returned as 422 and named after message. Anything else, such as a
+// database error, is logged and the caller only gets message.
+func serviceError(ctx *fiber.Ctx, message string, err error) error {
+	status, response := serviceErrorResponse(ctx, message, err)
+	return ctx.Status(status).JSON(response)
+}
+
+// serviceErrorResponse is the status and body serviceError answers with, for
+// handlers that add data to the body.
+func serviceErrorResponse(ctx *fiber.Ctx, message string, err error) (int, entities.ResponseModel) {
+	if service.IsValidationError(err) {
+		return fiber.StatusUnprocessableEntity, entities.ResponseModel{Message: strings.TrimSuffix(message, ".") + ": " + err.Error()}
+	}
+	fiberlog.Errorf("HTTPGateway -> %s %s: %s \n", ctx.Method(), ctx.Route().Path, err)
+	return fiber.StatusForbidden, entities.ResponseModel{Message: message}
 }
@@ -0,0 +1,59 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+	service "go-fiber-template/src/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get LLM usage by User ID
+// @Description Get token usage and estimated cost of AI calls for a user, grouped by feature and model
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param from query string false "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), default today"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/usage/{id} [get]
+func (gateway *HTTPGateway) GetUserUsage(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	from, to, err := service.ParseUsageRange(ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := gateway.UsageService.GetUserUsage(id, from, to)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get usage."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get daily LLM usage
+// @Description Admin only. Daily aggregates of AI calls across all users
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Admin API key"
+// @Param from query string false "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), default today"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 401 {object} entities.ResponseMessage
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/admin/usage/daily [get]
+func (gateway *HTTPGateway) GetDailyUsage(ctx *fiber.Ctx) error {
+	from, to, err := service.ParseUsageRange(ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := gateway.UsageService.GetDailyUsage(from, to)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get daily usage."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
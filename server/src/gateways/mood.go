@@ -2,6 +2,7 @@ package gateways
 
 import (
 	"go-fiber-template/domain/entities"
+	service "go-fiber-template/src/services"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -42,26 +43,83 @@ func (gateway *HTTPGateway) GetMoodByID(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: mood})
 }
 
-// @Summary Get MoodData by id
-// @Description Get MoodData by id
+// @Summary Log a mood
+// @Description Log a mood with a 1 to 5 score (or a label such as "Good"), optional 1 to 5 energy and stress, sleep hours, tags and a note. The date defaults to today in the user's timezone. The note's sentiment and themes are stored with the entry; a note with signs of a crisis is flagged and the response carries support resources for the user's region in data.support.
 // @Tags Mood
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
-// @Param bodyMood body entities.MoodResponse true "Mood Data"
+// @Param bodyMood body entities.MoodBody true "Mood Data"
 // @Success 200 {object} entities.ResponseModel
 // @Failure 403 {object} entities.ResponseModel
 // @Router /api/v1/users/mood/{id} [post]
 func (gateway *HTTPGateway) NewMood(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
-	bodyData := entities.MoodResponse{}
+	bodyData := entities.MoodBody{}
 	if err := ctx.BodyParser(&bodyData); err != nil {
 		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
 	}
-	bodyData.UserID = id
-	data, err := gateway.MoodService.NewMood(bodyData);
+	data, err := gateway.MoodService.NewMood(id, bodyData);
 	if  err != nil {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot insert mood data"})
+		status, response := serviceErrorResponse(ctx, "cannot insert mood data.", err)
+		if data.Support != nil {
+			response.Data = entities.MoodEntry{Support: data.Support}
+		}
+		return ctx.Status(status).JSON(response)
 	}
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success" ,Data: data})
-}
\ No newline at end of file
+}
+
+// @Summary Get mood analytics
+// @Description Daily and weekly averages of score, energy and stress, their variability, tag frequency and streaks of low-mood days over a date range (default the last 30 days)
+// @Tags Mood
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD), default today"
+// @Param low query int false "Highest daily average score counted as low mood, default 2"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/mood/{id}/analytics [get]
+func (gateway *HTTPGateway) GetMoodAnalytics(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	query, err := service.ParseMoodAnalyticsQuery(ctx.Query("from"), ctx.Query("to"), ctx.Query("low"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := gateway.MoodService.GetMoodAnalytics(id, query)
+	if err != nil {
+		return serviceError(ctx, "cannot get mood analytics.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get mood insights
+// @Description Compares the average daily mood score on days with and without each habit, habit category, a full night's sleep and a busy schedule, with the number of days on each side and a confidence of low, medium or high
+// @Tags Mood
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param days query int false "Days of history to look at, default 180"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/mood/{id}/insights [get]
+func (gateway *HTTPGateway) GetMoodInsights(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	days, err := service.ParseMoodInsightsDays(ctx.Query("days"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := gateway.MoodInsightsService.GetMoodInsights(id, days)
+	if err != nil {
+		return serviceError(ctx, "cannot get mood insights.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
@@ -0,0 +1,148 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get habit and task suggestions from a GeneratedPlan
+// @Description Extract the recurring habits and one-off tasks a plan suggests. Each suggestion has an id to adopt it with and is marked when already adopted.
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "GeneratedPlan ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/goal/{id}/suggestions [get]
+func (gateway *HTTPGateway) GetPlanSuggestions(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid plan id"})
+	}
+	data, err := gateway.PlanAdoptionService.SuggestFromPlan(id)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get plan suggestions."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Adopt suggestions from a GeneratedPlan
+// @Description Create the chosen habit and task suggestions for the plan's user, linked to the plan. Suggestions already adopted are returned instead of created again.
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "GeneratedPlan ID"
+// @Param bodyAdopt body entities.PlanAdoptBody true "Suggestions to adopt"
+// @Success 201 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/goal/{id}/adopt [post]
+func (gateway *HTTPGateway) AdoptPlanSuggestions(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid plan id"})
+	}
+	bodyData := entities.PlanAdoptBody{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := gateway.PlanAdoptionService.AdoptFromPlan(id, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot adopt plan suggestions.", err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get the adherence to a GeneratedPlan
+// @Description Get how many occurrences of the habits adopted from a plan were completed since adoption, how many of its tasks are done, and the share of suggestions adopted
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "GeneratedPlan ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/goal/{id}/adherence [get]
+func (gateway *HTTPGateway) GetPlanAdherence(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid plan id"})
+	}
+	data, err := gateway.PlanAdoptionService.GetPlanAdherence(id)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get plan adherence."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get Tasks by UserId
+// @Description Get the tasks a user adopted from plans, optionally filtered by plan and status
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param plan_id query string false "GeneratedPlan ID"
+// @Param status query string false "todo or done"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/task/{id} [get]
+func (h *HTTPGateway) GetTasksByUserID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	data, err := h.PlanAdoptionService.ListTasks(id, ctx.Query("plan_id"), ctx.Query("status"))
+	if err != nil {
+		return serviceError(ctx, "cannot get tasks.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Update a Task status
+// @Description Mark a task as todo or done
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param task_id path string true "Task ID"
+// @Param bodyStatus body entities.PlanTaskStatusBody true "New status"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/task/{id}/{task_id} [patch]
+func (h *HTTPGateway) UpdateTaskStatus(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	taskID := ctx.Params("task_id")
+	if id == "" || taskID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid task id"})
+	}
+	bodyData := entities.PlanTaskStatusBody{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.PlanAdoptionService.UpdateTaskStatus(id, taskID, bodyData.Status)
+	if err != nil {
+		return serviceError(ctx, "cannot update task.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Delete a Task
+// @Description Delete a task adopted from a plan
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/task/{id}/{task_id} [delete]
+func (h *HTTPGateway) DeleteTask(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	taskID := ctx.Params("task_id")
+	if id == "" || taskID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid task id"})
+	}
+	if err := h.PlanAdoptionService.DeleteTask(id, taskID); err != nil {
+		return serviceError(ctx, "cannot delete task.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
+}
@@ -0,0 +1,136 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Create a Reminder
+// @Description Create a habit_due, mood_log or weekly_review reminder at a time of day on some days, in the user's timezone or the one given, delivered by email, web_push and/or webhook. Reminders falling in quiet hours are moved to the end of them.
+// @Tags Reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param bodyReminder body entities.ReminderResponse true "Reminder Data"
+// @Success 201 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/reminder/{id} [post]
+func (h *HTTPGateway) CreateReminder(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	bodyData := entities.ReminderResponse{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.ReminderService.CreateReminder(id, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot create reminder.", err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get Reminders by UserId
+// @Description Get a user's reminders with their next run and the outcome of the last delivery
+// @Tags Reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/reminder/{id} [get]
+func (h *HTTPGateway) GetRemindersByUserID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	data, err := h.ReminderService.GetRemindersByUserID(id)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get reminders"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Update a Reminder
+// @Description Edit a reminder. Empty fields keep their value; enabled turns it on or off.
+// @Tags Reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param reminder_id path string true "Reminder ID"
+// @Param bodyReminder body entities.ReminderResponse true "Reminder fields to change"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/reminder/{id}/{reminder_id} [patch]
+func (h *HTTPGateway) UpdateReminder(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	reminderID := ctx.Params("reminder_id")
+	if id == "" || reminderID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid reminder id"})
+	}
+	bodyData := entities.ReminderResponse{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.ReminderService.UpdateReminder(id, reminderID, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot update reminder.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Delete a Reminder
+// @Description Delete a reminder
+// @Tags Reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param reminder_id path string true "Reminder ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/reminder/{id}/{reminder_id} [delete]
+func (h *HTTPGateway) DeleteReminder(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	reminderID := ctx.Params("reminder_id")
+	if id == "" || reminderID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid reminder id"})
+	}
+	if err := h.ReminderService.DeleteReminder(id, reminderID); err != nil {
+		return serviceError(ctx, "cannot delete reminder.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
+}
+
+// @Summary Send a test Reminder
+// @Description Deliver a reminder now on all its channels, ignoring quiet hours and whether anything is due. Failed channels are listed in the message.
+// @Tags Reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param reminder_id path string true "Reminder ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/reminder/{id}/{reminder_id}/test [post]
+func (h *HTTPGateway) SendTestReminder(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	reminderID := ctx.Params("reminder_id")
+	if id == "" || reminderID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid reminder id"})
+	}
+	if err := h.ReminderService.SendTest(id, reminderID); err != nil {
+		return serviceError(ctx, "cannot send reminder.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
+}
+
+// @Summary Get the web push key
+// @Description Get the VAPID public key to pass to pushManager.subscribe as applicationServerKey. It is empty when web push is not configured.
+// @Tags Reminders
+// @Produce json
+// @Success 200 {object} entities.ResponseModel
+// @Router /api/v1/users/reminder_web_push_key [get]
+func (h *HTTPGateway) GetWebPushKey(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: fiber.Map{"public_key": h.ReminderService.WebPushKey()}})
+}
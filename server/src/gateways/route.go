@@ -1,6 +1,10 @@
 package gateways
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"go-fiber-template/src/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func GatewayUsers(gateway HTTPGateway, app *fiber.App) {
 	api := app.Group("/api/v1/users")
@@ -14,6 +18,23 @@ func GatewayUsers(gateway HTTPGateway, app *fiber.App) {
 	api.Post("/finance_info/:id", gateway.CreateFinance)
 	api.Get("/finance_info", gateway.GetAllFinance)
 	api.Get("/finance_info/:id", gateway.GetFinanceByUserID)
+	api.Get("/transaction/:id", gateway.GetTransactionsByUserID)
+	api.Post("/transaction/:id", gateway.CreateTransaction)
+	api.Patch("/transaction/:id/:transaction_id", gateway.UpdateTransaction)
+	api.Delete("/transaction/:id/:transaction_id", gateway.DeleteTransaction)
+	api.Get("/finance_summary/:id", gateway.GetFinanceSummary)
+	api.Get("/budget/:id", gateway.GetBudgetsByUserID)
+	api.Post("/budget/:id", gateway.CreateBudget)
+	api.Get("/budget/:id/status", gateway.GetBudgetStatus)
+	api.Patch("/budget/:id/:budget_id", gateway.UpdateBudget)
+	api.Delete("/budget/:id/:budget_id", gateway.DeleteBudget)
+	api.Get("/savings_goal/:id", gateway.GetSavingsGoals)
+	api.Post("/savings_goal/:id", gateway.CreateSavingsGoal)
+	api.Get("/savings_goal/:id/:goal_id", gateway.GetSavingsGoal)
+	api.Patch("/savings_goal/:id/:goal_id", gateway.UpdateSavingsGoal)
+	api.Delete("/savings_goal/:id/:goal_id", gateway.DeleteSavingsGoal)
+	api.Post("/savings_goal/:id/:goal_id/contribution", gateway.AddContribution)
+	api.Delete("/savings_goal/:id/:goal_id/contribution/:contribution_id", gateway.DeleteContribution)
 
 
 	api.Get("/health_background/", gateway.GetHealth)
@@ -25,13 +46,40 @@ func GatewayUsers(gateway HTTPGateway, app *fiber.App) {
 	api.Get("/schedule/:id", gateway.GetScheduleByID)
 	api.Post("/schedule/:id", gateway.CreateSchedule)
 	api.Get("/user/schedule/:id", gateway.GetScheduleByUserID)
+	api.Get("/schedule_block/:id", gateway.GetScheduleBlocksByUserID)
+	api.Post("/schedule_block/:id", gateway.CreateScheduleBlock)
+	api.Get("/schedule_block/:id/occurrences", gateway.GetScheduleOccurrences)
 
 	api.Get("/habit/:id", gateway.GetHabitByUserID)
 	api.Post("/habit/:id", gateway.CreateHabit)
+	api.Get("/habit/:id/stats", gateway.GetUserHabitStats)
+	api.Patch("/habit/:id/:habit_id", gateway.UpdateHabit)
+	api.Delete("/habit/:id/:habit_id", gateway.DeleteHabit)
+	api.Post("/habit/:id/:habit_id/pause", gateway.PauseHabit)
+	api.Post("/habit/:id/:habit_id/resume", gateway.ResumeHabit)
+	api.Post("/habit/:id/:habit_id/archive", gateway.ArchiveHabit)
+	api.Post("/habit/:id/:habit_id/restore", gateway.RestoreHabit)
+	api.Post("/habit/:id/:habit_id/checkin", gateway.CheckInHabit)
+	api.Delete("/habit/:id/:habit_id/checkin/:date", gateway.UndoHabitCheckIn)
+	api.Get("/habit/:id/:habit_id/occurrences", gateway.GetHabitOccurrences)
+	api.Get("/habit/:id/:habit_id/stats", gateway.GetHabitStats)
 
 	api.Get("/mood", gateway.GetAllMood)
 	api.Get("/mood/:id", gateway.GetMoodByID)
 	api.Post("/mood/:id", gateway.NewMood)
+	api.Get("/mood/:id/analytics", gateway.GetMoodAnalytics)
+	api.Get("/mood/:id/insights", gateway.GetMoodInsights)
+
+	api.Get("/reminder_web_push_key", gateway.GetWebPushKey)
+	api.Get("/reminder/:id", gateway.GetRemindersByUserID)
+	api.Post("/reminder/:id", gateway.CreateReminder)
+	api.Patch("/reminder/:id/:reminder_id", gateway.UpdateReminder)
+	api.Delete("/reminder/:id/:reminder_id", gateway.DeleteReminder)
+	api.Post("/reminder/:id/:reminder_id/test", gateway.SendTestReminder)
+
+	api.Get("/task/:id", gateway.GetTasksByUserID)
+	api.Patch("/task/:id/:task_id", gateway.UpdateTaskStatus)
+	api.Delete("/task/:id/:task_id", gateway.DeleteTask)
 
 	api.Post("/user/add_alldata/:id", gateway.PostAllInfomation)
 }
@@ -51,11 +99,37 @@ func GatewayAiGen(gateway HTTPGateway, app *fiber.App) {
 
 	api.Post("/add_ai_prompt/:id", gateway.CreateAIPrompt)
 	api.Post("/create_ai_gen/:id", gateway.CreateAiGen)
+	api.Get("/jobs/:job_id", gateway.GetPlanJob)
+	api.Get("/jobs/user/:id", gateway.GetPlanJobsByUserID)
 	api.Get("/ai_gens", gateway.GetAllGenGoal)
 	api.Get("/ai_gen/:id", gateway.GetGenGoalByUserID)
 	api.Delete("/goal/:id", gateway.DeleteGenGoal)
+	api.Post("/goal/:id/feedback", gateway.RefineGenGoal)
+	api.Get("/goal/:id/versions", gateway.GetGenGoalVersions)
+	api.Get("/goal/:id/diff/:to_id", gateway.DiffGenGoal)
+	api.Get("/goal/:id/suggestions", gateway.GetPlanSuggestions)
+	api.Post("/goal/:id/adopt", gateway.AdoptPlanSuggestions)
+	api.Get("/goal/:id/adherence", gateway.GetPlanAdherence)
 
 	api.Get("/chat/:id", gateway.GetAiGenChatByUserID)
 	api.Post("/chat/:id", gateway.GenerateAiAssitant)
 	api.Delete("/chat/:id", gateway.DeleteGenChat)
+
+	api.Get("/usage/:id", gateway.GetUserUsage)
+
+	api.Post("/review/:id", gateway.GenerateWeeklyReview)
+	api.Get("/review/:id", gateway.GetWeeklyReviews)
+	api.Get("/review/:id/latest", gateway.GetLatestWeeklyReview)
+
+	api.Get("/actions/:id", gateway.GetAssistantActions)
+	api.Post("/actions/:id/:action_id/confirm", gateway.ConfirmAssistantAction)
+	api.Post("/actions/:id/:action_id/reject", gateway.RejectAssistantAction)
+}
+
+func GatewayAdmin(gateway HTTPGateway, app *fiber.App) {
+	api := app.Group("/api/v1/admin", middlewares.SetAdminKeyHandler())
+
+	api.Get("/usage/daily", gateway.GetDailyUsage)
+	api.Post("/exchange_rates", gateway.ImportExchangeRates)
+	api.Get("/exchange_rates", gateway.GetExchangeRates)
 }
\ No newline at end of file
@@ -0,0 +1,181 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get Savings Goals by User ID
+// @Description List a user's savings goals with what has been saved, progress in percent, the monthly contribution needed to reach the target by the deadline and a status of achieved, on_track, off_track, overdue or no_deadline
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param lifegoal_id query string false "Only goals linked to this life goal"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/savings_goal/{id} [get]
+func (h *HTTPGateway) GetSavingsGoals(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	data, err := h.FinanceService.GetSavingsGoals(id, ctx.Query("lifegoal_id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get savings goals"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Get a Savings Goal
+// @Description Get a savings goal's progress with its contributions
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param goal_id path string true "Savings goal ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/savings_goal/{id}/{goal_id} [get]
+func (h *HTTPGateway) GetSavingsGoal(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	goalID := ctx.Params("goal_id")
+	if id == "" || goalID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid savings goal id"})
+	}
+	data, err := h.FinanceService.GetSavingsGoal(id, goalID)
+	if err != nil {
+		return serviceError(ctx, "cannot get savings goal.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Create a Savings Goal
+// @Description Create a savings goal with a name, target and optional deadline. The currency defaults to the finance profile's and start_date to today; current, what is already saved, is recorded as a first contribution. lifegoal_id links the goal to one of the user's life goals.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param bodySavingsGoal body entities.SavingsGoalBody true "Savings goal Data"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/savings_goal/{id} [post]
+func (h *HTTPGateway) CreateSavingsGoal(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	bodyData := entities.SavingsGoalBody{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.FinanceService.CreateSavingsGoal(id, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot create savings goal.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Update a Savings Goal
+// @Description Edit a savings goal. Fields left out keep their value; an empty deadline or lifegoal_id removes it. What has been saved changes through contributions.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param goal_id path string true "Savings goal ID"
+// @Param bodySavingsGoal body entities.SavingsGoalBody true "Savings goal fields to change"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/savings_goal/{id}/{goal_id} [patch]
+func (h *HTTPGateway) UpdateSavingsGoal(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	goalID := ctx.Params("goal_id")
+	if id == "" || goalID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid savings goal id"})
+	}
+	bodyData := entities.SavingsGoalBody{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.FinanceService.UpdateSavingsGoal(id, goalID, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot update savings goal.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Delete a Savings Goal
+// @Description Delete a savings goal with its contributions
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param goal_id path string true "Savings goal ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/savings_goal/{id}/{goal_id} [delete]
+func (h *HTTPGateway) DeleteSavingsGoal(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	goalID := ctx.Params("goal_id")
+	if id == "" || goalID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid savings goal id"})
+	}
+	if err := h.FinanceService.DeleteSavingsGoal(id, goalID); err != nil {
+		return serviceError(ctx, "cannot delete savings goal.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
+}
+
+// @Summary Add a Savings Contribution
+// @Description Record money put towards a savings goal, or taken out with a negative amount. The date defaults to today and cannot be in the future. Returns the goal's updated progress.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param goal_id path string true "Savings goal ID"
+// @Param bodyContribution body entities.SavingsContributionResponse true "Contribution Data"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/savings_goal/{id}/{goal_id}/contribution [post]
+func (h *HTTPGateway) AddContribution(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	goalID := ctx.Params("goal_id")
+	if id == "" || goalID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid savings goal id"})
+	}
+	bodyData := entities.SavingsContributionResponse{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.FinanceService.AddContribution(id, goalID, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot add contribution.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Delete a Savings Contribution
+// @Description Delete a contribution and return the goal's updated progress
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param goal_id path string true "Savings goal ID"
+// @Param contribution_id path string true "Contribution ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/savings_goal/{id}/{goal_id}/contribution/{contribution_id} [delete]
+func (h *HTTPGateway) DeleteContribution(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	goalID := ctx.Params("goal_id")
+	contributionID := ctx.Params("contribution_id")
+	if id == "" || goalID == "" || contributionID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid contribution id"})
+	}
+	data, err := h.FinanceService.DeleteContribution(id, goalID, contributionID)
+	if err != nil {
+		return serviceError(ctx, "cannot delete contribution.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
@@ -2,6 +2,7 @@ package gateways
 
 import (
 	"go-fiber-template/domain/entities"
+	service "go-fiber-template/src/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -92,3 +93,78 @@ func (gateway *HTTPGateway) CreateSchedule(ctx *fiber.Ctx) error {
 	}
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
 }
+
+// @Summary Get schedule blocks by User ID
+// @Description Get the recurring time blocks in a user's schedule
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/schedule_block/{id} [get]
+func (gateway *HTTPGateway) GetScheduleBlocksByUserID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+
+	data, err := gateway.ScheduleService.GetScheduleBlocksByUserID(id)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get schedule blocks"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Create a new schedule block
+// @Description Add a recurring time block to a user's schedule
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param bodyScheduleBlock body entities.ScheduleBlockResponse true "Schedule Block Data"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/schedule_block/{id} [post]
+func (gateway *HTTPGateway) CreateScheduleBlock(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	bodyData := entities.ScheduleBlockResponse{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+
+	if err := gateway.ScheduleService.AddScheduleBlock(id, bodyData); err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot insert new schedule block."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
+}
+
+// @Summary Get schedule occurrences by User ID
+// @Description Expand the user's recurring schedule blocks into dated occurrences, ordered by date and start time
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param from query string false "Start date (YYYY-MM-DD), default today"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), default six days after from"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/schedule_block/{id}/occurrences [get]
+func (gateway *HTTPGateway) GetScheduleOccurrences(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	from, to, err := service.ParseOccurrenceRange(ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := gateway.ScheduleService.GetScheduleOccurrences(id, from, to)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get schedule occurrences"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
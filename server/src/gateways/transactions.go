@@ -0,0 +1,140 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+	service "go-fiber-template/src/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get Transactions by User ID
+// @Description List a user's income and expenses, newest first. A recurring transaction is listed once, on the day it started.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param type query string false "income or expense"
+// @Param category query string false "Category"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/transaction/{id} [get]
+func (h *HTTPGateway) GetTransactionsByUserID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	query, err := service.ParseTransactionQuery(ctx.Query("from"), ctx.Query("to"), ctx.Query("type"), ctx.Query("category"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := h.FinanceService.ListTransactions(id, query)
+	if err != nil {
+		return serviceError(ctx, "cannot get transactions.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Create a Transaction
+// @Description Record an income or expense. The date defaults to today in the user's timezone, the currency to the one on the finance profile and the category to other. Expenses are fixed or flexible, by default from the category. A recurring transaction repeats every month on the same day until recurring_end.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param bodyTransaction body entities.TransactionBody true "Transaction Data"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/transaction/{id} [post]
+func (h *HTTPGateway) CreateTransaction(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	bodyData := entities.TransactionBody{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.FinanceService.CreateTransaction(id, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot create transaction.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Update a Transaction
+// @Description Edit a transaction. Fields left out keep their value; an empty recurring_end makes a recurring transaction repeat indefinitely.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param transaction_id path string true "Transaction ID"
+// @Param bodyTransaction body entities.TransactionBody true "Transaction fields to change"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/transaction/{id}/{transaction_id} [patch]
+func (h *HTTPGateway) UpdateTransaction(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	transactionID := ctx.Params("transaction_id")
+	if id == "" || transactionID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid transaction id"})
+	}
+	bodyData := entities.TransactionBody{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	data, err := h.FinanceService.UpdateTransaction(id, transactionID, bodyData)
+	if err != nil {
+		return serviceError(ctx, "cannot update transaction.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+// @Summary Delete a Transaction
+// @Description Delete a transaction, with every month it repeats in when it is recurring
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param transaction_id path string true "Transaction ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/transaction/{id}/{transaction_id} [delete]
+func (h *HTTPGateway) DeleteTransaction(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	transactionID := ctx.Params("transaction_id")
+	if id == "" || transactionID == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid transaction id"})
+	}
+	if err := h.FinanceService.DeleteTransaction(id, transactionID); err != nil {
+		return serviceError(ctx, "cannot delete transaction.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
+}
+
+// @Summary Get monthly finance summaries
+// @Description Income, expenses split into fixed and flexible, net, savings rate and totals per category for each month, in the currency of the finance profile. Recurring transactions count in every month they repeat in.
+// @Tags Finance
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param from query string false "First month (YYYY-MM), default five months before to"
+// @Param to query string false "Last month (YYYY-MM), default the current month"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/users/finance_summary/{id} [get]
+func (h *HTTPGateway) GetFinanceSummary(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid user id"})
+	}
+	months, err := service.ParseMonthRange(ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	data, err := h.FinanceService.GetMonthlySummaries(id, months)
+	if err != nil {
+		return serviceError(ctx, "cannot get finance summary.", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
@@ -177,7 +177,7 @@ func (h *HTTPGateway) PostAllInfomation(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "invalid json body"})
 	}
 	if err := h.FinanceService.CreateFinance(id, FinanceBody); err != nil {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot insert new finance."})
+		return serviceError(ctx, "cannot insert new finance.", err)
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success"})
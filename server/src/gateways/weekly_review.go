@@ -0,0 +1,72 @@
+package gateways
+
+import (
+	"go-fiber-template/domain/entities"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//@Summary Generate a weekly review
+// @Description Review a week of habit completions, mood entries and the active plan with the AI coach. The week is the one containing week_start, or last week when it is omitted. An earlier review of the same week is replaced.
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param week_start query string false "Any date of the week to review (YYYY-MM-DD)"
+// @Success 201 {object} entities.ResponseModel
+// @Failure 400 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/review/{id} [post]
+func (gateway *HTTPGateway) GenerateWeeklyReview(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	var weekStart time.Time
+	if raw := ctx.Query("week_start"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseModel{Message: "invalid week_start"})
+		}
+		weekStart = parsed
+	}
+	data, err := gateway.WeeklyReviewService.Generate(id, weekStart)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot generate weekly review."})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+//@Summary Get weekly reviews By userID
+// @Description Get every weekly review of a user, newest week first
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 403 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/review/{id} [get]
+func (gateway *HTTPGateway) GetWeeklyReviews(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	data, err := gateway.WeeklyReviewService.GetReviewsByUserID(id)
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "cannot get weekly reviews."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
+
+//@Summary Get the latest weekly review
+// @Description Get the review of the most recent week that has one
+// @Tags Ai Gen
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} entities.ResponseModel
+// @Failure 404 {object} entities.ResponseModel
+// @Router /api/v1/ai_gen/review/{id}/latest [get]
+func (gateway *HTTPGateway) GetLatestWeeklyReview(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	data, err := gateway.WeeklyReviewService.GetLatestReview(id)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseModel{Message: "cannot get weekly review."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{Message: "success", Data: data})
+}
@@ -0,0 +1,96 @@
+package locale
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages, as ISO 639-1 codes.
+const (
+	English = "en"
+	Thai    = "th"
+)
+
+var names = map[string]string{
+	English: "English",
+	Thai:    "Thai",
+}
+
+// Normalize maps a language tag such as "th-TH", "TH" or "thai" to a
+// supported code, or returns "" when the language is not supported.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	switch tag {
+	case English, "english":
+		return English
+	case Thai, "thai", "ไทย":
+		return Thai
+	}
+	return ""
+}
+
+// Default is DEFAULT_LANGUAGE when it is supported, otherwise English.
+func Default() string {
+	if lang := Normalize(os.Getenv("DEFAULT_LANGUAGE")); lang != "" {
+		return lang
+	}
+	return English
+}
+
+// Name returns the English name of a supported language, for prompts.
+func Name(lang string) string {
+	if name, ok := names[Normalize(lang)]; ok {
+		return name
+	}
+	return names[English]
+}
+
+// Resolve picks the first supported language from candidates, which are tried
+// in order, and falls back to Default.
+func Resolve(candidates ...string) string {
+	for _, candidate := range candidates {
+		if lang := Normalize(candidate); lang != "" {
+			return lang
+		}
+	}
+	return Default()
+}
+
+// FromAcceptLanguage returns the supported language the client prefers most
+// in an Accept-Language header, or "" when none is supported.
+func FromAcceptLanguage(header string) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	accepted := []weighted{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := Normalize(fields[0])
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			accepted = append(accepted, weighted{lang: lang, q: q})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].q > accepted[j].q })
+	if len(accepted) == 0 {
+		return ""
+	}
+	return accepted[0].lang
+}
@@ -0,0 +1,147 @@
+package locale
+
+import (
+	"strings"
+)
+
+// messages translates the English API messages used by the gateways. Keys
+// ending in ": " are prefixes; the text after them (usually an error) is
+// kept as is.
+var messages = map[string]map[string]string{
+	Thai: {
+		"success":                                       "สำเร็จ",
+		"invalid json body":                             "ข้อมูล JSON ไม่ถูกต้อง",
+		"invalid json body please fill all":             "ข้อมูล JSON ไม่ถูกต้อง กรุณากรอกข้อมูลให้ครบ",
+		"invalid user id":                               "รหัสผู้ใช้ไม่ถูกต้อง",
+		"invalid life goal id":                          "รหัสเป้าหมายชีวิตไม่ถูกต้อง",
+		"invalid action id":                             "รหัสรายการไม่ถูกต้อง",
+		"invalid sender":                                "ผู้ส่งไม่ถูกต้อง",
+		"feedback is required":                          "กรุณาระบุความคิดเห็น",
+		"cannot insert new user account.":               "ไม่สามารถสร้างบัญชีผู้ใช้ใหม่ได้",
+		"cannot get user data":                          "ไม่สามารถดึงข้อมูลผู้ใช้ได้",
+		"cannot get all users data":                     "ไม่สามารถดึงข้อมูลผู้ใช้ทั้งหมดได้",
+		"cannot update user data":                       "ไม่สามารถแก้ไขข้อมูลผู้ใช้ได้",
+		"cannot delete user data":                       "ไม่สามารถลบข้อมูลผู้ใช้ได้",
+		"cannot insert new schedule.":                   "ไม่สามารถเพิ่มตารางเวลาได้",
+		"cannot insert new schedule block.":             "ไม่สามารถเพิ่มช่วงเวลาในตารางได้",
+		"cannot get schedule data":                      "ไม่สามารถดึงข้อมูลตารางเวลาได้",
+		"cannot get all schedule data":                  "ไม่สามารถดึงข้อมูลตารางเวลาทั้งหมดได้",
+		"cannot get schedule blocks":                    "ไม่สามารถดึงช่วงเวลาในตารางได้",
+		"cannot get schedule occurrences":               "ไม่สามารถดึงรอบของช่วงเวลาในตารางได้",
+		"cannot insert new life goal.":                  "ไม่สามารถเพิ่มเป้าหมายชีวิตได้",
+		"cannot get life goal data":                     "ไม่สามารถดึงข้อมูลเป้าหมายชีวิตได้",
+		"cannot get all life goals":                     "ไม่สามารถดึงเป้าหมายชีวิตทั้งหมดได้",
+		"cannot update life goal":                       "ไม่สามารถแก้ไขเป้าหมายชีวิตได้",
+		"successfully created new life goal":            "สร้างเป้าหมายชีวิตเรียบร้อยแล้ว",
+		"successfully updated life goal":                "แก้ไขเป้าหมายชีวิตเรียบร้อยแล้ว",
+		"cannot insert new health background":           "ไม่สามารถเพิ่มข้อมูลสุขภาพได้",
+		"cannot get health data by user ID":             "ไม่สามารถดึงข้อมูลสุขภาพของผู้ใช้ได้",
+		"cannot get all health data":                    "ไม่สามารถดึงข้อมูลสุขภาพทั้งหมดได้",
+		"successfully created new health background":    "เพิ่มข้อมูลสุขภาพเรียบร้อยแล้ว",
+		"cannot insert new finance: ":                   "ไม่สามารถเพิ่มข้อมูลการเงินได้: ",
+		"cannot create new finance: ":                   "ไม่สามารถสร้างข้อมูลการเงินได้: ",
+		"successfully created new finance":              "สร้างข้อมูลการเงินเรียบร้อยแล้ว",
+		"successfully fetched finance records":          "ดึงข้อมูลการเงินเรียบร้อยแล้ว",
+		"successfully fetched finance records for user": "ดึงข้อมูลการเงินของผู้ใช้เรียบร้อยแล้ว",
+		"failed to fetch finance records":               "ไม่สามารถดึงข้อมูลการเงินได้",
+		"failed to fetch finance records for user":      "ไม่สามารถดึงข้อมูลการเงินของผู้ใช้ได้",
+		"invalid transaction id":                        "รหัสรายการเงินไม่ถูกต้อง",
+		"cannot get transactions: ":                     "ไม่สามารถดึงรายการรายรับรายจ่ายได้: ",
+		"cannot create transaction: ":                   "ไม่สามารถบันทึกรายการรายรับรายจ่ายได้: ",
+		"cannot update transaction: ":                   "ไม่สามารถแก้ไขรายการรายรับรายจ่ายได้: ",
+		"cannot delete transaction: ":                   "ไม่สามารถลบรายการรายรับรายจ่ายได้: ",
+		"cannot get finance summary: ":                  "ไม่สามารถสรุปรายรับรายจ่ายรายเดือนได้: ",
+		"invalid budget id":                             "รหัสงบประมาณไม่ถูกต้อง",
+		"cannot get budgets":                            "ไม่สามารถดึงงบประมาณได้",
+		"cannot create budget: ":                        "ไม่สามารถสร้างงบประมาณได้: ",
+		"cannot update budget: ":                        "ไม่สามารถแก้ไขงบประมาณได้: ",
+		"cannot delete budget: ":                        "ไม่สามารถลบงบประมาณได้: ",
+		"cannot get budget status: ":                    "ไม่สามารถเปรียบเทียบงบประมาณกับค่าใช้จ่ายจริงได้: ",
+		"invalid savings goal id":                       "รหัสเป้าหมายการออมไม่ถูกต้อง",
+		"invalid contribution id":                       "รหัสรายการออมไม่ถูกต้อง",
+		"cannot get savings goals":                      "ไม่สามารถดึงเป้าหมายการออมได้",
+		"cannot get savings goal: ":                     "ไม่สามารถดึงเป้าหมายการออมได้: ",
+		"cannot create savings goal: ":                  "ไม่สามารถสร้างเป้าหมายการออมได้: ",
+		"cannot update savings goal: ":                  "ไม่สามารถแก้ไขเป้าหมายการออมได้: ",
+		"cannot delete savings goal: ":                  "ไม่สามารถลบเป้าหมายการออมได้: ",
+		"cannot add contribution: ":                     "ไม่สามารถบันทึกเงินออมได้: ",
+		"cannot delete contribution: ":                  "ไม่สามารถลบรายการเงินออมได้: ",
+		"cannot create new habit":                       "ไม่สามารถสร้างนิสัยใหม่ได้",
+		"cannot get habits":                             "ไม่สามารถดึงข้อมูลนิสัยได้",
+		"cannot get habits: ":                           "ไม่สามารถดึงข้อมูลนิสัยได้: ",
+		"invalid habit id":                              "รหัสนิสัยไม่ถูกต้อง",
+		"cannot check in habit: ":                       "ไม่สามารถบันทึกการทำนิสัยได้: ",
+		"cannot undo habit check-in: ":                  "ไม่สามารถยกเลิกการบันทึกนิสัยได้: ",
+		"cannot update habit: ":                         "ไม่สามารถแก้ไขนิสัยได้: ",
+		"cannot change habit status: ":                  "ไม่สามารถเปลี่ยนสถานะนิสัยได้: ",
+		"cannot delete habit: ":                         "ไม่สามารถลบนิสัยได้: ",
+		"cannot get habit occurrences: ":                "ไม่สามารถดึงรอบของนิสัยได้: ",
+		"cannot get habit stats: ":                      "ไม่สามารถดึงสถิติของนิสัยได้: ",
+		"cannot insert mood data: ":                     "ไม่สามารถบันทึกอารมณ์ได้: ",
+		"cannot get mood analytics: ":                   "ไม่สามารถวิเคราะห์อารมณ์ได้: ",
+		"cannot get mood insights: ":                    "ไม่สามารถดูข้อมูลเชิงลึกเกี่ยวกับอารมณ์ได้: ",
+		"cannot get mood.":                              "ไม่สามารถดึงข้อมูลอารมณ์ได้",
+		"cannot create reminder: ":                      "ไม่สามารถสร้างการแจ้งเตือนได้: ",
+		"cannot get reminders":                          "ไม่สามารถดึงการแจ้งเตือนได้",
+		"invalid reminder id":                           "รหัสการแจ้งเตือนไม่ถูกต้อง",
+		"cannot update reminder: ":                      "ไม่สามารถแก้ไขการแจ้งเตือนได้: ",
+		"cannot delete reminder: ":                      "ไม่สามารถลบการแจ้งเตือนได้: ",
+		"cannot send reminder: ":                        "ไม่สามารถส่งการแจ้งเตือนได้: ",
+		"cannot get tasks: ":                            "ไม่สามารถดึงรายการงานได้: ",
+		"invalid task id":                               "รหัสงานไม่ถูกต้อง",
+		"cannot update task: ":                          "ไม่สามารถแก้ไขงานได้: ",
+		"cannot delete task: ":                          "ไม่สามารถลบงานได้: ",
+		"cannot create new AI prompt.":                  "ไม่สามารถสร้างพรอมต์ AI ได้",
+		"successfully created new AI prompt":            "สร้างพรอมต์ AI เรียบร้อยแล้ว",
+		"cannot create new AI gen.":                     "ไม่สามารถสร้างแผนด้วย AI ได้",
+		"AI gen job queued":                             "เพิ่มงานสร้างแผนด้วย AI เข้าคิวแล้ว",
+		"cannot get AI gen job.":                        "ไม่สามารถดึงสถานะงานสร้างแผนได้",
+		"cannot get AI gen jobs.":                       "ไม่สามารถดึงรายการงานสร้างแผนได้",
+		"cannot get gen goal.":                          "ไม่สามารถดึงแผนที่สร้างไว้ได้",
+		"cannot get all gen goal.":                      "ไม่สามารถดึงแผนที่สร้างไว้ทั้งหมดได้",
+		"cannot refine gen goal.":                       "ไม่สามารถปรับแผนตามความคิดเห็นได้",
+		"cannot get gen goal versions.":                 "ไม่สามารถดึงเวอร์ชันของแผนได้",
+		"cannot diff gen goal.":                         "ไม่สามารถเปรียบเทียบแผนได้",
+		"cannot get plan suggestions.":                  "ไม่สามารถดึงนิสัยและงานที่แผนแนะนำได้",
+		"invalid plan id":                               "รหัสแผนไม่ถูกต้อง",
+		"cannot adopt plan suggestions: ":               "ไม่สามารถนำนิสัยและงานจากแผนมาใช้ได้: ",
+		"cannot get plan adherence.":                    "ไม่สามารถดึงความคืบหน้าในการทำตามแผนได้",
+		"cannot get gen chat.":                          "ไม่สามารถสนทนากับผู้ช่วย AI ได้",
+		"cannot get all gen chat.":                      "ไม่สามารถจัดการประวัติการสนทนาได้",
+		"invalid week_start":                            "วันที่เริ่มต้นสัปดาห์ไม่ถูกต้อง",
+		"cannot generate weekly review.":                "ไม่สามารถสร้างสรุปรายสัปดาห์ได้",
+		"cannot get weekly reviews.":                    "ไม่สามารถดึงสรุปรายสัปดาห์ได้",
+		"cannot get weekly review.":                     "ไม่พบสรุปรายสัปดาห์",
+		"cannot get usage.":                             "ไม่สามารถดึงข้อมูลการใช้งานได้",
+		"cannot get daily usage.":                       "ไม่สามารถดึงข้อมูลการใช้งานรายวันได้",
+		"cannot get assistant actions: ":                "ไม่สามารถดึงรายการที่ผู้ช่วยเสนอได้: ",
+		"cannot confirm assistant action: ":             "ไม่สามารถยืนยันรายการที่ผู้ช่วยเสนอได้: ",
+		"cannot reject assistant action: ":              "ไม่สามารถปฏิเสธรายการที่ผู้ช่วยเสนอได้: ",
+		"Unauthorization Token.":                        "โทเคนไม่ถูกต้องหรือหมดอายุ",
+		"Unauthorization admin key.":                    "คีย์ผู้ดูแลระบบไม่ถูกต้อง",
+		"cannot import exchange rates: ":                "ไม่สามารถนำเข้าอัตราแลกเปลี่ยนได้: ",
+		"cannot get exchange rates: ":                   "ไม่สามารถดึงอัตราแลกเปลี่ยนได้: ",
+	},
+}
+
+// Translate returns message in lang, or message unchanged when there is no
+// translation.
+func Translate(lang string, message string) string {
+	catalog, ok := messages[Normalize(lang)]
+	if !ok {
+		return message
+	}
+	if translated, ok := catalog[message]; ok {
+		return translated
+	}
+	// A generic "cannot ..." message shares the prefix of its detailed form.
+	if translated, ok := catalog[strings.TrimSuffix(message, ".")+": "]; ok {
+		return strings.TrimSuffix(translated, ": ")
+	}
+	for key, translated := range catalog {
+		if strings.HasSuffix(key, ": ") && strings.HasPrefix(message, key) {
+			return translated + strings.TrimPrefix(message, key)
+		}
+	}
+	return message
+}
@@ -0,0 +1,36 @@
+package locale
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// gatewayMessage matches the string literal a gateway response message starts
+// with and the messages passed to serviceError and serviceErrorResponse.
+var gatewayMessage = regexp.MustCompile(`(?:Message:\s*|serviceError(?:Response)?\(\w+,\s*)"([^"]*)"`)
+
+func TestEveryGatewayMessageHasThai(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "gateways", "*.go"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no gateway sources found: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range gatewayMessage.FindAllStringSubmatch(string(source), -1) {
+			message := match[1]
+			if message == "" || seen[message] {
+				continue
+			}
+			seen[message] = true
+			if Translate(Thai, message) == message {
+				t.Errorf("%s: no Thai translation for %q", filepath.Base(file), message)
+			}
+		}
+	}
+}
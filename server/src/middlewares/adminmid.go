@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"go-fiber-template/domain/entities"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetAdminKeyHandler guards admin routes with the X-Admin-Key header. When
+// ADMIN_API_KEY is not configured every admin request is rejected.
+func SetAdminKeyHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		expected := os.Getenv("ADMIN_API_KEY")
+		provided := c.Get("X-Admin-Key")
+		if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(provided)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization admin key."})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"go-fiber-template/src/locale"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocaleKey is where SetLocaleHandler stores the request language in
+// fiber.Ctx locals.
+const LocaleKey = "lang"
+
+// SetLocaleHandler picks the response language from the lang query parameter,
+// then the Accept-Language header, then DEFAULT_LANGUAGE, and translates the
+// "message" field of JSON responses into it.
+func SetLocaleHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		lang := locale.Resolve(c.Query("lang"), locale.FromAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)))
+		c.Locals(LocaleKey, lang)
+		err := c.Next()
+		c.Set(fiber.HeaderContentLanguage, lang)
+		if lang == locale.English || !strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+			return err
+		}
+		var payload map[string]json.RawMessage
+		if json.Unmarshal(c.Response().Body(), &payload) != nil {
+			return err
+		}
+		var message string
+		if json.Unmarshal(payload["message"], &message) != nil {
+			return err
+		}
+		translated := locale.Translate(lang, message)
+		if translated == message {
+			return err
+		}
+		payload["message"], _ = json.Marshal(translated)
+		if body, marshalErr := json.Marshal(payload); marshalErr == nil {
+			c.Response().SetBody(body)
+		}
+		return err
+	}
+}
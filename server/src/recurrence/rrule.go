@@ -0,0 +1,363 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of an RRULE. Only the frequencies that make
+// sense for habits and schedule blocks are supported.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum is one BYDAY entry. N picks the Nth such weekday of the month,
+// counting from the end when negative, and is 0 for every such weekday.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Day]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Day]
+}
+
+// Rule is the subset of an RFC 5545 RRULE used by the planner: FREQ,
+// INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL and WKST=MO. All dates are
+// calendar dates; times of day are not part of a rule.
+//
+// Unlike RFC 5545, a WEEKLY or MONTHLY rule without BYDAY or BYMONTHDAY does
+// not repeat on DTSTART's weekday or day of month. It is a flexible rule: the
+// occurrence is the whole week or month and can be done on any day in it,
+// which is what "3 times a week" means for a habit.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+// Occurrence is a due window of whole days, from Start up to but not
+// including End.
+type Occurrence struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Days is the length of the window in days.
+func (o Occurrence) Days() int {
+	return int(o.End.Sub(o.Start).Hours()/24 + 0.5)
+}
+
+// Contains reports whether date falls in the window.
+func (o Occurrence) Contains(date time.Time) bool {
+	date = Date(date)
+	return !date.Before(o.Start) && date.Before(o.End)
+}
+
+// Date truncates t to midnight UTC of its calendar date, which is how rules
+// represent days.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// FromFrequency maps the plain daily, weekly and monthly frequencies habits
+// used before recurrence rules existed.
+func FromFrequency(frequency string) (Rule, bool) {
+	switch strings.ToLower(strings.TrimSpace(frequency)) {
+	case "daily":
+		return Rule{Freq: Daily, Interval: 1}, true
+	case "weekly":
+		return Rule{Freq: Weekly, Interval: 1}, true
+	case "monthly":
+		return Rule{Freq: Monthly, Interval: 1}, true
+	}
+	return Rule{}, false
+}
+
+// Parse reads an RRULE such as "FREQ=WEEKLY;BYDAY=MO,WE,FR" or
+// "RRULE:FREQ=MONTHLY;BYDAY=1SU".
+func Parse(value string) (Rule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+	rule := Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if !ok || val == "" {
+			return Rule{}, fmt.Errorf("rule part %q needs a value", part)
+		}
+		if seen[key] {
+			return Rule{}, fmt.Errorf("%s is given more than once", key)
+		}
+		seen[key] = true
+		switch key {
+		case "FREQ":
+			switch Frequency(val) {
+			case Daily, Weekly, Monthly:
+				rule.Freq = Frequency(val)
+			default:
+				return Rule{}, fmt.Errorf("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return Rule{}, fmt.Errorf("INTERVAL must be a positive number")
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				day, err := parseWeekdayNum(item)
+				if err != nil {
+					return Rule{}, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(val, ",") {
+				day, err := strconv.Atoi(strings.TrimSpace(item))
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return Rule{}, fmt.Errorf("BYMONTHDAY %q must be 1 to 31 or -31 to -1", item)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, day)
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return Rule{}, fmt.Errorf("COUNT must be a positive number")
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Until = &until
+		case "WKST":
+			if val != "MO" {
+				return Rule{}, fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			return Rule{}, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+	if rule.Freq == "" {
+		return Rule{}, fmt.Errorf("FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return Rule{}, fmt.Errorf("COUNT and UNTIL cannot be used together")
+	}
+	if rule.Freq == Weekly && len(rule.ByMonthDay) > 0 {
+		return Rule{}, fmt.Errorf("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return Rule{}, fmt.Errorf("numbered BYDAY such as 1SU needs FREQ=MONTHLY")
+		}
+	}
+	return rule, nil
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", value)
+	}
+	day, ok := weekdayCodes[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", value)
+	}
+	n := 0
+	if prefix := value[:len(value)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", value)
+		}
+	}
+	return WeekdayNum{N: n, Day: day}, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102", "20060102T150405Z", "20060102T150405"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return Date(until), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("UNTIL must be YYYYMMDD")
+}
+
+// String formats the rule in canonical form, so equal rules compare equal.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Flexible reports whether occurrences are whole weeks or months rather than
+// single days.
+func (r Rule) Flexible() bool {
+	return r.Freq != Daily && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0
+}
+
+// Weekdays lists the distinct weekdays named in BYDAY, in rule order.
+func (r Rule) Weekdays() []time.Weekday {
+	days := []time.Weekday{}
+	seen := map[time.Weekday]bool{}
+	for _, day := range r.ByDay {
+		if !seen[day.Day] {
+			seen[day.Day] = true
+			days = append(days, day.Day)
+		}
+	}
+	return days
+}
+
+func (r Rule) periodStart(date time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	case Monthly:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return date
+	}
+}
+
+func (r Rule) nextPeriod(start time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return start.AddDate(0, 0, 7)
+	case Monthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// matches reports whether date satisfies the BYDAY and BYMONTHDAY filters.
+func (r Rule) matches(date time.Time) bool {
+	if len(r.ByMonthDay) > 0 {
+		last := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		found := false
+		for _, day := range r.ByMonthDay {
+			found = found || day == date.Day() || last+day+1 == date.Day()
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.ByDay) > 0 {
+		last := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		nth := (date.Day()-1)/7 + 1
+		nthFromEnd := -((last-date.Day())/7 + 1)
+		found := false
+		for _, day := range r.ByDay {
+			found = found || (day.Day == date.Weekday() && (day.N == 0 || day.N == nth || day.N == nthFromEnd))
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Between expands the rule from dtstart, the date of the first possible
+// occurrence, and returns the occurrences that overlap the days from up to
+// but not including to. INTERVAL counts periods from the one containing
+// dtstart, and COUNT counts occurrences from dtstart even when they fall
+// before from.
+func (r Rule) Between(dtstart time.Time, from time.Time, to time.Time) []Occurrence {
+	dtstart, from, to = Date(dtstart), Date(from), Date(to)
+	interval := max(r.Interval, 1)
+	occurrences := []Occurrence{}
+	count := 0
+	index := 0
+	for period := r.periodStart(dtstart); period.Before(to); period = r.nextPeriod(period) {
+		index++
+		if (index-1)%interval != 0 {
+			continue
+		}
+		end := r.nextPeriod(period)
+		if r.Flexible() {
+			if r.Until != nil && period.After(*r.Until) {
+				break
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				break
+			}
+			if end.After(from) {
+				occurrences = append(occurrences, Occurrence{Start: period, End: end})
+			}
+			continue
+		}
+		day := period
+		if day.Before(dtstart) {
+			day = dtstart
+		}
+		for ; day.Before(end) && day.Before(to); day = day.AddDate(0, 0, 1) {
+			if !r.matches(day) {
+				continue
+			}
+			if r.Until != nil && day.After(*r.Until) {
+				return occurrences
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return occurrences
+			}
+			if !day.Before(from) {
+				occurrences = append(occurrences, Occurrence{Start: day, End: day.AddDate(0, 0, 1)})
+			}
+		}
+	}
+	return occurrences
+}
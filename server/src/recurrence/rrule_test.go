@@ -0,0 +1,149 @@
+package recurrence
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func day(value string) time.Time {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+// formatOccurrences shows a single-day occurrence as its date and a longer
+// one as its start and exclusive end.
+func formatOccurrences(occurrences []Occurrence) []string {
+	formatted := []string{}
+	for _, occurrence := range occurrences {
+		value := occurrence.Start.Format("2006-01-02")
+		if occurrence.Days() != 1 {
+			value += "/" + occurrence.End.Format("2006-01-02")
+		}
+		formatted = append(formatted, value)
+	}
+	return formatted
+}
+
+func TestBetween(t *testing.T) {
+	// 1 March 2026 is a Sunday.
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart string
+		from    string
+		to      string
+		want    []string
+	}{
+		{"daily", "FREQ=DAILY", "2026-03-01", "2026-03-01", "2026-03-04", []string{"2026-03-01", "2026-03-02", "2026-03-03"}},
+		{"daily from dtstart only", "FREQ=DAILY", "2026-03-03", "2026-03-01", "2026-03-05", []string{"2026-03-03", "2026-03-04"}},
+		{"every other day", "FREQ=DAILY;INTERVAL=2", "2026-03-01", "2026-03-01", "2026-03-07", []string{"2026-03-01", "2026-03-03", "2026-03-05"}},
+		{"weekdays", "FREQ=WEEKLY;BYDAY=MO,WE,FR", "2026-03-02", "2026-03-02", "2026-03-09", []string{"2026-03-02", "2026-03-04", "2026-03-06"}},
+		{"every other tuesday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", "2026-03-02", "2026-03-02", "2026-04-05", []string{"2026-03-03", "2026-03-17", "2026-03-31"}},
+		{"last friday", "FREQ=MONTHLY;BYDAY=-1FR", "2026-01-01", "2026-01-01", "2026-04-01", []string{"2026-01-30", "2026-02-27", "2026-03-27"}},
+		{"first sunday", "FREQ=MONTHLY;BYDAY=1SU", "2026-01-01", "2026-01-01", "2026-04-01", []string{"2026-01-04", "2026-02-01", "2026-03-01"}},
+		{"31st skips short months", "FREQ=MONTHLY;BYMONTHDAY=31", "2026-01-01", "2026-01-01", "2026-05-01", []string{"2026-01-31", "2026-03-31"}},
+		{"last day of the month", "FREQ=MONTHLY;BYMONTHDAY=-1", "2026-01-01", "2026-01-01", "2026-04-01", []string{"2026-01-31", "2026-02-28", "2026-03-31"}},
+		{"last day in a leap year", "FREQ=MONTHLY;BYMONTHDAY=-1", "2028-02-01", "2028-02-01", "2028-03-01", []string{"2028-02-29"}},
+		{"count includes days before from", "FREQ=DAILY;COUNT=3", "2026-03-01", "2026-03-02", "2026-03-10", []string{"2026-03-02", "2026-03-03"}},
+		{"until is inclusive", "FREQ=DAILY;UNTIL=20260303", "2026-03-01", "2026-03-01", "2026-03-10", []string{"2026-03-01", "2026-03-02", "2026-03-03"}},
+		{"count with byday", "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3", "2026-03-02", "2026-03-01", "2026-03-31", []string{"2026-03-02", "2026-03-05", "2026-03-09"}},
+		{"flexible week", "FREQ=WEEKLY", "2026-03-04", "2026-03-04", "2026-03-18", []string{"2026-03-02/2026-03-09", "2026-03-09/2026-03-16", "2026-03-16/2026-03-23"}},
+		{"flexible week count", "FREQ=WEEKLY;COUNT=2", "2026-03-04", "2026-03-04", "2026-03-31", []string{"2026-03-02/2026-03-09", "2026-03-09/2026-03-16"}},
+		{"flexible week until", "FREQ=WEEKLY;UNTIL=20260310", "2026-03-04", "2026-03-04", "2026-03-31", []string{"2026-03-02/2026-03-09", "2026-03-09/2026-03-16"}},
+		{"flexible month", "FREQ=MONTHLY", "2026-01-31", "2026-02-15", "2026-03-15", []string{"2026-02-01/2026-03-01", "2026-03-01/2026-04-01"}},
+		{"flexible every other month", "FREQ=MONTHLY;INTERVAL=2", "2026-01-15", "2026-01-01", "2026-07-01", []string{"2026-01-01/2026-02-01", "2026-03-01/2026-04-01", "2026-05-01/2026-06-01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			got := formatOccurrences(rule.Between(day(tt.dtstart), day(tt.from), day(tt.to)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Between() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	rules := []string{
+		"",
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20260303",
+		"FREQ=DAILY;UNTIL=03/03/2026",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;COUNT",
+	}
+	for _, value := range rules {
+		if rule, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", value, rule)
+		}
+	}
+}
+
+func TestStringRoundTrips(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;byday=mo,we", "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"FREQ=WEEKLY;INTERVAL=1;WKST=MO", "FREQ=WEEKLY"},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "FREQ=MONTHLY;BYDAY=-1FR"},
+		{"FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1,-1;COUNT=6", "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1,-1;COUNT=6"},
+		{"FREQ=DAILY;UNTIL=20260303T120000Z", "FREQ=DAILY;UNTIL=20260303"},
+		{" FREQ=WEEKLY ; BYDAY = TU ", "FREQ=WEEKLY;BYDAY=TU"},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.rule, got, tt.want)
+		}
+		again, err := Parse(rule.String())
+		if err != nil || !reflect.DeepEqual(again, rule) {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", rule.String(), again, err, rule)
+		}
+	}
+}
+
+func TestFlexible(t *testing.T) {
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{"FREQ=DAILY", false},
+		{"FREQ=WEEKLY", true},
+		{"FREQ=WEEKLY;BYDAY=MO", false},
+		{"FREQ=MONTHLY", true},
+		{"FREQ=MONTHLY;BYMONTHDAY=15", false},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+		}
+		if got := rule.Flexible(); got != tt.want {
+			t.Errorf("Parse(%q).Flexible() = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
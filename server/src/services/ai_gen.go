@@ -2,8 +2,10 @@ package services
 
 import (
 	"fmt"
+	"go-fiber-template/domain/aimodel"
 	"go-fiber-template/domain/entities"
 	"go-fiber-template/domain/repositories"
+	"strings"
 	"time"
 
 	fiberlog "github.com/gofiber/fiber/v2/log"
@@ -17,19 +19,30 @@ type AiGenService struct {
 	HealthRepo   repositories.IHealthBackgroundRepository
 	FinanceRepo  repositories.IFinanceRepository
 	ScheduleRepo repositories.IScheduleRepository
+	ChatHistory  IChatHistoryManager
+	Usage        IUsageService
+	Tools        IAssistantTools
+	Safety       ISafetyService
+	Privacy      IPrivacyService
 }
 
 type IAiGenService interface {
-	GenerateLifeGoal(id string) (string, error)
+	GenerateLifeGoal(id string) (*entities.GeneratedPlan, error)
+	// GenerateLifeGoalFromPrompt generates the plan from one stored prompt of
+	// the user rather than their latest one.
+	GenerateLifeGoalFromPrompt(id string, promptID string) (*entities.GeneratedPlan, error)
 	GetAllGenGoal() (*[]entities.GeneratedPlan,error)
 	GetGenGoalByUserID(id string) (*[]entities.GeneratedPlan,error)
 	GenereateAiAssist(id string, bodyData entities.AIChatResponse) (string, error)
 	GetGenChatByUserID(id string) (*[]entities.AIChat, error)
 	DeleteGenChatByUserID(id string)  error 
 	DeleteGenGoalByID(id string)  error 
+	RefinePlan(id string, feedback string) (*entities.GeneratedPlan, error)
+	GetPlanVersions(id string) (*[]entities.GeneratedPlan, error)
+	DiffPlans(fromID string, toID string) (*entities.PlanDiff, error)
 }
 
-func NewAiGenService(aiGenRepo repositories.IAiGenRepository, aiPromptRepo repositories.IAipromptRepository, lifeGoalRepo repositories.ILifeGoalRepository, userRepo repositories.IUsersRepository, healthRepo repositories.IHealthBackgroundRepository, financeRepo repositories.IFinanceRepository , scheduleRepo repositories.IScheduleRepository) IAiGenService {
+func NewAiGenService(aiGenRepo repositories.IAiGenRepository, aiPromptRepo repositories.IAipromptRepository, lifeGoalRepo repositories.ILifeGoalRepository, userRepo repositories.IUsersRepository, healthRepo repositories.IHealthBackgroundRepository, financeRepo repositories.IFinanceRepository , scheduleRepo repositories.IScheduleRepository, chatHistory IChatHistoryManager, usage IUsageService, tools IAssistantTools, safety ISafetyService, privacy IPrivacyService) IAiGenService {
 	return &AiGenService{
 		AiGenRepo: aiGenRepo,
 		AiPromptRepo: aiPromptRepo,
@@ -38,23 +51,52 @@ func NewAiGenService(aiGenRepo repositories.IAiGenRepository, aiPromptRepo repos
 		HealthRepo:   healthRepo,
 		FinanceRepo:  financeRepo,
 		ScheduleRepo: scheduleRepo,
+		ChatHistory:  chatHistory,
+		Usage:        usage,
+		Tools:        tools,
+		Safety:       safety,
+		Privacy:      privacy,
 	}
 }
 
-func (sv *AiGenService) GenerateLifeGoal(id string) (string, error) {
+func (sv *AiGenService) GenerateLifeGoal(id string) (*entities.GeneratedPlan, error) {
 	data,  err:= sv.AiPromptRepo.GetPromptByUserID(id)
 	if err != nil {
 		fiberlog.Errorf("AiGenService -> GenerateLifeGoal: %s \n", err)
 		fmt.Println("Error getting prompt:", err)
-		return "", nil // or return an error if you prefer
+		return nil, err
+	}
+	return sv.generateLifeGoal(id, data)
+}
+
+func (sv *AiGenService) GenerateLifeGoalFromPrompt(id string, promptID string) (*entities.GeneratedPlan, error) {
+	data, err := sv.AiPromptRepo.GetPromptByID(promptID)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> GenerateLifeGoalFromPrompt: %s \n", err)
+		return nil, err
+	}
+	if data.UserID != id {
+		return nil, fmt.Errorf("prompt %s does not belong to user %s", promptID, id)
 	}
-	prompt := data.Prompt
-	response, err := sv.AiGenRepo.GenerateLifeGoal(prompt)
+	return sv.generateLifeGoal(id, data)
+}
+
+func (sv *AiGenService) generateLifeGoal(id string, data *entities.AiPromptModel) (*entities.GeneratedPlan, error) {
+	redaction := sv.Privacy.NewRedaction(id)
+	prompt := withInstruction(redaction.Redact(data.Prompt), redaction.Instruction())
+	response, usage, err := sv.AiGenRepo.GenerateLifeGoal(prompt)
+	sv.Usage.Record(id, aimodel.FeaturePlan, usage)
 	if err != nil {
 		fiberlog.Errorf("AiGenService -> GenerateLifeGoal: %s \n", err)
 		fmt.Println("Error generating life goal:", err)
-		return "", err
+		return nil, err
 	}
+	response, report := sv.Safety.Review(id, redaction.Restore(response), func(guidance string) (string, error) {
+		revised, usage, err := sv.AiGenRepo.GenerateLifeGoal(prompt + "\n\n" + redaction.Redact(guidance))
+		sv.Usage.Record(id, aimodel.FeaturePlan, usage)
+		return redaction.Restore(revised), err
+	})
+	logSafetyReport("AiGenService -> GenerateLifeGoal", id, report)
 	Goaldata := entities.GeneratedPlanResponse{
 		UserID: id,
 		Generated_Plan: response,
@@ -63,15 +105,16 @@ func (sv *AiGenService) GenerateLifeGoal(id string) (string, error) {
 		FinanceID: data.FinanceID,
 		HealthID: data.HealthID,
 		ScheduleID: data.ScheduleID,
+		Version: 1,
 		CreatedAt: time.Now().Add(7 * time.Hour),
 	}
-	err = sv.AiGenRepo.InsertGoal(Goaldata)
+	plan, err := sv.AiGenRepo.InsertGoal(Goaldata)
 	if err != nil {
 		fiberlog.Errorf("AiGenService -> GenerateLifeGoal: %s \n", err)
 		fmt.Println("Error inserting life goal:", err)
-		
+		return nil, err
 	}
-	return response, nil
+	return plan, nil
 }
 
 func (sv *AiGenService) GetAllGenGoal() (*[]entities.GeneratedPlan, error) {
@@ -110,12 +153,39 @@ func (sv *AiGenService) GenereateAiAssist(id string, bodyData entities.AIChatRes
 		fmt.Println("Error fetching life goal by User ID:", err)
 		return "", err
 	}
-	data, err := sv.AiGenRepo.GenerateAiChat(*history, bodyData.Message)
+	// The message we just stored is sent as the prompt, not as history.
+	previous := *history
+	if n := len(previous); n > 0 && previous[n-1].Sender == "user" && previous[n-1].Message == bodyData.Message {
+		previous = previous[:n-1]
+	}
+	summary, recent, err := sv.ChatHistory.BuildContext(id, previous)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> GenereateAiAssist: %s \n", err)
+		fmt.Println("Error building chat context:", err)
+		return "", err
+	}
+	// Placeholders are restored in the reply, so stored chats keep the
+	// user's own words and only the request to the model is redacted.
+	redaction := sv.Privacy.NewRedaction(id)
+	message := redaction.Redact(bodyData.Message)
+	recent = redaction.Chats(recent)
+	instruction := redaction.Redact(assistantInstruction(summary, userLanguage(sv.UserRepo, id)))
+	instruction = withInstruction(instruction, redaction.Instruction())
+	data, usage, err := sv.AiGenRepo.GenerateAiChat(instruction, recent, message, sv.Tools.Definitions(), redaction.Executor(sv.Tools.Executor(id)))
+	sv.Usage.Record(id, aimodel.FeatureChat, usage)
 	if err != nil {
 		fiberlog.Errorf("AiGenService -> GetGenGoalByID: %s \n", err)
 		fmt.Println("Error Genchat :", err)
 		return "", err
 	}
+	// Regenerated replies must not call tools again, or proposals made while
+	// writing the first draft would be duplicated.
+	data, report := sv.Safety.Review(id, redaction.Restore(data), func(guidance string) (string, error) {
+		revised, usage, err := sv.AiGenRepo.GenerateAiChat(instruction+"\n\n"+redaction.Redact(guidance), recent, message, nil, nil)
+		sv.Usage.Record(id, aimodel.FeatureChat, usage)
+		return redaction.Restore(revised), err
+	})
+	logSafetyReport("AiGenService -> GenereateAiAssist", id, report)
 	datasent := entities.AIChatResponse{
 		UserID: id,
 		Sender: "ai",
@@ -133,6 +203,23 @@ func (sv *AiGenService) GenereateAiAssist(id string, bodyData entities.AIChatRes
 	return data, nil
 }
 
+func assistantInstruction(summary string, lang string) string {
+	instruction := "You are the user's AI life-planning assistant. You can look up the user's own habits, recent moods, finances, schedule and generated plans with the provided tools. Call them whenever an answer depends on that data instead of guessing, and do not ask the user for information a tool can give you. " +
+		"When the user agrees to a concrete habit, schedule block or life goal change, propose it with the matching tool. Proposals are only saved after the user confirms them in the app, so say that they are waiting for confirmation. " +
+		replyLanguageInstruction(lang)
+	if summary != "" {
+		instruction += "\n\n" + summary
+	}
+	return instruction
+}
+
+func withInstruction(text string, instruction string) string {
+	if instruction == "" {
+		return text
+	}
+	return text + "\n\n" + instruction
+}
+
 func (sv *AiGenService) GetGenChatByUserID(id string) (*[]entities.AIChat, error) {
 	data, err := sv.AiGenRepo.GetGenAiChatByUserID(id)
 	if err != nil {
@@ -161,12 +248,33 @@ func (sv *AiGenService) DeleteGenGoalByID(id string)  error {
 		fmt.Println("Error Deleting GenGoal by ID:\n", err)
 		return err
 	}
+	// Deleting the first version deletes the whole plan. A revision's own
+	// revisions are moved onto its parent so the lineage stays connected.
+	if planRootID(data) == data.ID {
+		err = sv.AiGenRepo.DeletePlanRevisions(data.ID)
+	} else if data.ParentID != nil && *data.ParentID != "" {
+		err = sv.AiGenRepo.ReparentPlans(data.ID, *data.ParentID)
+	}
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> DeleteGenGoalByID: %s \n", err)
+		return err
+	}
 	err = sv.AiGenRepo.DeleteGoal(id)
 	if err != nil {
 		fiberlog.Errorf("AiGenService -> DeleteGenGoalByID: %s \n", err)
 		fmt.Println("Error Deleting GenChat by ID:\n", err)
 		return err
 	}
+	// Revisions share the prompt and profile records with the rest of their
+	// lineage, so those are only removed together with the last version.
+	lineage, err := sv.AiGenRepo.GetPlanLineage(planRootID(data))
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> DeleteGenGoalByID: %s \n", err)
+		return err
+	}
+	if len(*lineage) > 0 {
+		return nil
+	}
 	err = sv.AiPromptRepo.DeletePromptByID((*data).PromptID)
 	if err != nil {
 		fiberlog.Errorf("AiPromptService -> DeletePromptByID: %s \n", err)
@@ -198,4 +306,133 @@ func (sv *AiGenService) DeleteGenGoalByID(id string)  error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// RefinePlan asks the model to revise a plan according to the user's
+// feedback and stores the result as a new version linked to its parent.
+func (sv *AiGenService) RefinePlan(id string, feedback string) (*entities.GeneratedPlan, error) {
+	feedback = strings.TrimSpace(feedback)
+	if feedback == "" {
+		return nil, fmt.Errorf("feedback cannot be empty")
+	}
+	parent, err := sv.AiGenRepo.GetGenGoalByID(id)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> RefinePlan: %s \n", err)
+		return nil, err
+	}
+	rootID := planRootID(parent)
+	lineage, err := sv.AiGenRepo.GetPlanLineage(rootID)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> RefinePlan: %s \n", err)
+		return nil, err
+	}
+	nextVersion := 1
+	for _, plan := range *lineage {
+		if planVersion(plan) >= nextVersion {
+			nextVersion = planVersion(plan) + 1
+		}
+	}
+
+	var prompt strings.Builder
+	if original, err := sv.AiPromptRepo.GetPromptByID(parent.PromptID); err == nil {
+		prompt.WriteString("This was my original request and profile:\n")
+		prompt.WriteString(original.Prompt)
+		prompt.WriteString("\n\n")
+	}
+	prompt.WriteString("This is the life plan you generated for me:\n")
+	prompt.WriteString(parent.Generated_Plan)
+	prompt.WriteString("\n\nRevise the plan based on my feedback: ")
+	prompt.WriteString(feedback)
+	prompt.WriteString("\nKeep the same structure and section headings, change only what the feedback asks for, and return the complete revised plan. ")
+	prompt.WriteString(replyLanguageInstruction(userLanguage(sv.UserRepo, parent.UserID)))
+
+	redaction := sv.Privacy.NewRedaction(parent.UserID)
+	redacted := withInstruction(redaction.Redact(prompt.String()), redaction.Instruction())
+	response, usage, err := sv.AiGenRepo.GenerateLifeGoal(redacted)
+	sv.Usage.Record(parent.UserID, aimodel.FeaturePlan, usage)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> RefinePlan: %s \n", err)
+		fmt.Println("Error refining plan:", err)
+		return nil, err
+	}
+	response, report := sv.Safety.Review(parent.UserID, redaction.Restore(response), func(guidance string) (string, error) {
+		revised, usage, err := sv.AiGenRepo.GenerateLifeGoal(redacted + "\n\n" + redaction.Redact(guidance))
+		sv.Usage.Record(parent.UserID, aimodel.FeaturePlan, usage)
+		return redaction.Restore(revised), err
+	})
+	logSafetyReport("AiGenService -> RefinePlan", parent.UserID, report)
+	parentID := parent.ID
+	revision := entities.GeneratedPlanResponse{
+		UserID:         parent.UserID,
+		Generated_Plan: response,
+		PromptID:       parent.PromptID,
+		LifeGoalID:     parent.LifeGoalID,
+		FinanceID:      parent.FinanceID,
+		HealthID:       parent.HealthID,
+		ScheduleID:     parent.ScheduleID,
+		ParentID:       &parentID,
+		RootID:         &rootID,
+		Version:        nextVersion,
+		Feedback:       feedback,
+		CreatedAt:      time.Now().Add(7 * time.Hour),
+	}
+	plan, err := sv.AiGenRepo.InsertGoal(revision)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> RefinePlan: %s \n", err)
+		fmt.Println("Error inserting refined plan:", err)
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (sv *AiGenService) GetPlanVersions(id string) (*[]entities.GeneratedPlan, error) {
+	plan, err := sv.AiGenRepo.GetGenGoalByID(id)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> GetPlanVersions: %s \n", err)
+		return nil, err
+	}
+	data, err := sv.AiGenRepo.GetPlanLineage(planRootID(plan))
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> GetPlanVersions: %s \n", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+func (sv *AiGenService) DiffPlans(fromID string, toID string) (*entities.PlanDiff, error) {
+	from, err := sv.AiGenRepo.GetGenGoalByID(fromID)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> DiffPlans: %s \n", err)
+		return nil, err
+	}
+	to, err := sv.AiGenRepo.GetGenGoalByID(toID)
+	if err != nil {
+		fiberlog.Errorf("AiGenService -> DiffPlans: %s \n", err)
+		return nil, err
+	}
+	if from.UserID != to.UserID {
+		return nil, fmt.Errorf("plans belong to different users")
+	}
+	diff := DiffPlanSections(from.Generated_Plan, to.Generated_Plan)
+	diff.FromID = from.ID
+	diff.ToID = to.ID
+	diff.FromVersion = planVersion(*from)
+	diff.ToVersion = planVersion(*to)
+	return &diff, nil
+}
+
+// Plans generated before versioning have no root_id and version 0; they are
+// treated as version 1 of their own lineage.
+func planRootID(plan *entities.GeneratedPlan) string {
+	if plan.RootID != nil && *plan.RootID != "" {
+		return *plan.RootID
+	}
+	return plan.ID
+}
+
+func planVersion(plan entities.GeneratedPlan) int {
+	if plan.Version == 0 {
+		return 1
+	}
+	return plan.Version
+}
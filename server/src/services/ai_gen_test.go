@@ -0,0 +1,153 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"sort"
+	"testing"
+)
+
+// fakePlanRepository keeps generated plans in memory by id.
+type fakePlanRepository struct {
+	repositories.IAiGenRepository
+	plans map[string]entities.GeneratedPlan
+}
+
+func (repo *fakePlanRepository) GetGenGoalByID(id string) (*entities.GeneratedPlan, error) {
+	plan, ok := repo.plans[id]
+	if !ok {
+		return nil, fmt.Errorf("plan %s not found", id)
+	}
+	return &plan, nil
+}
+
+func (repo *fakePlanRepository) DeleteGoal(id string) error {
+	delete(repo.plans, id)
+	return nil
+}
+
+func (repo *fakePlanRepository) GetPlanLineage(rootID string) (*[]entities.GeneratedPlan, error) {
+	lineage := []entities.GeneratedPlan{}
+	for _, plan := range repo.plans {
+		if plan.ID == rootID || (plan.RootID != nil && *plan.RootID == rootID) {
+			lineage = append(lineage, plan)
+		}
+	}
+	return &lineage, nil
+}
+
+func (repo *fakePlanRepository) DeletePlanRevisions(rootID string) error {
+	for id, plan := range repo.plans {
+		if plan.RootID != nil && *plan.RootID == rootID {
+			delete(repo.plans, id)
+		}
+	}
+	return nil
+}
+
+func (repo *fakePlanRepository) ReparentPlans(parentID string, newParentID string) error {
+	for id, plan := range repo.plans {
+		if plan.ParentID != nil && *plan.ParentID == parentID {
+			plan.ParentID = &newParentID
+			repo.plans[id] = plan
+		}
+	}
+	return nil
+}
+
+// fakeProfileRecords records which of the records a plan was made from
+// were deleted.
+type fakeProfileRecords struct {
+	repositories.IAipromptRepository
+	repositories.ILifeGoalRepository
+	repositories.IFinanceRepository
+	repositories.IScheduleRepository
+	deleted []string
+}
+
+func (repo *fakeProfileRecords) DeletePromptByID(id string) error {
+	repo.deleted = append(repo.deleted, "prompt")
+	return nil
+}
+
+func (repo *fakeProfileRecords) DeleteLifeGoal(id string) error {
+	repo.deleted = append(repo.deleted, "lifegoal")
+	return nil
+}
+
+func (repo *fakeProfileRecords) DeleteFinance(id string) error {
+	repo.deleted = append(repo.deleted, "finance")
+	return nil
+}
+
+func (repo *fakeProfileRecords) DeleteSchedule(id string) error {
+	repo.deleted = append(repo.deleted, "schedule")
+	return nil
+}
+
+func TestDeleteGenGoalKeepsLineageConnected(t *testing.T) {
+	root := "v1"
+	plan := func(id string, parent string, version int) entities.GeneratedPlan {
+		plan := entities.GeneratedPlan{ID: id, UserID: "user", PromptID: "prompt", Version: version}
+		if parent != "" {
+			plan.ParentID = &parent
+			plan.RootID = &root
+		}
+		return plan
+	}
+	tests := []struct {
+		name        string
+		delete      string
+		remaining   []string
+		parents     map[string]string
+		recordsLeft bool
+	}{
+		{name: "root deletes every revision", delete: "v1", remaining: []string{}, recordsLeft: false},
+		{name: "revision moves its revisions onto its parent", delete: "v2", remaining: []string{"v1", "v3", "v4"}, parents: map[string]string{"v3": "v1", "v4": "v1"}, recordsLeft: true},
+		{name: "latest revision", delete: "v4", remaining: []string{"v1", "v2", "v3"}, parents: map[string]string{"v2": "v1", "v3": "v2"}, recordsLeft: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plans := &fakePlanRepository{plans: map[string]entities.GeneratedPlan{
+				"v1": plan("v1", "", 1),
+				"v2": plan("v2", "v1", 2),
+				"v3": plan("v3", "v2", 3),
+				"v4": plan("v4", "v2", 3),
+			}}
+			records := &fakeProfileRecords{}
+			health := &fakeHealthRepository{}
+			service := NewAiGenService(plans, records, records, nil, health, records, records, nil, nil, nil, nil, nil)
+
+			if err := service.DeleteGenGoalByID(tt.delete); err != nil {
+				t.Fatalf("DeleteGenGoalByID(%s) error = %v", tt.delete, err)
+			}
+			remaining := []string{}
+			for id, plan := range plans.plans {
+				remaining = append(remaining, id)
+				if plan.ParentID != nil {
+					if _, ok := plans.plans[*plan.ParentID]; !ok {
+						t.Errorf("%s points at deleted parent %s", id, *plan.ParentID)
+					}
+				}
+			}
+			sort.Strings(remaining)
+			if len(remaining) != len(tt.remaining) {
+				t.Fatalf("remaining = %v, want %v", remaining, tt.remaining)
+			}
+			for i := range remaining {
+				if remaining[i] != tt.remaining[i] {
+					t.Fatalf("remaining = %v, want %v", remaining, tt.remaining)
+				}
+			}
+			for id, parent := range tt.parents {
+				if got := plans.plans[id].ParentID; got == nil || *got != parent {
+					t.Errorf("%s parent = %v, want %s", id, got, parent)
+				}
+			}
+			if (len(records.deleted) == 0) != tt.recordsLeft {
+				t.Errorf("deleted records = %v, want them kept: %v", records.deleted, tt.recordsLeft)
+			}
+		})
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"go-fiber-template/domain/entities"
 	"go-fiber-template/domain/repositories"
-	"strings"
+	"go-fiber-template/src/locale"
 	"time"
 
 	fiberlog "github.com/gofiber/fiber/v2/log"
@@ -17,13 +17,15 @@ type AiPromptService struct {
 	HealthRepo   repositories.IHealthBackgroundRepository
 	FinanceRepo  repositories.IFinanceRepository
 	ScheduleRepo repositories.IScheduleRepository
+	Transactions repositories.ITransactionRepository
+	Rates        IExchangeRateService
 }
 type IAiPromptService interface {
 	CreateAIPrompt(id string) error
 	DeleteAIPrompt(id string) error
 }
 
-func NewAiPromptService(lifegoalrepo repositories.ILifeGoalRepository, userRepo repositories.IUsersRepository, aiPromptRepo repositories.IAipromptRepository, healthRepo repositories.IHealthBackgroundRepository, financeRepo repositories.IFinanceRepository , scheduleRepo repositories.IScheduleRepository) IAiPromptService {
+func NewAiPromptService(lifegoalrepo repositories.ILifeGoalRepository, userRepo repositories.IUsersRepository, aiPromptRepo repositories.IAipromptRepository, healthRepo repositories.IHealthBackgroundRepository, financeRepo repositories.IFinanceRepository , scheduleRepo repositories.IScheduleRepository, transactionRepo repositories.ITransactionRepository, rates IExchangeRateService) IAiPromptService {
 	return &AiPromptService{
 		LifeGoalRepo: lifegoalrepo,
 		UserRepo:     userRepo,
@@ -31,6 +33,8 @@ func NewAiPromptService(lifegoalrepo repositories.ILifeGoalRepository, userRepo
 		HealthRepo:   healthRepo,
 		FinanceRepo:  financeRepo,
 		ScheduleRepo: scheduleRepo,
+		Transactions: transactionRepo,
+		Rates:        rates,
 	}
 }
 
@@ -67,7 +71,11 @@ func (sv *AiPromptService) CreateAIPrompt(id string) error {
 	}
 	var data entities.AiPromptResponse
 	data.UserID = id
-	data.Prompt = fmt.Sprintf("Generate a life goal for me based on my profile information. \nMy LongTermGoal is %s and \nMy ShorttermGoal is %s.\nRight now I am %d years old %s . \nThis is my Health Background \nMy weight is %f kg, height is %f cm, medical condition is %s , allergies %s, current medication %s ,sleeppattern is %s and fitness level is %s \n My financial situation \n My income is %f %s ,Monthly expenses is %f %s \n I expect to save money %f %s, Risk Tolerence for investments is %s.\n I have workhour %s, available time %s, my most busy day is %s , preferred time for activity is %s." , lifeGoaldata.LongTerm, lifeGoaldata.ShortTerm, Userdata.Age, Userdata.Gender, Userdata.Weight, Userdata.Height, strings.Join(HealthData.Medical_Conditions, ","), strings.Join(HealthData.Allergies, ","), strings.Join(HealthData.Medications, ","), HealthData.Sleep_Pattern, HealthData.Fitness_Level, FinanceData.Income, FinanceData.Currency, FinanceData.Expenses, FinanceData.Currency, FinanceData.SavingsGoal, FinanceData.Currency, FinanceData.Risk_Tolerance, ScheDuleData.WorkHours, ScheDuleData.AvailableTime, strings.Join(ScheDuleData.BusyDays, ","), strings.Join(ScheDuleData.PreferredTime, ","))
+	data.Prompt, err = BuildPlanPromptWithSpending(locale.Resolve(Userdata.Language), *Userdata, *lifeGoaldata, *HealthData, *FinanceData, *ScheDuleData, sv.recentSpending(id, FinanceData.Currency))
+	if err != nil {
+		fiberlog.Errorf("AiPromptService -> BuildPlanPromptWithSpending: %s \n", err)
+		return err
+	}
 	data.LifeGoalID = lifeGoaldata.ID
 	data.HealthID = HealthData.ID
 	data.FinanceID = FinanceData.ID
@@ -91,4 +99,26 @@ func (sv *AiPromptService)DeleteAIPrompt(id string) error {
 		return err
 	}
 	return  nil
-}
\ No newline at end of file
+}
+// recentSpending summarises the user's recent transactions for the plan
+// prompt in the profile's currency. Without any, the prompt keeps the figures
+// from the finance profile.
+func (sv *AiPromptService) recentSpending(userID string, currency string) []entities.FinanceMonthSummary {
+	transactions, err := sv.Transactions.GetTransactionsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("AiPromptService -> recentSpending: %s \n", err)
+		return nil
+	}
+	today := localDate(time.Now(), userLocation(sv.UserRepo, userID))
+	month := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var convert CurrencyConverter
+	if sv.Rates != nil {
+		convert = sv.Rates.Convert
+	}
+	summaries, err := MonthlyFinanceSummaries(*transactions, profileCurrency(currency), month.AddDate(0, -recentFinanceMonths, 0), month, convert)
+	if err != nil {
+		fiberlog.Errorf("AiPromptService -> recentSpending: %s \n", err)
+		return nil
+	}
+	return RecentFinanceMonths(summaries, today)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// Action types the assistant can propose.
+const (
+	ActionCreateHabit      = "create_habit"
+	ActionAddScheduleBlock = "add_schedule_block"
+	ActionUpdateLifeGoal   = "update_life_goal"
+)
+
+// Action statuses. An action is claimed as processing before it runs so a
+// double confirmation cannot apply it twice.
+const (
+	ActionStatusPending    = "pending"
+	ActionStatusProcessing = "processing"
+	ActionStatusConfirmed  = "confirmed"
+	ActionStatusRejected   = "rejected"
+	ActionStatusFailed     = "failed"
+)
+
+var actionStatuses = map[string]bool{
+	ActionStatusPending:    true,
+	ActionStatusProcessing: true,
+	ActionStatusConfirmed:  true,
+	ActionStatusRejected:   true,
+	ActionStatusFailed:     true,
+}
+
+type AssistantActionService struct {
+	ActionRepo      repositories.IAssistantActionRepository
+	HabitsService   IHabitsService
+	ScheduleService IScheduleService
+	LifeGoalService ILifeGoalService
+}
+
+type IAssistantActionService interface {
+	ProposeAction(userID string, actionType string, payload interface{}, summary string) (*entities.AssistantActionModel, error)
+	GetActionsByUserID(userID string, status string) (*[]entities.AssistantActionModel, error)
+	ConfirmAction(userID string, actionID string) (*entities.AssistantActionModel, error)
+	RejectAction(userID string, actionID string) (*entities.AssistantActionModel, error)
+}
+
+func NewAssistantActionService(actionRepo repositories.IAssistantActionRepository, habits IHabitsService, schedule IScheduleService, lifeGoals ILifeGoalService) IAssistantActionService {
+	return &AssistantActionService{
+		ActionRepo:      actionRepo,
+		HabitsService:   habits,
+		ScheduleService: schedule,
+		LifeGoalService: lifeGoals,
+	}
+}
+
+func (sv *AssistantActionService) ProposeAction(userID string, actionType string, payload interface{}, summary string) (*entities.AssistantActionModel, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	data := entities.AssistantActionResponse{
+		UserID:     userID,
+		ActionType: actionType,
+		Payload:    raw,
+		Summary:    summary,
+		Status:     ActionStatusPending,
+		CreatedAt:  time.Now().Add(7 * time.Hour),
+	}
+	action, err := sv.ActionRepo.InsertAction(data)
+	if err != nil {
+		fiberlog.Errorf("AssistantActionService -> ProposeAction: %s \n", err)
+		fmt.Println("Error inserting assistant action:", err)
+		return nil, err
+	}
+	return action, nil
+}
+
+func (sv *AssistantActionService) GetActionsByUserID(userID string, status string) (*[]entities.AssistantActionModel, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	if status != "" && !actionStatuses[status] {
+		return nil, invalidf("status must be pending, processing, confirmed, rejected or failed")
+	}
+	data, err := sv.ActionRepo.GetActionsByUserID(userID, status)
+	if err != nil {
+		fiberlog.Errorf("AssistantActionService -> GetActionsByUserID: %s \n", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+func (sv *AssistantActionService) ConfirmAction(userID string, actionID string) (*entities.AssistantActionModel, error) {
+	action, err := sv.claimAction(userID, actionID, ActionStatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+	resolvedAt := time.Now().Add(7 * time.Hour)
+	update := entities.AssistantActionStatusUpdate{Status: ActionStatusConfirmed, ResolvedAt: &resolvedAt}
+	runErr := sv.apply(action)
+	if runErr != nil {
+		fiberlog.Errorf("AssistantActionService -> ConfirmAction: %s \n", runErr)
+		update.Status = ActionStatusFailed
+		update.Error = runErr.Error()
+	}
+	if _, err := sv.ActionRepo.UpdateActionStatus(action.ID, ActionStatusProcessing, update); err != nil {
+		fiberlog.Errorf("AssistantActionService -> ConfirmAction: %s \n", err)
+		sv.releaseAction(action, runErr, err)
+		return nil, err
+	}
+	action.Status = update.Status
+	action.Error = update.Error
+	action.ResolvedAt = update.ResolvedAt
+	if runErr != nil {
+		return action, runErr
+	}
+	return action, nil
+}
+
+// releaseAction gets an action out of processing when its final status could
+// not be saved. An action that failed to run changed nothing, so it goes back
+// to pending to be confirmed again; one that ran is marked failed with the
+// error rather than pending, so confirming it again cannot apply it twice.
+func (sv *AssistantActionService) releaseAction(action *entities.AssistantActionModel, runErr error, saveErr error) {
+	update := entities.AssistantActionStatusUpdate{Status: ActionStatusPending}
+	if runErr == nil {
+		resolvedAt := time.Now().Add(7 * time.Hour)
+		update = entities.AssistantActionStatusUpdate{
+			Status:     ActionStatusFailed,
+			Error:      "the action was applied but its status could not be saved: " + saveErr.Error(),
+			ResolvedAt: &resolvedAt,
+		}
+	}
+	if _, err := sv.ActionRepo.UpdateActionStatus(action.ID, ActionStatusProcessing, update); err != nil {
+		fiberlog.Errorf("AssistantActionService -> releaseAction: %s \n", err)
+	}
+}
+
+func (sv *AssistantActionService) RejectAction(userID string, actionID string) (*entities.AssistantActionModel, error) {
+	action, err := sv.claimAction(userID, actionID, ActionStatusRejected)
+	if err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// claimAction moves a pending action owned by userID to the next status.
+func (sv *AssistantActionService) claimAction(userID string, actionID string, next string) (*entities.AssistantActionModel, error) {
+	action, err := sv.ActionRepo.GetActionByID(actionID)
+	if err != nil {
+		fiberlog.Errorf("AssistantActionService -> claimAction: %s \n", err)
+		return nil, err
+	}
+	if action.UserID != userID {
+		return nil, fmt.Errorf("assistant action with ID %s not found", actionID)
+	}
+	if action.Status != ActionStatusPending {
+		return nil, invalidf("assistant action is already %s", action.Status)
+	}
+	update := entities.AssistantActionStatusUpdate{Status: next}
+	if next != ActionStatusProcessing {
+		resolvedAt := time.Now().Add(7 * time.Hour)
+		update.ResolvedAt = &resolvedAt
+	}
+	claimed, err := sv.ActionRepo.UpdateActionStatus(action.ID, ActionStatusPending, update)
+	if err != nil {
+		fiberlog.Errorf("AssistantActionService -> claimAction: %s \n", err)
+		return nil, err
+	}
+	if !claimed {
+		return nil, invalidf("assistant action is no longer pending")
+	}
+	action.Status = update.Status
+	action.ResolvedAt = update.ResolvedAt
+	return action, nil
+}
+
+// apply runs a confirmed action through the same services the REST API
+// uses, so it gets the same validation as a manual edit.
+func (sv *AssistantActionService) apply(action *entities.AssistantActionModel) error {
+	switch action.ActionType {
+	case ActionCreateHabit:
+		var habit entities.HabitResponse
+		if err := json.Unmarshal(action.Payload, &habit); err != nil {
+			return err
+		}
+		return sv.HabitsService.CreateHabit(action.UserID, habit)
+	case ActionAddScheduleBlock:
+		var block entities.ScheduleBlockResponse
+		if err := json.Unmarshal(action.Payload, &block); err != nil {
+			return err
+		}
+		return sv.ScheduleService.AddScheduleBlock(action.UserID, block)
+	case ActionUpdateLifeGoal:
+		var body entities.LifeGoalBody
+		if err := json.Unmarshal(action.Payload, &body); err != nil {
+			return err
+		}
+		current, err := sv.LifeGoalService.FindLifeGoalByUserID(action.UserID)
+		if err != nil {
+			return err
+		}
+		return sv.LifeGoalService.UpdateLifeGoal(current.ID, mergeLifeGoal(*current, body))
+	default:
+		return fmt.Errorf("unknown action type %q", action.ActionType)
+	}
+}
+
+// mergeLifeGoal keeps the current value of every field the assistant did not
+// propose to change; the update endpoint would otherwise clear them.
+func mergeLifeGoal(current entities.LifeGoalModel, body entities.LifeGoalBody) entities.LifeGoalUpdateBody {
+	update := entities.LifeGoalUpdateBody{
+		ShortTerm:  current.ShortTerm,
+		LongTerm:   current.LongTerm,
+		Priorities: current.Priorities,
+		TimeFrame:  current.TimeFrame,
+	}
+	if body.ShortTerm != nil {
+		update.ShortTerm = body.ShortTerm
+	}
+	if body.LongTerm != nil {
+		update.LongTerm = body.LongTerm
+	}
+	if body.Priorities != nil {
+		update.Priorities = body.Priorities
+	}
+	if body.TimeFrame != "" {
+		update.TimeFrame = body.TimeFrame
+	}
+	return update
+}
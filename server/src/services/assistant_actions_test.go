@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeActionRepository keeps one action and fails the status updates whose
+// target status is listed in failTo.
+type fakeActionRepository struct {
+	action entities.AssistantActionModel
+	failTo map[string]bool
+}
+
+func (repo *fakeActionRepository) InsertAction(data entities.AssistantActionResponse) (*entities.AssistantActionModel, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (repo *fakeActionRepository) GetActionsByUserID(userID string, status string) (*[]entities.AssistantActionModel, error) {
+	return &[]entities.AssistantActionModel{repo.action}, nil
+}
+
+func (repo *fakeActionRepository) GetActionByID(id string) (*entities.AssistantActionModel, error) {
+	action := repo.action
+	return &action, nil
+}
+
+func (repo *fakeActionRepository) UpdateActionStatus(id string, fromStatus string, data entities.AssistantActionStatusUpdate) (bool, error) {
+	if repo.failTo[data.Status] {
+		return false, fmt.Errorf("database unavailable")
+	}
+	if repo.action.Status != fromStatus {
+		return false, nil
+	}
+	repo.action.Status = data.Status
+	repo.action.Error = data.Error
+	return true, nil
+}
+
+type fakeHabitsService struct {
+	IHabitsService
+	err     error
+	created int
+}
+
+func (habits *fakeHabitsService) CreateHabit(id string, habit entities.HabitResponse) error {
+	if habits.err != nil {
+		return habits.err
+	}
+	habits.created++
+	return nil
+}
+
+func TestConfirmActionReleasesActionWhenStatusCannotBeSaved(t *testing.T) {
+	tests := []struct {
+		name      string
+		runErr    error
+		failTo    map[string]bool
+		want      string
+		wantError string
+	}{
+		{"confirmed", nil, nil, ActionStatusConfirmed, ""},
+		{"run failed", fmt.Errorf("invalid habit"), nil, ActionStatusFailed, "invalid habit"},
+		{"applied but not saved", nil, map[string]bool{ActionStatusConfirmed: true}, ActionStatusFailed, "could not be saved"},
+		{"failed and not saved", fmt.Errorf("invalid habit"), map[string]bool{ActionStatusFailed: true}, ActionStatusPending, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeActionRepository{
+				action: entities.AssistantActionModel{ID: "action", UserID: "user", ActionType: ActionCreateHabit, Payload: []byte(`{"name":"Read"}`), Status: ActionStatusPending},
+				failTo: tt.failTo,
+			}
+			habits := &fakeHabitsService{err: tt.runErr}
+			service := NewAssistantActionService(repo, habits, nil, nil)
+			if _, err := service.ConfirmAction("user", "action"); (err != nil) != (tt.runErr != nil || tt.failTo != nil) {
+				t.Errorf("ConfirmAction() error = %v", err)
+			}
+			if repo.action.Status != tt.want {
+				t.Errorf("status = %q, want %q", repo.action.Status, tt.want)
+			}
+			if !strings.Contains(repo.action.Error, tt.wantError) {
+				t.Errorf("error = %q, want it to contain %q", repo.action.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestGetActionsRejectsUnknownStatus(t *testing.T) {
+	service := NewAssistantActionService(&fakeActionRepository{}, nil, nil, nil)
+	for _, status := range []string{"pending&user_id=neq.x", "done", "PENDING"} {
+		if _, err := service.GetActionsByUserID("user", status); err == nil {
+			t.Errorf("GetActionsByUserID(%q) error = nil, want an error", status)
+		}
+	}
+	if _, err := service.GetActionsByUserID("user", ActionStatusPending); err != nil {
+		t.Errorf("GetActionsByUserID(pending) error = %v", err)
+	}
+}
+
+func TestStringSliceArg(t *testing.T) {
+	tests := []struct {
+		args map[string]any
+		want []string
+	}{
+		{map[string]any{}, nil},
+		{map[string]any{"list": nil}, nil},
+		{map[string]any{"list": ""}, nil},
+		{map[string]any{"list": " , "}, nil},
+		{map[string]any{"list": []any{}}, nil},
+		{map[string]any{"list": []any{" ", 3}}, nil},
+		{map[string]any{"list": "run, read"}, []string{"run", "read"}},
+		{map[string]any{"list": []any{"run", " read "}}, []string{"run", "read"}},
+		{map[string]any{"list": []string{"run", ""}}, []string{"run"}},
+	}
+	for _, tt := range tests {
+		if got := stringSliceArg(tt.args, "list"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("stringSliceArg(%v) = %#v, want %#v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestMergeLifeGoalKeepsMissingLists(t *testing.T) {
+	current := entities.LifeGoalModel{ShortTerm: []string{"run 5k"}, LongTerm: []string{"marathon"}, Priorities: []string{"health"}, TimeFrame: "1 year"}
+	body := entities.LifeGoalBody{
+		ShortTerm:  stringSliceArg(map[string]any{"short_term": "run 10k"}, "short_term"),
+		LongTerm:   stringSliceArg(map[string]any{}, "long_term"),
+		Priorities: stringSliceArg(map[string]any{"priorities": ""}, "priorities"),
+	}
+	got := mergeLifeGoal(current, body)
+	if !reflect.DeepEqual(got.ShortTerm, []string{"run 10k"}) || !reflect.DeepEqual(got.LongTerm, current.LongTerm) ||
+		!reflect.DeepEqual(got.Priorities, current.Priorities) || got.TimeFrame != current.TimeFrame {
+		t.Errorf("mergeLifeGoal() = %+v", got)
+	}
+}
+
+func TestAssistantActionCallerErrorsAreValidationErrors(t *testing.T) {
+	repo := &fakeActionRepository{action: entities.AssistantActionModel{ID: "action", UserID: "user", ActionType: ActionCreateHabit, Status: ActionStatusRejected}}
+	service := NewAssistantActionService(repo, &fakeHabitsService{}, nil, nil)
+	if _, err := service.GetActionsByUserID("user", "done"); !IsValidationError(err) {
+		t.Errorf("GetActionsByUserID() error = %v, want a validation error", err)
+	}
+	if _, err := service.RejectAction("user", "action"); !IsValidationError(err) {
+		t.Errorf("RejectAction() error = %v, want a validation error", err)
+	}
+	if _, err := service.RejectAction("someone else", "action"); err == nil || IsValidationError(err) {
+		t.Errorf("RejectAction() error = %v, want a not found error", err)
+	}
+}
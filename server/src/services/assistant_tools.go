@@ -0,0 +1,346 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/aimodel"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"sort"
+	"strings"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	defaultMoodLookbackDays = 14
+	maxMoodLookbackDays     = 90
+	maxPlanCharacters       = 6000
+)
+
+// AssistantTools exposes the user's own data to the chat model as tools.
+// Every tool is scoped to the user the executor was built for; the model
+// cannot ask for another user's data. Write tools never change anything
+// directly, they only propose an action the user has to confirm.
+type AssistantTools struct {
+	HabitsService   IHabitsService
+	MoodService     IMoodService
+	FinanceService  IFinanceService
+	ScheduleService IScheduleService
+	AiGenRepo       repositories.IAiGenRepository
+	Actions         IAssistantActionService
+}
+
+type IAssistantTools interface {
+	Definitions() []aimodel.Tool
+	Executor(userID string) aimodel.ToolExecutor
+}
+
+func NewAssistantTools(habits IHabitsService, mood IMoodService, finance IFinanceService, schedule IScheduleService, aiGenRepo repositories.IAiGenRepository, actions IAssistantActionService) IAssistantTools {
+	return &AssistantTools{
+		HabitsService:   habits,
+		MoodService:     mood,
+		FinanceService:  finance,
+		ScheduleService: schedule,
+		AiGenRepo:       aiGenRepo,
+		Actions:         actions,
+	}
+}
+
+func (t *AssistantTools) Definitions() []aimodel.Tool {
+	return []aimodel.Tool{
+		{
+			Name:        "get_habits",
+			Description: "List the user's habits with frequency, target count, current streak and completed dates.",
+			Parameters: []aimodel.ToolParameter{
+				{Name: "category", Type: "string", Description: "Only return habits in this category, e.g. health, productivity, mindfulness, learning."},
+			},
+		},
+		{
+			Name:        "get_recent_moods",
+			Description: "List the user's mood log entries from the last few days, newest first.",
+			Parameters: []aimodel.ToolParameter{
+				{Name: "days", Type: "integer", Description: "How many days back to look. Defaults to 14, at most 90."},
+			},
+		},
+		{
+			Name:        "get_finance_summary",
+			Description: "Get the user's income, monthly expenses, savings goal, currency and risk tolerance.",
+		},
+		{
+			Name:        "get_schedule",
+			Description: "Get the user's work hours, available time, busy days and preferred activity times.",
+		},
+		{
+			Name:        "get_generated_plans",
+			Description: "Get the most recent life plans generated for the user.",
+			Parameters: []aimodel.ToolParameter{
+				{Name: "limit", Type: "integer", Description: "How many plans to return, newest first. Defaults to 1."},
+			},
+		},
+		{
+			Name:        ActionCreateHabit,
+			Description: "Propose a new habit for the user. Nothing is saved until the user confirms it, so tell them it is waiting for their confirmation.",
+			Parameters: []aimodel.ToolParameter{
+				{Name: "name", Type: "string", Description: "Short habit name, e.g. Meditate 10 minutes.", Required: true},
+				{Name: "frequency", Type: "string", Description: "How often the habit repeats. Ignored when recurrence is given.", Enum: []string{"daily", "weekly", "monthly"}},
+				{Name: "recurrence", Type: "string", Description: "RFC 5545 RRULE for schedules a frequency cannot express, e.g. FREQ=WEEKLY;BYDAY=MO,WE,FR, FREQ=DAILY;INTERVAL=2 or FREQ=MONTHLY;BYDAY=1SU. FREQ=WEEKLY without BYDAY means any day of the week, so with target_count 3 it is three times a week."},
+				{Name: "description", Type: "string", Description: "Optional details."},
+				{Name: "category", Type: "string", Description: "One of health, productivity, mindfulness, learning."},
+				{Name: "target_count", Type: "integer", Description: "How many times per occurrence. Defaults to 1."},
+			},
+		},
+		{
+			Name:        ActionAddScheduleBlock,
+			Description: "Propose a recurring time block in the user's schedule. Nothing is saved until the user confirms it.",
+			Parameters: []aimodel.ToolParameter{
+				{Name: "title", Type: "string", Description: "What the block is for.", Required: true},
+				{Name: "days", Type: "array", Description: "Days of the week, e.g. Monday, Wednesday. Not needed when recurrence is given."},
+				{Name: "recurrence", Type: "string", Description: "RFC 5545 RRULE for blocks that do not repeat every week, e.g. FREQ=WEEKLY;INTERVAL=2;BYDAY=TU or FREQ=MONTHLY;BYDAY=1SU."},
+				{Name: "start_time", Type: "string", Description: "Start time as HH:MM in 24 hour format.", Required: true},
+				{Name: "duration_minutes", Type: "integer", Description: "Length of the block in minutes.", Required: true},
+				{Name: "notes", Type: "string", Description: "Optional notes."},
+			},
+		},
+		{
+			Name:        ActionUpdateLifeGoal,
+			Description: "Propose changes to the user's life goals. Only the fields you pass are replaced. Nothing is saved until the user confirms it.",
+			Parameters: []aimodel.ToolParameter{
+				{Name: "short_term", Type: "array", Description: "Full new list of short term goals."},
+				{Name: "long_term", Type: "array", Description: "Full new list of long term goals."},
+				{Name: "priorities", Type: "array", Description: "Full new list of priorities."},
+				{Name: "timeframe", Type: "string", Description: "New time frame, e.g. 6 months."},
+			},
+		},
+	}
+}
+
+func (t *AssistantTools) Executor(userID string) aimodel.ToolExecutor {
+	return func(call aimodel.ToolCall) (map[string]any, error) {
+		result, err := t.execute(userID, call)
+		if err != nil {
+			fiberlog.Errorf("AssistantTools -> %s: %s \n", call.Name, err)
+		}
+		return result, err
+	}
+}
+
+func (t *AssistantTools) execute(userID string, call aimodel.ToolCall) (map[string]any, error) {
+	switch call.Name {
+	case "get_habits":
+		return t.getHabits(userID, stringArg(call.Args, "category"))
+	case "get_recent_moods":
+		return t.getRecentMoods(userID, intArg(call.Args, "days", defaultMoodLookbackDays))
+	case "get_finance_summary":
+		return t.getFinanceSummary(userID)
+	case "get_schedule":
+		return t.getSchedule(userID)
+	case "get_generated_plans":
+		return t.getGeneratedPlans(userID, intArg(call.Args, "limit", 1))
+	case ActionCreateHabit:
+		return t.proposeHabit(userID, call.Args)
+	case ActionAddScheduleBlock:
+		return t.proposeScheduleBlock(userID, call.Args)
+	case ActionUpdateLifeGoal:
+		return t.proposeLifeGoalUpdate(userID, call.Args)
+	default:
+		return nil, fmt.Errorf("unknown tool %q", call.Name)
+	}
+}
+
+func (t *AssistantTools) getHabits(userID string, category string) (map[string]any, error) {
+	data, err := t.HabitsService.ListHabits(userID, HabitStatusActive, category)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"habits": *data}, nil
+}
+
+func (t *AssistantTools) getRecentMoods(userID string, days int) (map[string]any, error) {
+	if days <= 0 {
+		days = defaultMoodLookbackDays
+	}
+	if days > maxMoodLookbackDays {
+		days = maxMoodLookbackDays
+	}
+	data, err := t.MoodService.GetMoodByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+	since := time.Now().Add(7*time.Hour).AddDate(0, 0, -days)
+	moods := []entities.MoodModel{}
+	for _, mood := range *data {
+		if mood.CreatedAt.After(since) {
+			moods = append(moods, mood)
+		}
+	}
+	sort.Slice(moods, func(i, j int) bool { return moods[i].CreatedAt.After(moods[j].CreatedAt) })
+	return map[string]any{"days": days, "moods": moods}, nil
+}
+
+func (t *AssistantTools) getFinanceSummary(userID string) (map[string]any, error) {
+	data, err := t.FinanceService.GetAllFinanceByUserID(userID)
+	if err != nil {
+		return map[string]any{"finance": nil, "note": "the user has not entered finance information"}, nil
+	}
+	return map[string]any{"finance": data}, nil
+}
+
+func (t *AssistantTools) getSchedule(userID string) (map[string]any, error) {
+	data, err := t.ScheduleService.GetScheduleByUserID(userID)
+	if err != nil {
+		return map[string]any{"schedule": nil, "note": "the user has not entered a schedule"}, nil
+	}
+	return map[string]any{"schedule": data}, nil
+}
+
+func (t *AssistantTools) getGeneratedPlans(userID string, limit int) (map[string]any, error) {
+	if limit <= 0 {
+		limit = 1
+	}
+	data, err := t.AiGenRepo.GetGenGoalByUserID(userID)
+	if err != nil {
+		return map[string]any{"plans": []entities.GeneratedPlan{}}, nil
+	}
+	plans := append([]entities.GeneratedPlan{}, (*data)...)
+	sort.Slice(plans, func(i, j int) bool { return plans[i].CreatedAt.After(plans[j].CreatedAt) })
+	if len(plans) > limit {
+		plans = plans[:limit]
+	}
+	for i := range plans {
+		plans[i].Generated_Plan = truncateRunes(plans[i].Generated_Plan, maxPlanCharacters)
+	}
+	return map[string]any{"plans": plans}, nil
+}
+
+// Proposals are validated up front so the model can correct itself in the
+// same conversation instead of the user finding out at confirmation time.
+
+func (t *AssistantTools) proposeHabit(userID string, args map[string]any) (map[string]any, error) {
+	habit := entities.HabitResponse{
+		Name:        stringArg(args, "name"),
+		Frequency:   stringArg(args, "frequency"),
+		Recurrence:  stringArg(args, "recurrence"),
+		Description: stringArg(args, "description"),
+		Category:    stringArg(args, "category"),
+		TargetCount: intArg(args, "target_count", 1),
+	}
+	if err := ValidateHabit(&habit); err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("Create habit \"%s\" repeating %s (%d per occurrence)", habit.Name, habit.Recurrence, habit.TargetCount)
+	return t.propose(userID, ActionCreateHabit, habit, summary)
+}
+
+func (t *AssistantTools) proposeScheduleBlock(userID string, args map[string]any) (map[string]any, error) {
+	block := entities.ScheduleBlockResponse{
+		Title:           stringArg(args, "title"),
+		Days:            stringSliceArg(args, "days"),
+		Recurrence:      stringArg(args, "recurrence"),
+		StartTime:       stringArg(args, "start_time"),
+		DurationMinutes: intArg(args, "duration_minutes", 0),
+		Notes:           stringArg(args, "notes"),
+	}
+	if err := ValidateScheduleBlock(&block); err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("Add \"%s\" repeating %s at %s for %d minutes", block.Title, block.Recurrence, block.StartTime, block.DurationMinutes)
+	return t.propose(userID, ActionAddScheduleBlock, block, summary)
+}
+
+func (t *AssistantTools) proposeLifeGoalUpdate(userID string, args map[string]any) (map[string]any, error) {
+	body := entities.LifeGoalBody{
+		ShortTerm:  stringSliceArg(args, "short_term"),
+		LongTerm:   stringSliceArg(args, "long_term"),
+		Priorities: stringSliceArg(args, "priorities"),
+		TimeFrame:  stringArg(args, "timeframe"),
+	}
+	if body.ShortTerm == nil && body.LongTerm == nil && body.Priorities == nil && body.TimeFrame == "" {
+		return nil, fmt.Errorf("pass at least one field to change")
+	}
+	changed := []string{}
+	if body.ShortTerm != nil {
+		changed = append(changed, "short term goals")
+	}
+	if body.LongTerm != nil {
+		changed = append(changed, "long term goals")
+	}
+	if body.Priorities != nil {
+		changed = append(changed, "priorities")
+	}
+	if body.TimeFrame != "" {
+		changed = append(changed, "time frame")
+	}
+	summary := "Update life goal " + strings.Join(changed, ", ")
+	return t.propose(userID, ActionUpdateLifeGoal, body, summary)
+}
+
+func (t *AssistantTools) propose(userID string, actionType string, payload interface{}, summary string) (map[string]any, error) {
+	action, err := t.Actions.ProposeAction(userID, actionType, payload, summary)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"status":    "pending_confirmation",
+		"action_id": action.ID,
+		"summary":   summary,
+	}, nil
+}
+
+func stringArg(args map[string]any, name string) string {
+	if value, ok := args[name].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// stringSliceArg accepts a JSON array or a comma separated string. It returns
+// nil when the argument is missing or has no non-blank items, so callers
+// such as mergeLifeGoal keep the current value rather than clearing it.
+func stringSliceArg(args map[string]any, name string) []string {
+	var raw []string
+	switch value := args[name].(type) {
+	case []any:
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				raw = append(raw, text)
+			}
+		}
+	case []string:
+		raw = value
+	case string:
+		raw = strings.Split(value, ",")
+	}
+	var items []string
+	for _, item := range raw {
+		if strings.TrimSpace(item) != "" {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+	return items
+}
+
+// intArg accepts both JSON numbers and numeric strings, since models are not
+// consistent about which they send.
+func intArg(args map[string]any, name string, def int) int {
+	switch value := args[name].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	case string:
+		var parsed int
+		if _, err := fmt.Sscanf(value, "%d", &parsed); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "..."
+}
@@ -0,0 +1,597 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/configuration"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"go-fiber-template/domain/notify"
+	"go-fiber-template/src/locale"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// Budget rollover options.
+const (
+	RolloverNone    = "none"
+	RolloverUnspent = "unspent"
+	RolloverFull    = "full"
+)
+
+// Budget statuses.
+const (
+	BudgetUnder   = "under"
+	BudgetWarning = "warning"
+	BudgetOver    = "over"
+)
+
+const BudgetAlertKind = "budget_alert"
+
+var budgetRollovers = map[string]bool{RolloverNone: true, RolloverUnspent: true, RolloverFull: true}
+
+// BudgetConfig controls budget alerts. Thresholds are percentages of the
+// available budget; an alert is sent the first time spending in a month
+// reaches each of them. Alerts are checked when an expense is recorded and
+// every CheckInterval, which catches recurring expenses.
+type BudgetConfig struct {
+	AlertsEnabled bool
+	CheckInterval time.Duration
+	Thresholds    []int
+}
+
+func NewBudgetConfigFromEnv() BudgetConfig {
+	return BudgetConfig{
+		AlertsEnabled: configuration.GetEnvInt("BUDGET_ALERTS_ENABLED", 1) == 1,
+		CheckInterval: time.Duration(configuration.GetEnvInt("BUDGET_ALERT_CHECK_MINUTES", 60)) * time.Minute,
+		Thresholds:    ParseBudgetThresholds(os.Getenv("BUDGET_ALERT_THRESHOLDS")),
+	}
+}
+
+// ParseBudgetThresholds reads a comma-separated list of percentages, leaving
+// out anything that is not a whole number from 1 to 1000, sorted ascending.
+// Without any it returns the default, 80 and 100.
+func ParseBudgetThresholds(value string) []int {
+	thresholds := []int{}
+	seen := map[int]bool{}
+	for _, part := range strings.Split(value, ",") {
+		threshold, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || threshold < 1 || threshold > 1000 || seen[threshold] {
+			continue
+		}
+		seen[threshold] = true
+		thresholds = append(thresholds, threshold)
+	}
+	if len(thresholds) == 0 {
+		return []int{80, 100}
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// ParseBudgetMonth reads the month (YYYY-MM) of a budget report; empty is
+// the current month.
+func ParseBudgetMonth(month string) (*time.Time, error) {
+	if month == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(monthLayout, month)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month: %s", month)
+	}
+	return &parsed, nil
+}
+
+// validateBudget normalises the budget and checks it against the user's
+// other budgets; there is one budget per category.
+func (sv *FinanceService) validateBudget(userID string, budgetID string, budget *entities.BudgetResponse) error {
+	budget.Category = strings.Join(strings.Fields(strings.ToLower(budget.Category)), " ")
+	if budget.Category == "" {
+		return invalidf("category is required")
+	}
+	if len([]rune(budget.Category)) > maxTransactionCatLength {
+		return invalidf("category cannot be longer than %d characters", maxTransactionCatLength)
+	}
+	if budget.Amount <= 0 {
+		return invalidf("amount must be greater than 0")
+	}
+	currency, err := money.Validate(budget.Currency)
+	if err != nil {
+		return invalid(err)
+	}
+	budget.Currency = currency
+	if err := budget.Amount.CheckFits("amount", currency); err != nil {
+		return invalid(err)
+	}
+	budget.Rollover = strings.ToLower(strings.TrimSpace(budget.Rollover))
+	if budget.Rollover == "" {
+		budget.Rollover = RolloverNone
+	}
+	if !budgetRollovers[budget.Rollover] {
+		return invalidf("rollover must be none, unspent or full")
+	}
+	if _, err := time.Parse(monthLayout, budget.StartMonth); err != nil {
+		return invalidf("start_month must be YYYY-MM")
+	}
+	channels, err := validateChannels(sv.Channels, "budget alerts", budget.Channels, budget.Email, budget.WebhookURL, budget.PushSubscription)
+	if err != nil {
+		return err
+	}
+	budget.Channels = channels
+
+	budgets, err := sv.BudgetRepo.GetBudgetsByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, other := range *budgets {
+		if other.ID != budgetID && other.Category == budget.Category {
+			return invalidf("there is already a budget for %s", budget.Category)
+		}
+	}
+	return nil
+}
+
+// CreateBudget defaults the currency to the finance profile's and the start
+// month to the current one.
+func (sv *FinanceService) CreateBudget(userID string, data entities.BudgetResponse) (*entities.BudgetModel, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	data.UserID = userID
+	data.Currency = firstNonEmpty(data.Currency, sv.financeCurrency(userID))
+	if data.StartMonth == "" {
+		data.StartMonth = localDate(time.Now(), userLocation(sv.UserRepo, userID)).Format(monthLayout)
+	}
+	if data.Channels == nil {
+		data.Channels = []string{}
+	}
+	if err := sv.validateBudget(userID, "", &data); err != nil {
+		return nil, err
+	}
+	data.CreatedAt = time.Now().Add(7 * time.Hour)
+	data.UpdatedAt = time.Now().Add(7 * time.Hour)
+	budget, err := sv.BudgetRepo.InsertBudget(data)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> CreateBudget: %s \n", err)
+		return nil, err
+	}
+	go sv.alertBudgets(userID)
+	return budget, nil
+}
+
+func (sv *FinanceService) GetBudgetsByUserID(userID string) (*[]entities.BudgetModel, error) {
+	budgets, err := sv.BudgetRepo.GetBudgetsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> GetBudgetsByUserID: %s \n", err)
+		return nil, err
+	}
+	return budgets, nil
+}
+
+func (sv *FinanceService) userBudget(userID string, budgetID string) (*entities.BudgetModel, error) {
+	budget, err := sv.BudgetRepo.GetBudgetByID(budgetID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> userBudget: %s \n", err)
+		return nil, err
+	}
+	if budget.UserID != userID {
+		return nil, fmt.Errorf("budget does not belong to user")
+	}
+	return budget, nil
+}
+
+func (sv *FinanceService) UpdateBudget(userID string, budgetID string, data entities.BudgetResponse) (*entities.BudgetModel, error) {
+	current, err := sv.userBudget(userID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	edited := entities.BudgetResponse{
+		Category:         firstNonEmpty(data.Category, current.Category),
+		Amount:           current.Amount,
+		Currency:         firstNonEmpty(data.Currency, current.Currency),
+		Rollover:         firstNonEmpty(data.Rollover, current.Rollover),
+		StartMonth:       firstNonEmpty(data.StartMonth, current.StartMonth),
+		Channels:         current.Channels,
+		Email:            firstNonEmpty(data.Email, current.Email),
+		WebhookURL:       firstNonEmpty(data.WebhookURL, current.WebhookURL),
+		PushSubscription: current.PushSubscription,
+	}
+	if data.Amount != 0 {
+		edited.Amount = data.Amount
+	}
+	if data.Channels != nil {
+		edited.Channels = data.Channels
+	}
+	if data.PushSubscription != nil {
+		edited.PushSubscription = data.PushSubscription
+	}
+	if err := sv.validateBudget(userID, budgetID, &edited); err != nil {
+		return nil, err
+	}
+	budget, err := sv.BudgetRepo.UpdateBudget(budgetID, entities.BudgetUpdate{
+		Category:         edited.Category,
+		Amount:           edited.Amount,
+		Currency:         edited.Currency,
+		Rollover:         edited.Rollover,
+		StartMonth:       edited.StartMonth,
+		Channels:         edited.Channels,
+		Email:            edited.Email,
+		WebhookURL:       edited.WebhookURL,
+		PushSubscription: edited.PushSubscription,
+		UpdatedAt:        time.Now().Add(7 * time.Hour),
+	})
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> UpdateBudget: %s \n", err)
+		return nil, err
+	}
+	go sv.alertBudgets(userID)
+	return budget, nil
+}
+
+func (sv *FinanceService) DeleteBudget(userID string, budgetID string) error {
+	if _, err := sv.userBudget(userID, budgetID); err != nil {
+		return err
+	}
+	if err := sv.BudgetRepo.DeleteBudget(budgetID); err != nil {
+		fiberlog.Errorf("FinanceService -> DeleteBudget: %s \n", err)
+		return err
+	}
+	return nil
+}
+
+// GetBudgetReport compares each budget with the spending of month, the
+// current month in the user's timezone when nil.
+func (sv *FinanceService) GetBudgetReport(userID string, month *time.Time) (*entities.BudgetReport, error) {
+	budgets, err := sv.BudgetRepo.GetBudgetsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> GetBudgetReport: %s \n", err)
+		return nil, err
+	}
+	transactions, err := sv.TransactionRepo.GetTransactionsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> GetBudgetReport: %s \n", err)
+		return nil, err
+	}
+	today := localDate(time.Now(), userLocation(sv.UserRepo, userID))
+	first := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if month != nil {
+		first = *month
+	}
+	report, err := BuildBudgetReport(*budgets, *transactions, sv.financeCurrency(userID), first, today, sv.Config.Thresholds, sv.converter())
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> GetBudgetReport: %s \n", err)
+		return nil, err
+	}
+	return &report, nil
+}
+
+// BuildBudgetReport compares budgets with the spending of month as seen on
+// today, with unbudgeted spending in currency, the user's base currency.
+// Budgets starting after month are left out.
+func BuildBudgetReport(budgets []entities.BudgetModel, transactions []entities.TransactionModel, currency string, month time.Time, today time.Time, thresholds []int, convert CurrencyConverter) (entities.BudgetReport, error) {
+	report := entities.BudgetReport{
+		Month:           month.Format(monthLayout),
+		DaysInMonth:     month.AddDate(0, 1, -1).Day(),
+		Budgets:         []entities.BudgetStatus{},
+		Unbudgeted:      []entities.FinanceCategorySummary{},
+		OtherCurrencies: []string{},
+	}
+	report.DaysElapsed = daysElapsed(month, today)
+	budgeted := map[string]bool{}
+	for _, budget := range budgets {
+		budgeted[budget.Category] = true
+		if budget.StartMonth > report.Month {
+			continue
+		}
+		status, err := BudgetMonthStatus(budget, transactions, month, today, thresholds, convert)
+		if err != nil {
+			return report, err
+		}
+		report.Budgets = append(report.Budgets, status)
+	}
+	if currency == "" {
+		currency = mostUsedCurrency(transactions)
+	}
+	report.Currency = currency
+	categories := map[string]*entities.FinanceCategorySummary{}
+	others := map[string]bool{}
+	var total money.Amount
+	for _, transaction := range transactions {
+		if transaction.Type != TransactionExpense || budgeted[transaction.Category] {
+			continue
+		}
+		day, ok := TransactionOccursIn(transaction, month)
+		if !ok || day.After(today) {
+			continue
+		}
+		amount, ok, err := convertAmount(convert, transaction.Amount, transaction.Currency, currency, day.Format(habitDateLayout))
+		if err != nil {
+			return report, err
+		}
+		if !ok {
+			others[transaction.Currency] = true
+			continue
+		}
+		key := transaction.Category + "\x00" + transaction.ExpenseType
+		if categories[key] == nil {
+			categories[key] = &entities.FinanceCategorySummary{Category: transaction.Category, ExpenseType: transaction.ExpenseType}
+		}
+		categories[key].Amount += amount
+		categories[key].Count++
+		total += amount
+	}
+	report.Unbudgeted = categorySummaries(categories, total)
+	report.OtherCurrencies = currencyList(others)
+	return report, nil
+}
+
+// daysElapsed is how many days of month have passed by the end of today: all
+// of them for past months and none for future ones.
+func daysElapsed(month time.Time, today time.Time) int {
+	days := month.AddDate(0, 1, -1).Day()
+	switch current := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC); {
+	case month.Before(current):
+		return days
+	case month.After(current):
+		return 0
+	default:
+		return today.Day()
+	}
+}
+
+// budgetSpending totals the budget's expenses in month in its currency:
+// spent up to today, split into recurring and one-off, and still scheduled
+// after today. The currencies of expenses that could not be converted are
+// added to others.
+func budgetSpending(budget entities.BudgetModel, transactions []entities.TransactionModel, month time.Time, today time.Time, convert CurrencyConverter, others map[string]bool) (recurring money.Amount, oneOff money.Amount, scheduled money.Amount, err error) {
+	for _, transaction := range transactions {
+		if transaction.Type != TransactionExpense || transaction.Category != budget.Category {
+			continue
+		}
+		day, ok := TransactionOccursIn(transaction, month)
+		if !ok {
+			continue
+		}
+		amount, ok, err := convertAmount(convert, transaction.Amount, transaction.Currency, budget.Currency, day.Format(habitDateLayout))
+		switch {
+		case err != nil:
+			return 0, 0, 0, err
+		case !ok:
+			others[transaction.Currency] = true
+		case day.After(today):
+			scheduled += amount
+		case transaction.Recurring:
+			recurring += amount
+		default:
+			oneOff += amount
+		}
+	}
+	return recurring, oneOff, scheduled, nil
+}
+
+// currencyList is the currencies in set, sorted.
+func currencyList(set map[string]bool) []string {
+	currencies := []string{}
+	for currency := range set {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// BudgetMonthStatus is budget against actual for month as seen on today.
+// With rollover the months from the budget's start month carry over what
+// was left, and for full rollover what was overspent.
+func BudgetMonthStatus(budget entities.BudgetModel, transactions []entities.TransactionModel, month time.Time, today time.Time, thresholds []int, convert CurrencyConverter) (entities.BudgetStatus, error) {
+	status := entities.BudgetStatus{
+		BudgetID: budget.ID,
+		Category: budget.Category,
+		Currency: budget.Currency,
+		Rollover: budget.Rollover,
+		Amount:   budget.Amount,
+	}
+	others := map[string]bool{}
+	if start, err := time.Parse(monthLayout, budget.StartMonth); err == nil && budget.Rollover != RolloverNone && budget.Rollover != "" {
+		for previous := start; previous.Before(month); previous = previous.AddDate(0, 1, 0) {
+			recurring, oneOff, scheduled, err := budgetSpending(budget, transactions, previous, today, convert, others)
+			if err != nil {
+				return status, err
+			}
+			left := budget.Amount + status.CarriedOver - recurring - oneOff - scheduled
+			if budget.Rollover == RolloverUnspent {
+				left = max(left, 0)
+			}
+			status.CarriedOver = left
+		}
+	}
+	status.Available = budget.Amount + status.CarriedOver
+
+	recurring, oneOff, scheduled, err := budgetSpending(budget, transactions, month, today, convert, others)
+	if err != nil {
+		return status, err
+	}
+	status.OtherCurrencies = currencyList(others)
+	status.Spent = recurring + oneOff
+	status.Projected = status.Spent + scheduled
+	if elapsed := daysElapsed(month, today); elapsed > 0 {
+		rest := big.NewRat(int64(month.AddDate(0, 1, -1).Day()-elapsed), int64(elapsed))
+		pace, err := oneOff.Mul(rest)
+		if err != nil {
+			return status, err
+		}
+		status.Projected = (status.Projected + pace).Round(budget.Currency)
+	}
+	status.Remaining = status.Available - status.Spent
+	status.ProjectedRemaining = status.Available - status.Projected
+	switch {
+	case status.Available > 0:
+		status.Used = money.Ratio(status.Spent, status.Available)
+	case status.Spent > 0:
+		status.Used = 1
+	}
+	status.Status = BudgetUnder
+	if status.Used >= 1 {
+		status.Status = BudgetOver
+	} else if BudgetAlertLevel(status.Used, thresholds) > 0 {
+		status.Status = BudgetWarning
+	}
+
+	status.Used = roundTo(status.Used, 4)
+	return status, nil
+}
+
+// BudgetAlertLevel is the highest threshold, in percent, that used (a
+// fraction of the budget) has reached, or 0 for none.
+func BudgetAlertLevel(used float64, thresholds []int) int {
+	level := 0
+	for _, threshold := range thresholds {
+		if used*100 >= float64(threshold) {
+			level = threshold
+		}
+	}
+	return level
+}
+
+func (sv *FinanceService) Start() {
+	if !sv.Config.AlertsEnabled {
+		return
+	}
+	go func() {
+		sv.runBudgetAlerts()
+		ticker := time.NewTicker(sv.Config.CheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			sv.runBudgetAlerts()
+		}
+	}()
+}
+
+// runBudgetAlerts checks every budget that has alert channels. Start runs
+// it every CheckInterval.
+func (sv *FinanceService) runBudgetAlerts() {
+	if !sv.running.TryLock() {
+		return
+	}
+	defer sv.running.Unlock()
+
+	budgets, err := sv.BudgetRepo.GetAlertingBudgets()
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> runBudgetAlerts: %s \n", err)
+		return
+	}
+	byUser := map[string][]entities.BudgetModel{}
+	for _, budget := range *budgets {
+		byUser[budget.UserID] = append(byUser[budget.UserID], budget)
+	}
+	for userID, budgets := range byUser {
+		sv.sendBudgetAlerts(userID, budgets)
+	}
+}
+
+// alertBudgets checks the user's budgets after their spending or budgets
+// changed. Spending that grows without a write, such as a recurring expense
+// falling due or new exchange rates, is caught by runBudgetAlerts.
+func (sv *FinanceService) alertBudgets(userID string) {
+	if !sv.Config.AlertsEnabled {
+		return
+	}
+	budgets, err := sv.BudgetRepo.GetBudgetsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> alertBudgets: %s \n", err)
+		return
+	}
+	sv.sendBudgetAlerts(userID, *budgets)
+}
+
+// sendBudgetAlerts alerts on each budget whose spending this month has
+// reached a threshold it was not alerted on yet. Only the highest threshold
+// reached is sent, and the alert is claimed before it is.
+func (sv *FinanceService) sendBudgetAlerts(userID string, budgets []entities.BudgetModel) {
+	var transactions *[]entities.TransactionModel
+	location := userLocation(sv.UserRepo, userID)
+	today := localDate(time.Now(), location)
+	month := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for _, budget := range budgets {
+		if len(budget.Channels) == 0 || budget.StartMonth > month.Format(monthLayout) {
+			continue
+		}
+		if transactions == nil {
+			data, err := sv.TransactionRepo.GetTransactionsByUserID(userID)
+			if err != nil {
+				fiberlog.Errorf("FinanceService -> sendBudgetAlerts: %s \n", err)
+				return
+			}
+			transactions = data
+		}
+		status, err := BudgetMonthStatus(budget, *transactions, month, today, sv.Config.Thresholds, sv.converter())
+		if err != nil {
+			fiberlog.Errorf("FinanceService -> sendBudgetAlerts: budget %s: %s \n", budget.ID, err)
+			continue
+		}
+		level := BudgetAlertLevel(status.Used, sv.Config.Thresholds)
+		if level == 0 || (budget.AlertMonth == month.Format(monthLayout) && budget.AlertLevel >= level) {
+			continue
+		}
+		stamp := time.Now().Add(7 * time.Hour)
+		claimed, err := sv.BudgetRepo.ClaimBudgetAlert(budget.ID, entities.BudgetAlertUpdate{AlertMonth: month.Format(monthLayout), AlertLevel: level, UpdatedAt: stamp})
+		if err != nil || !claimed {
+			continue
+		}
+		message := budgetAlertMessage(userLanguage(sv.UserRepo, userID), budget, status, month.Format(monthLayout), level)
+		target := notify.Target{Email: budget.Email, WebhookURL: budget.WebhookURL, PushSubscription: budget.PushSubscription}
+		failures := sendNotification(sv.Channels, budget.Channels, target, message)
+		result := entities.BudgetAlertResult{LastAlertAt: budget.LastAlertAt, LastAlertError: strings.Join(failures, "; "), UpdatedAt: stamp}
+		if len(failures) < len(budget.Channels) {
+			result.LastAlertAt = &stamp
+		}
+		if err := sv.BudgetRepo.RecordBudgetAlert(budget.ID, result); err != nil {
+			fiberlog.Errorf("FinanceService -> sendBudgetAlerts: budget %s: %s \n", budget.ID, err)
+		}
+		if len(failures) > 0 {
+			fiberlog.Errorf("FinanceService -> sendBudgetAlerts: budget %s: %s \n", budget.ID, result.LastAlertError)
+		}
+	}
+}
+
+func budgetAlertMessage(lang string, budget entities.BudgetModel, status entities.BudgetStatus, month string, level int) notify.Message {
+	text := budgetAlertTexts[lang]
+	if text == nil {
+		text = budgetAlertTexts[locale.English]
+	}
+	message := notify.Message{
+		Kind:  BudgetAlertKind,
+		Title: fmt.Sprintf(text["title"], budget.Category),
+		Body:  fmt.Sprintf(text["warning"], int(math.Floor(status.Used*100)), budget.Category, status.Spent.Format(budget.Currency), status.Available.Format(budget.Currency), budget.Currency),
+		Data: map[string]any{
+			"budget_id": budget.ID,
+			"user_id":   budget.UserID,
+			"category":  budget.Category,
+			"level":     level,
+			"month":     month,
+		},
+		SentAt: time.Now().UTC(),
+	}
+	if status.Used >= 1 {
+		message.Body = fmt.Sprintf(text["over"], budget.Category, status.Spent.Format(budget.Currency), status.Available.Format(budget.Currency), budget.Currency)
+	}
+	return message
+}
+
+var budgetAlertTexts = map[string]map[string]string{
+	locale.English: {
+		"title":   "Budget alert: %s",
+		"warning": "You have used %d%% of your %s budget this month (%s of %s %s).",
+		"over":    "You have gone over your %s budget this month: %s of %s %s spent.",
+	},
+	locale.Thai: {
+		"title":   "แจ้งเตือนงบประมาณ: %s",
+		"warning": "เดือนนี้คุณใช้งบหมวด %[2]s ไปแล้ว %[1]d%% (%[3]s จาก %[4]s %[5]s)",
+		"over":    "เดือนนี้คุณใช้จ่ายหมวด %s เกินงบแล้ว: ใช้ไป %s จาก %s %s",
+	},
+}
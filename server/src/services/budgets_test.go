@@ -0,0 +1,142 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"go-fiber-template/domain/notify"
+	"go-fiber-template/domain/repositories"
+	"testing"
+	"time"
+)
+
+// fakeBudgetRepository keeps budgets in memory and claims alerts the way the
+// database does: only while the budget has not been alerted on at that level
+// or above this month.
+type fakeBudgetRepository struct {
+	repositories.IBudgetRepository
+	budgets []entities.BudgetModel
+}
+
+func (repo *fakeBudgetRepository) GetAlertingBudgets() (*[]entities.BudgetModel, error) {
+	budgets := []entities.BudgetModel{}
+	for _, budget := range repo.budgets {
+		if len(budget.Channels) > 0 {
+			budgets = append(budgets, budget)
+		}
+	}
+	return &budgets, nil
+}
+
+func (repo *fakeBudgetRepository) ClaimBudgetAlert(id string, data entities.BudgetAlertUpdate) (bool, error) {
+	for i := range repo.budgets {
+		budget := &repo.budgets[i]
+		if budget.ID != id || (budget.AlertMonth == data.AlertMonth && budget.AlertLevel >= data.AlertLevel) {
+			continue
+		}
+		budget.AlertMonth = data.AlertMonth
+		budget.AlertLevel = data.AlertLevel
+		return true, nil
+	}
+	return false, nil
+}
+
+func (repo *fakeBudgetRepository) RecordBudgetAlert(id string, data entities.BudgetAlertResult) error {
+	return nil
+}
+
+func (repo *fakeBudgetRepository) GetBudgetsByUserID(userID string) (*[]entities.BudgetModel, error) {
+	return &repo.budgets, nil
+}
+
+type fakeTransactionRepository struct {
+	repositories.ITransactionRepository
+	transactions []entities.TransactionModel
+}
+
+func (repo *fakeTransactionRepository) GetTransactionsByUserID(userID string) (*[]entities.TransactionModel, error) {
+	return &repo.transactions, nil
+}
+
+type fakeChannel struct {
+	sent []notify.Message
+}
+
+func (channel *fakeChannel) Name() string {
+	return notify.ChannelWebhook
+}
+
+func (channel *fakeChannel) Send(target notify.Target, message notify.Message) error {
+	channel.sent = append(channel.sent, message)
+	return nil
+}
+
+func TestScheduledBudgetAlertsCatchRecurringExpenses(t *testing.T) {
+	t.Setenv("DEFAULT_TIMEZONE", "UTC")
+	amount := func(value string) money.Amount {
+		parsed, err := money.ParseAmount(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	now := time.Now().UTC()
+	started := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -2, 0)
+	budgets := &fakeBudgetRepository{budgets: []entities.BudgetModel{
+		{ID: "food", UserID: "user", Category: "Food", Amount: amount("1000"), Currency: "THB", Rollover: RolloverNone, StartMonth: started.Format(monthLayout), Channels: []string{notify.ChannelWebhook}, WebhookURL: "https://example.com/hook"},
+		{ID: "rent", UserID: "user", Category: "Rent", Amount: amount("1000"), Currency: "THB", Rollover: RolloverNone, StartMonth: started.Format(monthLayout)},
+	}}
+	// Recurring expenses that were recorded months ago and fall on the 1st
+	// of this month without anything being written.
+	transactions := &fakeTransactionRepository{transactions: []entities.TransactionModel{
+		{ID: "meals", UserID: "user", Type: TransactionExpense, Amount: amount("900"), Currency: "THB", Category: "Food", Date: started.Format(habitDateLayout), Recurring: true},
+		{ID: "flat", UserID: "user", Type: TransactionExpense, Amount: amount("1200"), Currency: "THB", Category: "Rent", Date: started.Format(habitDateLayout), Recurring: true},
+	}}
+	channel := &fakeChannel{}
+	users := &fakeUsersRepository{users: map[string]entities.UserProfileModel{}}
+	service := NewFinanceService(nil, transactions, budgets, nil, nil, users, nil,
+		map[string]notify.IChannel{notify.ChannelWebhook: channel}, BudgetConfig{AlertsEnabled: true, Thresholds: []int{80, 100}}).(*FinanceService)
+
+	service.runBudgetAlerts()
+	if len(channel.sent) != 1 || channel.sent[0].Kind != BudgetAlertKind || channel.sent[0].Data["budget_id"] != "food" {
+		t.Fatalf("sent = %+v, want one alert for the food budget", channel.sent)
+	}
+	if budgets.budgets[0].AlertLevel != 80 {
+		t.Errorf("alert level = %d, want 80", budgets.budgets[0].AlertLevel)
+	}
+
+	service.runBudgetAlerts()
+	if len(channel.sent) != 1 {
+		t.Errorf("sent %d alerts after the second run, want the first one only", len(channel.sent))
+	}
+}
+
+func TestValidateBudgetReturnsValidationErrors(t *testing.T) {
+	budgets := &fakeBudgetRepository{budgets: []entities.BudgetModel{{ID: "food", UserID: "user", Category: "food"}}}
+	service := NewFinanceService(nil, nil, budgets, nil, nil, nil, nil, nil, BudgetConfig{}).(*FinanceService)
+	valid := entities.BudgetResponse{Category: "Rent", Amount: money.FromMinor(500000, "THB"), Currency: "THB", StartMonth: "2026-01"}
+	tests := []struct {
+		name   string
+		change func(budget *entities.BudgetResponse)
+	}{
+		{name: "no category", change: func(budget *entities.BudgetResponse) { budget.Category = " " }},
+		{name: "zero amount", change: func(budget *entities.BudgetResponse) { budget.Amount = 0 }},
+		{name: "unknown currency", change: func(budget *entities.BudgetResponse) { budget.Currency = "XYZ" }},
+		{name: "unknown rollover", change: func(budget *entities.BudgetResponse) { budget.Rollover = "some" }},
+		{name: "bad start month", change: func(budget *entities.BudgetResponse) { budget.StartMonth = "January" }},
+		{name: "duplicate category", change: func(budget *entities.BudgetResponse) { budget.Category = "Food" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := valid
+			tt.change(&budget)
+			err := service.validateBudget("user", "", &budget)
+			if !IsValidationError(err) {
+				t.Errorf("validateBudget() error = %v, want a validation error", err)
+			}
+		})
+	}
+	budget := valid
+	if err := service.validateBudget("user", "", &budget); err != nil {
+		t.Errorf("validateBudget() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/configuration"
+	"go-fiber-template/domain/aimodel"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// ChatHistoryConfig controls how much of a conversation is sent to the model.
+// TokenBudget bounds the verbatim recent turns, MinRecentTurns is always kept
+// even when it exceeds the budget, and SummaryBatchTurns is how many older
+// turns must pile up before the rolling summary is refreshed.
+type ChatHistoryConfig struct {
+	TokenBudget       int
+	MinRecentTurns    int
+	SummaryBatchTurns int
+	SummaryMaxWords   int
+}
+
+type ChatHistoryManager struct {
+	AiGenRepo repositories.IAiGenRepository
+	Usage     IUsageService
+	Privacy   IPrivacyService
+	Config    ChatHistoryConfig
+}
+
+type IChatHistoryManager interface {
+	BuildContext(userID string, history []entities.AIChat) (string, []entities.AIChat, error)
+}
+
+func NewChatHistoryConfigFromEnv() ChatHistoryConfig {
+	return ChatHistoryConfig{
+		TokenBudget:       configuration.GetEnvInt("CHAT_HISTORY_TOKEN_BUDGET", 3000),
+		MinRecentTurns:    configuration.GetEnvInt("CHAT_HISTORY_MIN_TURNS", 6),
+		SummaryBatchTurns: configuration.GetEnvInt("CHAT_SUMMARY_BATCH_TURNS", 10),
+		SummaryMaxWords:   configuration.GetEnvInt("CHAT_SUMMARY_MAX_WORDS", 250),
+	}
+}
+
+func NewChatHistoryManager(aiGenRepo repositories.IAiGenRepository, usage IUsageService, privacy IPrivacyService, config ChatHistoryConfig) IChatHistoryManager {
+	return &ChatHistoryManager{
+		AiGenRepo: aiGenRepo,
+		Usage:     usage,
+		Privacy:   privacy,
+		Config:    config,
+	}
+}
+
+// EstimateTokens gives a rough token count without calling the model. Latin
+// text averages about four characters per token; Thai and other scripts
+// without spaces tokenize closer to one token per two characters, so the
+// estimate takes whichever is larger.
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	byChars := utf8.RuneCountInString(text) / 4
+	byWords := len(strings.Fields(text)) * 4 / 3
+	nonASCII := 0
+	for _, r := range text {
+		if r >= utf8.RuneSelf {
+			nonASCII++
+		}
+	}
+	estimate := byChars
+	if byWords > estimate {
+		estimate = byWords
+	}
+	if nonASCII/2 > estimate {
+		estimate = nonASCII / 2
+	}
+	if estimate == 0 {
+		estimate = 1
+	}
+	return estimate
+}
+
+// BuildContext splits the history into a summary of older turns and the most
+// recent turns that are sent verbatim. The stored summary is only refreshed
+// once SummaryBatchTurns older turns have accumulated; until then those turns
+// stay verbatim so nothing is lost between refreshes.
+func (m *ChatHistoryManager) BuildContext(userID string, history []entities.AIChat) (string, []entities.AIChat, error) {
+	split := m.recentSplit(history)
+	older := history[:split]
+	recent := history[split:]
+
+	summary, err := m.AiGenRepo.GetChatSummaryByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("ChatHistoryManager -> BuildContext: %s \n", err)
+		return "", nil, err
+	}
+
+	pending := older
+	if summary != nil {
+		pending = unsummarizedTurns(older, summary.SummarizedUntil)
+	}
+
+	if len(pending) >= m.Config.SummaryBatchTurns && len(pending) > 0 {
+		refreshed, err := m.refreshSummary(userID, summary, pending)
+		if err != nil {
+			// Keep answering with the old summary and the pending turns
+			// verbatim; the next message retries the refresh.
+			fiberlog.Errorf("ChatHistoryManager -> BuildContext: %s \n", err)
+		} else {
+			summary = refreshed
+			pending = nil
+		}
+	}
+
+	combined := make([]entities.AIChat, 0, len(pending)+len(recent))
+	combined = append(combined, pending...)
+	combined = append(combined, recent...)
+
+	if summary == nil || summary.Summary == "" {
+		return "", combined, nil
+	}
+	return summaryInstruction(summary.Summary), combined, nil
+}
+
+// recentSplit returns the index of the first turn kept verbatim.
+func (m *ChatHistoryManager) recentSplit(history []entities.AIChat) int {
+	tokens := 0
+	split := len(history)
+	for i := len(history) - 1; i >= 0; i-- {
+		kept := len(history) - i
+		tokens += EstimateTokens(history[i].Message)
+		if kept > m.Config.MinRecentTurns && tokens > m.Config.TokenBudget {
+			break
+		}
+		split = i
+	}
+	return split
+}
+
+func unsummarizedTurns(older []entities.AIChat, summarizedUntil time.Time) []entities.AIChat {
+	for i, chat := range older {
+		if chat.CreatedAt.After(summarizedUntil) {
+			return older[i:]
+		}
+	}
+	return nil
+}
+
+func (m *ChatHistoryManager) refreshSummary(userID string, previous *entities.AIChatSummary, pending []entities.AIChat) (*entities.AIChatSummary, error) {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "You maintain a running summary of a conversation between a user and their AI life-planning assistant.\n")
+	fmt.Fprintf(&builder, "Update the summary with the new messages below. Keep facts about the user's goals, preferences, commitments, constraints and open questions. Drop small talk.\n")
+	fmt.Fprintf(&builder, "Write at most %d words in plain prose.\n\n", m.Config.SummaryMaxWords)
+	messageCount := len(pending)
+	if previous != nil && previous.Summary != "" {
+		fmt.Fprintf(&builder, "Current summary:\n%s\n\n", previous.Summary)
+		messageCount += previous.MessageCount
+	}
+	builder.WriteString("New messages:\n")
+	for _, chat := range pending {
+		fmt.Fprintf(&builder, "%s: %s\n", chat.Sender, chat.Message)
+	}
+
+	redaction := m.Privacy.NewRedaction(userID)
+	text, usage, err := m.AiGenRepo.GenerateChatSummary(withInstruction(redaction.Redact(builder.String()), redaction.Instruction()))
+	m.Usage.Record(userID, aimodel.FeatureSummary, usage)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(redaction.Restore(text))
+	data := entities.AIChatSummaryResponse{
+		UserID:          userID,
+		Summary:         text,
+		SummarizedUntil: pending[len(pending)-1].CreatedAt,
+		MessageCount:    messageCount,
+		TokenEstimate:   EstimateTokens(text),
+		UpdatedAt:       time.Now().Add(7 * time.Hour),
+	}
+	if err := m.AiGenRepo.UpsertChatSummary(data); err != nil {
+		return nil, err
+	}
+	return &entities.AIChatSummary{
+		UserID:          data.UserID,
+		Summary:         data.Summary,
+		SummarizedUntil: data.SummarizedUntil,
+		MessageCount:    data.MessageCount,
+		TokenEstimate:   data.TokenEstimate,
+		UpdatedAt:       data.UpdatedAt,
+	}, nil
+}
+
+func summaryInstruction(summary string) string {
+	return "Summary of the earlier conversation with this user:\n" + summary
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeSummaryRepository struct {
+	repositories.IAiGenRepository
+	summary  *entities.AIChatSummary
+	reply    string
+	err      error
+	prompts  []string
+	upserted []entities.AIChatSummaryResponse
+}
+
+func (repo *fakeSummaryRepository) GetChatSummaryByUserID(userID string) (*entities.AIChatSummary, error) {
+	return repo.summary, nil
+}
+
+func (repo *fakeSummaryRepository) GenerateChatSummary(prompt string) (string, entities.LLMUsage, error) {
+	repo.prompts = append(repo.prompts, prompt)
+	return repo.reply, entities.LLMUsage{}, repo.err
+}
+
+func (repo *fakeSummaryRepository) UpsertChatSummary(data entities.AIChatSummaryResponse) error {
+	repo.upserted = append(repo.upserted, data)
+	return nil
+}
+
+// chatTurns returns count turns of exactly ten estimated tokens each, a
+// minute apart.
+func chatTurns(count int) []entities.AIChat {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	turns := []entities.AIChat{}
+	for i := 0; i < count; i++ {
+		message := fmt.Sprintf("turn %d ", i)
+		message += strings.Repeat("x", 40-len(message))
+		turns = append(turns, entities.AIChat{Message: message, Sender: "user", CreatedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+	return turns
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"hi", 1},
+		{strings.Repeat("a", 40), 10},
+		{"one two three", 4},
+		{"สวัสดีครับ", 5},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRecentSplit(t *testing.T) {
+	long := chatTurns(6)
+	long[5].Message = strings.Repeat("a", 400)
+	tests := []struct {
+		name    string
+		history []entities.AIChat
+		budget  int
+		min     int
+		want    int
+	}{
+		{"exactly at the budget", chatTurns(6), 30, 2, 3},
+		{"one token short", chatTurns(6), 29, 2, 4},
+		{"minimum turns over the budget", chatTurns(6), 10, 5, 1},
+		{"long last turn", long, 30, 2, 4},
+		{"fewer turns than the minimum", chatTurns(3), 0, 6, 0},
+		{"empty", nil, 30, 2, 0},
+	}
+	for _, tt := range tests {
+		manager := &ChatHistoryManager{Config: ChatHistoryConfig{TokenBudget: tt.budget, MinRecentTurns: tt.min}}
+		if got := manager.recentSplit(tt.history); got != tt.want {
+			t.Errorf("%s: recentSplit() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildContext(t *testing.T) {
+	turns := chatTurns(6)
+	// A budget of 30 tokens keeps the last three turns verbatim.
+	tests := []struct {
+		name        string
+		batch       int
+		summary     *entities.AIChatSummary
+		err         error
+		wantSummary string
+		wantTurns   []entities.AIChat
+		wantRefresh []entities.AIChat
+		wantCount   int
+	}{
+		{"too few older turns", 4, nil, nil, "", turns, nil, 0},
+		{"first summary", 3, nil, nil, "new summary", turns[3:], turns[:3], 3},
+		{
+			"pending turns below the batch",
+			3,
+			&entities.AIChatSummary{Summary: "old summary", SummarizedUntil: turns[1].CreatedAt, MessageCount: 2},
+			nil, "old summary", turns[2:], nil, 0,
+		},
+		{
+			"refresh adds to the summary",
+			2,
+			&entities.AIChatSummary{Summary: "old summary", SummarizedUntil: turns[0].CreatedAt, MessageCount: 1},
+			nil, "new summary", turns[3:], turns[1:3], 3,
+		},
+		{
+			"failed refresh keeps turns verbatim",
+			2,
+			&entities.AIChatSummary{Summary: "old summary", SummarizedUntil: turns[0].CreatedAt, MessageCount: 1},
+			fmt.Errorf("model unavailable"), "old summary", turns[1:], turns[1:3], 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSummaryRepository{summary: tt.summary, reply: " new summary ", err: tt.err}
+			config := ChatHistoryConfig{TokenBudget: 30, MinRecentTurns: 2, SummaryBatchTurns: tt.batch, SummaryMaxWords: 100}
+			manager := NewChatHistoryManager(repo, &fakeUsage{}, NewPrivacyService(nil, PrivacyConfig{}), config)
+
+			instruction, verbatim, err := manager.BuildContext("user", turns)
+			if err != nil {
+				t.Fatalf("BuildContext() error = %v", err)
+			}
+			wantInstruction := ""
+			if tt.wantSummary != "" {
+				wantInstruction = summaryInstruction(tt.wantSummary)
+			}
+			if instruction != wantInstruction {
+				t.Errorf("instruction = %q, want %q", instruction, wantInstruction)
+			}
+			if !reflect.DeepEqual(verbatim, tt.wantTurns) {
+				t.Errorf("verbatim turns = %d, want the last %d", len(verbatim), len(tt.wantTurns))
+			}
+
+			if tt.wantRefresh == nil {
+				if len(repo.prompts) != 0 {
+					t.Errorf("summary refreshed, want the stored one kept")
+				}
+				return
+			}
+			if len(repo.prompts) != 1 {
+				t.Fatalf("summary refreshed %d times, want once", len(repo.prompts))
+			}
+			prompt := repo.prompts[0]
+			for _, turn := range turns {
+				summarized := false
+				for _, refreshed := range tt.wantRefresh {
+					summarized = summarized || refreshed.Message == turn.Message
+				}
+				if strings.Contains(prompt, turn.Message) != summarized {
+					t.Errorf("summary prompt contains %q: %v, want %v", turn.Message, !summarized, summarized)
+				}
+			}
+			if tt.summary != nil && !strings.Contains(prompt, "Current summary:\n"+tt.summary.Summary) {
+				t.Errorf("summary prompt does not carry the previous summary")
+			}
+			if tt.err != nil {
+				if len(repo.upserted) != 0 {
+					t.Errorf("summary stored after a failed refresh")
+				}
+				return
+			}
+			last := tt.wantRefresh[len(tt.wantRefresh)-1]
+			if len(repo.upserted) != 1 || repo.upserted[0].Summary != "new summary" || !repo.upserted[0].SummarizedUntil.Equal(last.CreatedAt) || repo.upserted[0].MessageCount != tt.wantCount {
+				t.Errorf("stored %+v, want the new summary until %v covering %d messages", repo.upserted, last.CreatedAt, tt.wantCount)
+			}
+		})
+	}
+}
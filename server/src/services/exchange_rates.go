@@ -0,0 +1,431 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/configuration"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"go-fiber-template/domain/repositories"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	ExchangeRateSourceAdmin = "admin"
+	defaultRatePivot        = "USD"
+	maxRateImport           = 10000
+	rateUpsertBatch         = 1000
+)
+
+// CurrencyConverter converts amount from one currency into another at the
+// rate of date (YYYY-MM-DD). ok is false when there is no rate for the pair;
+// err is set when the converted amount is out of range.
+type CurrencyConverter func(amount money.Amount, from string, to string, date string) (converted money.Amount, ok bool, err error)
+
+// ExchangeRateConfig sets where rates come from. Path is a JSON or CSV file
+// loaded at startup, Pivot the currency cross rates go through when a pair
+// has no rate of its own, and RefreshInterval how often rates added by
+// other instances are picked up.
+type ExchangeRateConfig struct {
+	Path            string
+	Pivot           string
+	RefreshInterval time.Duration
+}
+
+func NewExchangeRateConfigFromEnv() ExchangeRateConfig {
+	return ExchangeRateConfig{
+		Path:            os.Getenv("EXCHANGE_RATES_PATH"),
+		Pivot:           os.Getenv("EXCHANGE_RATE_PIVOT"),
+		RefreshInterval: time.Duration(configuration.GetEnvInt("EXCHANGE_RATE_REFRESH_MINUTES", 60)) * time.Minute,
+	}
+}
+
+// ExchangeRateService keeps the exchange_rates table in memory, each pair's
+// rates sorted by date, and converts amounts with it. No rates are fetched
+// from the network: they come from the file in Config.Path or the admin
+// endpoint.
+type ExchangeRateService struct {
+	RateRepo repositories.IExchangeRateRepository
+	Config   ExchangeRateConfig
+	mutex    sync.RWMutex
+	rates    map[string][]entities.ExchangeRateModel
+	loadedAt time.Time
+}
+
+type IExchangeRateService interface {
+	// Load reads the stored rates, then imports the rates file if one is
+	// configured.
+	Load() error
+	ImportRates(rates []entities.ExchangeRateResponse, source string) (*entities.ExchangeRateImport, error)
+	GetRates(base string, quote string) (*[]entities.ExchangeRateModel, error)
+	Rate(from string, to string, date string) (money.Rate, bool)
+	Convert(amount money.Amount, from string, to string, date string) (money.Amount, bool, error)
+}
+
+func NewExchangeRateService(rateRepo repositories.IExchangeRateRepository, config ExchangeRateConfig) IExchangeRateService {
+	config.Pivot = money.Normalize(config.Pivot)
+	if !money.Valid(config.Pivot) {
+		config.Pivot = defaultRatePivot
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Hour
+	}
+	return &ExchangeRateService{
+		RateRepo: rateRepo,
+		Config:   config,
+		rates:    map[string][]entities.ExchangeRateModel{},
+	}
+}
+
+func ratePair(base string, quote string) string {
+	return base + "/" + quote
+}
+
+func (sv *ExchangeRateService) Load() error {
+	if err := sv.reload(); err != nil {
+		return err
+	}
+	if sv.Config.Path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(sv.Config.Path)
+	if err != nil {
+		fiberlog.Errorf("ExchangeRateService -> Load: %s \n", err)
+		return err
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(sv.Config.Path)), ".")
+	rates, err := ParseExchangeRates(data, format)
+	if err != nil {
+		fiberlog.Errorf("ExchangeRateService -> Load: %s \n", err)
+		return err
+	}
+	result, err := sv.ImportRates(rates, filepath.Base(sv.Config.Path))
+	if err != nil {
+		return err
+	}
+	fiberlog.Infof("ExchangeRateService -> Load: imported %d rates from %s", result.Imported, sv.Config.Path)
+	return nil
+}
+
+// reload replaces the cache with the table.
+func (sv *ExchangeRateService) reload() error {
+	stored, err := sv.RateRepo.GetAllRates()
+	if err != nil {
+		fiberlog.Errorf("ExchangeRateService -> reload: %s \n", err)
+		return err
+	}
+	rates := map[string][]entities.ExchangeRateModel{}
+	for _, rate := range *stored {
+		if rate.Rate.IsZero() {
+			continue
+		}
+		pair := ratePair(rate.Base, rate.Quote)
+		rates[pair] = append(rates[pair], rate)
+	}
+	for _, history := range rates {
+		sortRates(history)
+	}
+	sv.mutex.Lock()
+	sv.rates = rates
+	sv.loadedAt = time.Now()
+	sv.mutex.Unlock()
+	return nil
+}
+
+// refresh reloads the cache when it is older than the refresh interval. A
+// failed reload keeps the rates already in memory until the next interval.
+func (sv *ExchangeRateService) refresh() {
+	sv.mutex.Lock()
+	stale := time.Since(sv.loadedAt) > sv.Config.RefreshInterval
+	if stale {
+		sv.loadedAt = time.Now()
+	}
+	sv.mutex.Unlock()
+	if stale {
+		_ = sv.reload()
+	}
+}
+
+func sortRates(rates []entities.ExchangeRateModel) {
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].Date < rates[j].Date
+	})
+}
+
+// ParseExchangeRates reads rates in JSON, either {"rates": [...]} or a bare
+// list, or CSV with the columns base,quote,rate,date and an optional source,
+// with or without a header row.
+func ParseExchangeRates(data []byte, format string) ([]entities.ExchangeRateResponse, error) {
+	if format == "csv" {
+		return parseExchangeRatesCSV(data)
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		rates := []entities.ExchangeRateResponse{}
+		if err := json.Unmarshal(data, &rates); err != nil {
+			return nil, fmt.Errorf("invalid rates json: %s", err)
+		}
+		return rates, nil
+	}
+	body := entities.ExchangeRateBody{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("invalid rates json: %s", err)
+	}
+	return body.Rates, nil
+}
+
+func parseExchangeRatesCSV(data []byte) ([]entities.ExchangeRateResponse, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rates csv: %s", err)
+	}
+	rates := []entities.ExchangeRateResponse{}
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "base") {
+			continue
+		}
+		if len(record) < 4 {
+			return nil, fmt.Errorf("rates csv line %d needs base,quote,rate,date", i+1)
+		}
+		rate, err := money.ParseRate(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("rates csv line %d: %s", i+1, err)
+		}
+		row := entities.ExchangeRateResponse{
+			Base:  record[0],
+			Quote: record[1],
+			Rate:  rate,
+			Date:  strings.TrimSpace(record[3]),
+		}
+		if len(record) > 4 {
+			row.Source = strings.TrimSpace(record[4])
+		}
+		rates = append(rates, row)
+	}
+	return rates, nil
+}
+
+// ImportRates validates rates and stores them, replacing the rate of a pair
+// already stored for the same date. Rates without a source get source.
+func (sv *ExchangeRateService) ImportRates(rates []entities.ExchangeRateResponse, source string) (*entities.ExchangeRateImport, error) {
+	if len(rates) == 0 {
+		return nil, invalidf("no rates given")
+	}
+	if len(rates) > maxRateImport {
+		return nil, invalidf("at most %d rates can be imported at once", maxRateImport)
+	}
+	now := time.Now().Add(7 * time.Hour)
+	// A pair and date given twice keeps the last rate, since one upsert
+	// cannot touch the same row twice.
+	index := map[string]int{}
+	rows := []entities.ExchangeRateResponse{}
+	for i, rate := range rates {
+		row, err := validateExchangeRate(rate)
+		if err != nil {
+			return nil, invalidf("rate %d: %s", i+1, err)
+		}
+		row.Source = firstNonEmpty(row.Source, source)
+		row.CreatedAt = now
+		key := ratePair(row.Base, row.Quote) + "@" + row.Date
+		if at, ok := index[key]; ok {
+			rows[at] = row
+			continue
+		}
+		index[key] = len(rows)
+		rows = append(rows, row)
+	}
+	stored := []entities.ExchangeRateModel{}
+	for start := 0; start < len(rows); start += rateUpsertBatch {
+		batch, err := sv.RateRepo.UpsertRates(rows[start:min(start+rateUpsertBatch, len(rows))])
+		if err != nil {
+			fiberlog.Errorf("ExchangeRateService -> ImportRates: %s \n", err)
+			return nil, err
+		}
+		stored = append(stored, *batch...)
+	}
+	sv.mutex.Lock()
+	pairs := map[string]bool{}
+	for _, rate := range stored {
+		pair := ratePair(rate.Base, rate.Quote)
+		pairs[pair] = true
+		history := sv.rates[pair]
+		replaced := false
+		for i := range history {
+			if history[i].Date == rate.Date {
+				history[i] = rate
+				replaced = true
+			}
+		}
+		if !replaced {
+			history = append(history, rate)
+		}
+		sortRates(history)
+		sv.rates[pair] = history
+	}
+	sv.mutex.Unlock()
+
+	result := &entities.ExchangeRateImport{Imported: len(stored), Pairs: []string{}}
+	for pair := range pairs {
+		result.Pairs = append(result.Pairs, pair)
+	}
+	sort.Strings(result.Pairs)
+	for _, row := range rows {
+		if result.From == "" || row.Date < result.From {
+			result.From = row.Date
+		}
+		if row.Date > result.To {
+			result.To = row.Date
+		}
+	}
+	return result, nil
+}
+
+func validateExchangeRate(rate entities.ExchangeRateResponse) (entities.ExchangeRateResponse, error) {
+	base, err := money.Validate(rate.Base)
+	if err != nil {
+		return rate, fmt.Errorf("base %s", err)
+	}
+	quote, err := money.Validate(rate.Quote)
+	if err != nil {
+		return rate, fmt.Errorf("quote %s", err)
+	}
+	if base == quote {
+		return rate, fmt.Errorf("base and quote must differ")
+	}
+	if rate.Rate.IsZero() {
+		return rate, fmt.Errorf("rate must be greater than 0")
+	}
+	date, err := time.Parse(habitDateLayout, strings.TrimSpace(rate.Date))
+	if err != nil {
+		return rate, fmt.Errorf("date must be YYYY-MM-DD")
+	}
+	rate.Base = base
+	rate.Quote = quote
+	rate.Date = date.Format(habitDateLayout)
+	rate.Source = strings.TrimSpace(rate.Source)
+	return rate, nil
+}
+
+// GetRates lists the rate history, optionally of one base or quote currency.
+func (sv *ExchangeRateService) GetRates(base string, quote string) (*[]entities.ExchangeRateModel, error) {
+	base = money.Normalize(base)
+	quote = money.Normalize(quote)
+	for _, code := range []string{base, quote} {
+		if code != "" && !money.Valid(code) {
+			return nil, invalidf("%s is not an ISO 4217 currency code", code)
+		}
+	}
+	sv.refresh()
+	sv.mutex.RLock()
+	defer sv.mutex.RUnlock()
+	rates := []entities.ExchangeRateModel{}
+	for _, history := range sv.rates {
+		for _, rate := range history {
+			if (base == "" || rate.Base == base) && (quote == "" || rate.Quote == quote) {
+				rates = append(rates, rate)
+			}
+		}
+	}
+	sort.SliceStable(rates, func(i, j int) bool {
+		if rates[i].Date != rates[j].Date {
+			return rates[i].Date < rates[j].Date
+		}
+		return ratePair(rates[i].Base, rates[i].Quote) < ratePair(rates[j].Base, rates[j].Quote)
+	})
+	return &rates, nil
+}
+
+// Rate is the rate from one currency into another on date: the pair's own
+// rate, else its inverse, else a cross rate through the pivot currency.
+func (sv *ExchangeRateService) Rate(from string, to string, date string) (money.Rate, bool) {
+	if from == to {
+		one, _ := money.ParseRate("1")
+		return one, true
+	}
+	sv.refresh()
+	sv.mutex.RLock()
+	defer sv.mutex.RUnlock()
+	if rate, ok := sv.pairRate(from, to, date); ok {
+		return rate, true
+	}
+	pivot := sv.Config.Pivot
+	if from == pivot || to == pivot {
+		return money.Rate{}, false
+	}
+	first, ok := sv.pairRate(from, pivot, date)
+	if !ok {
+		return money.Rate{}, false
+	}
+	second, ok := sv.pairRate(pivot, to, date)
+	if !ok {
+		return money.Rate{}, false
+	}
+	return first.Times(second), true
+}
+
+// pairRate looks a pair up directly or inverted. The caller holds the lock.
+func (sv *ExchangeRateService) pairRate(from string, to string, date string) (money.Rate, bool) {
+	if rate, ok := rateOn(sv.rates[ratePair(from, to)], date); ok {
+		return rate, true
+	}
+	if rate, ok := rateOn(sv.rates[ratePair(to, from)], date); ok {
+		return rate.Inverse(), true
+	}
+	return money.Rate{}, false
+}
+
+// rateOn picks the latest rate dated on or before date, or the earliest one
+// when date is before the history starts.
+func rateOn(history []entities.ExchangeRateModel, date string) (money.Rate, bool) {
+	if len(history) == 0 {
+		return money.Rate{}, false
+	}
+	at := sort.Search(len(history), func(i int) bool {
+		return history[i].Date > date
+	})
+	if at == 0 {
+		return history[0].Rate, true
+	}
+	return history[at-1].Rate, true
+}
+
+// Convert converts amount at the rate of date and rounds it to the minor
+// unit of the target currency.
+func (sv *ExchangeRateService) Convert(amount money.Amount, from string, to string, date string) (money.Amount, bool, error) {
+	if from == to {
+		return amount, true, nil
+	}
+	rate, ok := sv.Rate(from, to, date)
+	if !ok {
+		return 0, false, nil
+	}
+	converted, err := rate.Convert(amount, to)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot convert %s %s to %s: %w", amount, from, to, err)
+	}
+	return converted, true, nil
+}
+
+// convertAmount converts with convert, which may be nil when no rates are
+// available; amounts already in the target currency pass through.
+func convertAmount(convert CurrencyConverter, amount money.Amount, from string, to string, date string) (money.Amount, bool, error) {
+	if from == to {
+		return amount, true, nil
+	}
+	if convert == nil {
+		return 0, false, nil
+	}
+	return convert(amount, from, to, date)
+}
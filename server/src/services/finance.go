@@ -2,14 +2,26 @@ package services
 
 import (
 	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"go-fiber-template/domain/notify"
 	"go-fiber-template/domain/repositories"
+	"sync"
 	"time"
 
 	fiberlog "github.com/gofiber/fiber/v2/log"
 )
 
 type FinanceService struct {
-	FinanceRepo repositories.IFinanceRepository
+	FinanceRepo     repositories.IFinanceRepository
+	TransactionRepo repositories.ITransactionRepository
+	BudgetRepo      repositories.IBudgetRepository
+	SavingsRepo     repositories.ISavingsGoalRepository
+	LifeGoalRepo    repositories.ILifeGoalRepository
+	UserRepo        repositories.IUsersRepository
+	Rates           IExchangeRateService
+	Channels        map[string]notify.IChannel
+	Config          BudgetConfig
+	running         sync.Mutex
 }
 
 type IFinanceService interface {
@@ -18,11 +30,43 @@ type IFinanceService interface {
 	CreateFinance(id string, finance entities.FinanceRespond) error
 	// UpdateFinance(id string, finance entities.FinanceModel) (entities.FinanceModel, error)
 	// DeleteFinance(id string) error
-}	
+	CreateTransaction(userID string, body entities.TransactionBody) (*entities.TransactionModel, error)
+	ListTransactions(userID string, query TransactionQuery) (*[]entities.TransactionModel, error)
+	UpdateTransaction(userID string, transactionID string, body entities.TransactionBody) (*entities.TransactionModel, error)
+	DeleteTransaction(userID string, transactionID string) error
+	GetMonthlySummaries(userID string, months MonthRange) (*[]entities.FinanceMonthSummary, error)
+	CreateBudget(userID string, data entities.BudgetResponse) (*entities.BudgetModel, error)
+	GetBudgetsByUserID(userID string) (*[]entities.BudgetModel, error)
+	// UpdateBudget edits the budget. Fields left empty keep their value.
+	UpdateBudget(userID string, budgetID string, data entities.BudgetResponse) (*entities.BudgetModel, error)
+	DeleteBudget(userID string, budgetID string) error
+	GetBudgetReport(userID string, month *time.Time) (*entities.BudgetReport, error)
+	CreateSavingsGoal(userID string, body entities.SavingsGoalBody) (*entities.SavingsGoalProgress, error)
+	GetSavingsGoals(userID string, lifeGoalID string) (*[]entities.SavingsGoalProgress, error)
+	// GetSavingsGoal returns the goal's progress with its contributions.
+	GetSavingsGoal(userID string, goalID string) (*entities.SavingsGoalProgress, error)
+	UpdateSavingsGoal(userID string, goalID string, body entities.SavingsGoalBody) (*entities.SavingsGoalProgress, error)
+	DeleteSavingsGoal(userID string, goalID string) error
+	AddContribution(userID string, goalID string, data entities.SavingsContributionResponse) (*entities.SavingsGoalProgress, error)
+	DeleteContribution(userID string, goalID string, contributionID string) (*entities.SavingsGoalProgress, error)
+	// Start checks budget alerts periodically.
+	Start()
+}
 
-func NewFinanceService(financeRepo repositories.IFinanceRepository) IFinanceService {
+func NewFinanceService(financeRepo repositories.IFinanceRepository, transactionRepo repositories.ITransactionRepository, budgetRepo repositories.IBudgetRepository, savingsRepo repositories.ISavingsGoalRepository, lifeGoalRepo repositories.ILifeGoalRepository, userRepo repositories.IUsersRepository, rates IExchangeRateService, channels map[string]notify.IChannel, config BudgetConfig) IFinanceService {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = time.Hour
+	}
 	return &FinanceService{
-		FinanceRepo: financeRepo,
+		FinanceRepo:     financeRepo,
+		TransactionRepo: transactionRepo,
+		BudgetRepo:      budgetRepo,
+		SavingsRepo:     savingsRepo,
+		LifeGoalRepo:    lifeGoalRepo,
+		UserRepo:        userRepo,
+		Rates:           rates,
+		Channels:        channels,
+		Config:          config,
 	}
 }
 
@@ -44,11 +88,29 @@ func (sv *FinanceService) GetAllFinanceByUserID(userID string) (*entities.Financ
 	return data, nil
 }
 
+// CreateFinance checks that the currency is an ISO 4217 code and that the
+// amounts fit its minor unit.
 func (sv *FinanceService) CreateFinance(id string, finance entities.FinanceRespond) error {
+	currency, err := money.Validate(finance.Currency)
+	if err != nil {
+		return invalid(err)
+	}
+	finance.Currency = currency
+	for _, amount := range []struct {
+		Name  string
+		Value money.Amount
+	}{{"income", finance.Income}, {"expenses", finance.Expenses}, {"savings_goal", finance.Savings_Goal}} {
+		if amount.Value < 0 {
+			return invalidf("%s cannot be negative", amount.Name)
+		}
+		if err := amount.Value.CheckFits(amount.Name, currency); err != nil {
+			return invalid(err)
+		}
+	}
 	finance.UserID = id
 	finance.CreatedAt = time.Now().Add(7 * time.Hour)
 	finance.UpdatedAt = time.Now().Add(7 * time.Hour)
-	err := sv.FinanceRepo.CreateFinance(finance)
+	err = sv.FinanceRepo.CreateFinance(finance)
 	if err != nil {
 		fiberlog.Errorf("FinanceService -> CreateFinance: %s \n", err)
 		return err
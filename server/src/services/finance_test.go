@@ -0,0 +1,45 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"go-fiber-template/domain/repositories"
+	"testing"
+)
+
+type fakeFinanceRepository struct {
+	repositories.IFinanceRepository
+	err error
+}
+
+func (repo *fakeFinanceRepository) CreateFinance(finance entities.FinanceRespond) error {
+	return repo.err
+}
+
+func TestCreateFinanceSeparatesValidationErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		finance    entities.FinanceRespond
+		repoErr    error
+		validation bool
+	}{
+		{name: "unknown currency", finance: entities.FinanceRespond{Currency: "XYZ"}, validation: true},
+		{name: "negative income", finance: entities.FinanceRespond{Currency: "THB", Income: money.FromMinor(-100, "THB")}, validation: true},
+		{name: "too many decimals", finance: entities.FinanceRespond{Currency: "JPY", Income: money.FromMinor(1005, "USD")}, validation: true},
+		{name: "database error", finance: entities.FinanceRespond{Currency: "THB"}, repoErr: fmt.Errorf("duplicate key value violates unique constraint")},
+		{name: "saved", finance: entities.FinanceRespond{Currency: "THB"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewFinanceService(&fakeFinanceRepository{err: tt.repoErr}, nil, nil, nil, nil, nil, nil, nil, BudgetConfig{})
+			err := service.CreateFinance("user", tt.finance)
+			if (err != nil) != (tt.validation || tt.repoErr != nil) {
+				t.Fatalf("CreateFinance() error = %v", err)
+			}
+			if IsValidationError(err) != tt.validation {
+				t.Errorf("IsValidationError(%v) = %v, want %v", err, IsValidationError(err), tt.validation)
+			}
+		})
+	}
+}
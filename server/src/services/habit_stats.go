@@ -0,0 +1,259 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/src/recurrence"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HabitStatsQuery selects the completion rate windows, in days, and how many
+// days up to today the heatmap and weekday stats cover.
+type HabitStatsQuery struct {
+	Windows []int
+	Days    int
+}
+
+const (
+	defaultHabitStatsDays = 91
+	maxHabitStatsWindows  = 6
+)
+
+var defaultHabitStatsWindows = []int{7, 30, 90}
+
+// ParseHabitStatsQuery reads the windows and days query parameters, e.g.
+// windows=7,30,90 and days=91. Both are limited to a year.
+func ParseHabitStatsQuery(windows string, days string) (HabitStatsQuery, error) {
+	query := HabitStatsQuery{Windows: defaultHabitStatsWindows, Days: defaultHabitStatsDays}
+	if windows != "" {
+		query.Windows = []int{}
+		for _, item := range strings.Split(windows, ",") {
+			window, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil || window < 1 || window > maxOccurrenceRange {
+				return HabitStatsQuery{}, fmt.Errorf("windows must be numbers of days between 1 and %d", maxOccurrenceRange)
+			}
+			query.Windows = append(query.Windows, window)
+		}
+		if len(query.Windows) > maxHabitStatsWindows {
+			return HabitStatsQuery{}, fmt.Errorf("at most %d windows are allowed", maxHabitStatsWindows)
+		}
+	}
+	if days != "" {
+		parsed, err := strconv.Atoi(days)
+		if err != nil || parsed < 7 || parsed > maxOccurrenceRange {
+			return HabitStatsQuery{}, fmt.Errorf("days must be between 7 and %d", maxOccurrenceRange)
+		}
+		query.Days = parsed
+	}
+	return query, nil
+}
+
+// ComputeHabitStats works out a habit's completion rates, heatmap and
+// weekday performance as of today, a date in the user's timezone, from its
+// check-ins and recurrence rule. A window counts the occurrences completed
+// in it and those missed that ended in it, so a weekly or monthly occurrence
+// crossing the window's start is counted by the day it was completed or
+// missed.
+func ComputeHabitStats(habit entities.HabitModel, checkIns []entities.HabitCheckInModel, today time.Time, location *time.Location, query HabitStatsQuery) entities.HabitStats {
+	today = recurrence.Date(today)
+	streak := ComputeHabitStreak(habit, checkIns, today, location)
+	stats := emptyHabitStats(today, query)
+	stats.HabitID = habit.ID
+	stats.Name = habit.Name
+	stats.Recurrence = HabitRule(habit).String()
+	stats.Status = habitStatus(habit)
+	stats.CurrentStreak = streak.Current
+	stats.BestStreak = streak.Best
+	stats.TotalCheckIns = len(checkIns)
+
+	longest := query.Days
+	for _, window := range query.Windows {
+		longest = max(longest, window)
+	}
+	occurrences := HabitOccurrences(habit, checkIns, today.AddDate(0, 0, 1-longest), today.AddDate(0, 0, 1), today, location)
+	todayDate := today.Format(habitDateLayout)
+	counted := func(occurrence entities.HabitOccurrence) bool {
+		if occurrence.Completed {
+			return true
+		}
+		return !occurrence.Paused && occurrence.End < todayDate
+	}
+
+	dates := make([]string, 0, len(checkIns))
+	for _, checkIn := range checkIns {
+		dates = append(dates, checkIn.Date)
+	}
+	sort.Strings(dates)
+	for i := range stats.Windows {
+		window := &stats.Windows[i]
+		for _, occurrence := range occurrences {
+			switch {
+			case occurrence.Completed:
+				if done := completedOn(occurrence, dates); done >= window.From && done <= window.To {
+					window.Expected++
+					window.Completed++
+				}
+			case counted(occurrence) && occurrence.End >= window.From:
+				window.Expected++
+			}
+		}
+	}
+
+	perDay := map[string]int{}
+	for _, checkIn := range checkIns {
+		perDay[checkIn.Date]++
+	}
+	due := map[string]bool{}
+	for _, occurrence := range occurrences {
+		if occurrence.Paused {
+			continue
+		}
+		start, _ := time.Parse(habitDateLayout, occurrence.Start)
+		end, _ := time.Parse(habitDateLayout, occurrence.End)
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			due[day.Format(habitDateLayout)] = true
+		}
+	}
+	for _, row := range stats.Heatmap.Weeks {
+		for _, cell := range row {
+			if cell != nil {
+				cell.CheckIns = perDay[cell.Date]
+				cell.Due = due[cell.Date]
+			}
+		}
+	}
+
+	for _, checkIn := range checkIns {
+		if checkIn.Date >= stats.Heatmap.From && checkIn.Date <= stats.Heatmap.To {
+			if date, err := time.Parse(habitDateLayout, checkIn.Date); err == nil {
+				stats.Weekdays[weekdayIndex(date)].CheckIns++
+			}
+		}
+	}
+	for _, occurrence := range occurrences {
+		if occurrence.Start != occurrence.End || occurrence.Start < stats.Heatmap.From || !counted(occurrence) {
+			continue
+		}
+		date, _ := time.Parse(habitDateLayout, occurrence.Start)
+		stats.Weekdays[weekdayIndex(date)].Due++
+		if occurrence.Completed {
+			stats.Weekdays[weekdayIndex(date)].Completed++
+		}
+	}
+	finishHabitStats(&stats.Windows, &stats.Weekdays)
+	return stats
+}
+
+// completedOn is the day a completed occurrence reached its target, given
+// the sorted check-in dates.
+func completedOn(occurrence entities.HabitOccurrence, dates []string) string {
+	count := 0
+	for _, date := range dates {
+		if date >= occurrence.Start && date <= occurrence.End {
+			if count++; count >= occurrence.Target {
+				return date
+			}
+		}
+	}
+	return occurrence.End
+}
+
+// CombineHabitStats adds up the stats of several habits computed for the
+// same day and query.
+func CombineHabitStats(habits []entities.HabitStats, today time.Time, query HabitStatsQuery) entities.UserHabitStats {
+	empty := emptyHabitStats(recurrence.Date(today), query)
+	combined := entities.UserHabitStats{
+		Habits:   habits,
+		Windows:  empty.Windows,
+		Heatmap:  empty.Heatmap,
+		Weekdays: empty.Weekdays,
+	}
+	for _, habit := range habits {
+		combined.BestStreak = max(combined.BestStreak, habit.BestStreak)
+		combined.TotalCheckIns += habit.TotalCheckIns
+		for i, window := range habit.Windows {
+			combined.Windows[i].Expected += window.Expected
+			combined.Windows[i].Completed += window.Completed
+		}
+		for i, row := range habit.Heatmap.Weeks {
+			for j, cell := range row {
+				if cell != nil {
+					combined.Heatmap.Weeks[i][j].CheckIns += cell.CheckIns
+					combined.Heatmap.Weeks[i][j].Due = combined.Heatmap.Weeks[i][j].Due || cell.Due
+				}
+			}
+		}
+		for i, weekday := range habit.Weekdays {
+			combined.Weekdays[i].CheckIns += weekday.CheckIns
+			combined.Weekdays[i].Due += weekday.Due
+			combined.Weekdays[i].Completed += weekday.Completed
+		}
+	}
+	finishHabitStats(&combined.Windows, &combined.Weekdays)
+	return combined
+}
+
+// emptyHabitStats lays out the windows, heatmap and weekdays with zero
+// counts.
+func emptyHabitStats(today time.Time, query HabitStatsQuery) entities.HabitStats {
+	stats := entities.HabitStats{
+		Windows:  []entities.HabitCompletionWindow{},
+		Weekdays: []entities.HabitWeekdayStat{},
+	}
+	for _, days := range query.Windows {
+		stats.Windows = append(stats.Windows, entities.HabitCompletionWindow{
+			Days: days,
+			From: today.AddDate(0, 0, 1-days).Format(habitDateLayout),
+			To:   today.Format(habitDateLayout),
+		})
+	}
+	from := today.AddDate(0, 0, 1-query.Days)
+	stats.Heatmap = entities.HabitHeatmap{
+		From:  from.Format(habitDateLayout),
+		To:    today.Format(habitDateLayout),
+		Weeks: [][]*entities.HabitHeatmapCell{},
+	}
+	for week := WeekStart(from); !week.After(today); week = week.AddDate(0, 0, 7) {
+		row := make([]*entities.HabitHeatmapCell, 7)
+		for i := range row {
+			if day := week.AddDate(0, 0, i); !day.Before(from) && !day.After(today) {
+				row[i] = &entities.HabitHeatmapCell{Date: day.Format(habitDateLayout)}
+			}
+		}
+		stats.Heatmap.Weeks = append(stats.Heatmap.Weeks, row)
+	}
+	for i := 0; i < 7; i++ {
+		stats.Weekdays = append(stats.Weekdays, entities.HabitWeekdayStat{Weekday: time.Weekday((i + 1) % 7).String()})
+	}
+	return stats
+}
+
+func finishHabitStats(windows *[]entities.HabitCompletionWindow, weekdays *[]entities.HabitWeekdayStat) {
+	for i := range *windows {
+		window := &(*windows)[i]
+		if window.Expected > 0 {
+			window.Rate = float64(window.Completed) / float64(window.Expected)
+		}
+	}
+	total := 0
+	for _, weekday := range *weekdays {
+		total += weekday.CheckIns
+	}
+	for i := range *weekdays {
+		weekday := &(*weekdays)[i]
+		if weekday.Due > 0 {
+			weekday.Rate = float64(weekday.Completed) / float64(weekday.Due)
+		}
+		if total > 0 {
+			weekday.Share = float64(weekday.CheckIns) / float64(total)
+		}
+	}
+}
+
+// weekdayIndex counts from Monday.
+func weekdayIndex(date time.Time) int {
+	return (int(date.Weekday()) + 6) % 7
+}
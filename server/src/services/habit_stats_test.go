@@ -0,0 +1,53 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"testing"
+	"time"
+)
+
+func TestComputeHabitStatsCountsOccurrencesCrossingTheWindow(t *testing.T) {
+	// Wednesday 15 April 2026; weeks start on Monday.
+	today := time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(7 * time.Hour)
+	checkIns := func(dates ...string) []entities.HabitCheckInModel {
+		models := []entities.HabitCheckInModel{}
+		for _, date := range dates {
+			models = append(models, entities.HabitCheckInModel{HabitID: 1, Date: date})
+		}
+		return models
+	}
+	tests := []struct {
+		name      string
+		frequency string
+		checkIns  []entities.HabitCheckInModel
+		expected  int
+		completed int
+	}{
+		// The current week started before the 2-day window and is done.
+		{"current week completed", "weekly", checkIns("2026-04-14"), 1, 1},
+		// Completed on Monday, before the window began.
+		{"completed before the window", "weekly", checkIns("2026-04-13"), 0, 0},
+		// The current week is still in progress.
+		{"current week open", "weekly", nil, 0, 0},
+		// April started before the window and was completed in it.
+		{"current month completed", "monthly", checkIns("2026-04-15"), 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			habit := entities.HabitModel{ID: 1, Frequency: tt.frequency, TargetCount: 1, CreatedAt: created}
+			stats := ComputeHabitStats(habit, tt.checkIns, today, time.UTC, HabitStatsQuery{Windows: []int{2}, Days: 7})
+			window := stats.Windows[0]
+			if window.Expected != tt.expected || window.Completed != tt.completed {
+				t.Errorf("window = %d of %d, want %d of %d", window.Completed, window.Expected, tt.completed, tt.expected)
+			}
+		})
+	}
+
+	// A missed week counts in the window it ended in.
+	habit := entities.HabitModel{ID: 1, Frequency: "weekly", TargetCount: 1, CreatedAt: created}
+	stats := ComputeHabitStats(habit, nil, today, time.UTC, HabitStatsQuery{Windows: []int{7}, Days: 7})
+	if window := stats.Windows[0]; window.Expected != 1 || window.Completed != 0 {
+		t.Errorf("missed week: window = %d of %d, want 0 of 1", window.Completed, window.Expected)
+	}
+}
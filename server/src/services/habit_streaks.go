@@ -0,0 +1,150 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/src/recurrence"
+	"sort"
+	"time"
+)
+
+const habitDateLayout = "2006-01-02"
+
+// HabitStreak is the number of consecutive completed occurrences of a habit.
+// An occurrence is completed when it has at least TargetCount check-ins.
+type HabitStreak struct {
+	Current       int
+	Best          int
+	CompletedDate []string
+}
+
+// HabitRule is the habit's recurrence rule. Habits stored before recurrence
+// rules existed fall back to their frequency, and to daily without one.
+func HabitRule(habit entities.HabitModel) recurrence.Rule {
+	if habit.Recurrence != "" {
+		if rule, err := recurrence.Parse(habit.Recurrence); err == nil {
+			return rule
+		}
+	}
+	if rule, ok := recurrence.FromFrequency(habit.Frequency); ok {
+		return rule
+	}
+	rule, _ := recurrence.FromFrequency("daily")
+	return rule
+}
+
+// habitStartDate is the rule's DTSTART: the day the habit was created in
+// location, or its earliest check-in when that is earlier.
+func habitStartDate(habit entities.HabitModel, checkIns []entities.HabitCheckInModel, today time.Time, location *time.Location) time.Time {
+	start := recurrence.Date(today)
+	if !habit.CreatedAt.IsZero() {
+		start = habitCreatedDate(habit, location)
+	}
+	for _, checkIn := range checkIns {
+		if date, err := time.Parse(habitDateLayout, checkIn.Date); err == nil && date.Before(start) {
+			start = date
+		}
+	}
+	return start
+}
+
+// habitCreatedDate is the day the habit was created in location. CreatedAt
+// is stored seven hours ahead, as Bangkok wall time.
+func habitCreatedDate(habit entities.HabitModel, location *time.Location) time.Time {
+	return localDate(habit.CreatedAt.Add(-7*time.Hour), location)
+}
+
+// habitOccurrencePaused reports whether any day of the occurrence falls in
+// one of the habit's pauses.
+func habitOccurrencePaused(habit entities.HabitModel, occurrence recurrence.Occurrence) bool {
+	for _, pause := range habit.Pauses {
+		pauseStart, err := time.Parse(habitDateLayout, pause.Start)
+		if err != nil || !pauseStart.Before(occurrence.End) {
+			continue
+		}
+		if pause.End == nil {
+			return true
+		}
+		if pauseEnd, err := time.Parse(habitDateLayout, *pause.End); err == nil && occurrence.Start.Before(pauseEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+// HabitOccurrences expands the habit's rule over the days from up to but not
+// including to, and counts the check-ins in each occurrence. location is the
+// user's timezone, which today is a date in.
+func HabitOccurrences(habit entities.HabitModel, checkIns []entities.HabitCheckInModel, from time.Time, to time.Time, today time.Time, location *time.Location) []entities.HabitOccurrence {
+	target := max(habit.TargetCount, 1)
+	dates := make([]time.Time, 0, len(checkIns))
+	for _, checkIn := range checkIns {
+		if date, err := time.Parse(habitDateLayout, checkIn.Date); err == nil {
+			dates = append(dates, date)
+		}
+	}
+	occurrences := []entities.HabitOccurrence{}
+	for _, occurrence := range HabitRule(habit).Between(habitStartDate(habit, checkIns, today, location), from, to) {
+		count := 0
+		for _, date := range dates {
+			if occurrence.Contains(date) {
+				count++
+			}
+		}
+		occurrences = append(occurrences, entities.HabitOccurrence{
+			Start:     occurrence.Start.Format(habitDateLayout),
+			End:       occurrence.End.AddDate(0, 0, -1).Format(habitDateLayout),
+			Target:    target,
+			CheckIns:  count,
+			Completed: count >= target,
+			Paused:    habitOccurrencePaused(habit, occurrence),
+		})
+	}
+	return occurrences
+}
+
+// ComputeHabitStreak works out the streaks of habit as of today, a date in
+// the user's timezone, from the occurrences its rule has produced so far. An
+// occurrence does not break the streak while it is still in progress, so a
+// daily habit keeps yesterday's streak until the user checks in today or the
+// day ends. Occurrences that overlap a pause and were not completed are
+// skipped: they neither add to nor break a streak. Check-ins on days the
+// habit is not due do not count.
+func ComputeHabitStreak(habit entities.HabitModel, checkIns []entities.HabitCheckInModel, today time.Time, location *time.Location) HabitStreak {
+	streak := HabitStreak{CompletedDate: []string{}}
+	seen := map[string]bool{}
+	for _, checkIn := range checkIns {
+		if !seen[checkIn.Date] {
+			seen[checkIn.Date] = true
+			streak.CompletedDate = append(streak.CompletedDate, checkIn.Date)
+		}
+	}
+	sort.Strings(streak.CompletedDate)
+
+	today = recurrence.Date(today)
+	start := habitStartDate(habit, checkIns, today, location)
+	occurrences := HabitOccurrences(habit, checkIns, start, today.AddDate(0, 0, 1), today, location)
+	inProgress := func(occurrence entities.HabitOccurrence) bool {
+		return !occurrence.Completed && occurrence.End >= today.Format(habitDateLayout)
+	}
+
+	run := 0
+	for _, occurrence := range occurrences {
+		switch {
+		case occurrence.Completed:
+			run++
+			streak.Best = max(streak.Best, run)
+		case occurrence.Paused || inProgress(occurrence):
+		default:
+			run = 0
+		}
+	}
+	for i := len(occurrences) - 1; i >= 0; i-- {
+		occurrence := occurrences[i]
+		if occurrence.Completed {
+			streak.Current++
+		} else if !occurrence.Paused && !inProgress(occurrence) {
+			break
+		}
+	}
+	return streak
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"testing"
+	"time"
+)
+
+func TestHabitStartDateInUserTimezone(t *testing.T) {
+	// Created at 20:00 on 10 March in New York, stored seven hours ahead of
+	// UTC like every other CreatedAt.
+	created := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC).Add(7 * time.Hour)
+	habit := entities.HabitModel{ID: 1, Frequency: "daily", TargetCount: 1, CreatedAt: created}
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"America/New_York", "2026-03-10"},
+		{"UTC", "2026-03-11"},
+		{"Asia/Bangkok", "2026-03-11"},
+	}
+	for _, tt := range tests {
+		location, err := time.LoadLocation(tt.timezone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		today := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+		if got := habitStartDate(habit, nil, today, location).Format(habitDateLayout); got != tt.want {
+			t.Errorf("%s: habitStartDate() = %s, want %s", tt.timezone, got, tt.want)
+		}
+	}
+}
+
+func TestComputeHabitStreak(t *testing.T) {
+	checkIns := func(dates ...string) []entities.HabitCheckInModel {
+		result := []entities.HabitCheckInModel{}
+		for i, date := range dates {
+			result = append(result, entities.HabitCheckInModel{ID: i + 1, HabitID: 1, Date: date})
+		}
+		return result
+	}
+	resumed := "2026-03-06"
+	tests := []struct {
+		name        string
+		recurrence  string
+		target      int
+		created     string
+		pauses      []entities.HabitPause
+		checkIns    []entities.HabitCheckInModel
+		today       string
+		wantCurrent int
+		wantBest    int
+	}{
+		{
+			name: "daily with a missed day", recurrence: "FREQ=DAILY", created: "2026-03-01",
+			checkIns: checkIns("2026-03-01", "2026-03-02", "2026-03-03", "2026-03-05", "2026-03-06"),
+			today:    "2026-03-06", wantCurrent: 2, wantBest: 3,
+		},
+		{
+			name: "today not done yet", recurrence: "FREQ=DAILY", created: "2026-03-01",
+			checkIns: checkIns("2026-03-01", "2026-03-02", "2026-03-03", "2026-03-04", "2026-03-05"),
+			today:    "2026-03-06", wantCurrent: 5, wantBest: 5,
+		},
+		{
+			name: "yesterday missed", recurrence: "FREQ=DAILY", created: "2026-03-01",
+			checkIns: checkIns("2026-03-01", "2026-03-02", "2026-03-03", "2026-03-04"),
+			today:    "2026-03-06", wantCurrent: 0, wantBest: 4,
+		},
+		{
+			name: "daily target of two", recurrence: "FREQ=DAILY", target: 2, created: "2026-03-01",
+			checkIns: checkIns("2026-03-01", "2026-03-01", "2026-03-02", "2026-03-03", "2026-03-03", "2026-03-04", "2026-03-04"),
+			today:    "2026-03-04", wantCurrent: 2, wantBest: 2,
+		},
+		{
+			// 2 March 2026 is a Monday. The Tuesday check-in is not due and
+			// does not make up for the missed Wednesday.
+			name: "weekly on given days", recurrence: "FREQ=WEEKLY;BYDAY=MO,WE,FR", created: "2026-03-02",
+			checkIns: checkIns("2026-03-02", "2026-03-04", "2026-03-06", "2026-03-09", "2026-03-10", "2026-03-13"),
+			today:    "2026-03-13", wantCurrent: 1, wantBest: 4,
+		},
+		{
+			name: "three times a week", recurrence: "FREQ=WEEKLY", target: 3, created: "2026-03-02",
+			checkIns: checkIns(
+				"2026-03-02", "2026-03-03", "2026-03-05",
+				"2026-03-09", "2026-03-10",
+				"2026-03-16", "2026-03-18", "2026-03-22",
+				"2026-03-23", "2026-03-24", "2026-03-25",
+				"2026-03-30",
+			),
+			today: "2026-03-31", wantCurrent: 2, wantBest: 2,
+		},
+		{
+			name: "last day of the month", recurrence: "FREQ=MONTHLY;BYMONTHDAY=-1", created: "2026-01-01",
+			checkIns: checkIns("2026-01-31", "2026-02-28", "2026-04-30"),
+			today:    "2026-05-10", wantCurrent: 1, wantBest: 2,
+		},
+		{
+			name: "any day of the month", recurrence: "FREQ=MONTHLY", created: "2026-01-15",
+			checkIns: checkIns("2026-01-20", "2026-02-03", "2026-03-30"),
+			today:    "2026-04-05", wantCurrent: 3, wantBest: 3,
+		},
+		{
+			name: "paused days are skipped", recurrence: "FREQ=DAILY", created: "2026-03-01",
+			pauses:   []entities.HabitPause{{Start: "2026-03-03", End: &resumed}},
+			checkIns: checkIns("2026-03-01", "2026-03-02", "2026-03-06", "2026-03-07"),
+			today:    "2026-03-07", wantCurrent: 4, wantBest: 4,
+		},
+		{
+			name: "still paused", recurrence: "FREQ=DAILY", created: "2026-03-01",
+			pauses:   []entities.HabitPause{{Start: "2026-03-03"}},
+			checkIns: checkIns("2026-03-01", "2026-03-02"),
+			today:    "2026-03-06", wantCurrent: 2, wantBest: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, _ := time.Parse(habitDateLayout, tt.created)
+			habit := entities.HabitModel{
+				ID:          1,
+				Recurrence:  tt.recurrence,
+				TargetCount: tt.target,
+				Pauses:      tt.pauses,
+				CreatedAt:   created.Add(9 * time.Hour).Add(7 * time.Hour),
+			}
+			today, _ := time.Parse(habitDateLayout, tt.today)
+			streak := ComputeHabitStreak(habit, tt.checkIns, today, time.UTC)
+			if streak.Current != tt.wantCurrent || streak.Best != tt.wantBest {
+				t.Errorf("ComputeHabitStreak() = current %d, best %d, want current %d, best %d", streak.Current, streak.Best, tt.wantCurrent, tt.wantBest)
+			}
+		})
+	}
+}
+
+func TestComputeHabitStreakLateAtNightInUserTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 23:30 on 12 March in New York is already 13 March in UTC and Bangkok,
+	// so the streak only survives when today is taken in New York.
+	now := time.Date(2026, 3, 13, 3, 30, 0, 0, time.UTC)
+	habit := entities.HabitModel{ID: 1, Recurrence: "FREQ=DAILY", TargetCount: 1, CreatedAt: time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC).Add(7 * time.Hour)}
+	checkIns := []entities.HabitCheckInModel{{ID: 1, HabitID: 1, Date: "2026-03-10"}, {ID: 2, HabitID: 1, Date: "2026-03-11"}}
+
+	streak := ComputeHabitStreak(habit, checkIns, localDate(now, newYork), newYork)
+	if streak.Current != 2 {
+		t.Errorf("current streak before checking in at 23:30 = %d, want 2", streak.Current)
+	}
+	checkIns = append(checkIns, entities.HabitCheckInModel{ID: 3, HabitID: 1, Date: localDate(now, newYork).Format(habitDateLayout)})
+	streak = ComputeHabitStreak(habit, checkIns, localDate(now, newYork), newYork)
+	if streak.Current != 3 || streak.CompletedDate[2] != "2026-03-12" {
+		t.Errorf("after checking in at 23:30 = current %d, dates %v, want 3 ending 2026-03-12", streak.Current, streak.CompletedDate)
+	}
+}
@@ -1,30 +1,115 @@
 package services
 
 import(
+	"fmt"
 	"go-fiber-template/domain/repositories"
 	"go-fiber-template/domain/entities"
+	"go-fiber-template/src/recurrence"
+	"strings"
 	"time"
 	fiberlog "github.com/gofiber/fiber/v2/log"
 )
 
 type HabitsService struct {
-	HabitsRepo repositories.IHabitRepository
+	HabitsRepo   repositories.IHabitRepository
+	UserRepo     repositories.IUsersRepository
+	ReminderRepo repositories.IReminderRepository
 }
 
 type IHabitsService interface {
 	CreateHabit(id string, habits entities.HabitResponse) error
 	GetHabitsByUserID(userId string) (*[]entities.HabitModel, error)
+	// ListHabits filters by status and category; empty filters match all.
+	ListHabits(userID string, status string, category string) (*[]entities.HabitModel, error)
+	UpdateHabit(userID string, habitID int, data entities.HabitUpdate) (*entities.HabitModel, error)
+	PauseHabit(userID string, habitID int) (*entities.HabitModel, error)
+	ResumeHabit(userID string, habitID int) (*entities.HabitModel, error)
+	ArchiveHabit(userID string, habitID int) (*entities.HabitModel, error)
+	RestoreHabit(userID string, habitID int) (*entities.HabitModel, error)
+	DeleteHabit(userID string, habitID int) error
+	CheckIn(userID string, habitID int, body entities.HabitCheckInBody) (*entities.HabitModel, error)
+	UndoCheckIn(userID string, habitID int, date string) (*entities.HabitModel, error)
+	// GetHabitOccurrences lists the habit's due occurrences from from to to,
+	// both inclusive.
+	GetHabitOccurrences(userID string, habitID int, from time.Time, to time.Time) (*[]entities.HabitOccurrence, error)
+	GetHabitStats(userID string, habitID int, query HabitStatsQuery) (*entities.HabitStats, error)
+	// GetUserHabitStats covers every habit that is not archived.
+	GetUserHabitStats(userID string, query HabitStatsQuery) (*entities.UserHabitStats, error)
 }
 
-func NewHabitsService (HabitsRepo repositories.IHabitRepository) IHabitsService {
+func NewHabitsService (HabitsRepo repositories.IHabitRepository, userRepo repositories.IUsersRepository, reminderRepo repositories.IReminderRepository) IHabitsService {
 	return &HabitsService{
-		HabitsRepo: HabitsRepo,
+		HabitsRepo:   HabitsRepo,
+		UserRepo:     userRepo,
+		ReminderRepo: reminderRepo,
 	}
 }
 
 
+var habitFrequencies = map[string]bool{"daily": true, "weekly": true, "monthly": true}
+
+// Habit statuses. Habits stored before statuses existed have none and count
+// as active.
+const (
+	HabitStatusActive   = "active"
+	HabitStatusPaused   = "paused"
+	HabitStatusArchived = "archived"
+)
+
+var habitStatuses = map[string]bool{HabitStatusActive: true, HabitStatusPaused: true, HabitStatusArchived: true}
+
+func habitStatus(habit entities.HabitModel) string {
+	if habit.Status == "" {
+		return HabitStatusActive
+	}
+	return habit.Status
+}
+
+// ValidateHabit checks the fields a client or the assistant can set and
+// fills in the default target count. A recurrence rule takes precedence over
+// the frequency, which is then set to the rule's FREQ so older clients still
+// see one; a bare frequency becomes the equivalent rule.
+func ValidateHabit(habit *entities.HabitResponse) error {
+	if habit.Name == "" {
+		return invalidf("habit name cannot be empty")
+	}
+	if habit.Recurrence != "" {
+		rule, err := recurrence.Parse(habit.Recurrence)
+		if err != nil {
+			return invalidf("invalid recurrence: %s", err)
+		}
+		habit.Recurrence = rule.String()
+		habit.Frequency = strings.ToLower(string(rule.Freq))
+	} else {
+		rule, ok := recurrence.FromFrequency(habit.Frequency)
+		if !ok || !habitFrequencies[habit.Frequency] {
+			return invalidf("habit frequency must be daily, weekly or monthly")
+		}
+		habit.Recurrence = rule.String()
+	}
+	if habit.TargetCount < 0 {
+		return invalidf("habit target count cannot be negative")
+	}
+	if habit.TargetCount == 0 {
+		habit.TargetCount = 1
+	}
+	return nil
+}
+
 func (sv *HabitsService) CreateHabit(id string, habits entities.HabitResponse) error {
+	if id == "" {
+		return fmt.Errorf("userID cannot be empty")
+	}
+	if err := ValidateHabit(&habits); err != nil {
+		fiberlog.Errorf("HabitsService -> CreateHabits: %s \n", err)
+		return err
+	}
 	habits.UserID = id
+	// Streaks and completions are computed from check-ins, not taken from
+	// the client.
+	habits.CurrentStreak = 0
+	habits.CompletedDate = []string{}
+	habits.Status = HabitStatusActive
 	habits.CreatedAt = time.Now().Add(7 * time.Hour)
 	habits.UpdatedAt = time.Now().Add(7 * time.Hour)
 	err := sv.HabitsRepo.CreateHabit(&habits)
@@ -35,11 +120,438 @@ func (sv *HabitsService) CreateHabit(id string, habits entities.HabitResponse) e
 	return nil
 }
 
+// GetHabitsByUserID returns the habits with their current streak as of
+// today, since a stored streak goes stale when the user stops checking in.
 func (sv *HabitsService) GetHabitsByUserID(id string) (*[]entities.HabitModel, error) {
 	data, err := sv.HabitsRepo.GetHabitsByUserID(id)
 	if err != nil {
 		fiberlog.Errorf("HabitsService -> GetHabitsByUserID: %s \n", err)
 		return nil, err
 	}
+	checkIns, err := sv.HabitsRepo.GetCheckInsByUserID(id)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> GetHabitsByUserID: %s \n", err)
+		return data, nil
+	}
+	byHabit := map[int][]entities.HabitCheckInModel{}
+	for _, checkIn := range *checkIns {
+		byHabit[checkIn.HabitID] = append(byHabit[checkIn.HabitID], checkIn)
+	}
+	location := userLocation(sv.UserRepo, id)
+	today := localDate(time.Now(), location)
+	for i, habit := range *data {
+		streak := ComputeHabitStreak(habit, byHabit[habit.ID], today, location)
+		(*data)[i].CurrentStreak = streak.Current
+		(*data)[i].BestStreak = streak.Best
+		(*data)[i].CompletedDate = streak.CompletedDate
+	}
 	return data, nil
-}
\ No newline at end of file
+}
+
+func (sv *HabitsService) ListHabits(userID string, status string, category string) (*[]entities.HabitModel, error) {
+	if status != "" && !habitStatuses[status] {
+		return nil, invalidf("status must be active, paused or archived")
+	}
+	data, err := sv.GetHabitsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	habits := []entities.HabitModel{}
+	for _, habit := range *data {
+		if status != "" && habitStatus(habit) != status {
+			continue
+		}
+		if category != "" && !strings.EqualFold(habit.Category, category) {
+			continue
+		}
+		habits = append(habits, habit)
+	}
+	return &habits, nil
+}
+
+// UpdateHabit edits the habit's definition. Fields left empty keep their
+// value, and a new frequency without a recurrence replaces the old rule.
+// Streaks are recomputed because the rule and target change what a completed
+// occurrence is.
+func (sv *HabitsService) UpdateHabit(userID string, habitID int, data entities.HabitUpdate) (*entities.HabitModel, error) {
+	habit, err := sv.userHabit(userID, habitID)
+	if err != nil {
+		return nil, err
+	}
+	edited := entities.HabitResponse{
+		Name:        firstNonEmpty(data.Name, habit.Name),
+		Frequency:   firstNonEmpty(data.Frequency, habit.Frequency),
+		Recurrence:  data.Recurrence,
+		Description: firstNonEmpty(data.Description, habit.Description),
+		TargetCount: data.TargetCount,
+		Category:    firstNonEmpty(data.Category, habit.Category),
+	}
+	if data.Recurrence == "" && data.Frequency == "" {
+		edited.Recurrence = habit.Recurrence
+	}
+	if edited.TargetCount == 0 {
+		edited.TargetCount = habit.TargetCount
+	}
+	if err := ValidateHabit(&edited); err != nil {
+		return nil, err
+	}
+	update := entities.HabitUpdate{
+		Name:        edited.Name,
+		Frequency:   edited.Frequency,
+		Recurrence:  edited.Recurrence,
+		Description: edited.Description,
+		TargetCount: edited.TargetCount,
+		Category:    edited.Category,
+		UpdatedAt:   time.Now().Add(7 * time.Hour),
+	}
+	if err := sv.HabitsRepo.UpdateHabit(habitID, update); err != nil {
+		fiberlog.Errorf("HabitsService -> UpdateHabit: %s \n", err)
+		return nil, err
+	}
+	habit.Name = update.Name
+	habit.Frequency = update.Frequency
+	habit.Recurrence = update.Recurrence
+	habit.Description = update.Description
+	habit.TargetCount = update.TargetCount
+	habit.Category = update.Category
+	return sv.refreshProgress(habit, userLocation(sv.UserRepo, userID))
+}
+
+// PauseHabit stops the habit from today until it is resumed. Occurrences in
+// the pause do not break the streak.
+func (sv *HabitsService) PauseHabit(userID string, habitID int) (*entities.HabitModel, error) {
+	return sv.changeStatus(userID, habitID, HabitStatusPaused, HabitStatusActive)
+}
+
+func (sv *HabitsService) ResumeHabit(userID string, habitID int) (*entities.HabitModel, error) {
+	return sv.changeStatus(userID, habitID, HabitStatusActive, HabitStatusPaused)
+}
+
+// ArchiveHabit hides the habit from the active list. Archived time is not a
+// pause, so the streak breaks if the habit is restored later.
+func (sv *HabitsService) ArchiveHabit(userID string, habitID int) (*entities.HabitModel, error) {
+	return sv.changeStatus(userID, habitID, HabitStatusArchived, HabitStatusActive, HabitStatusPaused)
+}
+
+func (sv *HabitsService) RestoreHabit(userID string, habitID int) (*entities.HabitModel, error) {
+	return sv.changeStatus(userID, habitID, HabitStatusActive, HabitStatusArchived)
+}
+
+// DeleteHabit removes the habit together with its check-ins and the
+// reminders for it.
+func (sv *HabitsService) DeleteHabit(userID string, habitID int) error {
+	if _, err := sv.userHabit(userID, habitID); err != nil {
+		return err
+	}
+	if err := sv.ReminderRepo.DeleteRemindersByHabitID(habitID); err != nil {
+		fiberlog.Errorf("HabitsService -> DeleteHabit: %s \n", err)
+		return err
+	}
+	if err := sv.HabitsRepo.DeleteCheckInsByHabitID(habitID); err != nil {
+		fiberlog.Errorf("HabitsService -> DeleteHabit: %s \n", err)
+		return err
+	}
+	if err := sv.HabitsRepo.DeleteHabit(habitID); err != nil {
+		fiberlog.Errorf("HabitsService -> DeleteHabit: %s \n", err)
+		return err
+	}
+	return nil
+}
+
+// changeStatus moves the habit to status when it is currently in one of
+// from, opening a pause when pausing and closing the open one otherwise.
+func (sv *HabitsService) changeStatus(userID string, habitID int, status string, from ...string) (*entities.HabitModel, error) {
+	habit, err := sv.userHabit(userID, habitID)
+	if err != nil {
+		return nil, err
+	}
+	current := habitStatus(*habit)
+	allowed := false
+	for _, candidate := range from {
+		allowed = allowed || candidate == current
+	}
+	if !allowed {
+		return nil, invalidf("habit is %s", current)
+	}
+	location := userLocation(sv.UserRepo, userID)
+	today := localDate(time.Now(), location).Format(habitDateLayout)
+	pauses := append([]entities.HabitPause{}, habit.Pauses...)
+	for i := range pauses {
+		if pauses[i].End == nil {
+			end := today
+			pauses[i].End = &end
+		}
+	}
+	if status == HabitStatusPaused {
+		pauses = append(pauses, entities.HabitPause{Start: today})
+	}
+	update := entities.HabitStatusUpdate{
+		Status:    status,
+		Pauses:    pauses,
+		UpdatedAt: time.Now().Add(7 * time.Hour),
+	}
+	if err := sv.HabitsRepo.UpdateHabitStatus(habitID, update); err != nil {
+		fiberlog.Errorf("HabitsService -> changeStatus: %s \n", err)
+		return nil, err
+	}
+	habit.Status = update.Status
+	habit.Pauses = update.Pauses
+	return sv.refreshProgress(habit, location)
+}
+
+func (sv *HabitsService) refreshProgress(habit *entities.HabitModel, location *time.Location) (*entities.HabitModel, error) {
+	checkIns, err := sv.HabitsRepo.GetCheckInsByHabitID(habit.ID)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> refreshProgress: %s \n", err)
+		return nil, err
+	}
+	return sv.saveProgress(habit, *checkIns, localDate(time.Now(), location), location)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// CheckIn records one completion of the habit. Without a date it lands on
+// today in the body's timezone, or the user's. The date must fall in one of
+// the habit's occurrences, and an occurrence that already has TargetCount
+// check-ins does not take more.
+func (sv *HabitsService) CheckIn(userID string, habitID int, body entities.HabitCheckInBody) (*entities.HabitModel, error) {
+	habit, err := sv.userHabit(userID, habitID)
+	if err != nil {
+		return nil, err
+	}
+	if status := habitStatus(*habit); status != HabitStatusActive {
+		return nil, invalidf("habit is %s", status)
+	}
+	location := userLocation(sv.UserRepo, userID)
+	if body.Timezone != "" {
+		if location, err = LoadTimezone(body.Timezone); err != nil {
+			return nil, invalid(err)
+		}
+	}
+	today := localDate(time.Now(), location)
+	date := today
+	if body.Date != "" {
+		if date, err = time.Parse(habitDateLayout, body.Date); err != nil {
+			return nil, invalidf("date must be YYYY-MM-DD")
+		}
+	}
+	if date.After(today) {
+		return nil, invalidf("check-in date cannot be in the future")
+	}
+
+	checkIns, err := sv.HabitsRepo.GetCheckInsByHabitID(habitID)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> CheckIn: %s \n", err)
+		return nil, err
+	}
+	if date.Before(habitStartDate(*habit, *checkIns, today, location)) {
+		return nil, invalidf("check-in date is before the habit started")
+	}
+	occurrences := HabitOccurrences(*habit, *checkIns, date, date.AddDate(0, 0, 1), today, location)
+	if len(occurrences) == 0 {
+		return nil, invalidf("habit is not due on %s", date.Format(habitDateLayout))
+	}
+	if occurrences[0].Completed {
+		return nil, invalidf("habit is already completed for this %s", habitOccurrenceName(occurrences[0]))
+	}
+	checkIn, err := sv.HabitsRepo.InsertCheckIn(entities.HabitCheckInResponse{
+		HabitID:   habitID,
+		UserID:    userID,
+		Date:      date.Format(habitDateLayout),
+		CreatedAt: time.Now().Add(7 * time.Hour),
+	})
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> CheckIn: %s \n", err)
+		return nil, err
+	}
+	updated := append(*checkIns, *checkIn)
+	return sv.saveProgress(habit, updated, today, location)
+}
+
+// UndoCheckIn removes the most recent check-in of the habit on date.
+func (sv *HabitsService) UndoCheckIn(userID string, habitID int, date string) (*entities.HabitModel, error) {
+	habit, err := sv.userHabit(userID, habitID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse(habitDateLayout, date); err != nil {
+		return nil, invalidf("date must be YYYY-MM-DD")
+	}
+	checkIns, err := sv.HabitsRepo.GetCheckInsByHabitID(habitID)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> UndoCheckIn: %s \n", err)
+		return nil, err
+	}
+	remaining := *checkIns
+	removed := -1
+	for i := len(remaining) - 1; i >= 0; i-- {
+		if remaining[i].Date == date {
+			removed = i
+			break
+		}
+	}
+	if removed < 0 {
+		return nil, invalidf("no check-in on %s", date)
+	}
+	if err := sv.HabitsRepo.DeleteCheckIn(remaining[removed].ID); err != nil {
+		fiberlog.Errorf("HabitsService -> UndoCheckIn: %s \n", err)
+		return nil, err
+	}
+	remaining = append(remaining[:removed:removed], remaining[removed+1:]...)
+	location := userLocation(sv.UserRepo, userID)
+	today := localDate(time.Now(), location)
+	return sv.saveProgress(habit, remaining, today, location)
+}
+
+func (sv *HabitsService) userHabit(userID string, habitID int) (*entities.HabitModel, error) {
+	habit, err := sv.HabitsRepo.GetHabitByID(habitID)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> userHabit: %s \n", err)
+		return nil, err
+	}
+	if habit.UserID != userID {
+		return nil, fmt.Errorf("habit with ID %d not found", habitID)
+	}
+	return habit, nil
+}
+
+func (sv *HabitsService) saveProgress(habit *entities.HabitModel, checkIns []entities.HabitCheckInModel, today time.Time, location *time.Location) (*entities.HabitModel, error) {
+	streak := ComputeHabitStreak(*habit, checkIns, today, location)
+	update := entities.HabitProgressUpdate{
+		CurrentStreak: streak.Current,
+		BestStreak:    streak.Best,
+		CompletedDate: streak.CompletedDate,
+		UpdatedAt:     time.Now().Add(7 * time.Hour),
+	}
+	if err := sv.HabitsRepo.UpdateHabitProgress(habit.ID, update); err != nil {
+		fiberlog.Errorf("HabitsService -> saveProgress: %s \n", err)
+		return nil, err
+	}
+	habit.CurrentStreak = update.CurrentStreak
+	habit.BestStreak = update.BestStreak
+	habit.CompletedDate = update.CompletedDate
+	habit.UpdatedAt = update.UpdatedAt
+	return habit, nil
+}
+
+func habitOccurrenceName(occurrence entities.HabitOccurrence) string {
+	start, _ := time.Parse(habitDateLayout, occurrence.Start)
+	end, _ := time.Parse(habitDateLayout, occurrence.End)
+	switch days := int(end.Sub(start).Hours()/24) + 1; {
+	case days == 1:
+		return "day"
+	case days == 7:
+		return "week"
+	default:
+		return "month"
+	}
+}
+
+// maxOccurrenceRange bounds GetHabitOccurrences and the schedule's
+// occurrences so a daily rule cannot be expanded over decades.
+const maxOccurrenceRange = 366
+
+func (sv *HabitsService) GetHabitOccurrences(userID string, habitID int, from time.Time, to time.Time) (*[]entities.HabitOccurrence, error) {
+	if err := validateOccurrenceRange(from, to); err != nil {
+		return nil, err
+	}
+	habit, err := sv.userHabit(userID, habitID)
+	if err != nil {
+		return nil, err
+	}
+	checkIns, err := sv.HabitsRepo.GetCheckInsByHabitID(habitID)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> GetHabitOccurrences: %s \n", err)
+		return nil, err
+	}
+	location := userLocation(sv.UserRepo, userID)
+	today := localDate(time.Now(), location)
+	occurrences := HabitOccurrences(*habit, *checkIns, from, to.AddDate(0, 0, 1), today, location)
+	return &occurrences, nil
+}
+
+func (sv *HabitsService) GetHabitStats(userID string, habitID int, query HabitStatsQuery) (*entities.HabitStats, error) {
+	habit, err := sv.userHabit(userID, habitID)
+	if err != nil {
+		return nil, err
+	}
+	checkIns, err := sv.HabitsRepo.GetCheckInsByHabitID(habitID)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> GetHabitStats: %s \n", err)
+		return nil, err
+	}
+	location := userLocation(sv.UserRepo, userID)
+	today := localDate(time.Now(), location)
+	stats := ComputeHabitStats(*habit, *checkIns, today, location, query)
+	return &stats, nil
+}
+
+func (sv *HabitsService) GetUserHabitStats(userID string, query HabitStatsQuery) (*entities.UserHabitStats, error) {
+	habits, err := sv.HabitsRepo.GetHabitsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> GetUserHabitStats: %s \n", err)
+		return nil, err
+	}
+	checkIns, err := sv.HabitsRepo.GetCheckInsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("HabitsService -> GetUserHabitStats: %s \n", err)
+		return nil, err
+	}
+	byHabit := map[int][]entities.HabitCheckInModel{}
+	for _, checkIn := range *checkIns {
+		byHabit[checkIn.HabitID] = append(byHabit[checkIn.HabitID], checkIn)
+	}
+	location := userLocation(sv.UserRepo, userID)
+	today := localDate(time.Now(), location)
+	stats := []entities.HabitStats{}
+	for _, habit := range *habits {
+		if habitStatus(habit) != HabitStatusArchived {
+			stats = append(stats, ComputeHabitStats(habit, byHabit[habit.ID], today, location, query))
+		}
+	}
+	combined := CombineHabitStats(stats, today, query)
+	return &combined, nil
+}
+
+// ParseOccurrenceRange reads inclusive YYYY-MM-DD query dates. The range
+// defaults to the week starting today.
+func ParseOccurrenceRange(from string, to string) (time.Time, time.Time, error) {
+	now := time.Now().Add(7 * time.Hour)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if from != "" {
+		parsed, err := time.Parse(habitDateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date: %s", from)
+		}
+		start = parsed
+	}
+	end := start.AddDate(0, 0, 6)
+	if to != "" {
+		parsed, err := time.Parse(habitDateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date: %s", to)
+		}
+		end = parsed
+	}
+	if err := validateOccurrenceRange(start, end); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+func validateOccurrenceRange(from time.Time, to time.Time) error {
+	if to.Before(from) {
+		return invalidf("from must not be after to")
+	}
+	if to.Sub(from).Hours()/24 >= maxOccurrenceRange {
+		return invalidf("date range cannot be longer than %d days", maxOccurrenceRange)
+	}
+	return nil
+}
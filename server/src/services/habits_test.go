@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"testing"
+	"time"
+)
+
+// fakeHabitRepository serves one user's habits and check-ins from memory and
+// fails every write with err.
+type fakeHabitRepository struct {
+	repositories.IHabitRepository
+	habits   []entities.HabitModel
+	checkIns []entities.HabitCheckInModel
+	err      error
+}
+
+func (repo *fakeHabitRepository) GetHabitByID(id int) (*entities.HabitModel, error) {
+	for _, habit := range repo.habits {
+		if habit.ID == id {
+			return &habit, nil
+		}
+	}
+	return nil, fmt.Errorf("no rows in result set")
+}
+
+func (repo *fakeHabitRepository) GetCheckInsByHabitID(habitID int) (*[]entities.HabitCheckInModel, error) {
+	checkIns := []entities.HabitCheckInModel{}
+	for _, checkIn := range repo.checkIns {
+		if checkIn.HabitID == habitID {
+			checkIns = append(checkIns, checkIn)
+		}
+	}
+	return &checkIns, nil
+}
+
+func (repo *fakeHabitRepository) InsertCheckIn(data entities.HabitCheckInResponse) (*entities.HabitCheckInModel, error) {
+	return nil, repo.err
+}
+
+func TestCheckInSeparatesValidationErrors(t *testing.T) {
+	today := time.Now().UTC().Format(habitDateLayout)
+	created := time.Now().AddDate(0, 0, -10)
+	repo := &fakeHabitRepository{
+		habits: []entities.HabitModel{
+			{ID: 1, UserID: "user", Frequency: "daily", Recurrence: "FREQ=DAILY", TargetCount: 1, Status: HabitStatusActive, CreatedAt: created},
+			{ID: 2, UserID: "user", Frequency: "daily", Recurrence: "FREQ=DAILY", TargetCount: 1, Status: HabitStatusPaused, CreatedAt: created},
+		},
+		checkIns: []entities.HabitCheckInModel{{ID: 1, HabitID: 1, UserID: "user", Date: time.Now().UTC().AddDate(0, 0, -1).Format(habitDateLayout)}},
+		err:      fmt.Errorf("duplicate key value violates unique constraint"),
+	}
+	service := NewHabitsService(repo, &fakeUsersRepository{users: map[string]entities.UserProfileModel{"user": {Timezone: "UTC"}}}, nil)
+	tests := []struct {
+		name       string
+		habitID    int
+		body       entities.HabitCheckInBody
+		validation bool
+	}{
+		{name: "paused habit", habitID: 2, body: entities.HabitCheckInBody{Date: today}, validation: true},
+		{name: "bad date", habitID: 1, body: entities.HabitCheckInBody{Date: "yesterday"}, validation: true},
+		{name: "future date", habitID: 1, body: entities.HabitCheckInBody{Date: "2999-01-01"}, validation: true},
+		{name: "unknown timezone", habitID: 1, body: entities.HabitCheckInBody{Timezone: "Mars/Olympus"}, validation: true},
+		{name: "already completed", habitID: 1, body: entities.HabitCheckInBody{Date: repo.checkIns[0].Date}, validation: true},
+		{name: "database error", habitID: 1, body: entities.HabitCheckInBody{Date: today, Timezone: "UTC"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CheckIn("user", tt.habitID, tt.body)
+			if err == nil {
+				t.Fatal("CheckIn() error = nil")
+			}
+			if IsValidationError(err) != tt.validation {
+				t.Errorf("IsValidationError(%v) = %v, want %v", err, IsValidationError(err), tt.validation)
+			}
+		})
+	}
+}
+
+func TestValidateHabitReturnsValidationErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		habit entities.HabitResponse
+	}{
+		{name: "no name", habit: entities.HabitResponse{Frequency: "daily"}},
+		{name: "bad recurrence", habit: entities.HabitResponse{Name: "Run", Recurrence: "FREQ=HOURLY"}},
+		{name: "bad frequency", habit: entities.HabitResponse{Name: "Run", Frequency: "hourly"}},
+		{name: "negative target", habit: entities.HabitResponse{Name: "Run", Frequency: "daily", TargetCount: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateHabit(&tt.habit); !IsValidationError(err) {
+				t.Errorf("ValidateHabit() error = %v, want a validation error", err)
+			}
+		})
+	}
+}
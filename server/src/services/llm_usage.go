@@ -0,0 +1,221 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+const usageDateLayout = "2006-01-02"
+
+// ModelPrice is the list price in USD per one million tokens.
+type ModelPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+var defaultModelPrices = map[string]ModelPrice{
+	"gemini-2.0-flash":      {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.0-flash-lite": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+	"gemini-1.5-flash":      {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+	"gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+}
+
+type UsageService struct {
+	UsageRepo repositories.ILLMUsageRepository
+	Prices    map[string]ModelPrice
+}
+
+type IUsageService interface {
+	Record(userID string, feature string, usage entities.LLMUsage)
+	EstimateCost(usage entities.LLMUsage) float64
+	GetUserUsage(userID string, from time.Time, to time.Time) (*entities.LLMUserUsageSummary, error)
+	GetDailyUsage(from time.Time, to time.Time) (*[]entities.LLMDailyUsage, error)
+}
+
+// NewUsageService uses the built-in price list, overridden per model by the
+// LLM_PRICING environment variable, e.g.
+// {"gemini-2.0-flash":{"input_per_million":0.1,"output_per_million":0.4}}.
+func NewUsageService(usageRepo repositories.ILLMUsageRepository) IUsageService {
+	prices := map[string]ModelPrice{}
+	for model, price := range defaultModelPrices {
+		prices[model] = price
+	}
+	if raw := os.Getenv("LLM_PRICING"); raw != "" {
+		var overrides map[string]ModelPrice
+		if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+			fiberlog.Errorf("UsageService -> NewUsageService: invalid LLM_PRICING: %s \n", err)
+		} else {
+			for model, price := range overrides {
+				prices[model] = price
+			}
+		}
+	}
+	return &UsageService{
+		UsageRepo: usageRepo,
+		Prices:    prices,
+	}
+}
+
+// Record stores one call. Accounting must never fail the user's request, so
+// errors are only logged.
+func (sv *UsageService) Record(userID string, feature string, usage entities.LLMUsage) {
+	if usage.Model == "" {
+		return
+	}
+	data := entities.LLMUsageResponse{
+		UserID:           userID,
+		Feature:          feature,
+		Model:            usage.Model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		LatencyMs:        usage.LatencyMs,
+		EstimatedCost:    sv.EstimateCost(usage),
+		CreatedAt:        time.Now().Add(7 * time.Hour),
+	}
+	if err := sv.UsageRepo.InsertUsage(data); err != nil {
+		fiberlog.Errorf("UsageService -> Record: %s \n", err)
+		fmt.Println("Error recording llm usage:", err)
+	}
+}
+
+func (sv *UsageService) EstimateCost(usage entities.LLMUsage) float64 {
+	price, ok := sv.Prices[usage.Model]
+	if !ok {
+		// Versioned names such as gemini-2.0-flash-001 share the base price.
+		for model, p := range sv.Prices {
+			if strings.HasPrefix(usage.Model, model+"-") {
+				price, ok = p, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return 0
+	}
+	return (float64(usage.PromptTokens)*price.InputPerMillion + float64(usage.CompletionTokens)*price.OutputPerMillion) / 1_000_000
+}
+
+// GetUserUsage sums the user's calls in the half-open range [from, to) and
+// reports it with the inclusive last day.
+func (sv *UsageService) GetUserUsage(userID string, from time.Time, to time.Time) (*entities.LLMUserUsageSummary, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	rows, err := sv.UsageRepo.GetUsageByUserID(userID, from, to)
+	if err != nil {
+		fiberlog.Errorf("UsageService -> GetUserUsage: %s \n", err)
+		return nil, err
+	}
+	summary := entities.LLMUserUsageSummary{
+		UserID:    userID,
+		From:      from.Format(usageDateLayout),
+		To:        to.AddDate(0, 0, -1).Format(usageDateLayout),
+		ByFeature: map[string]entities.LLMUsageTotals{},
+		ByModel:   map[string]entities.LLMUsageTotals{},
+	}
+	var latency int64
+	featureLatency := map[string]int64{}
+	modelLatency := map[string]int64{}
+	for _, row := range *rows {
+		summary.Total = addUsage(summary.Total, row)
+		summary.ByFeature[row.Feature] = addUsage(summary.ByFeature[row.Feature], row)
+		summary.ByModel[row.Model] = addUsage(summary.ByModel[row.Model], row)
+		latency += row.LatencyMs
+		featureLatency[row.Feature] += row.LatencyMs
+		modelLatency[row.Model] += row.LatencyMs
+	}
+	summary.Total.AvgLatencyMs = averageLatency(latency, summary.Total.Calls)
+	for feature, totals := range summary.ByFeature {
+		totals.AvgLatencyMs = averageLatency(featureLatency[feature], totals.Calls)
+		summary.ByFeature[feature] = totals
+	}
+	for model, totals := range summary.ByModel {
+		totals.AvgLatencyMs = averageLatency(modelLatency[model], totals.Calls)
+		summary.ByModel[model] = totals
+	}
+	return &summary, nil
+}
+
+func (sv *UsageService) GetDailyUsage(from time.Time, to time.Time) (*[]entities.LLMDailyUsage, error) {
+	rows, err := sv.UsageRepo.GetUsageBetween(from, to)
+	if err != nil {
+		fiberlog.Errorf("UsageService -> GetDailyUsage: %s \n", err)
+		return nil, err
+	}
+	days := map[string]*entities.LLMDailyUsage{}
+	users := map[string]map[string]bool{}
+	latency := map[string]int64{}
+	for _, row := range *rows {
+		date := row.CreatedAt.Format(usageDateLayout)
+		day, ok := days[date]
+		if !ok {
+			day = &entities.LLMDailyUsage{Date: date, ByFeature: map[string]entities.LLMUsageTotals{}}
+			days[date] = day
+			users[date] = map[string]bool{}
+		}
+		day.Total = addUsage(day.Total, row)
+		day.ByFeature[row.Feature] = addUsage(day.ByFeature[row.Feature], row)
+		users[date][row.UserID] = true
+		latency[date] += row.LatencyMs
+	}
+	data := make([]entities.LLMDailyUsage, 0, len(days))
+	for date, day := range days {
+		day.Users = len(users[date])
+		day.Total.AvgLatencyMs = averageLatency(latency[date], day.Total.Calls)
+		data = append(data, *day)
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i].Date < data[j].Date })
+	return &data, nil
+}
+
+func addUsage(totals entities.LLMUsageTotals, row entities.LLMUsageModel) entities.LLMUsageTotals {
+	totals.Calls++
+	totals.PromptTokens += row.PromptTokens
+	totals.CompletionTokens += row.CompletionTokens
+	totals.TotalTokens += row.TotalTokens
+	totals.EstimatedCost += row.EstimatedCost
+	return totals
+}
+
+func averageLatency(total int64, calls int) int64 {
+	if calls == 0 {
+		return 0
+	}
+	return total / int64(calls)
+}
+
+// ParseUsageRange reads an inclusive from/to date pair (YYYY-MM-DD) and
+// returns a half-open range. Missing values default to the last 30 days.
+func ParseUsageRange(from string, to string) (time.Time, time.Time, error) {
+	now := time.Now().Add(7 * time.Hour)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if to != "" {
+		parsed, err := time.Parse(usageDateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date: %s", to)
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+	start := end.AddDate(0, 0, -30)
+	if from != "" {
+		parsed, err := time.Parse(usageDateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date: %s", from)
+		}
+		start = parsed
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+	return start, end, nil
+}
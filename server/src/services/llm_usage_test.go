@@ -0,0 +1,42 @@
+package services
+
+import (
+	"go-fiber-template/domain/aimodel"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"testing"
+	"time"
+)
+
+type fakeUsageRepository struct {
+	repositories.ILLMUsageRepository
+	rows []entities.LLMUsageModel
+}
+
+func (repo *fakeUsageRepository) GetUsageByUserID(userID string, from time.Time, to time.Time) (*[]entities.LLMUsageModel, error) {
+	return &repo.rows, nil
+}
+
+func TestGetUserUsageReportsInclusiveRange(t *testing.T) {
+	repo := &fakeUsageRepository{rows: []entities.LLMUsageModel{
+		{UserID: "user", Feature: aimodel.FeaturePlan, Model: "gemini-2.0-flash", TotalTokens: 30, LatencyMs: 100},
+		{UserID: "user", Feature: aimodel.FeatureChat, Model: "gemini-2.0-flash", TotalTokens: 10, LatencyMs: 300},
+	}}
+	from, to, err := ParseUsageRange("2026-04-01", "2026-04-30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := NewUsageService(repo).GetUserUsage("user", from, to)
+	if err != nil {
+		t.Fatalf("GetUserUsage() error = %v", err)
+	}
+	if summary.From != "2026-04-01" || summary.To != "2026-04-30" {
+		t.Errorf("range = %s..%s, want 2026-04-01..2026-04-30", summary.From, summary.To)
+	}
+	if summary.Total.Calls != 2 || summary.Total.TotalTokens != 40 || summary.Total.AvgLatencyMs != 200 {
+		t.Errorf("total = %+v, want 2 calls, 40 tokens, 200ms", summary.Total)
+	}
+	if summary.ByFeature[aimodel.FeaturePlan].Calls != 1 || summary.ByFeature[aimodel.FeatureChat].Calls != 1 {
+		t.Errorf("by feature = %+v, want one plan and one chat call", summary.ByFeature)
+	}
+}
@@ -4,34 +4,168 @@ package services
 import (
 	"go-fiber-template/domain/repositories"
 	"go-fiber-template/domain/entities"
+	"strings"
 	"time"
 	fiberlog "github.com/gofiber/fiber/v2/log"
 )
 
 type MoodService struct {
 	MoodRepository repositories.IMoodRepository
+	UserRepo       repositories.IUsersRepository
+	Notes          IMoodNoteService
 }
 
 type IMoodService interface {
-	NewMood(mood entities.MoodResponse) (entities.MoodResponse, error)
+	NewMood(userID string, body entities.MoodBody) (entities.MoodEntry, error)
 	GetMoodByUserId(userId string) (*[]entities.MoodModel, error)
 	GetAllMood() (*[]entities.MoodModel, error)
+	GetMoodAnalytics(userID string, query MoodAnalyticsQuery) (*entities.MoodAnalytics, error)
 }
 
-func NewMoodService(moodRepository repositories.IMoodRepository) *MoodService {
+func NewMoodService(moodRepository repositories.IMoodRepository, userRepo repositories.IUsersRepository, notes IMoodNoteService) *MoodService {
 	return &MoodService{
 		MoodRepository: moodRepository,
+		UserRepo:       userRepo,
+		Notes:          notes,
 	}
 }
 
-func (service *MoodService) NewMood(mood entities.MoodResponse) (entities.MoodResponse, error) {
+const (
+	maxMoodTags      = 10
+	maxMoodTagLength = 32
+)
+
+// moodLabels are the labels the client shows for scores 1 to 5.
+var moodLabels = []string{"Very Bad", "Bad", "Okay", "Good", "Excellent"}
+
+var moodLabelScores = map[string]int{
+	"very bad": 1, "terrible": 1, "awful": 1, "แย่มาก": 1,
+	"bad": 2, "sad": 2, "แย่": 2,
+	"okay": 3, "ok": 3, "neutral": 3, "เฉยๆ": 3, "เฉย ๆ": 3,
+	"good": 4, "happy": 4, "ดี": 4,
+	"excellent": 5, "great": 5, "ดีมาก": 5,
+}
+
+// MoodScore is the entry's score, or the score its label stands for. It is
+// 0 for old entries with an unknown label.
+func MoodScore(mood entities.MoodModel) int {
+	if mood.Score != nil {
+		return *mood.Score
+	}
+	return moodLabelScores[strings.ToLower(strings.TrimSpace(mood.Mood))]
+}
+
+// MoodDate is the calendar day of the entry. Entries logged before dates
+// were stored fall back to their creation time in location.
+func MoodDate(mood entities.MoodModel, location *time.Location) time.Time {
+	if date, err := time.Parse(habitDateLayout, mood.Date); err == nil {
+		return date
+	}
+	return localDate(mood.CreatedAt.Add(-7*time.Hour), location)
+}
+
+func validMoodLevel(name string, value *int) error {
+	if value != nil && (*value < 1 || *value > 5) {
+		return invalidf("%s must be between 1 and 5", name)
+	}
+	return nil
+}
+
+// normalizeMoodTags lowercases and trims tags and drops empty and repeated
+// ones.
+func normalizeMoodTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+		tag = strings.TrimPrefix(tag, "#")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len([]rune(tag)) > maxMoodTagLength {
+			return nil, invalidf("tags cannot be longer than %d characters", maxMoodTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxMoodTags {
+		return nil, invalidf("at most %d tags are allowed", maxMoodTags)
+	}
+	return normalized, nil
+}
+
+func (service *MoodService) NewMood(userID string, body entities.MoodBody) (entities.MoodEntry, error) {
+	// The note is read before anything is validated, so a note with crisis
+	// language gets its support resources even when the entry is rejected.
+	analysis := service.Notes.Analyze(userID, body.Note)
+	rejected := entities.MoodEntry{Support: analysis.Support}
+	mood := entities.MoodResponse{
+		UserID:     userID,
+		Mood:       strings.TrimSpace(body.Mood),
+		Note:       body.Note,
+		Score:      body.Score,
+		Energy:     body.Energy,
+		Stress:     body.Stress,
+		SleepHours: body.SleepHours,
+	}
+	for _, level := range []struct {
+		Name  string
+		Value *int
+	}{{"score", body.Score}, {"energy", body.Energy}, {"stress", body.Stress}} {
+		if err := validMoodLevel(level.Name, level.Value); err != nil {
+			return rejected, err
+		}
+	}
+	if body.SleepHours != nil && (*body.SleepHours < 0 || *body.SleepHours > 24) {
+		return rejected, invalidf("sleep_hours must be between 0 and 24")
+	}
+	if mood.Score == nil {
+		score, ok := moodLabelScores[strings.ToLower(mood.Mood)]
+		if !ok {
+			return rejected, invalidf("score between 1 and 5 is required")
+		}
+		mood.Score = &score
+	}
+	if mood.Mood == "" {
+		mood.Mood = moodLabels[*mood.Score-1]
+	}
+	tags, err := normalizeMoodTags(body.Tags)
+	if err != nil {
+		return rejected, err
+	}
+	mood.Tags = tags
+
+	location := userLocation(service.UserRepo, userID)
+	if body.Timezone != "" {
+		if location, err = LoadTimezone(body.Timezone); err != nil {
+			return rejected, invalid(err)
+		}
+	}
+	today := localDate(time.Now(), location)
+	date := today
+	if body.Date != "" {
+		if date, err = time.Parse(habitDateLayout, body.Date); err != nil {
+			return rejected, invalidf("date must be YYYY-MM-DD")
+		}
+	}
+	if date.After(today) {
+		return rejected, invalidf("mood date cannot be in the future")
+	}
+	mood.Date = date.Format(habitDateLayout)
+
+	mood.Sentiment = analysis.Sentiment
+	mood.SentimentLabel = analysis.Label
+	mood.Themes = analysis.Themes
+	mood.AnalysisSource = analysis.Source
+	mood.Flagged = analysis.Flagged
+
 	mood.CreatedAt = time.Now().Add(7 * time.Hour)
-	err := service.MoodRepository.NewMood(mood)
+	err = service.MoodRepository.NewMood(mood)
 	if err != nil {
 		fiberlog.Error("Cannot insert mood",err)
-		return entities.MoodResponse{}, err
+		return rejected, err
 	}
-	return mood, nil
+	return entities.MoodEntry{MoodResponse: mood, Support: analysis.Support}, nil
 }
 
 func (service *MoodService) GetMoodByUserId(userId string) (*[]entities.MoodModel, error) {
@@ -50,4 +184,26 @@ func (service *MoodService) GetAllMood() (*[]entities.MoodModel, error) {
 		return nil, err
 	}
 	return data,nil
-}
\ No newline at end of file
+}
+
+func (service *MoodService) GetMoodAnalytics(userID string, query MoodAnalyticsQuery) (*entities.MoodAnalytics, error) {
+	data, err := service.MoodRepository.GetMoodById(userID)
+	if err != nil {
+		fiberlog.Errorf("MoodService -> GetMoodAnalytics: %s \n", err)
+		return nil, err
+	}
+	location := userLocation(service.UserRepo, userID)
+	to := localDate(time.Now(), location)
+	if query.To != nil {
+		to = *query.To
+	}
+	from := to.AddDate(0, 0, 1-defaultMoodAnalyticsDays)
+	if query.From != nil {
+		from = *query.From
+	}
+	if err := validateOccurrenceRange(from, to); err != nil {
+		return nil, err
+	}
+	analytics := ComputeMoodAnalytics(*data, location, from, to, query.Low)
+	return &analytics, nil
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MoodAnalyticsQuery selects the days covered, both inclusive, and the
+// highest daily average score that counts as low mood. Nil dates fall back
+// to the 30 days up to today.
+type MoodAnalyticsQuery struct {
+	From *time.Time
+	To   *time.Time
+	Low  int
+}
+
+const (
+	defaultMoodAnalyticsDays = 30
+	defaultLowMoodThreshold  = 2
+)
+
+// ParseMoodAnalyticsQuery reads the from, to (YYYY-MM-DD) and low query
+// parameters.
+func ParseMoodAnalyticsQuery(from string, to string, low string) (MoodAnalyticsQuery, error) {
+	query := MoodAnalyticsQuery{Low: defaultLowMoodThreshold}
+	for _, param := range []struct {
+		Name  string
+		Value string
+		Date  **time.Time
+	}{{"from", from, &query.From}, {"to", to, &query.To}} {
+		if param.Value == "" {
+			continue
+		}
+		date, err := time.Parse(habitDateLayout, param.Value)
+		if err != nil {
+			return MoodAnalyticsQuery{}, fmt.Errorf("invalid %s date: %s", param.Name, param.Value)
+		}
+		*param.Date = &date
+	}
+	if query.From != nil && query.To == nil {
+		to := query.From.AddDate(0, 0, defaultMoodAnalyticsDays-1)
+		query.To = &to
+	}
+	if low != "" {
+		parsed, err := strconv.Atoi(low)
+		if err != nil || parsed < 1 || parsed > 4 {
+			return MoodAnalyticsQuery{}, fmt.Errorf("low must be between 1 and 4")
+		}
+		query.Low = parsed
+	}
+	return query, nil
+}
+
+// moodSums accumulates the three dimensions of a set of entries.
+type moodSums struct {
+	entries int
+	values  [3][]int
+}
+
+func (s *moodSums) add(mood entities.MoodModel) {
+	s.entries++
+	for i, value := range moodLevels(mood) {
+		if value > 0 {
+			s.values[i] = append(s.values[i], value)
+		}
+	}
+}
+
+func (s *moodSums) average(dimension int) *float64 {
+	values := s.values[dimension]
+	if len(values) == 0 {
+		return nil
+	}
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	average := float64(total) / float64(len(values))
+	return &average
+}
+
+func (s *moodSums) stat(start string) entities.MoodPeriodStat {
+	return entities.MoodPeriodStat{
+		Start:   start,
+		Entries: s.entries,
+		Score:   s.average(0),
+		Energy:  s.average(1),
+		Stress:  s.average(2),
+	}
+}
+
+// moodLevels is the entry's score, energy and stress, 0 where missing.
+func moodLevels(mood entities.MoodModel) [3]int {
+	levels := [3]int{MoodScore(mood)}
+	if mood.Energy != nil {
+		levels[1] = *mood.Energy
+	}
+	if mood.Stress != nil {
+		levels[2] = *mood.Stress
+	}
+	return levels
+}
+
+// ComputeMoodAnalytics summarises the entries dated from from to to, both
+// inclusive, in location. Days and weeks without entries are left out of the
+// daily and weekly lists.
+func ComputeMoodAnalytics(moods []entities.MoodModel, location *time.Location, from time.Time, to time.Time, low int) entities.MoodAnalytics {
+	if low == 0 {
+		low = defaultLowMoodThreshold
+	}
+	analytics := entities.MoodAnalytics{
+		From:   from.Format(habitDateLayout),
+		To:     to.Format(habitDateLayout),
+		Daily:  []entities.MoodPeriodStat{},
+		Weekly: []entities.MoodPeriodStat{},
+		Tags:   []entities.MoodTagStat{},
+		Low:    entities.MoodLowStreaks{Threshold: low, Streaks: []entities.MoodStreak{}},
+	}
+
+	total := moodSums{}
+	days := map[string]*moodSums{}
+	weeks := map[string]*moodSums{}
+	tags := map[string]*moodSums{}
+	for _, mood := range moods {
+		date := MoodDate(mood, location)
+		if date.Before(from) || date.After(to) {
+			continue
+		}
+		total.add(mood)
+		addMood(days, date.Format(habitDateLayout), mood)
+		addMood(weeks, WeekStart(date).Format(habitDateLayout), mood)
+		for _, tag := range mood.Tags {
+			addMood(tags, tag, mood)
+		}
+	}
+	analytics.Entries = total.entries
+
+	for _, key := range sortedKeys(days) {
+		analytics.Daily = append(analytics.Daily, days[key].stat(key))
+	}
+	for _, key := range sortedKeys(weeks) {
+		analytics.Weekly = append(analytics.Weekly, weeks[key].stat(key))
+	}
+	for i, dimension := range []*entities.MoodDimension{&analytics.Score, &analytics.Energy, &analytics.Stress} {
+		*dimension = moodDimension(total.values[i], analytics.Daily, i)
+	}
+
+	for tag, sums := range tags {
+		analytics.Tags = append(analytics.Tags, entities.MoodTagStat{
+			Tag:          tag,
+			Count:        sums.entries,
+			Share:        float64(sums.entries) / float64(total.entries),
+			AverageScore: sums.average(0),
+		})
+	}
+	sort.Slice(analytics.Tags, func(i, j int) bool {
+		if analytics.Tags[i].Count != analytics.Tags[j].Count {
+			return analytics.Tags[i].Count > analytics.Tags[j].Count
+		}
+		return analytics.Tags[i].Tag < analytics.Tags[j].Tag
+	})
+
+	analytics.Low = lowMoodStreaks(days, from, to, low)
+	return analytics
+}
+
+func moodDimension(values []int, daily []entities.MoodPeriodStat, dimension int) entities.MoodDimension {
+	stat := entities.MoodDimension{Entries: len(values)}
+	if len(values) == 0 {
+		return stat
+	}
+	stat.Min, stat.Max = values[0], values[0]
+	total := 0
+	for _, value := range values {
+		total += value
+		stat.Min = min(stat.Min, value)
+		stat.Max = max(stat.Max, value)
+	}
+	stat.Average = float64(total) / float64(len(values))
+	variance := 0.0
+	for _, value := range values {
+		variance += math.Pow(float64(value)-stat.Average, 2)
+	}
+	stat.StdDev = math.Sqrt(variance / float64(len(values)))
+
+	averages := []float64{}
+	for _, day := range daily {
+		if average := [3]*float64{day.Score, day.Energy, day.Stress}[dimension]; average != nil {
+			averages = append(averages, *average)
+		}
+	}
+	if len(averages) > 1 {
+		change := 0.0
+		for i := 1; i < len(averages); i++ {
+			change += math.Abs(averages[i] - averages[i-1])
+		}
+		stat.MeanChange = change / float64(len(averages)-1)
+	}
+	return stat
+}
+
+func lowMoodStreaks(days map[string]*moodSums, from time.Time, to time.Time, low int) entities.MoodLowStreaks {
+	streaks := entities.MoodLowStreaks{Threshold: low, Streaks: []entities.MoodStreak{}}
+	var current *entities.MoodStreak
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(habitDateLayout)
+		var score *float64
+		if sums := days[key]; sums != nil {
+			score = sums.average(0)
+		}
+		if score == nil || *score > float64(low) {
+			current = nil
+			continue
+		}
+		streaks.LowDays++
+		if current == nil {
+			streaks.Streaks = append(streaks.Streaks, entities.MoodStreak{Start: key})
+			current = &streaks.Streaks[len(streaks.Streaks)-1]
+		}
+		current.End = key
+		current.Days++
+		streaks.Longest = max(streaks.Longest, current.Days)
+	}
+	if len(streaks.Streaks) > 0 {
+		last := streaks.Streaks[len(streaks.Streaks)-1]
+		lastDay := to.Format(habitDateLayout)
+		if last.End == lastDay || (last.End == to.AddDate(0, 0, -1).Format(habitDateLayout) && days[lastDay] == nil) {
+			streaks.Current = last.Days
+		}
+	}
+	return streaks
+}
+
+func addMood(groups map[string]*moodSums, key string, mood entities.MoodModel) {
+	if groups[key] == nil {
+		groups[key] = &moodSums{}
+	}
+	groups[key].add(mood)
+}
+
+func sortedKeys(periods map[string]*moodSums) []string {
+	keys := make([]string, 0, len(periods))
+	for key := range periods {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
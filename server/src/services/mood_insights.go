@@ -0,0 +1,499 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/locale"
+	"go-fiber-template/src/recurrence"
+	"go-fiber-template/src/stats"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+type MoodInsightsService struct {
+	MoodRepo     repositories.IMoodRepository
+	HabitsRepo   repositories.IHabitRepository
+	ScheduleRepo repositories.IScheduleRepository
+	UserRepo     repositories.IUsersRepository
+}
+
+type IMoodInsightsService interface {
+	GetMoodInsights(userID string, days int) (*entities.MoodInsights, error)
+}
+
+func NewMoodInsightsService(moodRepo repositories.IMoodRepository, habitsRepo repositories.IHabitRepository, scheduleRepo repositories.IScheduleRepository, userRepo repositories.IUsersRepository) IMoodInsightsService {
+	return &MoodInsightsService{
+		MoodRepo:     moodRepo,
+		HabitsRepo:   habitsRepo,
+		ScheduleRepo: scheduleRepo,
+		UserRepo:     userRepo,
+	}
+}
+
+const (
+	defaultMoodInsightDays = 180
+	minMoodInsightDays     = 14
+	// minInsightSample is the fewest days each side of a comparison needs
+	// before it is reported at all.
+	minInsightSample = 3
+	// restfulSleepHours splits nights into short and full ones.
+	restfulSleepHours = 7.0
+)
+
+const (
+	InsightHabit    = "habit"
+	InsightCategory = "category"
+	InsightSleep    = "sleep"
+	InsightBusyness = "busyness"
+	InsightBusyDays = "busy_days"
+)
+
+const (
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+)
+
+// ParseMoodInsightsDays reads the days query parameter, how far back the
+// insights look.
+func ParseMoodInsightsDays(days string) (int, error) {
+	if days == "" {
+		return defaultMoodInsightDays, nil
+	}
+	parsed, err := strconv.Atoi(days)
+	if err != nil || parsed < minMoodInsightDays || parsed > maxOccurrenceRange {
+		return 0, fmt.Errorf("days must be between %d and %d", minMoodInsightDays, maxOccurrenceRange)
+	}
+	return parsed, nil
+}
+
+// MoodInsightData is everything the insights are computed from. From and To
+// are both inclusive; Today is the user's current date, used for habits
+// still in progress.
+type MoodInsightData struct {
+	Moods    []entities.MoodModel
+	Habits   []entities.HabitModel
+	CheckIns []entities.HabitCheckInModel
+	Blocks   []entities.ScheduleBlockModel
+	BusyDays []string
+	Location *time.Location
+	From     time.Time
+	To       time.Time
+	Today    time.Time
+	Language string
+}
+
+func (sv *MoodInsightsService) GetMoodInsights(userID string, days int) (*entities.MoodInsights, error) {
+	if days == 0 {
+		days = defaultMoodInsightDays
+	}
+	moods, err := sv.MoodRepo.GetMoodById(userID)
+	if err != nil {
+		fiberlog.Errorf("MoodInsightsService -> GetMoodById: %s \n", err)
+		return nil, err
+	}
+	habits, err := sv.HabitsRepo.GetHabitsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("MoodInsightsService -> GetHabitsByUserID: %s \n", err)
+		return nil, err
+	}
+	checkIns, err := sv.HabitsRepo.GetCheckInsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("MoodInsightsService -> GetCheckInsByUserID: %s \n", err)
+		return nil, err
+	}
+	blocks, err := sv.ScheduleRepo.GetScheduleBlocksByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("MoodInsightsService -> GetScheduleBlocksByUserID: %s \n", err)
+		return nil, err
+	}
+	data := MoodInsightData{
+		Moods:    *moods,
+		Habits:   *habits,
+		CheckIns: *checkIns,
+		Blocks:   *blocks,
+		Location: userLocation(sv.UserRepo, userID),
+		Language: locale.Default(),
+	}
+	// Not every user has filled in a schedule, so a missing one only leaves
+	// out the busy days comparison.
+	if schedule, err := sv.ScheduleRepo.GetScheduleByUserID(userID); err == nil && schedule != nil {
+		data.BusyDays = schedule.BusyDays
+	}
+	if user, err := sv.UserRepo.FindByID(userID); err == nil && user != nil {
+		data.Language = locale.Resolve(user.Language)
+	}
+	data.Today = localDate(time.Now(), data.Location)
+	data.To = data.Today
+	data.From = data.To.AddDate(0, 0, 1-days)
+	insights := ComputeMoodInsights(data)
+	return &insights, nil
+}
+
+// moodFactor collects the mood scores of the days with and without a factor.
+type moodFactor struct {
+	with    []float64
+	without []float64
+}
+
+func (f *moodFactor) add(score float64, present bool) {
+	if present {
+		f.with = append(f.with, score)
+	} else {
+		f.without = append(f.without, score)
+	}
+}
+
+// ComputeMoodInsights compares each day's average mood score with what
+// happened that day: the habits and habit categories done, the hours slept
+// the night before, the minutes of schedule blocks and whether it is one of
+// the busy days in the user's schedule. A habit only counts on days it was
+// due and not paused, so a Monday habit compares Mondays done with Mondays
+// missed. A category is left out when it has a single habit, which would
+// repeat that habit's finding.
+func ComputeMoodInsights(data MoodInsightData) entities.MoodInsights {
+	texts := insightTexts[data.Language]
+	if texts == nil {
+		texts = insightTexts[locale.English]
+	}
+	scores := dailyMoodScores(data.Moods, data.Location, data.From, data.To)
+	result := entities.MoodInsights{
+		From:     data.From.Format(habitDateLayout),
+		To:       data.To.Format(habitDateLayout),
+		MoodDays: len(scores),
+		Insights: []entities.MoodInsight{},
+	}
+	if len(scores) == 0 {
+		return result
+	}
+	days := make([]string, 0, len(scores))
+	for day := range scores {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	checkIns := map[int][]entities.HabitCheckInModel{}
+	for _, checkIn := range data.CheckIns {
+		checkIns[checkIn.HabitID] = append(checkIns[checkIn.HabitID], checkIn)
+	}
+	type categoryDays struct {
+		habits int
+		due    map[string]bool
+		done   map[string]bool
+	}
+	categories := map[string]*categoryDays{}
+	for _, habit := range data.Habits {
+		due, done := habitMoodDays(habit, checkIns[habit.ID], data.From, data.To, data.Today, data.Location)
+		factor := moodFactor{}
+		for _, day := range days {
+			if due[day] {
+				factor.add(scores[day], done[day])
+			}
+		}
+		habitID := habit.ID
+		if insight, ok := moodInsight(factor, [2][]float64{}); ok {
+			insight.Factor = InsightHabit + ":" + strconv.Itoa(habit.ID)
+			insight.Kind = InsightHabit
+			insight.HabitID = &habitID
+			insight.Finding = insightFinding(texts, insight.Difference, fmt.Sprintf(texts["habit"], habit.Name))
+			result.Insights = append(result.Insights, insight)
+		}
+
+		category := strings.ToLower(strings.TrimSpace(habit.Category))
+		if category == "" {
+			continue
+		}
+		if categories[category] == nil {
+			categories[category] = &categoryDays{due: map[string]bool{}, done: map[string]bool{}}
+		}
+		categories[category].habits++
+		for day := range due {
+			categories[category].due[day] = true
+		}
+		for day := range done {
+			categories[category].done[day] = true
+		}
+	}
+	for category, group := range categories {
+		if group.habits < 2 {
+			continue
+		}
+		factor := moodFactor{}
+		for _, day := range days {
+			if group.due[day] || group.done[day] {
+				factor.add(scores[day], group.done[day])
+			}
+		}
+		if insight, ok := moodInsight(factor, [2][]float64{}); ok {
+			insight.Factor = InsightCategory + ":" + category
+			insight.Kind = InsightCategory
+			condition := texts["category:"+category]
+			if condition == "" {
+				condition = fmt.Sprintf(texts["category"], category)
+			}
+			insight.Finding = insightFinding(texts, insight.Difference, condition)
+			result.Insights = append(result.Insights, insight)
+		}
+	}
+
+	sleep := dailySleepHours(data.Moods, data.Location, data.From, data.To)
+	factor := moodFactor{}
+	hours, sleepScores := []float64{}, []float64{}
+	for _, day := range days {
+		if slept, ok := sleep[day]; ok {
+			factor.add(scores[day], slept >= restfulSleepHours)
+			hours = append(hours, slept)
+			sleepScores = append(sleepScores, scores[day])
+		}
+	}
+	if insight, ok := moodInsight(factor, [2][]float64{hours, sleepScores}); ok {
+		insight.Factor = InsightSleep
+		insight.Kind = InsightSleep
+		insight.Finding = insightFinding(texts, insight.Difference, fmt.Sprintf(texts["sleep"], formatHours(restfulSleepHours)))
+		result.Insights = append(result.Insights, insight)
+	}
+
+	if len(data.Blocks) > 0 {
+		minutes := scheduledMinutes(data.Blocks, data.From, data.To)
+		busy, dayScores := make([]float64, len(days)), make([]float64, len(days))
+		for i, day := range days {
+			busy[i] = float64(minutes[day])
+			dayScores[i] = scores[day]
+		}
+		threshold := stats.Median(busy)
+		factor := moodFactor{}
+		for i := range days {
+			factor.add(dayScores[i], busy[i] > threshold)
+		}
+		if insight, ok := moodInsight(factor, [2][]float64{busy, dayScores}); ok {
+			insight.Factor = InsightBusyness
+			insight.Kind = InsightBusyness
+			condition := texts["busyness_any"]
+			if threshold > 0 {
+				condition = fmt.Sprintf(texts["busyness"], formatHours(threshold/60))
+			}
+			insight.Finding = insightFinding(texts, insight.Difference, condition)
+			result.Insights = append(result.Insights, insight)
+		}
+	}
+
+	busyDays := map[time.Weekday]bool{}
+	for _, day := range data.BusyDays {
+		if name, ok := scheduleDays[strings.ToLower(strings.TrimSpace(day))]; ok {
+			busyDays[weekdaysByName[name]] = true
+		}
+	}
+	if len(busyDays) > 0 {
+		factor := moodFactor{}
+		for _, day := range days {
+			date, _ := time.Parse(habitDateLayout, day)
+			factor.add(scores[day], busyDays[date.Weekday()])
+		}
+		if insight, ok := moodInsight(factor, [2][]float64{}); ok {
+			insight.Factor = InsightBusyDays
+			insight.Kind = InsightBusyDays
+			insight.Finding = insightFinding(texts, insight.Difference, texts["busy_days"])
+			result.Insights = append(result.Insights, insight)
+		}
+	}
+
+	rank := map[string]int{ConfidenceHigh: 0, ConfidenceMedium: 1, ConfidenceLow: 2}
+	sort.SliceStable(result.Insights, func(i, j int) bool {
+		a, b := result.Insights[i], result.Insights[j]
+		if rank[a.Confidence] != rank[b.Confidence] {
+			return rank[a.Confidence] < rank[b.Confidence]
+		}
+		if math.Abs(a.Difference) != math.Abs(b.Difference) {
+			return math.Abs(a.Difference) > math.Abs(b.Difference)
+		}
+		return a.Factor < b.Factor
+	})
+	return result
+}
+
+// moodInsight compares the two sides of factor, and adds the correlation of
+// amounts[0] with amounts[1] when they are given. It reports false when
+// either side has too few days.
+func moodInsight(factor moodFactor, amounts [2][]float64) (entities.MoodInsight, bool) {
+	if len(factor.with) < minInsightSample || len(factor.without) < minInsightSample {
+		return entities.MoodInsight{}, false
+	}
+	comparison := stats.Welch(factor.with, factor.without)
+	insight := entities.MoodInsight{
+		Difference:  roundTo(comparison.Difference, 2),
+		MeanWith:    roundTo(comparison.MeanA, 2),
+		MeanWithout: roundTo(comparison.MeanB, 2),
+		DaysWith:    comparison.SizeA,
+		DaysWithout: comparison.SizeB,
+		PValue:      roundTo(comparison.PValue, 4),
+		Confidence:  insightConfidence(comparison),
+	}
+	if amounts[0] != nil {
+		r, _ := stats.Pearson(amounts[0], amounts[1])
+		r = roundTo(r, 2)
+		insight.Correlation = &r
+	}
+	return insight, true
+}
+
+// insightConfidence is high for a difference that is very unlikely to be
+// chance over at least ten days on each side, and medium for one that is
+// unlikely over at least five.
+func insightConfidence(comparison stats.Comparison) string {
+	smaller := min(comparison.SizeA, comparison.SizeB)
+	switch {
+	case smaller >= 10 && comparison.PValue < 0.01:
+		return ConfidenceHigh
+	case smaller >= 5 && comparison.PValue < 0.05:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
+
+func insightFinding(texts map[string]string, difference float64, condition string) string {
+	switch {
+	case difference >= 0.05:
+		return fmt.Sprintf(texts["higher"], difference, condition)
+	case difference <= -0.05:
+		return fmt.Sprintf(texts["lower"], -difference, condition)
+	default:
+		return fmt.Sprintf(texts["same"], condition)
+	}
+}
+
+// dailyMoodScores averages the scores of each day's entries. Entries without
+// a known score are skipped.
+func dailyMoodScores(moods []entities.MoodModel, location *time.Location, from time.Time, to time.Time) map[string]float64 {
+	values := map[string][]float64{}
+	for _, mood := range moods {
+		date := MoodDate(mood, location)
+		score := MoodScore(mood)
+		if score == 0 || date.Before(from) || date.After(to) {
+			continue
+		}
+		key := date.Format(habitDateLayout)
+		values[key] = append(values[key], float64(score))
+	}
+	averages := make(map[string]float64, len(values))
+	for day, scores := range values {
+		averages[day] = stats.Mean(scores)
+	}
+	return averages
+}
+
+func dailySleepHours(moods []entities.MoodModel, location *time.Location, from time.Time, to time.Time) map[string]float64 {
+	values := map[string][]float64{}
+	for _, mood := range moods {
+		date := MoodDate(mood, location)
+		if mood.SleepHours == nil || date.Before(from) || date.After(to) {
+			continue
+		}
+		key := date.Format(habitDateLayout)
+		values[key] = append(values[key], *mood.SleepHours)
+	}
+	averages := make(map[string]float64, len(values))
+	for day, hours := range values {
+		averages[day] = stats.Mean(hours)
+	}
+	return averages
+}
+
+// habitMoodDays lists the days the habit was due and not paused, and the
+// days it was checked in.
+func habitMoodDays(habit entities.HabitModel, checkIns []entities.HabitCheckInModel, from time.Time, to time.Time, today time.Time, location *time.Location) (map[string]bool, map[string]bool) {
+	due := map[string]bool{}
+	done := map[string]bool{}
+	for _, checkIn := range checkIns {
+		done[checkIn.Date] = true
+	}
+	for _, occurrence := range HabitOccurrences(habit, checkIns, from, to.AddDate(0, 0, 1), today, location) {
+		if occurrence.Paused {
+			continue
+		}
+		start, err := time.Parse(habitDateLayout, occurrence.Start)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse(habitDateLayout, occurrence.End)
+		if err != nil {
+			continue
+		}
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			due[day.Format(habitDateLayout)] = true
+		}
+	}
+	return due, done
+}
+
+// scheduledMinutes adds up the schedule block minutes of each day, counting
+// blocks the same way as GetScheduleOccurrences.
+func scheduledMinutes(blocks []entities.ScheduleBlockModel, from time.Time, to time.Time) map[string]int {
+	minutes := map[string]int{}
+	for _, block := range blocks {
+		rule, err := recurrence.Parse(block.Recurrence)
+		if block.Recurrence == "" || err != nil {
+			rule = scheduleBlockRule(block.Days)
+		}
+		start := from
+		if !block.CreatedAt.IsZero() {
+			start = block.CreatedAt
+		}
+		for _, occurrence := range rule.Between(start, from, to.AddDate(0, 0, 1)) {
+			minutes[occurrence.Start.Format(habitDateLayout)] += block.DurationMinutes
+		}
+	}
+	return minutes
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
+
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(roundTo(hours, 1), 'f', -1, 64)
+}
+
+var insightTexts = map[string]map[string]string{
+	locale.English: {
+		"higher":               "Your mood is %.1f higher %s",
+		"lower":                "Your mood is %.1f lower %s",
+		"same":                 "Your mood is about the same %s",
+		"habit":                "on days you do \"%s\"",
+		"category":             "on days you do your %s habits",
+		"category:fitness":     "on days you exercise",
+		"category:nutrition":   "on days you keep your eating habits",
+		"category:sleep":       "on days you keep your sleep habits",
+		"category:finance":     "on days you work on your finances",
+		"category:mindfulness": "on days you practise mindfulness",
+		"category:learning":    "on days you read or study",
+		"sleep":                "after nights of %s hours of sleep or more",
+		"busyness":             "on days with more than %s hours scheduled",
+		"busyness_any":         "on days with anything scheduled",
+		"busy_days":            "on the days you marked as busy",
+	},
+	locale.Thai: {
+		"higher":               "อารมณ์ของคุณดีกว่า %.1f คะแนน %s",
+		"lower":                "อารมณ์ของคุณแย่กว่า %.1f คะแนน %s",
+		"same":                 "อารมณ์ของคุณใกล้เคียงเดิม %s",
+		"habit":                "ในวันที่คุณทำ \"%s\"",
+		"category":             "ในวันที่คุณทำนิสัยหมวด %s",
+		"category:fitness":     "ในวันที่คุณออกกำลังกาย",
+		"category:nutrition":   "ในวันที่คุณทำตามนิสัยการกิน",
+		"category:sleep":       "ในวันที่คุณทำตามนิสัยการนอน",
+		"category:finance":     "ในวันที่คุณจัดการการเงิน",
+		"category:mindfulness": "ในวันที่คุณฝึกสติ",
+		"category:learning":    "ในวันที่คุณอ่านหนังสือหรือเรียนรู้",
+		"sleep":                "หลังคืนที่นอน %s ชั่วโมงขึ้นไป",
+		"busyness":             "ในวันที่มีตารางเกิน %s ชั่วโมง",
+		"busyness_any":         "ในวันที่มีตารางนัดหมาย",
+		"busy_days":            "ในวันที่คุณระบุว่ายุ่ง",
+	},
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/src/locale"
+	"go-fiber-template/src/stats"
+	"testing"
+	"time"
+)
+
+// habitMoodData has a daily habit and one mood entry a day: the scores of
+// the days the habit was done, then those of the days it was missed.
+func habitMoodData(done []int, missed []int) MoodInsightData {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	data := MoodInsightData{
+		Habits:   []entities.HabitModel{{ID: 1, Name: "Walk", Recurrence: "FREQ=DAILY", TargetCount: 1, CreatedAt: from.AddDate(0, 0, -7)}},
+		Location: time.UTC,
+		From:     from,
+		Language: locale.English,
+	}
+	day := from
+	for i, score := range append(append([]int{}, done...), missed...) {
+		date := day.Format(habitDateLayout)
+		data.Moods = append(data.Moods, entities.MoodModel{ID: i + 1, Score: &score, Date: date})
+		if i < len(done) {
+			data.CheckIns = append(data.CheckIns, entities.HabitCheckInModel{ID: i + 1, HabitID: 1, Date: date})
+		}
+		data.To = day
+		day = day.AddDate(0, 0, 1)
+	}
+	data.Today = data.To
+	return data
+}
+
+func TestComputeMoodInsightsMinimumSampleAndConfidence(t *testing.T) {
+	tests := []struct {
+		name       string
+		done       []int
+		missed     []int
+		want       bool
+		confidence string
+	}{
+		{"two days done", []int{5, 5}, []int{1, 2, 1, 2}, false, ""},
+		{"two days missed", []int{5, 4, 5, 4}, []int{1, 2}, false, ""},
+		{"three days each", []int{5, 4, 5}, []int{1, 2, 1}, true, ConfidenceLow},
+		{"five days each", []int{5, 4, 5, 4, 5}, []int{1, 2, 1, 2, 1}, true, ConfidenceMedium},
+		{"ten days each", []int{5, 4, 5, 4, 5, 4, 5, 4, 5, 4}, []int{1, 2, 1, 2, 1, 2, 1, 2, 1, 2}, true, ConfidenceHigh},
+		{"ten days, no difference", []int{5, 1, 5, 1, 5, 1, 5, 1, 5, 1}, []int{1, 5, 1, 5, 1, 5, 1, 5, 1, 5}, true, ConfidenceLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ComputeMoodInsights(habitMoodData(tt.done, tt.missed))
+			if result.MoodDays != len(tt.done)+len(tt.missed) {
+				t.Errorf("MoodDays = %d, want %d", result.MoodDays, len(tt.done)+len(tt.missed))
+			}
+			var habit *entities.MoodInsight
+			for i := range result.Insights {
+				if result.Insights[i].Kind == InsightHabit {
+					habit = &result.Insights[i]
+				}
+			}
+			if (habit != nil) != tt.want {
+				t.Fatalf("habit insight = %+v, want one: %v", habit, tt.want)
+			}
+			if habit == nil {
+				return
+			}
+			if habit.DaysWith != len(tt.done) || habit.DaysWithout != len(tt.missed) {
+				t.Errorf("days = %d with, %d without, want %d and %d", habit.DaysWith, habit.DaysWithout, len(tt.done), len(tt.missed))
+			}
+			if habit.Confidence != tt.confidence {
+				t.Errorf("confidence = %q (p %v), want %q", habit.Confidence, habit.PValue, tt.confidence)
+			}
+		})
+	}
+}
+
+func TestInsightConfidenceTiers(t *testing.T) {
+	tests := []struct {
+		sizeA, sizeB int
+		p            float64
+		want         string
+	}{
+		{10, 10, 0.009, ConfidenceHigh},
+		{10, 10, 0.01, ConfidenceMedium},
+		{10, 9, 0.001, ConfidenceMedium},
+		{5, 20, 0.049, ConfidenceMedium},
+		{5, 20, 0.05, ConfidenceLow},
+		{4, 20, 0.001, ConfidenceLow},
+		{3, 3, 0, ConfidenceLow},
+	}
+	for _, tt := range tests {
+		comparison := stats.Comparison{SizeA: tt.sizeA, SizeB: tt.sizeB, PValue: tt.p}
+		if got := insightConfidence(comparison); got != tt.want {
+			t.Errorf("insightConfidence(%d, %d, p %v) = %q, want %q", tt.sizeA, tt.sizeB, tt.p, got, tt.want)
+		}
+	}
+}
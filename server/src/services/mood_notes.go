@@ -0,0 +1,576 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/configuration"
+	"go-fiber-template/domain/aimodel"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/locale"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// How mood notes are read for sentiment and themes. Crisis language is
+// looked for in every mode.
+const (
+	NoteAnalysisOff     = "off"
+	NoteAnalysisLexicon = "lexicon"
+	NoteAnalysisLLM     = "llm"
+)
+
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+const (
+	maxNoteThemes = 3
+	// defaultCrisisRegion has resources that apply anywhere.
+	defaultCrisisRegion = "INTL"
+)
+
+// MoodNoteConfig holds the thresholds for reading notes. A sentiment at or
+// above PositiveThreshold is positive and at or below NegativeThreshold
+// negative. A note is flagged when the weights of the crisis phrases it
+// contains add up to CrisisThreshold, explicit phrases weighing 1 and
+// indirect ones 0.5, or when the LLM rates its crisis risk at
+// LLMCrisisThreshold or more. Resources replaces the built-in support
+// services of the regions it lists.
+type MoodNoteConfig struct {
+	Mode               string                               `json:"mode"`
+	PositiveThreshold  float64                              `json:"positive_threshold"`
+	NegativeThreshold  float64                              `json:"negative_threshold"`
+	CrisisThreshold    float64                              `json:"crisis_threshold"`
+	LLMCrisisThreshold float64                              `json:"llm_crisis_threshold"`
+	DefaultRegion      string                               `json:"default_region"`
+	Resources          map[string][]entities.CrisisResource `json:"resources"`
+}
+
+func DefaultMoodNoteConfig() MoodNoteConfig {
+	return MoodNoteConfig{
+		Mode:               NoteAnalysisLexicon,
+		PositiveThreshold:  0.25,
+		NegativeThreshold:  -0.25,
+		CrisisThreshold:    1,
+		LLMCrisisThreshold: 0.7,
+		DefaultRegion:      defaultCrisisRegion,
+		Resources:          map[string][]entities.CrisisResource{},
+	}
+}
+
+// NewMoodNoteConfigFromEnv reads MOOD_NOTE_ANALYSIS, the thresholds
+// MOOD_SENTIMENT_POSITIVE, MOOD_SENTIMENT_NEGATIVE, MOOD_CRISIS_THRESHOLD and
+// MOOD_CRISIS_LLM_THRESHOLD, CRISIS_DEFAULT_REGION for users whose region
+// cannot be told from their timezone or language, and CRISIS_RESOURCES_PATH,
+// a JSON file of {"TH":[{"name":"...","phone":"..."}]} that replaces the
+// built-in resources of the regions in it.
+func NewMoodNoteConfigFromEnv() MoodNoteConfig {
+	config := DefaultMoodNoteConfig()
+	switch mode := strings.ToLower(os.Getenv("MOOD_NOTE_ANALYSIS")); mode {
+	case "":
+	case NoteAnalysisOff, NoteAnalysisLexicon, NoteAnalysisLLM:
+		config.Mode = mode
+	default:
+		fiberlog.Errorf("MoodNoteService -> NewMoodNoteConfigFromEnv: unknown MOOD_NOTE_ANALYSIS %q \n", mode)
+	}
+	config.PositiveThreshold = configuration.GetEnvFloat("MOOD_SENTIMENT_POSITIVE", config.PositiveThreshold)
+	config.NegativeThreshold = configuration.GetEnvFloat("MOOD_SENTIMENT_NEGATIVE", config.NegativeThreshold)
+	config.CrisisThreshold = configuration.GetEnvFloat("MOOD_CRISIS_THRESHOLD", config.CrisisThreshold)
+	config.LLMCrisisThreshold = configuration.GetEnvFloat("MOOD_CRISIS_LLM_THRESHOLD", config.LLMCrisisThreshold)
+	if region := strings.ToUpper(strings.TrimSpace(os.Getenv("CRISIS_DEFAULT_REGION"))); region != "" {
+		config.DefaultRegion = region
+	}
+	if path := os.Getenv("CRISIS_RESOURCES_PATH"); path != "" {
+		raw, err := os.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(raw, &config.Resources)
+		}
+		if err != nil {
+			fiberlog.Errorf("MoodNoteService -> NewMoodNoteConfigFromEnv: %s \n", err)
+		}
+	}
+	return config
+}
+
+type MoodNoteService struct {
+	AiGenRepo repositories.IAiGenRepository
+	UserRepo  repositories.IUsersRepository
+	Usage     IUsageService
+	Privacy   IPrivacyService
+	Config    MoodNoteConfig
+}
+
+type IMoodNoteService interface {
+	Analyze(userID string, note string) entities.MoodNoteAnalysis
+	Support(userID string) entities.CrisisSupport
+}
+
+// NewMoodNoteService falls back to the defaults for thresholds that would
+// flag every note or none.
+func NewMoodNoteService(aiGenRepo repositories.IAiGenRepository, userRepo repositories.IUsersRepository, usage IUsageService, privacy IPrivacyService, config MoodNoteConfig) IMoodNoteService {
+	defaults := DefaultMoodNoteConfig()
+	if config.CrisisThreshold <= 0 {
+		config.CrisisThreshold = defaults.CrisisThreshold
+	}
+	if config.LLMCrisisThreshold <= 0 || config.LLMCrisisThreshold > 1 {
+		config.LLMCrisisThreshold = defaults.LLMCrisisThreshold
+	}
+	if config.PositiveThreshold < config.NegativeThreshold {
+		config.PositiveThreshold, config.NegativeThreshold = defaults.PositiveThreshold, defaults.NegativeThreshold
+	}
+	if config.DefaultRegion == "" {
+		config.DefaultRegion = defaultCrisisRegion
+	}
+	return &MoodNoteService{
+		AiGenRepo: aiGenRepo,
+		UserRepo:  userRepo,
+		Usage:     usage,
+		Privacy:   privacy,
+		Config:    config,
+	}
+}
+
+// Analyze reads the sentiment and themes of note as the configured mode
+// says, using the lexicon when the LLM fails or answers with something that
+// cannot be read, and flags it when it contains crisis language.
+func (sv *MoodNoteService) Analyze(userID string, note string) entities.MoodNoteAnalysis {
+	analysis := entities.MoodNoteAnalysis{Themes: []string{}}
+	if strings.TrimSpace(note) == "" {
+		return analysis
+	}
+	text := normalizeNote(note)
+	analysis.CrisisScore, _ = CrisisScore(text)
+	flagged := analysis.CrisisScore >= sv.Config.CrisisThreshold
+
+	if sv.Config.Mode == NoteAnalysisLLM {
+		if result, err := sv.analyzeWithLLM(userID, note); err != nil {
+			fiberlog.Errorf("MoodNoteService -> Analyze: %s \n", err)
+		} else {
+			analysis.Sentiment = &result.Sentiment
+			analysis.Themes = result.Themes
+			analysis.Source = NoteAnalysisLLM
+			flagged = flagged || result.CrisisRisk >= sv.Config.LLMCrisisThreshold
+		}
+	}
+	if sv.Config.Mode != NoteAnalysisOff && analysis.Source == "" {
+		sentiment := LexiconSentiment(text)
+		analysis.Sentiment = &sentiment
+		analysis.Themes = NoteThemes(text)
+		analysis.Source = NoteAnalysisLexicon
+	}
+	if analysis.Sentiment != nil {
+		analysis.Label = SentimentLabel(*analysis.Sentiment, sv.Config.PositiveThreshold, sv.Config.NegativeThreshold)
+	}
+	if flagged {
+		support := sv.Support(userID)
+		analysis.Flagged = true
+		analysis.Support = &support
+	}
+	return analysis
+}
+
+// Support picks the resources for the user's region, told from their
+// timezone, DEFAULT_TIMEZONE for users without one, or else their language.
+func (sv *MoodNoteService) Support(userID string) entities.CrisisSupport {
+	language := userLanguage(sv.UserRepo, userID)
+	region := CrisisRegion(userLocation(sv.UserRepo, userID).String(), language, sv.Config.DefaultRegion)
+	resources, ok := sv.Config.Resources[region]
+	if !ok {
+		resources, ok = crisisResources[region]
+	}
+	if !ok {
+		region = defaultCrisisRegion
+		resources = crisisResources[defaultCrisisRegion]
+	}
+	message := crisisMessages[language]
+	if message == "" {
+		message = crisisMessages[locale.English]
+	}
+	return entities.CrisisSupport{
+		Region:    region,
+		Message:   message,
+		Resources: resources,
+	}
+}
+
+type llmNoteAnalysis struct {
+	Sentiment  float64
+	Themes     []string
+	CrisisRisk float64
+}
+
+func (sv *MoodNoteService) analyzeWithLLM(userID string, note string) (*llmNoteAnalysis, error) {
+	redaction := sv.Privacy.NewRedaction(userID)
+	prompt := withInstruction(noteAnalysisPrompt(redaction.Redact(note)), redaction.Instruction())
+	text, usage, err := sv.AiGenRepo.GenerateNoteAnalysis(prompt)
+	sv.Usage.Record(userID, aimodel.FeatureNotes, usage)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNoteAnalysis(text)
+}
+
+func noteAnalysisPrompt(note string) string {
+	themes := make([]string, 0, len(noteThemes))
+	for _, theme := range noteThemes {
+		themes = append(themes, theme.Theme)
+	}
+	var prompt strings.Builder
+	prompt.WriteString("You read a short private mood journal note, written in English or Thai, and answer with JSON only:\n")
+	prompt.WriteString(`{"sentiment": <number from -1, very negative, to 1, very positive>, "themes": [<up to 3 of: ` + strings.Join(themes, ", ") + `>], "crisis_risk": <number from 0 to 1, how likely the note expresses thoughts of suicide or self-harm>}` + "\n")
+	prompt.WriteString("Only use themes the note is clearly about. Do not add any other text.\n\nNote:\n\"\"\"\n")
+	prompt.WriteString(note)
+	prompt.WriteString("\n\"\"\"")
+	return prompt.String()
+}
+
+// ParseNoteAnalysis reads the model's JSON answer. Values out of range are
+// clamped and unknown themes dropped.
+func ParseNoteAnalysis(text string) (*llmNoteAnalysis, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("note analysis is not a JSON object")
+	}
+	var raw struct {
+		Sentiment  *float64 `json:"sentiment"`
+		Themes     []string `json:"themes"`
+		CrisisRisk *float64 `json:"crisis_risk"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("invalid note analysis: %w", err)
+	}
+	if raw.Sentiment == nil || math.IsNaN(*raw.Sentiment) {
+		return nil, fmt.Errorf("note analysis has no sentiment")
+	}
+	result := &llmNoteAnalysis{
+		Sentiment: roundTo(math.Max(-1, math.Min(1, *raw.Sentiment)), 2),
+		Themes:    []string{},
+	}
+	if raw.CrisisRisk != nil {
+		result.CrisisRisk = math.Max(0, math.Min(1, *raw.CrisisRisk))
+	}
+	known := map[string]bool{}
+	for _, theme := range noteThemes {
+		known[theme.Theme] = true
+	}
+	for _, theme := range raw.Themes {
+		theme = strings.ToLower(strings.TrimSpace(theme))
+		if known[theme] && len(result.Themes) < maxNoteThemes {
+			known[theme] = false
+			result.Themes = append(result.Themes, theme)
+		}
+	}
+	return result, nil
+}
+
+func SentimentLabel(sentiment float64, positive float64, negative float64) string {
+	switch {
+	case sentiment >= positive:
+		return SentimentPositive
+	case sentiment <= negative:
+		return SentimentNegative
+	default:
+		return SentimentNeutral
+	}
+}
+
+// lexiconTerm is a word or phrase with its weight. English terms match whole
+// words, or words starting with the term when prefix matching is asked for;
+// Thai is written without spaces, so Thai terms match anywhere.
+type lexiconTerm struct {
+	Term   string
+	Weight float64
+}
+
+type lexiconHit struct {
+	lexiconTerm
+	Start int
+}
+
+var noteSeparator = regexp.MustCompile(`\s+`)
+
+// normalizeNote lowercases note, straightens apostrophes and collapses
+// whitespace so the lexicons can match it.
+func normalizeNote(note string) string {
+	note = strings.ToLower(strings.NewReplacer("’", "'", "‘", "'").Replace(note))
+	return strings.TrimSpace(noteSeparator.ReplaceAllString(note, " "))
+}
+
+// scanLexicon finds the terms in text, longest first, so "burned out" is not
+// also counted as "out" and "ซึมเศร้า" not also as "เศร้า".
+func scanLexicon(text string, terms []lexiconTerm, prefix bool) []lexiconHit {
+	sorted := append([]lexiconTerm{}, terms...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Term) > len(sorted[j].Term)
+	})
+	taken := make([]bool, len(text))
+	hits := []lexiconHit{}
+	for _, term := range sorted {
+		for offset := 0; offset < len(text); {
+			index := strings.Index(text[offset:], term.Term)
+			if index < 0 {
+				break
+			}
+			start, end := offset+index, offset+index+len(term.Term)
+			offset = end
+			if !termBoundary(text, term.Term, start, end, prefix) || anyTaken(taken[start:end]) {
+				continue
+			}
+			for i := start; i < end; i++ {
+				taken[i] = true
+			}
+			hits = append(hits, lexiconHit{lexiconTerm: term, Start: start})
+		}
+	}
+	return hits
+}
+
+func termBoundary(text string, term string, start int, end int, prefix bool) bool {
+	first, _ := utf8.DecodeRuneInString(term)
+	if first >= utf8.RuneSelf {
+		return true
+	}
+	if start > 0 {
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		if unicode.IsLetter(before) {
+			return false
+		}
+	}
+	if !prefix && end < len(text) {
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if unicode.IsLetter(after) || after == '\'' {
+			return false
+		}
+	}
+	return true
+}
+
+func anyTaken(taken []bool) bool {
+	for _, t := range taken {
+		if t {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	englishNegations = map[string]bool{
+		"not": true, "no": true, "never": true, "without": true, "hardly": true, "barely": true,
+		"don't": true, "dont": true, "didn't": true, "didnt": true, "doesn't": true, "doesnt": true,
+		"isn't": true, "isnt": true, "wasn't": true, "wasnt": true, "aren't": true, "werent": true, "weren't": true,
+		"can't": true, "cant": true, "couldn't": true, "couldnt": true, "won't": true, "wont": true,
+	}
+	thaiNegations   = []string{"ไม่ค่อย", "ไม่ได้", "ไม่"}
+	clauseBoundary  = regexp.MustCompile(`[.!?,;:\n]`)
+	englishWordChar = regexp.MustCompile(`[a-z']+`)
+)
+
+// negated reports whether the term at start follows a negation in the same
+// clause: "ไม่" right before it, or an English negation in the three words
+// before it.
+func negated(text string, start int) bool {
+	before := text[:start]
+	if bounds := clauseBoundary.FindAllStringIndex(before, -1); len(bounds) > 0 {
+		before = before[bounds[len(bounds)-1][1]:]
+	}
+	trimmed := strings.TrimRight(before, " ")
+	for _, negation := range thaiNegations {
+		if strings.HasSuffix(trimmed, negation) {
+			return true
+		}
+	}
+	words := englishWordChar.FindAllString(before, -1)
+	for i := max(len(words)-3, 0); i < len(words); i++ {
+		if englishNegations[words[i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// LexiconSentiment scores normalized text from -1 to 1. Negated terms count
+// for less than their opposite, so "not bad" is mildly positive. The sum of
+// weights is squashed the way VADER does it, so a single word is a moderate
+// score and many agreeing words approach the ends of the scale.
+func LexiconSentiment(text string) float64 {
+	total := 0.0
+	for _, hit := range scanLexicon(text, sentimentLexicon, false) {
+		if negated(text, hit.Start) {
+			total -= 0.75 * hit.Weight
+		} else {
+			total += hit.Weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return roundTo(total/math.Sqrt(total*total+4), 2)
+}
+
+// CrisisScore adds up the weights of the crisis phrases in normalized text
+// and returns the phrases found. Negations are ignored on purpose: a note
+// saying "I don't want to die" is still worth a gentle check-in.
+func CrisisScore(text string) (float64, []string) {
+	score := 0.0
+	found := []string{}
+	for _, hit := range scanLexicon(text, crisisLexicon, true) {
+		score += hit.Weight
+		found = append(found, hit.Term)
+	}
+	return score, found
+}
+
+// NoteThemes lists up to three themes of normalized text, the most
+// mentioned first.
+func NoteThemes(text string) []string {
+	type themeCount struct {
+		Theme string
+		Count int
+	}
+	counts := []themeCount{}
+	for _, theme := range noteThemes {
+		terms := make([]lexiconTerm, 0, len(theme.Words))
+		for _, word := range theme.Words {
+			terms = append(terms, lexiconTerm{Term: word, Weight: 1})
+		}
+		if hits := scanLexicon(text, terms, true); len(hits) > 0 {
+			counts = append(counts, themeCount{theme.Theme, len(hits)})
+		}
+	}
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+	themes := []string{}
+	for i := 0; i < len(counts) && i < maxNoteThemes; i++ {
+		themes = append(themes, counts[i].Theme)
+	}
+	return themes
+}
+
+// CrisisRegion maps a timezone, or a language when the timezone says
+// nothing, to a region with support resources.
+func CrisisRegion(timezone string, language string, fallback string) string {
+	if region, ok := timezoneRegions[timezone]; ok {
+		return region
+	}
+	if strings.HasPrefix(timezone, "Australia/") {
+		return "AU"
+	}
+	if language == locale.Thai {
+		return "TH"
+	}
+	if fallback == "" {
+		return defaultCrisisRegion
+	}
+	return fallback
+}
+
+var timezoneRegions = map[string]string{
+	"Asia/Bangkok":        "TH",
+	"Asia/Singapore":      "SG",
+	"Europe/London":       "GB",
+	"America/New_York":    "US",
+	"America/Chicago":     "US",
+	"America/Denver":      "US",
+	"America/Phoenix":     "US",
+	"America/Los_Angeles": "US",
+	"America/Anchorage":   "US",
+	"Pacific/Honolulu":    "US",
+}
+
+var crisisResources = map[string][]entities.CrisisResource{
+	"TH": {
+		{Name: "Department of Mental Health hotline (สายด่วนสุขภาพจิต 1323)", Phone: "1323", Hours: "24 hours", Description: "Free, confidential counselling in Thai"},
+		{Name: "Samaritans of Thailand (สมาคมสะมาริตันส์แห่งประเทศไทย)", Phone: "02-113-6789", Description: "Emotional support in Thai and English"},
+		{Name: "Emergency medical services (สายด่วนการแพทย์ฉุกเฉิน)", Phone: "1669", Hours: "24 hours", Description: "Call if you or someone else is in immediate danger"},
+	},
+	"US": {
+		{Name: "988 Suicide & Crisis Lifeline", Phone: "988", URL: "https://988lifeline.org", Hours: "24 hours", Description: "Call or text 988"},
+		{Name: "Emergency services", Phone: "911", Hours: "24 hours", Description: "Call if you or someone else is in immediate danger"},
+	},
+	"GB": {
+		{Name: "Samaritans", Phone: "116 123", URL: "https://www.samaritans.org", Hours: "24 hours", Description: "Free to call, any time"},
+		{Name: "Emergency services", Phone: "999", Hours: "24 hours", Description: "Call if you or someone else is in immediate danger"},
+	},
+	"AU": {
+		{Name: "Lifeline Australia", Phone: "13 11 14", URL: "https://www.lifeline.org.au", Hours: "24 hours", Description: "Crisis support and suicide prevention"},
+		{Name: "Emergency services", Phone: "000", Hours: "24 hours", Description: "Call if you or someone else is in immediate danger"},
+	},
+	"SG": {
+		{Name: "Samaritans of Singapore (SOS)", Phone: "1767", URL: "https://www.sos.org.sg", Hours: "24 hours", Description: "Crisis support and suicide prevention"},
+		{Name: "Emergency services", Phone: "995", Hours: "24 hours", Description: "Call if you or someone else is in immediate danger"},
+	},
+	defaultCrisisRegion: {
+		{Name: "Find A Helpline", URL: "https://findahelpline.com", Description: "Free, confidential helplines in your country"},
+		{Name: "Local emergency services", Description: "Call your local emergency number if you or someone else is in immediate danger"},
+	},
+}
+
+var crisisMessages = map[string]string{
+	locale.English: "It sounds like you are going through something very hard, and you do not have to face it alone. If you are thinking about harming yourself, please reach out now to one of these services or someone you trust.",
+	locale.Thai:    "ดูเหมือนว่าคุณกำลังเผชิญกับเรื่องที่หนักมาก และคุณไม่จำเป็นต้องผ่านมันไปคนเดียว หากคุณกำลังคิดจะทำร้ายตัวเอง โปรดติดต่อบริการเหล่านี้หรือคนที่คุณไว้ใจตอนนี้",
+}
+
+// sentimentLexicon weighs words from -2 to 2.
+var sentimentLexicon = []lexiconTerm{
+	{"good", 1}, {"great", 2}, {"happy", 1.5}, {"glad", 1}, {"calm", 1}, {"relaxed", 1}, {"grateful", 1.5},
+	{"thankful", 1.5}, {"proud", 1.5}, {"excited", 1.5}, {"fun", 1}, {"love", 2}, {"loved", 2}, {"enjoyed", 1.5},
+	{"productive", 1}, {"peaceful", 1}, {"hopeful", 1}, {"energetic", 1}, {"better", 1}, {"awesome", 2},
+	{"amazing", 2}, {"wonderful", 2}, {"content", 1}, {"motivated", 1}, {"accomplished", 1.5}, {"rested", 1},
+	{"laughed", 1}, {"fine", 0.5}, {"nice", 1}, {"okay", 0.3}, {"relieved", 1},
+	{"bad", -1}, {"sad", -1.5}, {"tired", -1}, {"exhausted", -2}, {"stressed", -1.5}, {"stress", -1}, {"anxious", -1.5},
+	{"anxiety", -1.5}, {"worried", -1}, {"angry", -1.5}, {"upset", -1.5}, {"lonely", -1.5}, {"depressed", -2},
+	{"awful", -2}, {"terrible", -2}, {"horrible", -2}, {"frustrated", -1.5}, {"overwhelmed", -2}, {"sick", -1},
+	{"hurt", -1.5}, {"cry", -1.5}, {"cried", -1.5}, {"crying", -1.5}, {"hate", -2}, {"bored", -1}, {"annoyed", -1},
+	{"scared", -1.5}, {"afraid", -1.5}, {"nervous", -1}, {"miserable", -2}, {"hopeless", -2}, {"worse", -1.5},
+	{"pain", -1}, {"drained", -1.5}, {"burned out", -2}, {"burnt out", -2}, {"burnout", -2},
+	{"มีความสุข", 2}, {"ดีใจ", 2}, {"สุขใจ", 1.5}, {"สบายใจ", 1.5}, {"ผ่อนคลาย", 1}, {"สนุก", 1.5}, {"ภูมิใจ", 1.5},
+	{"ขอบคุณ", 1}, {"รัก", 1.5}, {"ตื่นเต้น", 1}, {"โล่งใจ", 1}, {"ดีมาก", 2}, {"สดชื่น", 1}, {"มีกำลังใจ", 1.5},
+	{"เศร้า", -1.5}, {"เสียใจ", -1.5}, {"เหนื่อย", -1}, {"เครียด", -1.5}, {"กังวล", -1}, {"โกรธ", -1.5}, {"เหงา", -1.5},
+	{"ซึมเศร้า", -2}, {"แย่", -1.5}, {"ท้อ", -1.5}, {"หงุดหงิด", -1}, {"ผิดหวัง", -1.5}, {"กลัว", -1}, {"เบื่อ", -1},
+	{"ร้องไห้", -1.5}, {"ป่วย", -1}, {"เจ็บ", -1}, {"ไม่สบาย", -1}, {"หมดไฟ", -2}, {"ทุกข์", -1.5},
+}
+
+var crisisLexicon = []lexiconTerm{
+	{"suicid", 1}, {"kill myself", 1}, {"killing myself", 1}, {"end my life", 1}, {"ending my life", 1},
+	{"take my own life", 1}, {"want to die", 1}, {"wanna die", 1}, {"wish i was dead", 1}, {"wish i were dead", 1},
+	{"better off dead", 1}, {"hurt myself", 1}, {"hurting myself", 1}, {"harm myself", 1}, {"self-harm", 1},
+	{"self harm", 1}, {"cut myself", 1}, {"cutting myself", 1}, {"overdose", 1},
+	{"no reason to live", 0.5}, {"nothing to live for", 0.5}, {"better off without me", 0.5}, {"can't go on", 0.5},
+	{"cant go on", 0.5}, {"cannot go on", 0.5}, {"hopeless", 0.5}, {"no way out", 0.5}, {"give up on life", 0.5},
+	{"disappear forever", 0.5}, {"don't want to be here", 0.5}, {"dont want to be here", 0.5},
+	{"don't want to wake up", 0.5}, {"dont want to wake up", 0.5}, {"worthless", 0.5}, {"i'm a burden", 0.5},
+	{"ฆ่าตัวตาย", 1}, {"อยากตาย", 1}, {"ทำร้ายตัวเอง", 1}, {"จบชีวิต", 1}, {"ไม่อยากมีชีวิตอยู่", 1},
+	{"กินยาเกินขนาด", 1}, {"กรีดข้อมือ", 1},
+	{"ไม่อยากอยู่แล้ว", 0.5}, {"หมดหวัง", 0.5}, {"อยากหายไป", 0.5}, {"ไม่มีทางออก", 0.5}, {"ไร้ค่า", 0.5},
+	{"เป็นภาระ", 0.5}, {"ไม่ไหวแล้ว", 0.5},
+}
+
+// noteThemes are matched on word starts, so "work" also finds "working".
+var noteThemes = []struct {
+	Theme string
+	Words []string
+}{
+	{"work", []string{"work", "job", "office", "boss", "meeting", "deadline", "colleague", "coworker", "project", "งาน", "หัวหน้า", "ประชุม", "เจ้านาย"}},
+	{"study", []string{"study", "studies", "exam", "homework", "school", "university", "lecture", "เรียน", "สอบ", "การบ้าน", "มหาลัย", "โรงเรียน"}},
+	{"family", []string{"family", "mom", "mum", "mother", "dad", "father", "parent", "sister", "brother", "kids", "children", "ครอบครัว", "พ่อ", "แม่", "พี่น้อง"}},
+	{"relationships", []string{"partner", "boyfriend", "girlfriend", "husband", "wife", "relationship", "breakup", "broke up", "แฟน", "คนรัก", "สามี", "ภรรยา", "เลิกกัน"}},
+	{"friends", []string{"friend", "party", "hung out", "hang out", "เพื่อน"}},
+	{"health", []string{"sick", "headache", "doctor", "hospital", "fever", "pain", "illness", "ป่วย", "ไม่สบาย", "ปวด", "หมอ", "โรงพยาบาล"}},
+	{"sleep", []string{"sleep", "slept", "insomnia", "nap", "nightmare", "นอน", "หลับ", "ง่วง"}},
+	{"exercise", []string{"exercise", "workout", "gym", "run", "walk", "yoga", "swim", "ออกกำลัง", "วิ่ง", "ฟิตเนส", "โยคะ"}},
+	{"money", []string{"money", "bills", "rent", "debt", "salary", "budget", "expensive", "เงิน", "หนี้", "ค่าใช้จ่าย"}},
+	{"food", []string{"food", "meal", "eat", "dinner", "lunch", "breakfast", "cook", "อาหาร", "กินข้าว"}},
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/src/locale"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeUsersRepository serves profiles from a map.
+type fakeUsersRepository struct {
+	users map[string]entities.UserProfileModel
+}
+
+func (repo *fakeUsersRepository) FindAll() (*[]entities.UserProfileModel, error) {
+	users := []entities.UserProfileModel{}
+	for _, user := range repo.users {
+		users = append(users, user)
+	}
+	return &users, nil
+}
+
+func (repo *fakeUsersRepository) InsertUser(data entities.UserProfileResponse) error {
+	return nil
+}
+
+func (repo *fakeUsersRepository) FindByID(id string) (*entities.UserProfileModel, error) {
+	user, ok := repo.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user %s not found", id)
+	}
+	return &user, nil
+}
+
+func (repo *fakeUsersRepository) UpdateUser(data entities.UserProfileModel) error {
+	repo.users[data.UserID] = data
+	return nil
+}
+
+func TestNewMoodNoteConfigFromEnv(t *testing.T) {
+	defaults := DefaultMoodNoteConfig()
+	tests := []struct {
+		name string
+		env  map[string]string
+		want MoodNoteConfig
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{},
+			want: defaults,
+		},
+		{
+			name: "thresholds from env",
+			env: map[string]string{
+				"MOOD_NOTE_ANALYSIS":        "llm",
+				"MOOD_SENTIMENT_POSITIVE":   "0.4",
+				"MOOD_SENTIMENT_NEGATIVE":   "-0.5",
+				"MOOD_CRISIS_THRESHOLD":     "1.5",
+				"MOOD_CRISIS_LLM_THRESHOLD": "0.9",
+				"CRISIS_DEFAULT_REGION":     "gb",
+			},
+			want: MoodNoteConfig{
+				Mode:               NoteAnalysisLLM,
+				PositiveThreshold:  0.4,
+				NegativeThreshold:  -0.5,
+				CrisisThreshold:    1.5,
+				LLMCrisisThreshold: 0.9,
+				DefaultRegion:      "GB",
+			},
+		},
+		{
+			name: "unreadable values keep the defaults",
+			env: map[string]string{
+				"MOOD_NOTE_ANALYSIS":      "sometimes",
+				"MOOD_SENTIMENT_POSITIVE": "high",
+				"MOOD_CRISIS_THRESHOLD":   "one",
+			},
+			want: defaults,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"MOOD_NOTE_ANALYSIS", "MOOD_SENTIMENT_POSITIVE", "MOOD_SENTIMENT_NEGATIVE", "MOOD_CRISIS_THRESHOLD", "MOOD_CRISIS_LLM_THRESHOLD", "CRISIS_DEFAULT_REGION", "CRISIS_RESOURCES_PATH"} {
+				t.Setenv(key, tt.env[key])
+			}
+			got := NewMoodNoteConfigFromEnv()
+			if got.Mode != tt.want.Mode || got.PositiveThreshold != tt.want.PositiveThreshold ||
+				got.NegativeThreshold != tt.want.NegativeThreshold || got.CrisisThreshold != tt.want.CrisisThreshold ||
+				got.LLMCrisisThreshold != tt.want.LLMCrisisThreshold || got.DefaultRegion != tt.want.DefaultRegion {
+				t.Errorf("NewMoodNoteConfigFromEnv() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMoodNoteServiceFallsBackToDefaults(t *testing.T) {
+	defaults := DefaultMoodNoteConfig()
+	tests := []struct {
+		name   string
+		config MoodNoteConfig
+		want   MoodNoteConfig
+	}{
+		{
+			name:   "zero crisis thresholds",
+			config: MoodNoteConfig{CrisisThreshold: 0, LLMCrisisThreshold: 0, PositiveThreshold: 0.3, NegativeThreshold: -0.3},
+			want:   MoodNoteConfig{CrisisThreshold: defaults.CrisisThreshold, LLMCrisisThreshold: defaults.LLMCrisisThreshold, PositiveThreshold: 0.3, NegativeThreshold: -0.3},
+		},
+		{
+			name:   "llm threshold above 1",
+			config: MoodNoteConfig{CrisisThreshold: 2, LLMCrisisThreshold: 1.2, PositiveThreshold: 0.3, NegativeThreshold: -0.3},
+			want:   MoodNoteConfig{CrisisThreshold: 2, LLMCrisisThreshold: defaults.LLMCrisisThreshold, PositiveThreshold: 0.3, NegativeThreshold: -0.3},
+		},
+		{
+			name:   "positive below negative",
+			config: MoodNoteConfig{CrisisThreshold: 1, LLMCrisisThreshold: 0.5, PositiveThreshold: -0.5, NegativeThreshold: 0.5},
+			want:   MoodNoteConfig{CrisisThreshold: 1, LLMCrisisThreshold: 0.5, PositiveThreshold: defaults.PositiveThreshold, NegativeThreshold: defaults.NegativeThreshold},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMoodNoteService(nil, nil, nil, nil, tt.config).(*MoodNoteService).Config
+			if got.CrisisThreshold != tt.want.CrisisThreshold || got.LLMCrisisThreshold != tt.want.LLMCrisisThreshold ||
+				got.PositiveThreshold != tt.want.PositiveThreshold || got.NegativeThreshold != tt.want.NegativeThreshold {
+				t.Errorf("config = %+v, want %+v", got, tt.want)
+			}
+			if got.DefaultRegion != defaultCrisisRegion {
+				t.Errorf("DefaultRegion = %q, want %q", got.DefaultRegion, defaultCrisisRegion)
+			}
+		})
+	}
+}
+
+func TestSentimentLabel(t *testing.T) {
+	tests := []struct {
+		sentiment float64
+		want      string
+	}{
+		{0.25, SentimentPositive},
+		{0.24, SentimentNeutral},
+		{0, SentimentNeutral},
+		{-0.24, SentimentNeutral},
+		{-0.25, SentimentNegative},
+		{-1, SentimentNegative},
+	}
+	for _, tt := range tests {
+		if got := SentimentLabel(tt.sentiment, 0.25, -0.25); got != tt.want {
+			t.Errorf("SentimentLabel(%v) = %q, want %q", tt.sentiment, got, tt.want)
+		}
+	}
+}
+
+func TestCrisisScore(t *testing.T) {
+	tests := []struct {
+		note  string
+		score float64
+	}{
+		{"had a lovely walk with friends", 0},
+		{"i feel hopeless", 0.5},
+		{"hopeless and worthless", 1},
+		{"some days i want to die", 1},
+		{"i don't want to die", 1},
+		{"thinking about suicide, i feel worthless", 1.5},
+		{"อยากตาย", 1},
+		{"ช่วงนี้หมดหวัง", 0.5},
+	}
+	for _, tt := range tests {
+		if got, _ := CrisisScore(normalizeNote(tt.note)); got != tt.score {
+			t.Errorf("CrisisScore(%q) = %v, want %v", tt.note, got, tt.score)
+		}
+	}
+}
+
+func TestAnalyzeFlagsAtCrisisThreshold(t *testing.T) {
+	users := &fakeUsersRepository{users: map[string]entities.UserProfileModel{}}
+	tests := []struct {
+		threshold float64
+		note      string
+		flagged   bool
+	}{
+		{1, "i feel hopeless", false},
+		{1, "hopeless and worthless", true},
+		{1, "some days i want to die", true},
+		{1.5, "some days i want to die", false},
+		{1.5, "some days i want to die, it is hopeless", true},
+		{0.5, "i feel hopeless", true},
+		{0.5, "a calm and happy day", false},
+	}
+	for _, tt := range tests {
+		config := DefaultMoodNoteConfig()
+		config.CrisisThreshold = tt.threshold
+		analysis := NewMoodNoteService(nil, users, nil, nil, config).Analyze("user", tt.note)
+		if analysis.Flagged != tt.flagged {
+			t.Errorf("threshold %v, %q: flagged = %v, want %v (score %v)", tt.threshold, tt.note, analysis.Flagged, tt.flagged, analysis.CrisisScore)
+		}
+		if (analysis.Support != nil) != tt.flagged {
+			t.Errorf("threshold %v, %q: support = %v, want it only when flagged", tt.threshold, tt.note, analysis.Support)
+		}
+	}
+}
+
+func TestSupportPicksRegion(t *testing.T) {
+	t.Setenv("DEFAULT_TIMEZONE", "UTC")
+	t.Setenv("DEFAULT_LANGUAGE", "")
+	users := &fakeUsersRepository{users: map[string]entities.UserProfileModel{
+		"bangkok": {UserID: "bangkok", Timezone: "Asia/Bangkok"},
+		"london":  {UserID: "london", Timezone: "Europe/London", Language: locale.English},
+		"sydney":  {UserID: "sydney", Timezone: "Australia/Sydney"},
+		"thai":    {UserID: "thai", Language: locale.Thai},
+		"berlin":  {UserID: "berlin", Timezone: "Europe/Berlin"},
+	}}
+	tests := []struct {
+		userID   string
+		fallback string
+		region   string
+		phone    string
+	}{
+		{"bangkok", "", "TH", "1323"},
+		{"london", "", "GB", "116 123"},
+		{"sydney", "", "AU", "13 11 14"},
+		{"thai", "", "TH", "1323"},
+		{"berlin", "", defaultCrisisRegion, ""},
+		{"berlin", "US", "US", "988"},
+		{"berlin", "XX", defaultCrisisRegion, ""},
+		{"missing", "", defaultCrisisRegion, ""},
+	}
+	for _, tt := range tests {
+		config := DefaultMoodNoteConfig()
+		config.DefaultRegion = tt.fallback
+		support := NewMoodNoteService(nil, users, nil, nil, config).Support(tt.userID)
+		if support.Region != tt.region {
+			t.Errorf("%s with fallback %q: region = %q, want %q", tt.userID, tt.fallback, support.Region, tt.region)
+		}
+		if len(support.Resources) == 0 || support.Resources[0].Phone != tt.phone {
+			t.Errorf("%s: resources = %+v, want the first to have phone %q", tt.userID, support.Resources, tt.phone)
+		}
+	}
+	thai := NewMoodNoteService(nil, users, nil, nil, DefaultMoodNoteConfig()).Support("thai")
+	if thai.Message != crisisMessages[locale.Thai] {
+		t.Errorf("thai message = %q, want the Thai message", thai.Message)
+	}
+}
+
+func TestSupportUsesConfiguredResources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.json")
+	if err := os.WriteFile(path, []byte(`{"TH":[{"name":"Local clinic","phone":"02-000-0000"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CRISIS_RESOURCES_PATH", path)
+	users := &fakeUsersRepository{users: map[string]entities.UserProfileModel{
+		"bangkok": {UserID: "bangkok", Timezone: "Asia/Bangkok"},
+		"london":  {UserID: "london", Timezone: "Europe/London"},
+	}}
+	service := NewMoodNoteService(nil, users, nil, nil, NewMoodNoteConfigFromEnv())
+	if got := service.Support("bangkok").Resources; len(got) != 1 || got[0].Phone != "02-000-0000" {
+		t.Errorf("TH resources = %+v, want the configured clinic", got)
+	}
+	if got := service.Support("london").Resources; len(got) == 0 || got[0].Phone != "116 123" {
+		t.Errorf("GB resources = %+v, want the built-in ones", got)
+	}
+}
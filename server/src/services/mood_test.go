@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"testing"
+)
+
+type fakeMoodRepository struct {
+	saved []entities.MoodResponse
+	err   error
+}
+
+func (repo *fakeMoodRepository) GetMood() (*[]entities.MoodModel, error) {
+	return &[]entities.MoodModel{}, nil
+}
+
+func (repo *fakeMoodRepository) GetMoodById(id string) (*[]entities.MoodModel, error) {
+	return &[]entities.MoodModel{}, nil
+}
+
+func (repo *fakeMoodRepository) NewMood(mood entities.MoodResponse) error {
+	if repo.err != nil {
+		return repo.err
+	}
+	repo.saved = append(repo.saved, mood)
+	return nil
+}
+
+func TestNewMoodReturnsSupportWhenRejected(t *testing.T) {
+	score := 9
+	tests := []struct {
+		name       string
+		body       entities.MoodBody
+		repoErr    error
+		validation bool
+	}{
+		{"missing score", entities.MoodBody{Note: "i want to die"}, nil, true},
+		{"score out of range", entities.MoodBody{Note: "i want to die", Score: &score}, nil, true},
+		{"bad date", entities.MoodBody{Mood: "bad", Note: "i want to die", Date: "yesterday"}, nil, true},
+		{"unknown timezone", entities.MoodBody{Mood: "bad", Note: "i want to die", Timezone: "Mars/Base"}, nil, true},
+		{"insert fails", entities.MoodBody{Mood: "bad", Note: "i want to die"}, fmt.Errorf("insert failed"), false},
+	}
+	users := &fakeUsersRepository{users: map[string]entities.UserProfileModel{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notes := NewMoodNoteService(nil, users, nil, nil, DefaultMoodNoteConfig())
+			service := NewMoodService(&fakeMoodRepository{err: tt.repoErr}, users, notes)
+			entry, err := service.NewMood("user", tt.body)
+			if err == nil {
+				t.Fatal("NewMood() error = nil, want an error")
+			}
+			if IsValidationError(err) != tt.validation {
+				t.Errorf("IsValidationError(%v) = %v, want %v", err, IsValidationError(err), tt.validation)
+			}
+			if entry.Support == nil || len(entry.Support.Resources) == 0 {
+				t.Errorf("NewMood() support = %v, want crisis resources", entry.Support)
+			}
+		})
+	}
+}
@@ -0,0 +1,572 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/recurrence"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// Plan task statuses.
+const (
+	PlanTaskTodo = "todo"
+	PlanTaskDone = "done"
+)
+
+type PlanAdoptionService struct {
+	AiGenRepo     repositories.IAiGenRepository
+	HabitsRepo    repositories.IHabitRepository
+	PlanTaskRepo  repositories.IPlanTaskRepository
+	UserRepo      repositories.IUsersRepository
+	HabitsService IHabitsService
+}
+
+type IPlanAdoptionService interface {
+	// SuggestFromPlan extracts the habits and tasks a plan suggests and marks
+	// the ones already adopted.
+	SuggestFromPlan(planID string) (*entities.PlanSuggestions, error)
+	// AdoptFromPlan creates the chosen suggestions for the plan's user,
+	// linked to the plan. Adopting a suggestion twice returns the existing
+	// habit or task, so a failed request can be retried as a whole.
+	AdoptFromPlan(planID string, body entities.PlanAdoptBody) (*entities.PlanAdoption, error)
+	GetPlanAdherence(planID string) (*entities.PlanAdherence, error)
+	// ListTasks filters by plan and status; empty filters match all.
+	ListTasks(userID string, planID string, status string) (*[]entities.PlanTaskModel, error)
+	UpdateTaskStatus(userID string, taskID string, status string) (*entities.PlanTaskModel, error)
+	DeleteTask(userID string, taskID string) error
+}
+
+func NewPlanAdoptionService(aiGenRepo repositories.IAiGenRepository, habitsRepo repositories.IHabitRepository, planTaskRepo repositories.IPlanTaskRepository, userRepo repositories.IUsersRepository, habits IHabitsService) IPlanAdoptionService {
+	return &PlanAdoptionService{
+		AiGenRepo:     aiGenRepo,
+		HabitsRepo:    habitsRepo,
+		PlanTaskRepo:  planTaskRepo,
+		UserRepo:      userRepo,
+		HabitsService: habits,
+	}
+}
+
+var (
+	planListItem     = regexp.MustCompile(`^(?:[-*+•]|\d+[.)])\s+(?:\[[ xX]?\]\s*)?(.+)$`)
+	planCheckbox     = regexp.MustCompile(`^(?:[-*+]\s+)?\[[ xX]?\]\s*(.+)$`)
+	planEmphasis     = regexp.MustCompile("\\*\\*|__|`")
+	planTimesPerWeek = regexp.MustCompile(`(?i)\b(\d+|two|three|four|five|six|twice)\s*(?:x|times?)?\s*(?:a|per|each|/)\s*week\b|(\d+)\s*ครั้ง\s*(?:ต่อ|/)\s*สัปดาห์|สัปดาห์ละ\s*(\d+)\s*ครั้ง`)
+	planDailyCue     = regexp.MustCompile(`(?i)\b(?:daily|nightly|every\s+(?:day|morning|evening|night)|each\s+(?:day|morning|evening|night)|(?:a|per)\s+day)\b|ทุกวัน|ทุกเช้า|ทุกเย็น|ทุกคืน|วันละ`)
+	planWeeklyCue    = regexp.MustCompile(`(?i)\b(?:weekly|every\s+week|each\s+week|(?:a|per)\s+week)\b|ทุกสัปดาห์|สัปดาห์ละ`)
+	planMonthlyCue   = regexp.MustCompile(`(?i)\b(?:monthly|every\s+month|each\s+month|(?:a|per)\s+month)\b|ทุกเดือน|เดือนละ`)
+	planWeekday      = regexp.MustCompile(`(?i)\b(monday|tuesday|wednesday|thursday|friday|saturday|sunday)s?\b|วัน(จันทร์|อังคาร|พุธ|พฤหัสบดี|พฤหัส|ศุกร์|เสาร์|อาทิตย์)`)
+	planNameSplit    = regexp.MustCompile(`\s*(?::|：|\s[-–—]\s)\s*`)
+)
+
+var planCountWords = map[string]int{"two": 2, "twice": 2, "three": 3, "four": 4, "five": 5, "six": 6}
+
+var planWeekdays = map[string]time.Weekday{
+	"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday, "thursday": time.Thursday,
+	"friday": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+	"จันทร์": time.Monday, "อังคาร": time.Tuesday, "พุธ": time.Wednesday, "พฤหัสบดี": time.Thursday,
+	"พฤหัส": time.Thursday, "ศุกร์": time.Friday, "เสาร์": time.Saturday, "อาทิตย์": time.Sunday,
+}
+
+// Section titles containing these words hold recurring activities or
+// one-off steps, so their items become habits or tasks even without a
+// frequency in the text.
+var (
+	planRoutineSections = []string{"habit", "routine", "daily", "weekly", "นิสัย", "กิจวัตร", "ประจำวัน", "ประจำสัปดาห์"}
+	planTaskSections    = []string{"step", "action", "task", "to do", "todo", "checklist", "milestone", "getting started", "next", "ขั้นตอน", "สิ่งที่ต้องทำ", "เริ่มต้น", "แผนปฏิบัติ"}
+)
+
+var planCategories = []struct {
+	Category string
+	Words    []string
+}{
+	{"fitness", []string{"exercise", "workout", "walk", "run", "jog", "gym", "cardio", "stretch", "yoga", "swim", "cycle", "ออกกำลัง", "เดิน", "วิ่ง", "โยคะ"}},
+	{"nutrition", []string{"diet", "nutrition", "meal", "eat", "food", "water", "breakfast", "lunch", "dinner", "snack", "อาหาร", "กิน", "ดื่มน้ำ"}},
+	{"sleep", []string{"sleep", "bed", "nap", "นอน"}},
+	{"finance", []string{"budget", "saving", "save", "expense", "money", "invest", "การเงิน", "ออม", "เงิน"}},
+	{"mindfulness", []string{"meditat", "mindful", "journal", "breath", "gratitude", "stress", "สมาธิ", "จิตใจ", "หายใจ"}},
+	{"learning", []string{"read", "study", "learn", "course", "อ่าน", "เรียน"}},
+}
+
+// ExtractPlanSuggestions finds the habits and tasks in a Markdown plan. A
+// list item becomes a habit when it states how often to do it ("daily",
+// "3 times a week", "every Monday", "ทุกวัน") or sits in a routine section,
+// and a task when it sits in a section of steps or actions or is a checkbox.
+// Other items, such as meal ideas, are left out.
+func ExtractPlanSuggestions(plan string) ([]entities.PlanHabitSuggestion, []entities.PlanTaskSuggestion) {
+	habits := []entities.PlanHabitSuggestion{}
+	tasks := []entities.PlanTaskSuggestion{}
+	seen := map[string]bool{}
+	for _, section := range splitPlanSections(plan) {
+		title := strings.ToLower(section.Title)
+		routine := mentionsAny(title, planRoutineSections)
+		steps := mentionsAny(title, planTaskSections)
+		for _, line := range section.Lines {
+			text, checkbox := planItemText(line)
+			if text == "" {
+				continue
+			}
+			rule, cue := planItemRule(text)
+			switch {
+			case cue || (routine && !checkbox):
+				if !cue {
+					rule = recurrence.Rule{Freq: recurrence.Daily, Interval: 1}
+					if strings.Contains(title, "weekly") || strings.Contains(title, "สัปดาห์") {
+						rule.Freq = recurrence.Weekly
+					}
+				}
+				id := planItemID("h", section.Title, text)
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				target := planTargetCount(text)
+				if rule.Freq != recurrence.Weekly || len(rule.ByDay) > 0 {
+					target = 1
+				}
+				habits = append(habits, entities.PlanHabitSuggestion{
+					ID:          id,
+					Name:        planItemName(text),
+					Description: text,
+					Section:     section.Title,
+					Category:    planItemCategory(section.Title + " " + text),
+					Frequency:   strings.ToLower(string(rule.Freq)),
+					Recurrence:  rule.String(),
+					TargetCount: target,
+				})
+			case steps || checkbox:
+				id := planItemID("t", section.Title, text)
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				tasks = append(tasks, entities.PlanTaskSuggestion{
+					ID:      id,
+					Title:   text,
+					Section: section.Title,
+				})
+			}
+		}
+	}
+	return habits, tasks
+}
+
+// planItemText returns a list item without its marker and emphasis, and
+// whether it was a checkbox. Lines that are not list items give "".
+func planItemText(line string) (string, bool) {
+	checkbox := planCheckbox.MatchString(line)
+	var text string
+	if m := planListItem.FindStringSubmatch(line); m != nil {
+		text = m[1]
+	} else if m := planCheckbox.FindStringSubmatch(line); m != nil {
+		text = m[1]
+	} else {
+		return "", false
+	}
+	text = strings.TrimSpace(planEmphasis.ReplaceAllString(text, ""))
+	return strings.TrimRight(text, " ."), checkbox
+}
+
+// planItemRule reads how often the item is to be done and reports whether
+// it said.
+func planItemRule(text string) (recurrence.Rule, bool) {
+	days := []recurrence.WeekdayNum{}
+	seen := map[time.Weekday]bool{}
+	for _, m := range planWeekday.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(m[1])
+		if name == "" {
+			name = m[2]
+		}
+		if day, ok := planWeekdays[name]; ok && !seen[day] {
+			seen[day] = true
+			days = append(days, recurrence.WeekdayNum{Day: day})
+		}
+	}
+	switch {
+	case len(days) > 0:
+		return recurrence.Rule{Freq: recurrence.Weekly, Interval: 1, ByDay: days}, true
+	case planDailyCue.MatchString(text):
+		return recurrence.Rule{Freq: recurrence.Daily, Interval: 1}, true
+	case planTimesPerWeek.MatchString(text), planWeeklyCue.MatchString(text):
+		return recurrence.Rule{Freq: recurrence.Weekly, Interval: 1}, true
+	case planMonthlyCue.MatchString(text):
+		return recurrence.Rule{Freq: recurrence.Monthly, Interval: 1}, true
+	}
+	return recurrence.Rule{}, false
+}
+
+// planTargetCount reads "3 times a week" as a target of 3.
+func planTargetCount(text string) int {
+	m := planTimesPerWeek.FindStringSubmatch(text)
+	if m == nil {
+		return 1
+	}
+	for _, group := range m[1:] {
+		if group == "" {
+			continue
+		}
+		if count, ok := planCountWords[strings.ToLower(group)]; ok {
+			return count
+		}
+		if count, err := strconv.Atoi(group); err == nil && count >= 1 && count <= 7 {
+			return count
+		}
+	}
+	return 1
+}
+
+// planItemName is the label before a colon or dash, e.g. "Morning walk" in
+// "Morning walk: 30 minutes every day", or the text cut to 80 characters.
+func planItemName(text string) string {
+	if parts := planNameSplit.Split(text, 2); len(parts) == 2 {
+		if length := utf8.RuneCountInString(parts[0]); length >= 3 && length <= 60 {
+			return parts[0]
+		}
+	}
+	if utf8.RuneCountInString(text) <= 80 {
+		return text
+	}
+	return string([]rune(text)[:79]) + "…"
+}
+
+func planItemCategory(text string) string {
+	text = strings.ToLower(text)
+	for _, category := range planCategories {
+		if mentionsAny(text, category.Words) {
+			return category.Category
+		}
+	}
+	return ""
+}
+
+// planItemID is derived from the section and the item, so it stays the same
+// every time the same plan is read.
+func planItemID(kind string, section string, text string) string {
+	sum := sha1.Sum([]byte(sectionKey(section) + "\n" + strings.ToLower(text)))
+	return kind + "-" + hex.EncodeToString(sum[:])[:10]
+}
+
+// mentionsAny reports whether text contains any of words, also inside
+// longer words, which suits word stems and Thai.
+func mentionsAny(text string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sv *PlanAdoptionService) SuggestFromPlan(planID string) (*entities.PlanSuggestions, error) {
+	plan, err := sv.AiGenRepo.GetGenGoalByID(planID)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> SuggestFromPlan: %s \n", err)
+		return nil, err
+	}
+	habits, tasks := ExtractPlanSuggestions(plan.Generated_Plan)
+	adoptedHabits, adoptedTasks, err := sv.adopted(plan)
+	if err != nil {
+		return nil, err
+	}
+	for i := range habits {
+		if habit, ok := adoptedHabits[habits[i].ID]; ok {
+			habits[i].Adopted = true
+			habits[i].HabitID = &habit.ID
+		}
+	}
+	for i := range tasks {
+		if task, ok := adoptedTasks[tasks[i].ID]; ok {
+			tasks[i].Adopted = true
+			tasks[i].TaskID = &task.ID
+		}
+	}
+	return &entities.PlanSuggestions{PlanID: plan.ID, Habits: habits, Tasks: tasks}, nil
+}
+
+// adopted returns the plan's adopted habits and tasks by suggestion ID.
+func (sv *PlanAdoptionService) adopted(plan *entities.GeneratedPlan) (map[string]entities.HabitModel, map[string]entities.PlanTaskModel, error) {
+	habits, err := sv.HabitsRepo.GetHabitsByUserID(plan.UserID)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> adopted: %s \n", err)
+		return nil, nil, err
+	}
+	tasks, err := sv.PlanTaskRepo.GetTasksByPlanID(plan.ID)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> adopted: %s \n", err)
+		return nil, nil, err
+	}
+	habitsByItem := map[string]entities.HabitModel{}
+	for _, habit := range *habits {
+		if habit.PlanID != nil && *habit.PlanID == plan.ID && habit.PlanItemID != "" {
+			habitsByItem[habit.PlanItemID] = habit
+		}
+	}
+	tasksByItem := map[string]entities.PlanTaskModel{}
+	for _, task := range *tasks {
+		tasksByItem[task.PlanItemID] = task
+	}
+	return habitsByItem, tasksByItem, nil
+}
+
+func (sv *PlanAdoptionService) AdoptFromPlan(planID string, body entities.PlanAdoptBody) (*entities.PlanAdoption, error) {
+	if len(body.Habits) == 0 && len(body.Tasks) == 0 {
+		return nil, invalidf("choose at least one habit or task to adopt")
+	}
+	plan, err := sv.AiGenRepo.GetGenGoalByID(planID)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> AdoptFromPlan: %s \n", err)
+		return nil, err
+	}
+	habitSuggestions, taskSuggestions := ExtractPlanSuggestions(plan.Generated_Plan)
+	habitsByID := map[string]entities.PlanHabitSuggestion{}
+	for _, habit := range habitSuggestions {
+		habitsByID[habit.ID] = habit
+	}
+	tasksByID := map[string]entities.PlanTaskSuggestion{}
+	for _, task := range taskSuggestions {
+		tasksByID[task.ID] = task
+	}
+
+	// Everything is checked before anything is created.
+	newHabits := []entities.HabitResponse{}
+	for _, item := range body.Habits {
+		suggestion, ok := habitsByID[item.ID]
+		if !ok {
+			return nil, invalidf("plan has no habit suggestion %q", item.ID)
+		}
+		habit := entities.HabitResponse{
+			Name:        firstNonEmpty(item.Name, suggestion.Name),
+			Frequency:   suggestion.Frequency,
+			Recurrence:  firstNonEmpty(item.Recurrence, suggestion.Recurrence),
+			Description: suggestion.Description,
+			TargetCount: suggestion.TargetCount,
+			Category:    suggestion.Category,
+			PlanID:      &plan.ID,
+			PlanItemID:  suggestion.ID,
+		}
+		if item.TargetCount != 0 {
+			habit.TargetCount = item.TargetCount
+		}
+		if err := ValidateHabit(&habit); err != nil {
+			return nil, invalidf("habit %q: %s", item.ID, err)
+		}
+		newHabits = append(newHabits, habit)
+	}
+	newTasks := []entities.PlanTaskResponse{}
+	for _, item := range body.Tasks {
+		suggestion, ok := tasksByID[item.ID]
+		if !ok {
+			return nil, invalidf("plan has no task suggestion %q", item.ID)
+		}
+		task := entities.PlanTaskResponse{
+			UserID:     plan.UserID,
+			PlanID:     plan.ID,
+			PlanItemID: suggestion.ID,
+			Title:      firstNonEmpty(item.Name, suggestion.Title),
+			Section:    suggestion.Section,
+			Status:     PlanTaskTodo,
+		}
+		if item.DueDate != "" {
+			due, err := time.Parse(habitDateLayout, item.DueDate)
+			if err != nil {
+				return nil, invalidf("task %q: due_date must be YYYY-MM-DD", item.ID)
+			}
+			date := due.Format(habitDateLayout)
+			task.DueDate = &date
+		}
+		newTasks = append(newTasks, task)
+	}
+
+	adoptedHabits, adoptedTasks, err := sv.adopted(plan)
+	if err != nil {
+		return nil, err
+	}
+	result := &entities.PlanAdoption{PlanID: plan.ID, Habits: []entities.HabitModel{}, Tasks: []entities.PlanTaskModel{}}
+	created := map[string]bool{}
+	for _, habit := range newHabits {
+		if existing, ok := adoptedHabits[habit.PlanItemID]; ok {
+			result.Habits = append(result.Habits, existing)
+			continue
+		}
+		if err := sv.HabitsService.CreateHabit(plan.UserID, habit); err != nil {
+			fiberlog.Errorf("PlanAdoptionService -> AdoptFromPlan: %s \n", err)
+			return nil, err
+		}
+		created[habit.PlanItemID] = true
+	}
+	if len(created) > 0 {
+		adoptedHabits, _, err = sv.adopted(plan)
+		if err != nil {
+			return nil, err
+		}
+		for _, habit := range newHabits {
+			if existing, ok := adoptedHabits[habit.PlanItemID]; ok && created[habit.PlanItemID] {
+				result.Habits = append(result.Habits, existing)
+			}
+		}
+	}
+	now := time.Now().Add(7 * time.Hour)
+	for _, task := range newTasks {
+		if existing, ok := adoptedTasks[task.PlanItemID]; ok {
+			result.Tasks = append(result.Tasks, existing)
+			continue
+		}
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		inserted, err := sv.PlanTaskRepo.InsertTask(task)
+		if err != nil {
+			fiberlog.Errorf("PlanAdoptionService -> AdoptFromPlan: %s \n", err)
+			return nil, err
+		}
+		adoptedTasks[task.PlanItemID] = *inserted
+		result.Tasks = append(result.Tasks, *inserted)
+	}
+	return result, nil
+}
+
+// GetPlanAdherence counts, for each habit adopted from the plan, the
+// occurrences since it was created that are over or already completed, and
+// the plan's tasks that are done. Archived habits are left out; paused
+// occurrences are not expected.
+func (sv *PlanAdoptionService) GetPlanAdherence(planID string) (*entities.PlanAdherence, error) {
+	plan, err := sv.AiGenRepo.GetGenGoalByID(planID)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> GetPlanAdherence: %s \n", err)
+		return nil, err
+	}
+	adoptedHabits, adoptedTasks, err := sv.adopted(plan)
+	if err != nil {
+		return nil, err
+	}
+	checkIns, err := sv.HabitsRepo.GetCheckInsByUserID(plan.UserID)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> GetPlanAdherence: %s \n", err)
+		return nil, err
+	}
+	byHabit := map[int][]entities.HabitCheckInModel{}
+	for _, checkIn := range *checkIns {
+		byHabit[checkIn.HabitID] = append(byHabit[checkIn.HabitID], checkIn)
+	}
+	location := userLocation(sv.UserRepo, plan.UserID)
+	today := localDate(time.Now(), location)
+	todayDate := today.Format(habitDateLayout)
+
+	habitSuggestions, taskSuggestions := ExtractPlanSuggestions(plan.Generated_Plan)
+	adherence := &entities.PlanAdherence{
+		PlanID:             plan.ID,
+		Habits:             []entities.PlanHabitAdherence{},
+		SuggestionsTotal:   len(habitSuggestions) + len(taskSuggestions),
+		SuggestionsAdopted: len(adoptedHabits) + len(adoptedTasks),
+	}
+	expected, completed := 0, 0
+	for _, habit := range adoptedHabits {
+		if habitStatus(habit) == HabitStatusArchived {
+			continue
+		}
+		item := entities.PlanHabitAdherence{HabitID: habit.ID, Name: habit.Name, Status: habitStatus(habit)}
+		start := habitStartDate(habit, byHabit[habit.ID], today, location)
+		for _, occurrence := range HabitOccurrences(habit, byHabit[habit.ID], start, today.AddDate(0, 0, 1), today, location) {
+			if !occurrence.Completed && (occurrence.Paused || occurrence.End >= todayDate) {
+				continue
+			}
+			item.Expected++
+			if occurrence.Completed {
+				item.Completed++
+			}
+		}
+		if item.Expected > 0 {
+			item.Rate = float64(item.Completed) / float64(item.Expected)
+		}
+		expected += item.Expected
+		completed += item.Completed
+		adherence.Habits = append(adherence.Habits, item)
+	}
+	for _, task := range adoptedTasks {
+		adherence.TasksTotal++
+		if task.Status == PlanTaskDone {
+			adherence.TasksDone++
+		} else if task.DueDate != nil && *task.DueDate < todayDate {
+			adherence.TasksOverdue++
+		}
+	}
+	if expected > 0 {
+		adherence.HabitRate = float64(completed) / float64(expected)
+	}
+	if adherence.TasksTotal > 0 {
+		adherence.TaskRate = float64(adherence.TasksDone) / float64(adherence.TasksTotal)
+	}
+	if total := expected + adherence.TasksTotal; total > 0 {
+		adherence.Adherence = float64(completed+adherence.TasksDone) / float64(total)
+	}
+	return adherence, nil
+}
+
+func (sv *PlanAdoptionService) ListTasks(userID string, planID string, status string) (*[]entities.PlanTaskModel, error) {
+	if status != "" && status != PlanTaskTodo && status != PlanTaskDone {
+		return nil, invalidf("status must be todo or done")
+	}
+	data, err := sv.PlanTaskRepo.GetTasksByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> ListTasks: %s \n", err)
+		return nil, err
+	}
+	tasks := []entities.PlanTaskModel{}
+	for _, task := range *data {
+		if (planID != "" && task.PlanID != planID) || (status != "" && task.Status != status) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return &tasks, nil
+}
+
+func (sv *PlanAdoptionService) UpdateTaskStatus(userID string, taskID string, status string) (*entities.PlanTaskModel, error) {
+	if status != PlanTaskTodo && status != PlanTaskDone {
+		return nil, invalidf("status must be todo or done")
+	}
+	if _, err := sv.userTask(userID, taskID); err != nil {
+		return nil, err
+	}
+	now := time.Now().Add(7 * time.Hour)
+	update := entities.PlanTaskStatusUpdate{Status: status, UpdatedAt: now}
+	if status == PlanTaskDone {
+		update.CompletedAt = &now
+	}
+	task, err := sv.PlanTaskRepo.UpdateTaskStatus(taskID, update)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> UpdateTaskStatus: %s \n", err)
+		return nil, err
+	}
+	return task, nil
+}
+
+func (sv *PlanAdoptionService) DeleteTask(userID string, taskID string) error {
+	if _, err := sv.userTask(userID, taskID); err != nil {
+		return err
+	}
+	if err := sv.PlanTaskRepo.DeleteTask(taskID); err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> DeleteTask: %s \n", err)
+		return err
+	}
+	return nil
+}
+
+func (sv *PlanAdoptionService) userTask(userID string, taskID string) (*entities.PlanTaskModel, error) {
+	task, err := sv.PlanTaskRepo.GetTaskByID(taskID)
+	if err != nil {
+		fiberlog.Errorf("PlanAdoptionService -> userTask: %s \n", err)
+		return nil, err
+	}
+	if task.UserID != userID {
+		return nil, fmt.Errorf("task with ID %s not found", taskID)
+	}
+	return task, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"reflect"
+	"testing"
+)
+
+const englishPlanFixture = `Here is your plan to get fitter and sleep better.
+
+## Daily Habits
+- **Morning walk**: 30 minutes every day
+- Drink 8 glasses of water
+- [ ] Buy a water bottle
+
+## Weekly Routine
+- Yoga class - Tuesday and Thursday
+- Strength training 3 times a week
+- Review your budget
+
+## Getting Started
+1. Sign up for a gym membership.
+2. Book a check-up with your doctor
+
+## Meal Ideas
+- Oatmeal with berries
+- Grilled chicken salad
+
+**Monthly**
+- Check your savings every month
+- Go for a long run twice a week
+`
+
+const thaiPlanFixture = `## กิจวัตรประจำวัน
+- เดินเร็ว 30 นาที ทุกเช้า
+- นั่งสมาธิ 10 นาที
+
+## ออกกำลังกาย
+- วิ่ง 3 ครั้งต่อสัปดาห์
+- โยคะ สัปดาห์ละ 2 ครั้ง
+- ว่ายน้ำวันเสาร์
+
+## ขั้นตอนเริ่มต้น
+- ซื้อรองเท้าวิ่ง
+- ดื่มน้ำวันละ 8 แก้ว
+`
+
+// describeSuggestions flattens suggestions to one line each: a habit's
+// name, section, rule, target and category, and a task's title and section.
+func describeSuggestions(habits []entities.PlanHabitSuggestion, tasks []entities.PlanTaskSuggestion) []string {
+	described := []string{}
+	for _, habit := range habits {
+		described = append(described, fmt.Sprintf("habit %s | %s | %s | %d | %s", habit.Name, habit.Section, habit.Recurrence, habit.TargetCount, habit.Category))
+	}
+	for _, task := range tasks {
+		described = append(described, fmt.Sprintf("task %s | %s", task.Title, task.Section))
+	}
+	return described
+}
+
+func TestExtractPlanSuggestions(t *testing.T) {
+	tests := []struct {
+		name string
+		plan string
+		want []string
+	}{
+		{"english", englishPlanFixture, []string{
+			"habit Morning walk | Daily Habits | FREQ=DAILY | 1 | fitness",
+			"habit Drink 8 glasses of water | Daily Habits | FREQ=DAILY | 1 | nutrition",
+			"habit Yoga class | Weekly Routine | FREQ=WEEKLY;BYDAY=TU,TH | 1 | fitness",
+			"habit Strength training 3 times a week | Weekly Routine | FREQ=WEEKLY | 3 | ",
+			"habit Review your budget | Weekly Routine | FREQ=WEEKLY | 1 | finance",
+			"habit Check your savings every month | Monthly | FREQ=MONTHLY | 1 | finance",
+			"habit Go for a long run twice a week | Monthly | FREQ=WEEKLY | 2 | fitness",
+			"task Buy a water bottle | Daily Habits",
+			"task Sign up for a gym membership | Getting Started",
+			"task Book a check-up with your doctor | Getting Started",
+		}},
+		{"thai", thaiPlanFixture, []string{
+			"habit เดินเร็ว 30 นาที ทุกเช้า | กิจวัตรประจำวัน | FREQ=DAILY | 1 | fitness",
+			"habit นั่งสมาธิ 10 นาที | กิจวัตรประจำวัน | FREQ=DAILY | 1 | mindfulness",
+			"habit วิ่ง 3 ครั้งต่อสัปดาห์ | ออกกำลังกาย | FREQ=WEEKLY | 3 | fitness",
+			"habit โยคะ สัปดาห์ละ 2 ครั้ง | ออกกำลังกาย | FREQ=WEEKLY | 2 | fitness",
+			"habit ว่ายน้ำวันเสาร์ | ออกกำลังกาย | FREQ=WEEKLY;BYDAY=SA | 1 | fitness",
+			"habit ดื่มน้ำวันละ 8 แก้ว | ขั้นตอนเริ่มต้น | FREQ=DAILY | 1 | nutrition",
+			"task ซื้อรองเท้าวิ่ง | ขั้นตอนเริ่มต้น",
+		}},
+		{"no lists", "Sleep more and stress less.", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describeSuggestions(ExtractPlanSuggestions(tt.plan))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractPlanSuggestions() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanItemRuleAndTarget(t *testing.T) {
+	tests := []struct {
+		text   string
+		rule   string
+		cue    bool
+		target int
+	}{
+		{"Stretch every morning", "FREQ=DAILY", true, 1},
+		{"Journal nightly", "FREQ=DAILY", true, 1},
+		{"Swim 2x per week", "FREQ=WEEKLY", true, 2},
+		{"Run five times a week", "FREQ=WEEKLY", true, 5},
+		{"Cook at home weekly", "FREQ=WEEKLY", true, 1},
+		{"Long ride on Saturdays and Sundays", "FREQ=WEEKLY;BYDAY=SA,SU", true, 1},
+		{"Pay off the card each month", "FREQ=MONTHLY", true, 1},
+		{"อ่านหนังสือทุกคืน", "FREQ=DAILY", true, 1},
+		{"ปั่นจักรยานวันจันทร์และวันพุธ", "FREQ=WEEKLY;BYDAY=MO,WE", true, 1},
+		{"ออมเงินทุกเดือน", "FREQ=MONTHLY", true, 1},
+		{"Oatmeal with berries", "", false, 1},
+	}
+	for _, tt := range tests {
+		rule, cue := planItemRule(tt.text)
+		if cue != tt.cue || (cue && rule.String() != tt.rule) {
+			t.Errorf("planItemRule(%q) = %q, %v, want %q, %v", tt.text, rule.String(), cue, tt.rule, tt.cue)
+		}
+		if got := planTargetCount(tt.text); got != tt.target {
+			t.Errorf("planTargetCount(%q) = %d, want %d", tt.text, got, tt.target)
+		}
+	}
+}
+
+// Adopted habits and tasks are matched to suggestions by ID, so an ID must
+// not change between reads of a plan or with cosmetic edits to it. Changing
+// these values breaks the link to everything adopted so far.
+func TestPlanItemIDIsStable(t *testing.T) {
+	habits, tasks := ExtractPlanSuggestions(englishPlanFixture)
+	if habits[0].ID != "h-1fdc5afcc3" || habits[2].ID != "h-761d1292a9" || tasks[1].ID != "t-49c9a88a69" {
+		t.Errorf("IDs = %s, %s, %s, want h-1fdc5afcc3, h-761d1292a9, t-49c9a88a69", habits[0].ID, habits[2].ID, tasks[1].ID)
+	}
+	thaiHabits, _ := ExtractPlanSuggestions(thaiPlanFixture)
+	if thaiHabits[0].ID != "h-5a81de3b05" {
+		t.Errorf("Thai ID = %s, want h-5a81de3b05", thaiHabits[0].ID)
+	}
+
+	want := habits[0].ID
+	for _, plan := range []string{
+		"## Daily Habits\n- Morning walk: 30 minutes every day",
+		"### daily habits ###\n* **Morning walk**: 30 minutes every day.",
+		"**Daily Habits:**\n1. Morning walk: 30 minutes every day",
+		"## Daily habits!\n+ `Morning walk`: 30 Minutes Every Day",
+	} {
+		if got, _ := ExtractPlanSuggestions(plan); len(got) != 1 || got[0].ID != want {
+			t.Errorf("ExtractPlanSuggestions(%q) = %+v, want ID %s", plan, got, want)
+		}
+	}
+	for _, plan := range []string{
+		"## Morning Routine\n- Morning walk: 30 minutes every day",
+		"## Daily Habits\n- Morning walk: 45 minutes every day",
+	} {
+		if got, _ := ExtractPlanSuggestions(plan); len(got) != 1 || got[0].ID == want {
+			t.Errorf("ExtractPlanSuggestions(%q) = %+v, want a different ID", plan, got)
+		}
+	}
+}
+
+func TestExtractPlanSuggestionsSkipsRepeatedItems(t *testing.T) {
+	plan := "## Daily Habits\n- Walk every day\n- **Walk every day**\n\n## Steps\n- Buy shoes\n- Buy shoes."
+	habits, tasks := ExtractPlanSuggestions(plan)
+	if len(habits) != 1 || len(tasks) != 1 {
+		t.Errorf("ExtractPlanSuggestions() = %d habits, %d tasks, want 1 and 1", len(habits), len(tasks))
+	}
+}
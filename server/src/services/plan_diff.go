@@ -0,0 +1,167 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"regexp"
+	"strings"
+)
+
+// Section statuses reported by DiffPlanSections.
+const (
+	SectionAdded     = "added"
+	SectionRemoved   = "removed"
+	SectionChanged   = "changed"
+	SectionUnchanged = "unchanged"
+)
+
+// Text before the first heading is reported under this title.
+const planIntroSection = "Introduction"
+
+var (
+	markdownHeading = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*$`)
+	boldHeading     = regexp.MustCompile(`^\*\*(.+?)\*\*:?$`)
+	headingNoise    = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+type planSection struct {
+	Title string
+	Lines []string
+}
+
+// DiffPlanSections compares two generated plans section by section. Plans are
+// Markdown, so a section starts at a "#" heading or at a line that is bold
+// text only. Sections are matched by title, ignoring case and punctuation.
+func DiffPlanSections(before string, after string) entities.PlanDiff {
+	from := splitPlanSections(before)
+	to := splitPlanSections(after)
+
+	fromByKey := map[string]planSection{}
+	for _, section := range from {
+		fromByKey[sectionKey(section.Title)] = section
+	}
+	toKeys := map[string]bool{}
+
+	diff := entities.PlanDiff{Sections: []entities.PlanSectionDiff{}}
+	for _, section := range to {
+		key := sectionKey(section.Title)
+		toKeys[key] = true
+		previous, ok := fromByKey[key]
+		if !ok {
+			diff.Added++
+			diff.Sections = append(diff.Sections, entities.PlanSectionDiff{
+				Title:  section.Title,
+				Status: SectionAdded,
+				After:  strings.Join(section.Lines, "\n"),
+			})
+			continue
+		}
+		changes := diffLines(previous.Lines, section.Lines)
+		item := entities.PlanSectionDiff{
+			Title:  section.Title,
+			Before: strings.Join(previous.Lines, "\n"),
+			After:  strings.Join(section.Lines, "\n"),
+		}
+		if len(changes) == 0 {
+			diff.Unchanged++
+			item.Status = SectionUnchanged
+		} else {
+			diff.Changed++
+			item.Status = SectionChanged
+			item.Changes = changes
+		}
+		diff.Sections = append(diff.Sections, item)
+	}
+	for _, section := range from {
+		if toKeys[sectionKey(section.Title)] {
+			continue
+		}
+		diff.Removed++
+		diff.Sections = append(diff.Sections, entities.PlanSectionDiff{
+			Title:  section.Title,
+			Status: SectionRemoved,
+			Before: strings.Join(section.Lines, "\n"),
+		})
+	}
+	return diff
+}
+
+func splitPlanSections(plan string) []planSection {
+	sections := []planSection{}
+	current := planSection{Title: planIntroSection}
+	for _, raw := range strings.Split(strings.ReplaceAll(plan, "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		if title, ok := headingTitle(line); ok {
+			if current.Title != planIntroSection || len(current.Lines) > 0 {
+				sections = append(sections, current)
+			}
+			current = planSection{Title: title}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		current.Lines = append(current.Lines, line)
+	}
+	if current.Title != planIntroSection || len(current.Lines) > 0 {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+func headingTitle(line string) (string, bool) {
+	if m := markdownHeading.FindStringSubmatch(line); m != nil {
+		return strings.Trim(m[1], "* "), true
+	}
+	if m := boldHeading.FindStringSubmatch(line); m != nil {
+		return strings.TrimSpace(m[1]), true
+	}
+	return "", false
+}
+
+func sectionKey(title string) string {
+	return strings.TrimSpace(headingNoise.ReplaceAllString(strings.ToLower(title), " "))
+}
+
+// diffLines returns the added and removed lines of a section using the
+// longest common subsequence, so reordered or edited bullets show up as a
+// removal and an addition. An empty result means the section is unchanged.
+func diffLines(before []string, after []string) []entities.PlanLineChange {
+	n, m := len(before), len(after)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	changes := []entities.PlanLineChange{}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case before[i] == after[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			changes = append(changes, entities.PlanLineChange{Op: "removed", Text: before[i]})
+			i++
+		default:
+			changes = append(changes, entities.PlanLineChange{Op: "added", Text: after[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		changes = append(changes, entities.PlanLineChange{Op: "removed", Text: before[i]})
+	}
+	for ; j < m; j++ {
+		changes = append(changes, entities.PlanLineChange{Op: "added", Text: after[j]})
+	}
+	return changes
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/configuration"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// Plan job statuses. A job is claimed as running by exactly one worker; a
+// failed attempt goes back to queued until MaxAttempts is reached.
+const (
+	PlanJobQueued    = "queued"
+	PlanJobRunning   = "running"
+	PlanJobSucceeded = "succeeded"
+	PlanJobFailed    = "failed"
+)
+
+const (
+	planJobProgressStarted = 10
+	planJobProgressDone    = 100
+)
+
+// planJobWriteAttempts is how often the outcome of an attempt is written
+// before the job is left running for the next restart to pick up.
+const planJobWriteAttempts = 3
+
+// PlanJobConfig controls the worker pool. RetryDelay grows linearly with the
+// attempt number. PollInterval is how often the datastore is scanned for due
+// jobs, which picks up retries and anything the in-memory queue dropped.
+type PlanJobConfig struct {
+	Workers      int
+	MaxAttempts  int
+	RetryDelay   time.Duration
+	PollInterval time.Duration
+}
+
+type PlanJobService struct {
+	JobRepo      repositories.IPlanJobRepository
+	PromptRepo   repositories.IAipromptRepository
+	AiGenService IAiGenService
+	Config       PlanJobConfig
+	queue        chan string
+	// writeRetryDelay grows linearly between failed writes of an outcome.
+	writeRetryDelay time.Duration
+}
+
+type IPlanJobService interface {
+	// Enqueue queues a plan for the user's latest prompt. The job keeps that
+	// prompt, so a retry does not pick up a prompt created after it.
+	Enqueue(userID string) (*entities.PlanJobModel, error)
+	GetJob(id string) (*entities.PlanJobModel, error)
+	GetJobsByUserID(userID string) (*[]entities.PlanJobModel, error)
+	Start()
+}
+
+func NewPlanJobConfigFromEnv() PlanJobConfig {
+	return PlanJobConfig{
+		Workers:      configuration.GetEnvInt("PLAN_JOB_WORKERS", 2),
+		MaxAttempts:  configuration.GetEnvInt("PLAN_JOB_MAX_ATTEMPTS", 3),
+		RetryDelay:   time.Duration(configuration.GetEnvInt("PLAN_JOB_RETRY_SECONDS", 30)) * time.Second,
+		PollInterval: time.Duration(configuration.GetEnvInt("PLAN_JOB_POLL_SECONDS", 15)) * time.Second,
+	}
+}
+
+func NewPlanJobService(jobRepo repositories.IPlanJobRepository, promptRepo repositories.IAipromptRepository, aiGenService IAiGenService, config PlanJobConfig) IPlanJobService {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	return &PlanJobService{
+		JobRepo:         jobRepo,
+		PromptRepo:      promptRepo,
+		AiGenService:    aiGenService,
+		Config:          config,
+		queue:           make(chan string, 100),
+		writeRetryDelay: time.Second,
+	}
+}
+
+func (sv *PlanJobService) Enqueue(userID string) (*entities.PlanJobModel, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	prompt, err := sv.PromptRepo.GetPromptByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("PlanJobService -> Enqueue: %s \n", err)
+		return nil, err
+	}
+	now := time.Now().Add(7 * time.Hour)
+	job, err := sv.JobRepo.InsertJob(entities.PlanJobResponse{
+		UserID:      userID,
+		PromptID:    prompt.ID,
+		Status:      PlanJobQueued,
+		MaxAttempts: sv.Config.MaxAttempts,
+		NextRunAt:   now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		fiberlog.Errorf("PlanJobService -> Enqueue: %s \n", err)
+		fmt.Println("Error enqueuing plan job:", err)
+		return nil, err
+	}
+	sv.push(job.ID)
+	return job, nil
+}
+
+func (sv *PlanJobService) GetJob(id string) (*entities.PlanJobModel, error) {
+	job, err := sv.JobRepo.GetJobByID(id)
+	if err != nil {
+		fiberlog.Errorf("PlanJobService -> GetJob: %s \n", err)
+		return nil, err
+	}
+	return job, nil
+}
+
+func (sv *PlanJobService) GetJobsByUserID(userID string) (*[]entities.PlanJobModel, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	jobs, err := sv.JobRepo.GetJobsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("PlanJobService -> GetJobsByUserID: %s \n", err)
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Start resumes jobs left over from the previous run and starts the workers
+// and the poller. Jobs still marked running were interrupted by the restart;
+// they go back to the queue and the interrupted attempt counts as failed.
+// This assumes a single server process works the plan_jobs table.
+func (sv *PlanJobService) Start() {
+	sv.resumeInterrupted()
+	for i := 0; i < sv.Config.Workers; i++ {
+		go sv.worker()
+	}
+	go sv.poll()
+}
+
+func (sv *PlanJobService) resumeInterrupted() {
+	now := time.Now().Add(7 * time.Hour)
+	jobs, err := sv.JobRepo.GetJobsByStatus([]string{PlanJobRunning}, now)
+	if err != nil {
+		fiberlog.Errorf("PlanJobService -> resumeInterrupted: %s \n", err)
+		return
+	}
+	for _, job := range *jobs {
+		update := jobUpdate(job)
+		update.UpdatedAt = now
+		update.Error = "interrupted by server restart"
+		if job.Attempts >= job.MaxAttempts {
+			update.Status = PlanJobFailed
+			update.FinishedAt = &now
+		} else {
+			update.Status = PlanJobQueued
+			update.Progress = 0
+			update.NextRunAt = now
+		}
+		if _, err := sv.JobRepo.UpdateJob(job.ID, PlanJobRunning, update); err != nil {
+			fiberlog.Errorf("PlanJobService -> resumeInterrupted: %s \n", err)
+		}
+	}
+}
+
+func (sv *PlanJobService) worker() {
+	for id := range sv.queue {
+		sv.run(id)
+	}
+}
+
+func (sv *PlanJobService) poll() {
+	sv.enqueueDue()
+	ticker := time.NewTicker(sv.Config.PollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		sv.enqueueDue()
+	}
+}
+
+func (sv *PlanJobService) enqueueDue() {
+	jobs, err := sv.JobRepo.GetJobsByStatus([]string{PlanJobQueued}, time.Now().Add(7*time.Hour))
+	if err != nil {
+		fiberlog.Errorf("PlanJobService -> enqueueDue: %s \n", err)
+		return
+	}
+	for _, job := range *jobs {
+		sv.push(job.ID)
+	}
+}
+
+// push never blocks the caller; a job that does not fit in the queue stays
+// queued in the datastore and the poller picks it up.
+func (sv *PlanJobService) push(id string) {
+	select {
+	case sv.queue <- id:
+	default:
+	}
+}
+
+func (sv *PlanJobService) run(id string) {
+	job, err := sv.JobRepo.GetJobByID(id)
+	if err != nil {
+		fiberlog.Errorf("PlanJobService -> run: %s \n", err)
+		return
+	}
+	now := time.Now().Add(7 * time.Hour)
+	if job.Status != PlanJobQueued || job.NextRunAt.After(now) {
+		return
+	}
+	update := jobUpdate(*job)
+	update.Status = PlanJobRunning
+	update.Progress = planJobProgressStarted
+	update.Attempts = job.Attempts + 1
+	update.UpdatedAt = now
+	update.StartedAt = &now
+	claimed, err := sv.JobRepo.UpdateJob(job.ID, PlanJobQueued, update)
+	if err != nil || !claimed {
+		return
+	}
+
+	var plan *entities.GeneratedPlan
+	var runErr error
+	if job.PromptID == "" {
+		// Queued before jobs kept their prompt.
+		plan, runErr = sv.AiGenService.GenerateLifeGoal(job.UserID)
+	} else {
+		plan, runErr = sv.AiGenService.GenerateLifeGoalFromPrompt(job.UserID, job.PromptID)
+	}
+	finished := time.Now().Add(7 * time.Hour)
+	update.UpdatedAt = finished
+	switch {
+	case runErr == nil:
+		update.Status = PlanJobSucceeded
+		update.Progress = planJobProgressDone
+		update.PlanID = &plan.ID
+		update.Error = ""
+		update.FinishedAt = &finished
+	case update.Attempts < job.MaxAttempts:
+		fiberlog.Errorf("PlanJobService -> run: attempt %d of job %s: %s \n", update.Attempts, job.ID, runErr)
+		delay := sv.Config.RetryDelay * time.Duration(update.Attempts)
+		update.Status = PlanJobQueued
+		update.Progress = 0
+		update.Error = runErr.Error()
+		update.NextRunAt = finished.Add(delay)
+		time.AfterFunc(delay, func() { sv.push(job.ID) })
+	default:
+		fiberlog.Errorf("PlanJobService -> run: job %s failed: %s \n", job.ID, runErr)
+		update.Status = PlanJobFailed
+		update.Error = runErr.Error()
+		update.FinishedAt = &finished
+	}
+	sv.finish(job.ID, update)
+}
+
+// finish writes the outcome of an attempt, retrying a failed write so the job
+// does not stay running until the next restart.
+func (sv *PlanJobService) finish(id string, update entities.PlanJobUpdate) {
+	for attempt := 1; ; attempt++ {
+		_, err := sv.JobRepo.UpdateJob(id, PlanJobRunning, update)
+		if err == nil {
+			return
+		}
+		if attempt == planJobWriteAttempts {
+			fiberlog.Errorf("PlanJobService -> finish: job %s stays running until the next restart: %s \n", id, err)
+			return
+		}
+		fiberlog.Errorf("PlanJobService -> finish: write %d of job %s: %s \n", attempt, id, err)
+		time.Sleep(sv.writeRetryDelay * time.Duration(attempt))
+	}
+}
+
+func jobUpdate(job entities.PlanJobModel) entities.PlanJobUpdate {
+	return entities.PlanJobUpdate{
+		Status:     job.Status,
+		Progress:   job.Progress,
+		Attempts:   job.Attempts,
+		PlanID:     job.PlanID,
+		Error:      job.Error,
+		NextRunAt:  job.NextRunAt,
+		UpdatedAt:  job.UpdatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"testing"
+	"time"
+)
+
+type fakePlanJobRepository struct {
+	jobs map[string]entities.PlanJobModel
+	// failWrites is how many outcome writes fail before one succeeds.
+	failWrites int
+	writes     int
+}
+
+func (repo *fakePlanJobRepository) InsertJob(data entities.PlanJobResponse) (*entities.PlanJobModel, error) {
+	job := entities.PlanJobModel{
+		ID:          fmt.Sprintf("job-%d", len(repo.jobs)+1),
+		UserID:      data.UserID,
+		PromptID:    data.PromptID,
+		Status:      data.Status,
+		MaxAttempts: data.MaxAttempts,
+		NextRunAt:   data.NextRunAt,
+		CreatedAt:   data.CreatedAt,
+		UpdatedAt:   data.UpdatedAt,
+	}
+	repo.jobs[job.ID] = job
+	return &job, nil
+}
+
+func (repo *fakePlanJobRepository) GetJobByID(id string) (*entities.PlanJobModel, error) {
+	job, ok := repo.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("plan job %s not found", id)
+	}
+	return &job, nil
+}
+
+func (repo *fakePlanJobRepository) GetJobsByUserID(userID string) (*[]entities.PlanJobModel, error) {
+	return &[]entities.PlanJobModel{}, nil
+}
+
+func (repo *fakePlanJobRepository) GetJobsByStatus(statuses []string, dueBefore time.Time) (*[]entities.PlanJobModel, error) {
+	return &[]entities.PlanJobModel{}, nil
+}
+
+func (repo *fakePlanJobRepository) UpdateJob(id string, fromStatus string, data entities.PlanJobUpdate) (bool, error) {
+	job, ok := repo.jobs[id]
+	if !ok || job.Status != fromStatus {
+		return false, nil
+	}
+	if fromStatus == PlanJobRunning {
+		repo.writes++
+		if repo.failWrites > 0 {
+			repo.failWrites--
+			return false, fmt.Errorf("connection reset")
+		}
+	}
+	job.Status = data.Status
+	job.Progress = data.Progress
+	job.Attempts = data.Attempts
+	job.PlanID = data.PlanID
+	job.Error = data.Error
+	job.NextRunAt = data.NextRunAt
+	job.UpdatedAt = data.UpdatedAt
+	job.StartedAt = data.StartedAt
+	job.FinishedAt = data.FinishedAt
+	repo.jobs[id] = job
+	return true, nil
+}
+
+type fakePlanGenerator struct {
+	IAiGenService
+	prompts []string
+}
+
+func (generator *fakePlanGenerator) GenerateLifeGoal(id string) (*entities.GeneratedPlan, error) {
+	generator.prompts = append(generator.prompts, "latest")
+	return &entities.GeneratedPlan{ID: "plan"}, nil
+}
+
+func (generator *fakePlanGenerator) GenerateLifeGoalFromPrompt(id string, promptID string) (*entities.GeneratedPlan, error) {
+	generator.prompts = append(generator.prompts, promptID)
+	return &entities.GeneratedPlan{ID: "plan"}, nil
+}
+
+func TestPlanJobRunsOnTheQueuedPrompt(t *testing.T) {
+	jobs := &fakePlanJobRepository{jobs: map[string]entities.PlanJobModel{}}
+	generator := &fakePlanGenerator{}
+	service := NewPlanJobService(jobs, &fakePromptRepository{}, generator, PlanJobConfig{MaxAttempts: 3}).(*PlanJobService)
+
+	job, err := service.Enqueue("user")
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if job.PromptID != "prompt" {
+		t.Fatalf("Enqueue() prompt = %q, want the latest prompt", job.PromptID)
+	}
+	service.run(job.ID)
+	if len(generator.prompts) != 1 || generator.prompts[0] != "prompt" {
+		t.Errorf("generated from %v, want [prompt]", generator.prompts)
+	}
+	if got := jobs.jobs[job.ID]; got.Status != PlanJobSucceeded || got.PlanID == nil || *got.PlanID != "plan" {
+		t.Errorf("job = %+v, want succeeded with plan", got)
+	}
+}
+
+func TestPlanJobRetriesTheOutcomeWrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		failWrites int
+		wantStatus string
+		wantWrites int
+	}{
+		{"first write works", 0, PlanJobSucceeded, 1},
+		{"write fails once", 1, PlanJobSucceeded, 2},
+		{"every write fails", planJobWriteAttempts, PlanJobRunning, planJobWriteAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := &fakePlanJobRepository{jobs: map[string]entities.PlanJobModel{}, failWrites: tt.failWrites}
+			service := &PlanJobService{JobRepo: jobs, PromptRepo: &fakePromptRepository{}, AiGenService: &fakePlanGenerator{}, Config: PlanJobConfig{MaxAttempts: 3}, queue: make(chan string, 1)}
+			job, err := service.Enqueue("user")
+			if err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+			service.run(job.ID)
+			if got := jobs.jobs[job.ID].Status; got != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got, tt.wantStatus)
+			}
+			if jobs.writes != tt.wantWrites {
+				t.Errorf("outcome written %d times, want %d", jobs.writes, tt.wantWrites)
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/domain/aimodel"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// Categories of personal data that can be redacted before a request leaves
+// for the model.
+const (
+	PIIName       = "name"
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIINationalID = "national_id"
+	PIILocation   = "location"
+)
+
+var piiCategories = []string{PIIEmail, PIINationalID, PIIPhone, PIILocation, PIIName}
+
+// PrivacyConfig selects the categories redacted for each tenant. Users
+// without a tenant, or with a tenant that is not listed, get Default. A
+// tenant listed with an empty array sends everything verbatim.
+type PrivacyConfig struct {
+	Default []string            `json:"default"`
+	Tenants map[string][]string `json:"tenants"`
+}
+
+type PrivacyService struct {
+	UserRepo repositories.IUsersRepository
+	Config   PrivacyConfig
+}
+
+type IPrivacyService interface {
+	NewRedaction(userID string) *Redaction
+}
+
+func DefaultPrivacyConfig() PrivacyConfig {
+	return PrivacyConfig{
+		Default: append([]string{}, piiCategories...),
+		Tenants: map[string][]string{},
+	}
+}
+
+// NewPrivacyConfigFromEnv redacts every category unless PII_REDACTION_PATH
+// points to a JSON file with the same shape as PrivacyConfig.
+func NewPrivacyConfigFromEnv() PrivacyConfig {
+	config := DefaultPrivacyConfig()
+	path := os.Getenv("PII_REDACTION_PATH")
+	if path == "" {
+		return config
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		fiberlog.Errorf("PrivacyService -> NewPrivacyConfigFromEnv: %s \n", err)
+		return config
+	}
+	var loaded PrivacyConfig
+	if err := json.Unmarshal(raw, &loaded); err != nil {
+		fiberlog.Errorf("PrivacyService -> NewPrivacyConfigFromEnv: invalid config in %s: %s \n", path, err)
+		return config
+	}
+	if loaded.Default == nil {
+		loaded.Default = config.Default
+	}
+	return loaded
+}
+
+func NewPrivacyService(userRepo repositories.IUsersRepository, config PrivacyConfig) IPrivacyService {
+	for tenant, categories := range config.Tenants {
+		config.Tenants[tenant] = knownPIICategories(categories)
+	}
+	config.Default = knownPIICategories(config.Default)
+	return &PrivacyService{
+		UserRepo: userRepo,
+		Config:   config,
+	}
+}
+
+func knownPIICategories(categories []string) []string {
+	known := []string{}
+	for _, category := range categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		found := false
+		for _, valid := range piiCategories {
+			found = found || valid == category
+		}
+		if !found {
+			fiberlog.Errorf("PrivacyService -> NewPrivacyService: unknown category %q \n", category)
+			continue
+		}
+		known = append(known, category)
+	}
+	return known
+}
+
+// NewRedaction starts a redaction for one request of userID. The user's own
+// full name is always recognised as a name; if the profile cannot be loaded
+// the default categories apply.
+func (sv *PrivacyService) NewRedaction(userID string) *Redaction {
+	categories := sv.Config.Default
+	names := []string{}
+	if sv.UserRepo != nil {
+		if user, err := sv.UserRepo.FindByID(userID); err == nil && user != nil {
+			if tenant, ok := sv.Config.Tenants[user.TenantID]; ok && user.TenantID != "" {
+				categories = tenant
+			}
+			names = append(names, user.Fullname)
+		} else if err != nil {
+			fiberlog.Errorf("PrivacyService -> NewRedaction: %s \n", err)
+		}
+	}
+	return NewRedaction(categories, names...)
+}
+
+// Redaction replaces personal data with numbered placeholders such as
+// [EMAIL_1] and restores them in the model's answer. The same value always
+// gets the same placeholder, so one Redaction should cover everything sent
+// in a single request, including retries.
+type Redaction struct {
+	categories   map[string]bool
+	names        []string
+	placeholders map[string]string
+	originals    map[string]string
+	counts       map[string]int
+}
+
+func NewRedaction(categories []string, names ...string) *Redaction {
+	r := &Redaction{
+		categories:   map[string]bool{},
+		placeholders: map[string]string{},
+		originals:    map[string]string{},
+		counts:       map[string]int{},
+	}
+	for _, category := range categories {
+		r.categories[category] = true
+	}
+	for _, name := range names {
+		r.addName(name)
+	}
+	return r
+}
+
+// addName registers a full name and each of its parts, longest first so the
+// full name is replaced as one placeholder.
+func (r *Redaction) addName(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+	candidates := append([]string{name}, strings.Fields(name)...)
+	for _, candidate := range candidates {
+		if len([]rune(candidate)) < 2 {
+			continue
+		}
+		exists := false
+		for _, known := range r.names {
+			exists = exists || strings.EqualFold(known, candidate)
+		}
+		if !exists {
+			r.names = append(r.names, candidate)
+		}
+	}
+	sort.SliceStable(r.names, func(i, j int) bool { return len(r.names[i]) > len(r.names[j]) })
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// Thai national IDs (13 digits, usually written 1-2345-67890-12-3) and
+	// US social security numbers.
+	thaiIDPattern = regexp.MustCompile(`\b\d[\s-]?\d{4}[\s-]?\d{5}[\s-]?\d{2}[\s-]?\d\b`)
+	ssnPattern    = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	// Numbers starting with a country code or a trunk 0, e.g. 081-234-5678,
+	// 02 123 4567 or +66 81 234 5678.
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?\(?\d{1,4}\)?|\(?\b0\d{1,2}\)?)[\s.-]?\d{3}[\s.-]?\d{3,4}\b`)
+	// Coordinates, street addresses and Thai address parts.
+	coordinatePattern  = regexp.MustCompile(`-?\b\d{1,2}\.\d{3,},\s*-?\d{1,3}\.\d{3,}\b`)
+	addressPattern     = regexp.MustCompile(`\b\d{1,5}(?:/\d{1,4})?,?\s+(?:[A-Z][A-Za-z]*\.?\s+){1,4}(?:Road|Rd|Street|St|Avenue|Ave|Lane|Ln|Soi|Boulevard|Blvd|Drive|Dr)\b\.?`)
+	thaiAddressPattern = regexp.MustCompile(`(?:เลขที่\s*\d+(?:/\d+)?|หมู่(?:ที่)?\s*\d+|ซอย\s*\S+|ถนน\s*\S+|(?:Soi|Moo)\s+\d+)`)
+	// Names the user introduces or addresses with an honorific. Only the
+	// name itself is replaced.
+	introducedNamePattern = regexp.MustCompile(`(?:(?i:my name is|my name's|call me|name:)\s+|\b(?:Mr|Mrs|Ms|Miss|Dr|Khun)\.?\s+)([A-Z][a-z]+(?:\s+[A-Z][a-z]+)?)`)
+	placeholderPattern    = regexp.MustCompile(`\[(?:NAME|EMAIL|PHONE|NATIONAL_ID|LOCATION)_\d+\]`)
+)
+
+// Redact replaces every enabled category in text with placeholders.
+func (r *Redaction) Redact(text string) string {
+	if len(r.categories) == 0 || text == "" {
+		return text
+	}
+	if r.categories[PIIEmail] {
+		text = r.replace(text, PIIEmail, emailPattern, nil)
+	}
+	if r.categories[PIINationalID] {
+		text = r.replace(text, PIINationalID, thaiIDPattern, validThaiID)
+		text = r.replace(text, PIINationalID, ssnPattern, nil)
+	}
+	if r.categories[PIIPhone] {
+		text = r.replace(text, PIIPhone, phonePattern, validPhone)
+	}
+	if r.categories[PIILocation] {
+		text = r.replace(text, PIILocation, coordinatePattern, nil)
+		text = r.replace(text, PIILocation, addressPattern, nil)
+		text = r.replace(text, PIILocation, thaiAddressPattern, nil)
+	}
+	if r.categories[PIIName] {
+		for _, match := range introducedNamePattern.FindAllStringSubmatch(text, -1) {
+			r.addName(match[1])
+		}
+		for _, name := range r.names {
+			text = r.replaceName(text, name)
+		}
+	}
+	return text
+}
+
+// Restore puts the original values back in place of the placeholders.
+func (r *Redaction) Restore(text string) string {
+	if len(r.originals) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if original, ok := r.originals[placeholder]; ok {
+			return original
+		}
+		return placeholder
+	})
+}
+
+// Chats redacts a copy of a chat history.
+func (r *Redaction) Chats(history []entities.AIChat) []entities.AIChat {
+	redacted := make([]entities.AIChat, len(history))
+	for i, chat := range history {
+		chat.Message = r.Redact(chat.Message)
+		redacted[i] = chat
+	}
+	return redacted
+}
+
+// Executor wraps a tool executor so the tool sees the original values in
+// its arguments and the model only sees placeholders in the result.
+func (r *Redaction) Executor(execute aimodel.ToolExecutor) aimodel.ToolExecutor {
+	if execute == nil {
+		return nil
+	}
+	return func(call aimodel.ToolCall) (map[string]any, error) {
+		call.Args = r.mapValues(call.Args, r.Restore)
+		result, err := execute(call)
+		if err != nil {
+			return result, err
+		}
+		return r.mapValues(result, r.Redact), nil
+	}
+}
+
+// Instruction tells the model to keep the placeholders it was sent. It is
+// empty when nothing was redacted.
+func (r *Redaction) Instruction() string {
+	if len(r.originals) == 0 {
+		return ""
+	}
+	return "Some personal details were replaced with placeholders such as [NAME_1] or [EMAIL_1]. Refer to those details only by their placeholders and copy the placeholders exactly."
+}
+
+// Redacted is the number of distinct values replaced so far.
+func (r *Redaction) Redacted() int {
+	return len(r.originals)
+}
+
+func (r *Redaction) replace(text string, category string, pattern *regexp.Regexp, valid func(string) bool) string {
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if valid != nil && !valid(match) {
+			return match
+		}
+		return r.placeholder(category, match)
+	})
+}
+
+// replaceName replaces whole-word occurrences of name that start with a
+// capital letter, in any case after that, so a user called "May" does not
+// lose every "may". Thai is written without spaces, so names in Thai script
+// match anywhere.
+func (r *Redaction) replaceName(text string, name string) string {
+	lower := strings.ToLower(text)
+	target := strings.ToLower(name)
+	if len(lower) != len(text) {
+		lower, target = text, name
+	}
+	latin := !strings.ContainsFunc(name, func(c rune) bool { return unicode.Is(unicode.Thai, c) })
+	var builder strings.Builder
+	start := 0
+	for {
+		index := strings.Index(lower[start:], target)
+		if index < 0 {
+			break
+		}
+		index += start
+		end := index + len(target)
+		if latin && (!wordBoundaryBefore(text, index) || !wordBoundaryAfter(text, end) || !startsUpper(text[index:])) {
+			builder.WriteString(text[start:end])
+			start = end
+			continue
+		}
+		builder.WriteString(text[start:index])
+		builder.WriteString(r.placeholder(PIIName, text[index:end]))
+		start = end
+	}
+	if start == 0 {
+		return text
+	}
+	builder.WriteString(text[start:])
+	return builder.String()
+}
+
+func (r *Redaction) placeholder(category string, value string) string {
+	key := category + "\x00" + strings.ToLower(value)
+	if placeholder, ok := r.placeholders[key]; ok {
+		return placeholder
+	}
+	r.counts[category]++
+	placeholder := fmt.Sprintf("[%s_%d]", strings.ToUpper(category), r.counts[category])
+	r.placeholders[key] = placeholder
+	r.originals[placeholder] = value
+	return placeholder
+}
+
+// mapValues applies transform to every string in a JSON-serialisable map.
+// Values that do not survive a JSON round trip are returned unchanged.
+func (r *Redaction) mapValues(values map[string]any, transform func(string) string) map[string]any {
+	if values == nil {
+		return nil
+	}
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return values
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return values
+	}
+	transformed, ok := transformStrings(decoded, transform).(map[string]any)
+	if !ok {
+		return values
+	}
+	return transformed
+}
+
+func transformStrings(value any, transform func(string) string) any {
+	switch v := value.(type) {
+	case string:
+		return transform(v)
+	case []any:
+		for i := range v {
+			v[i] = transformStrings(v[i], transform)
+		}
+		return v
+	case map[string]any:
+		for key := range v {
+			v[key] = transformStrings(v[key], transform)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func wordBoundaryBefore(text string, index int) bool {
+	if index == 0 {
+		return true
+	}
+	r := []rune(text[:index])
+	return !unicode.IsLetter(r[len(r)-1]) && !unicode.IsDigit(r[len(r)-1])
+}
+
+func wordBoundaryAfter(text string, index int) bool {
+	if index >= len(text) {
+		return true
+	}
+	for _, c := range text[index:] {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	}
+	return true
+}
+
+func startsUpper(text string) bool {
+	for _, c := range text {
+		return unicode.IsUpper(c)
+	}
+	return false
+}
+
+// validThaiID checks the mod-11 check digit so 13-digit amounts and account
+// numbers are left alone.
+func validThaiID(match string) bool {
+	digits := onlyDigits(match)
+	if len(digits) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(digits[i]-'0') * (13 - i)
+	}
+	return (11-sum%11)%10 == int(digits[12]-'0')
+}
+
+func validPhone(match string) bool {
+	digits := onlyDigits(match)
+	return len(digits) >= 9 && len(digits) <= 15
+}
+
+func onlyDigits(text string) string {
+	var builder strings.Builder
+	for _, c := range text {
+		if c >= '0' && c <= '9' {
+			builder.WriteRune(c)
+		}
+	}
+	return builder.String()
+}
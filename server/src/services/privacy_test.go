@@ -0,0 +1,140 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	all := piiCategories
+	tests := []struct {
+		name       string
+		categories []string
+		names      []string
+		text       string
+		want       string
+	}{
+		{"email", all, nil, "write to jane.doe+plans@example.co.th today", "write to [EMAIL_1] today"},
+		{"thai id with dashes", all, nil, "my ID is 1-1017-00203-75-1.", "my ID is [NATIONAL_ID_1]."},
+		{"thai id with spaces", all, nil, "ID 1 1017 00203 75 1", "ID [NATIONAL_ID_1]"},
+		{"thai id digits only", all, nil, "ID 3100400123456", "ID [NATIONAL_ID_1]"},
+		{"thai id with a bad check digit", all, nil, "ref 1-1017-00203-75-2", "ref 1-1017-00203-75-2"},
+		{"13-digit amount", all, nil, "saved 1234567890123 baht", "saved 1234567890123 baht"},
+		{"ssn", all, nil, "SSN 123-45-6789", "SSN [NATIONAL_ID_1]"},
+		{"thai mobile", all, nil, "call 081-234-5678", "call [PHONE_1]"},
+		{"bangkok landline", all, nil, "call 02 123 4567", "call [PHONE_1]"},
+		{"international", all, nil, "call +66 81 234 5678 now", "call [PHONE_1] now"},
+		{"not a phone", all, nil, "walk 10000 steps and lift 100 kg", "walk 10000 steps and lift 100 kg"},
+		{"coordinates", all, nil, "I run at 13.7563, 100.5018 daily", "I run at [LOCATION_1] daily"},
+		{"street address", all, nil, "I live at 221 Baker Street near the park", "I live at [LOCATION_1] near the park"},
+		{"thai address", all, nil, "บ้านอยู่ซอย 5 ถนน สุขุมวิท", "บ้านอยู่[LOCATION_1] [LOCATION_2]"},
+		{"introduced name", all, nil, "Hi, my name is Anna Smith and I want to sleep better", "Hi, my name is [NAME_1] and I want to sleep better"},
+		{"honorific", all, nil, "Khun Somchai asked me to run", "Khun [NAME_1] asked me to run"},
+		{"profile name", all, []string{"May Tan"}, "May Tan says you may rest, May", "[NAME_1] says you may rest, [NAME_2]"},
+		{"thai profile name", all, []string{"สมชาย ใจดี"}, "คุณสมชายอยากนอนให้พอ", "คุณ[NAME_1]อยากนอนให้พอ"},
+		{"only enabled categories", []string{PIIEmail}, []string{"Anna"}, "Anna: anna@example.com, 081-234-5678", "Anna: [EMAIL_1], 081-234-5678"},
+		{"nothing enabled", nil, []string{"Anna"}, "Anna: anna@example.com", "Anna: anna@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRedaction(tt.categories, tt.names...).Redact(tt.text); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidThaiID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"1101700203751", true},
+		{"3-1004-00123-45-6", true},
+		{"1101700203752", false},
+		{"110170020375", false},
+	}
+	for _, tt := range tests {
+		if got := validThaiID(tt.id); got != tt.want {
+			t.Errorf("validThaiID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestRestoreRoundTrips(t *testing.T) {
+	r := NewRedaction(piiCategories, "Anna Smith")
+	redacted := r.Redact("Anna Smith, anna@example.com, 081-234-5678. Mail ANNA@EXAMPLE.COM again.")
+	want := "[NAME_1], [EMAIL_1], [PHONE_1]. Mail [EMAIL_1] again."
+	if redacted != want {
+		t.Fatalf("Redact() = %q, want %q", redacted, want)
+	}
+	if r.Redacted() != 3 {
+		t.Errorf("Redacted() = %d, want 3", r.Redacted())
+	}
+	// The model repeats placeholders and can invent ones it was never sent.
+	answer := "Hi [NAME_1]! I will write to [EMAIL_1]. [NAME_1], call [PHONE_1], not [PHONE_2]."
+	restored := r.Restore(answer)
+	if restored != "Hi Anna Smith! I will write to anna@example.com. Anna Smith, call 081-234-5678, not [PHONE_2]." {
+		t.Errorf("Restore() = %q", restored)
+	}
+	// A later message of the same request keeps the numbering.
+	if got := r.Redact("my other mail is bob@example.com"); got != "my other mail is [EMAIL_2]" {
+		t.Errorf("Redact() = %q, want [EMAIL_2]", got)
+	}
+	if got := NewRedaction(piiCategories).Restore("[NAME_1]"); got != "[NAME_1]" {
+		t.Errorf("Restore() without redactions = %q", got)
+	}
+}
+
+func TestPrivacyConfigFromEnvPerTenant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "privacy.json")
+	config := `{"default":["email"],"tenants":{"clinic":["email","phone","name","ssn"],"open":[]}}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PII_REDACTION_PATH", path)
+	users := &fakeUsersRepository{users: map[string]entities.UserProfileModel{
+		"plain":   {UserID: "plain", Fullname: "Anna Smith"},
+		"clinic":  {UserID: "clinic", Fullname: "Anna Smith", TenantID: "clinic"},
+		"open":    {UserID: "open", Fullname: "Anna Smith", TenantID: "open"},
+		"unknown": {UserID: "unknown", Fullname: "Anna Smith", TenantID: "gym"},
+	}}
+	service := NewPrivacyService(users, NewPrivacyConfigFromEnv())
+	text := "Anna Smith: anna@example.com, 081-234-5678"
+	tests := []struct {
+		userID string
+		want   string
+	}{
+		{"plain", "Anna Smith: [EMAIL_1], 081-234-5678"},
+		{"clinic", "[NAME_1]: [EMAIL_1], [PHONE_1]"},
+		{"open", text},
+		{"unknown", "Anna Smith: [EMAIL_1], 081-234-5678"},
+		{"missing", "Anna Smith: [EMAIL_1], 081-234-5678"},
+	}
+	for _, tt := range tests {
+		if got := service.NewRedaction(tt.userID).Redact(text); got != tt.want {
+			t.Errorf("%s: Redact() = %q, want %q", tt.userID, got, tt.want)
+		}
+	}
+}
+
+func TestPrivacyConfigFromEnvFallsBackToDefault(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	noDefault := filepath.Join(dir, "no_default.json")
+	if err := os.WriteFile(invalid, []byte(`{"default":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(noDefault, []byte(`{"tenants":{"open":[]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"", filepath.Join(dir, "missing.json"), invalid, noDefault} {
+		t.Setenv("PII_REDACTION_PATH", path)
+		config := NewPrivacyConfigFromEnv()
+		if len(config.Default) != len(piiCategories) {
+			t.Errorf("PII_REDACTION_PATH=%q: default = %v, want every category", path, config.Default)
+		}
+	}
+}
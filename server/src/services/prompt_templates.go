@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/locale"
+	"strings"
+)
+
+// planPromptFormats are the plan-generation prompts per language. Every
+// format takes the same arguments in the same order; see BuildPlanPrompt.
+var planPromptFormats = map[string]string{
+	locale.English: "Generate a life goal for me based on my profile information. \nMy LongTermGoal is %s and \nMy ShorttermGoal is %s.\nRight now I am %d years old %s . \nThis is my Health Background \nMy weight is %f kg, height is %f cm, medical condition is %s , allergies %s, current medication %s ,sleeppattern is %s and fitness level is %s \n My financial situation \n My income is %f %s ,Monthly expenses is %f %s \n I expect to save money %f %s, Risk Tolerence for investments is %s.\n I have workhour %s, available time %s, my most busy day is %s , preferred time for activity is %s.",
+	locale.Thai:    "ช่วยวางแผนเป้าหมายชีวิตให้ฉันจากข้อมูลโปรไฟล์ต่อไปนี้ \nเป้าหมายระยะยาวของฉันคือ %s และ \nเป้าหมายระยะสั้นของฉันคือ %s\nตอนนี้ฉันอายุ %d ปี เพศ %s \nข้อมูลสุขภาพของฉัน \nน้ำหนัก %f กก. ส่วนสูง %f ซม. โรคประจำตัว %s อาการแพ้ %s ยาที่ใช้อยู่ %s รูปแบบการนอน %s และระดับความฟิต %s \n สถานะการเงินของฉัน \n รายได้ %f %s ค่าใช้จ่ายต่อเดือน %f %s \n ฉันตั้งเป้าออมเงิน %f %s ระดับความเสี่ยงในการลงทุนที่รับได้คือ %s\n เวลาทำงานของฉันคือ %s เวลาว่าง %s วันที่ยุ่งที่สุดคือ %s และช่วงเวลาที่สะดวกทำกิจกรรมคือ %s",
+}
+
+// BuildPlanPrompt fills the plan template for lang from the user's records
+// and tells the model which language to write the plan in. It has no side
+// effects, so the evaluation harness builds prompts the same way.
+func BuildPlanPrompt(lang string, user entities.UserProfileModel, lifeGoal entities.LifeGoalModel, health entities.HealthBackgroundModel, finance entities.FinanceModel, schedule entities.ScheduleModel) string {
+	// Without summaries nothing is averaged, so there is no error.
+	prompt, _ := BuildPlanPromptWithSpending(lang, user, lifeGoal, health, finance, schedule, nil)
+	return prompt
+}
+
+// BuildPlanPromptWithSpending is BuildPlanPrompt for a user who tracks
+// transactions: income and expenses are the monthly averages of summaries
+// and the prompt describes their spending by category.
+func BuildPlanPromptWithSpending(lang string, user entities.UserProfileModel, lifeGoal entities.LifeGoalModel, health entities.HealthBackgroundModel, finance entities.FinanceModel, schedule entities.ScheduleModel, summaries []entities.FinanceMonthSummary) (string, error) {
+	finance, err := FinanceWithSummaries(finance, summaries)
+	if err != nil {
+		return "", err
+	}
+	spending, err := SpendingPrompt(lang, summaries)
+	if err != nil {
+		return "", err
+	}
+	format, ok := planPromptFormats[lang]
+	if !ok {
+		format = planPromptFormats[locale.English]
+	}
+	prompt := fmt.Sprintf(format, lifeGoal.LongTerm, lifeGoal.ShortTerm, user.Age, user.Gender, user.Weight, user.Height, strings.Join(health.Medical_Conditions, ","), strings.Join(health.Allergies, ","), strings.Join(health.Medications, ","), health.Sleep_Pattern, health.Fitness_Level, finance.Income.Float(), finance.Currency, finance.Expenses.Float(), finance.Currency, finance.SavingsGoal.Float(), finance.Currency, finance.Risk_Tolerance, schedule.WorkHours, schedule.AvailableTime, strings.Join(schedule.BusyDays, ","), strings.Join(schedule.PreferredTime, ","))
+	if spending != "" {
+		prompt += "\n" + spending
+	}
+	return prompt + "\n\n" + replyLanguageInstruction(lang), nil
+}
+
+func replyLanguageInstruction(lang string) string {
+	switch lang {
+	case locale.Thai:
+		return "Write the whole answer in Thai (ภาษาไทย), even when parts of the input are in English."
+	default:
+		return fmt.Sprintf("Write the whole answer in %s.", locale.Name(lang))
+	}
+}
+
+// userLanguage is the user's preferred language, or the default when the
+// profile cannot be read or has none.
+func userLanguage(userRepo repositories.IUsersRepository, userID string) string {
+	user, err := userRepo.FindByID(userID)
+	if err != nil {
+		return locale.Default()
+	}
+	return locale.Resolve(user.Language)
+}
@@ -0,0 +1,617 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/configuration"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/notify"
+	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/locale"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// Reminder kinds.
+const (
+	ReminderHabitDue     = "habit_due"
+	ReminderMoodLog      = "mood_log"
+	ReminderWeeklyReview = "weekly_review"
+)
+
+var reminderKinds = map[string]bool{ReminderHabitDue: true, ReminderMoodLog: true, ReminderWeeklyReview: true}
+
+// ReminderConfig controls the scheduler. A reminder found more than MaxDelay
+// after it was due, e.g. after downtime, is skipped and rescheduled rather
+// than sent late.
+type ReminderConfig struct {
+	Enabled       bool
+	CheckInterval time.Duration
+	MaxDelay      time.Duration
+}
+
+type ReminderService struct {
+	ReminderRepo repositories.IReminderRepository
+	HabitsRepo   repositories.IHabitRepository
+	MoodRepo     repositories.IMoodRepository
+	ReviewRepo   repositories.IWeeklyReviewRepository
+	UserRepo     repositories.IUsersRepository
+	Channels     map[string]notify.IChannel
+	Config       ReminderConfig
+	running      sync.Mutex
+}
+
+type IReminderService interface {
+	CreateReminder(userID string, data entities.ReminderResponse) (*entities.ReminderModel, error)
+	GetRemindersByUserID(userID string) (*[]entities.ReminderModel, error)
+	// UpdateReminder edits the reminder. Fields left empty keep their value.
+	UpdateReminder(userID string, reminderID string, data entities.ReminderResponse) (*entities.ReminderModel, error)
+	DeleteReminder(userID string, reminderID string) error
+	// SendTest delivers the reminder now on every channel, ignoring quiet
+	// hours and whether anything is due, and reports the channels that failed.
+	SendTest(userID string, reminderID string) error
+	// WebPushKey is the VAPID public key clients subscribe with, or "" when
+	// web push is not configured.
+	WebPushKey() string
+	Start()
+}
+
+func NewReminderConfigFromEnv() ReminderConfig {
+	return ReminderConfig{
+		Enabled:       configuration.GetEnvInt("REMINDERS_ENABLED", 1) == 1,
+		CheckInterval: time.Duration(configuration.GetEnvInt("REMINDER_CHECK_SECONDS", 60)) * time.Second,
+		MaxDelay:      time.Duration(configuration.GetEnvInt("REMINDER_MAX_DELAY_MINUTES", 60)) * time.Minute,
+	}
+}
+
+func NewReminderService(reminderRepo repositories.IReminderRepository, habitsRepo repositories.IHabitRepository, moodRepo repositories.IMoodRepository, reviewRepo repositories.IWeeklyReviewRepository, userRepo repositories.IUsersRepository, channels map[string]notify.IChannel, config ReminderConfig) IReminderService {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = time.Minute
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = time.Hour
+	}
+	return &ReminderService{
+		ReminderRepo: reminderRepo,
+		HabitsRepo:   habitsRepo,
+		MoodRepo:     moodRepo,
+		ReviewRepo:   reviewRepo,
+		UserRepo:     userRepo,
+		Channels:     channels,
+		Config:       config,
+	}
+}
+
+// validateReminder normalises the reminder and checks that every channel is
+// configured on the server and has somewhere to deliver to.
+func (sv *ReminderService) validateReminder(userID string, reminder *entities.ReminderResponse) error {
+	if !reminderKinds[reminder.Kind] {
+		return invalidf("kind must be habit_due, mood_log or weekly_review")
+	}
+	if reminder.HabitID != nil {
+		if reminder.Kind != ReminderHabitDue {
+			return invalidf("habit_id is only allowed for habit_due reminders")
+		}
+		habit, err := sv.HabitsRepo.GetHabitByID(*reminder.HabitID)
+		if err != nil || habit.UserID != userID {
+			return invalidf("habit with ID %d not found", *reminder.HabitID)
+		}
+	}
+	clock, err := time.Parse("15:04", reminder.Time)
+	if err != nil {
+		return invalidf("time must be HH:MM")
+	}
+	reminder.Time = clock.Format("15:04")
+	days := []string{}
+	for _, day := range reminder.Days {
+		name, ok := scheduleDays[strings.ToLower(strings.TrimSpace(day))]
+		if !ok {
+			return invalidf("invalid day %q", day)
+		}
+		days = append(days, name)
+	}
+	if len(days) == 0 && reminder.Kind == ReminderWeeklyReview {
+		days = []string{"Monday"}
+	}
+	reminder.Days = days
+	if reminder.Timezone != "" {
+		if _, err := LoadTimezone(reminder.Timezone); err != nil {
+			return invalid(err)
+		}
+	}
+	if (reminder.QuietStart == "") != (reminder.QuietEnd == "") {
+		return invalidf("quiet hours need both quiet_start and quiet_end")
+	}
+	for _, value := range []*string{&reminder.QuietStart, &reminder.QuietEnd} {
+		if *value == "" {
+			continue
+		}
+		clock, err := time.Parse("15:04", *value)
+		if err != nil {
+			return invalidf("quiet hours must be HH:MM")
+		}
+		*value = clock.Format("15:04")
+	}
+
+	if len(reminder.Channels) == 0 {
+		return invalidf("reminder needs at least one channel")
+	}
+	channels, err := validateChannels(sv.Channels, "reminders", reminder.Channels, reminder.Email, reminder.WebhookURL, reminder.PushSubscription)
+	if err != nil {
+		return err
+	}
+	reminder.Channels = channels
+	return nil
+}
+
+// validateChannels normalises the channel names, dropping repeats, and checks
+// that each one is configured in available and has somewhere to deliver to.
+// What names the notifications in the errors, e.g. reminders.
+func validateChannels(available map[string]notify.IChannel, what string, names []string, email string, webhookURL string, push *entities.PushSubscription) ([]string, error) {
+	channels := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		switch name {
+		case notify.ChannelEmail:
+			if _, err := mail.ParseAddress(email); err != nil {
+				return nil, invalidf("email %s need a valid email", what)
+			}
+		case notify.ChannelWebhook:
+			if err := notify.ValidateWebhookURL(webhookURL); err != nil {
+				return nil, invalid(err)
+			}
+		case notify.ChannelWebPush:
+			if err := notify.ValidatePushSubscription(push); err != nil {
+				return nil, invalid(err)
+			}
+		default:
+			return nil, invalidf("channel must be email, web_push or webhook")
+		}
+		if available[name] == nil {
+			return nil, invalidf("%s %s are not configured on this server", name, what)
+		}
+		channels = append(channels, name)
+	}
+	return channels, nil
+}
+
+func (sv *ReminderService) CreateReminder(userID string, data entities.ReminderResponse) (*entities.ReminderModel, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	if err := sv.validateReminder(userID, &data); err != nil {
+		return nil, err
+	}
+	enabled := data.Enabled == nil || *data.Enabled
+	data.Enabled = &enabled
+	data.UserID = userID
+	data.NextRunAt = sv.nextRun(reminderFromResponse(data), time.Now()).Add(7 * time.Hour)
+	data.CreatedAt = time.Now().Add(7 * time.Hour)
+	data.UpdatedAt = time.Now().Add(7 * time.Hour)
+	reminder, err := sv.ReminderRepo.InsertReminder(data)
+	if err != nil {
+		fiberlog.Errorf("ReminderService -> CreateReminder: %s \n", err)
+		return nil, err
+	}
+	return reminder, nil
+}
+
+func (sv *ReminderService) GetRemindersByUserID(userID string) (*[]entities.ReminderModel, error) {
+	reminders, err := sv.ReminderRepo.GetRemindersByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("ReminderService -> GetRemindersByUserID: %s \n", err)
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (sv *ReminderService) UpdateReminder(userID string, reminderID string, data entities.ReminderResponse) (*entities.ReminderModel, error) {
+	current, err := sv.userReminder(userID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+	edited := entities.ReminderResponse{
+		Kind:             firstNonEmpty(data.Kind, current.Kind),
+		HabitID:          current.HabitID,
+		Time:             firstNonEmpty(data.Time, current.Time),
+		Days:             current.Days,
+		Timezone:         firstNonEmpty(data.Timezone, current.Timezone),
+		QuietStart:       firstNonEmpty(data.QuietStart, current.QuietStart),
+		QuietEnd:         firstNonEmpty(data.QuietEnd, current.QuietEnd),
+		Channels:         current.Channels,
+		Email:            firstNonEmpty(data.Email, current.Email),
+		WebhookURL:       firstNonEmpty(data.WebhookURL, current.WebhookURL),
+		PushSubscription: current.PushSubscription,
+	}
+	if data.HabitID != nil {
+		edited.HabitID = data.HabitID
+	}
+	if data.Days != nil {
+		edited.Days = data.Days
+	}
+	if data.Channels != nil {
+		edited.Channels = data.Channels
+	}
+	if data.PushSubscription != nil {
+		edited.PushSubscription = data.PushSubscription
+	}
+	if err := sv.validateReminder(userID, &edited); err != nil {
+		return nil, err
+	}
+	enabled := current.Enabled
+	if data.Enabled != nil {
+		enabled = *data.Enabled
+	}
+	edited.Enabled = &enabled
+	update := entities.ReminderUpdate{
+		Kind:             edited.Kind,
+		HabitID:          edited.HabitID,
+		Time:             edited.Time,
+		Days:             edited.Days,
+		Timezone:         edited.Timezone,
+		QuietStart:       edited.QuietStart,
+		QuietEnd:         edited.QuietEnd,
+		Channels:         edited.Channels,
+		Email:            edited.Email,
+		WebhookURL:       edited.WebhookURL,
+		PushSubscription: edited.PushSubscription,
+		Enabled:          enabled,
+		NextRunAt:        sv.nextRun(reminderFromResponse(edited), time.Now()).Add(7 * time.Hour),
+		UpdatedAt:        time.Now().Add(7 * time.Hour),
+	}
+	reminder, err := sv.ReminderRepo.UpdateReminder(reminderID, update)
+	if err != nil {
+		fiberlog.Errorf("ReminderService -> UpdateReminder: %s \n", err)
+		return nil, err
+	}
+	return reminder, nil
+}
+
+func (sv *ReminderService) DeleteReminder(userID string, reminderID string) error {
+	if _, err := sv.userReminder(userID, reminderID); err != nil {
+		return err
+	}
+	if err := sv.ReminderRepo.DeleteReminder(reminderID); err != nil {
+		fiberlog.Errorf("ReminderService -> DeleteReminder: %s \n", err)
+		return err
+	}
+	return nil
+}
+
+func (sv *ReminderService) SendTest(userID string, reminderID string) error {
+	reminder, err := sv.userReminder(userID, reminderID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	message, _, err := sv.reminderMessage(*reminder, now, sv.reminderLocation(*reminder), true)
+	if err != nil {
+		return err
+	}
+	return sv.deliver(*reminder, message, now)
+}
+
+func (sv *ReminderService) WebPushKey() string {
+	if push, ok := sv.Channels[notify.ChannelWebPush].(*notify.WebPushChannel); ok {
+		return push.PublicKey
+	}
+	return ""
+}
+
+func (sv *ReminderService) userReminder(userID string, reminderID string) (*entities.ReminderModel, error) {
+	reminder, err := sv.ReminderRepo.GetReminderByID(reminderID)
+	if err != nil {
+		fiberlog.Errorf("ReminderService -> userReminder: %s \n", err)
+		return nil, err
+	}
+	if reminder.UserID != userID {
+		return nil, fmt.Errorf("reminder with ID %s not found", reminderID)
+	}
+	return reminder, nil
+}
+
+func reminderFromResponse(data entities.ReminderResponse) entities.ReminderModel {
+	return entities.ReminderModel{
+		UserID:     data.UserID,
+		Kind:       data.Kind,
+		Time:       data.Time,
+		Days:       data.Days,
+		Timezone:   data.Timezone,
+		QuietStart: data.QuietStart,
+		QuietEnd:   data.QuietEnd,
+	}
+}
+
+func (sv *ReminderService) reminderLocation(reminder entities.ReminderModel) *time.Location {
+	if reminder.Timezone != "" {
+		if location, err := LoadTimezone(reminder.Timezone); err == nil {
+			return location
+		}
+	}
+	return userLocation(sv.UserRepo, reminder.UserID)
+}
+
+func (sv *ReminderService) nextRun(reminder entities.ReminderModel, after time.Time) time.Time {
+	return NextReminderRun(reminder, sv.reminderLocation(reminder), after)
+}
+
+// NextReminderRun is the first time after after at which the reminder is
+// due: its time of day on one of its days (every day when it has none), in
+// location, moved to the end of quiet hours when it falls inside them.
+func NextReminderRun(reminder entities.ReminderModel, location *time.Location, after time.Time) time.Time {
+	clock, _ := time.Parse("15:04", reminder.Time)
+	days := map[string]bool{}
+	for _, day := range reminder.Days {
+		days[day] = true
+	}
+	local := after.In(location)
+	for i := 0; i <= 8; i++ {
+		day := local.AddDate(0, 0, i)
+		candidate := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+		if !candidate.After(after) || (len(days) > 0 && !days[candidate.Weekday().String()]) {
+			continue
+		}
+		return outOfQuietHours(reminder, candidate)
+	}
+	return after.Add(24 * time.Hour)
+}
+
+func minuteOfDay(clock string) (int, bool) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+// inQuietHours reports whether t, in the reminder's timezone, falls in its
+// quiet hours. Quiet hours that start after they end wrap past midnight.
+func inQuietHours(reminder entities.ReminderModel, t time.Time) bool {
+	start, ok := minuteOfDay(reminder.QuietStart)
+	end, okEnd := minuteOfDay(reminder.QuietEnd)
+	if !ok || !okEnd || start == end {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func outOfQuietHours(reminder entities.ReminderModel, t time.Time) time.Time {
+	if !inQuietHours(reminder, t) {
+		return t
+	}
+	end, _ := minuteOfDay(reminder.QuietEnd)
+	moved := time.Date(t.Year(), t.Month(), t.Day(), end/60, end%60, 0, 0, t.Location())
+	if !moved.After(t) {
+		moved = moved.AddDate(0, 0, 1)
+	}
+	return moved
+}
+
+func (sv *ReminderService) Start() {
+	if !sv.Config.Enabled {
+		return
+	}
+	go func() {
+		sv.runDue()
+		ticker := time.NewTicker(sv.Config.CheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			sv.runDue()
+		}
+	}()
+}
+
+// runDue sends every reminder that is due. Each one is claimed by moving it
+// to its next run before anything is sent, so a failed delivery is recorded
+// and not retried.
+func (sv *ReminderService) runDue() {
+	if !sv.running.TryLock() {
+		return
+	}
+	defer sv.running.Unlock()
+
+	now := time.Now()
+	stamp := now.Add(7 * time.Hour)
+	reminders, err := sv.ReminderRepo.GetDueReminders(stamp)
+	if err != nil {
+		fiberlog.Errorf("ReminderService -> runDue: %s \n", err)
+		return
+	}
+	for _, reminder := range *reminders {
+		location := sv.reminderLocation(reminder)
+		due := reminder.NextRunAt.Add(-7 * time.Hour)
+		next := NextReminderRun(reminder, location, now)
+		quiet := inQuietHours(reminder, now.In(location))
+		if quiet {
+			next = outOfQuietHours(reminder, now.In(location))
+		}
+		claimed, err := sv.ReminderRepo.ClaimReminder(reminder.ID, stamp, entities.ReminderRunUpdate{NextRunAt: next.Add(7 * time.Hour), UpdatedAt: stamp})
+		if err != nil || !claimed || quiet || now.Sub(due) > sv.Config.MaxDelay {
+			continue
+		}
+		message, send, err := sv.reminderMessage(reminder, now, location, false)
+		if err != nil {
+			fiberlog.Errorf("ReminderService -> runDue: reminder %s: %s \n", reminder.ID, err)
+			continue
+		}
+		if !send {
+			continue
+		}
+		if err := sv.deliver(reminder, message, now); err != nil {
+			fiberlog.Errorf("ReminderService -> runDue: reminder %s: %s \n", reminder.ID, err)
+		}
+	}
+}
+
+// deliver sends message on every channel of the reminder and records the
+// outcome.
+func (sv *ReminderService) deliver(reminder entities.ReminderModel, message notify.Message, now time.Time) error {
+	target := notify.Target{
+		Email:            reminder.Email,
+		WebhookURL:       reminder.WebhookURL,
+		PushSubscription: reminder.PushSubscription,
+	}
+	failures := sendNotification(sv.Channels, reminder.Channels, target, message)
+	result := entities.ReminderResultUpdate{
+		LastSentAt: reminder.LastSentAt,
+		LastError:  strings.Join(failures, "; "),
+		UpdatedAt:  now.Add(7 * time.Hour),
+	}
+	if len(failures) < len(reminder.Channels) {
+		sent := now.Add(7 * time.Hour)
+		result.LastSentAt = &sent
+	}
+	if err := sv.ReminderRepo.RecordReminderRun(reminder.ID, result); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", result.LastError)
+	}
+	return nil
+}
+
+// sendNotification sends message on each of the named channels and lists
+// the ones that failed, with their errors.
+func sendNotification(available map[string]notify.IChannel, names []string, target notify.Target, message notify.Message) []string {
+	failures := []string{}
+	for _, name := range names {
+		channel := available[name]
+		if channel == nil {
+			failures = append(failures, name+": not configured")
+			continue
+		}
+		if err := channel.Send(target, message); err != nil {
+			failures = append(failures, name+": "+err.Error())
+		}
+	}
+	return failures
+}
+
+// reminderMessage builds the notification and reports whether there is
+// anything to remind about: a habit_due reminder is skipped once its habits
+// are done for today and a mood_log reminder once today's mood is logged.
+// With force the message is built regardless.
+func (sv *ReminderService) reminderMessage(reminder entities.ReminderModel, now time.Time, location *time.Location, force bool) (notify.Message, bool, error) {
+	lang := locale.Default()
+	if user, err := sv.UserRepo.FindByID(reminder.UserID); err == nil && user != nil {
+		lang = locale.Resolve(user.Language)
+	}
+	text := reminderTexts[lang]
+	if text == nil {
+		text = reminderTexts[locale.English]
+	}
+	today := localDate(now, location)
+	message := notify.Message{
+		Kind:   reminder.Kind,
+		Data:   map[string]any{"reminder_id": reminder.ID, "user_id": reminder.UserID},
+		SentAt: now.UTC(),
+	}
+
+	switch reminder.Kind {
+	case ReminderHabitDue:
+		pending, err := sv.pendingHabits(reminder, today, location)
+		if err != nil {
+			return message, false, err
+		}
+		names := []string{}
+		ids := []int{}
+		for _, habit := range pending {
+			names = append(names, habit.Name)
+			ids = append(ids, habit.ID)
+		}
+		message.Title = text["habit_title"]
+		message.Body = fmt.Sprintf(text["habit_body"], strings.Join(names, ", "))
+		if len(names) == 0 {
+			message.Body = text["habit_none"]
+		}
+		message.Data["habit_ids"] = ids
+		return message, force || len(pending) > 0, nil
+	case ReminderMoodLog:
+		message.Title = text["mood_title"]
+		message.Body = text["mood_body"]
+		if force {
+			return message, true, nil
+		}
+		moods, err := sv.MoodRepo.GetMoodById(reminder.UserID)
+		if err != nil {
+			return message, false, err
+		}
+		for _, mood := range *moods {
+			if MoodDate(mood, location).Equal(today) {
+				return message, false, nil
+			}
+		}
+		return message, true, nil
+	default:
+		weekStart := ReviewWeekStart(now.In(location))
+		message.Title = text["review_title"]
+		message.Body = text["review_body"]
+		message.Data["week_start"] = weekStart.Format(reviewDateLayout)
+		if review, err := sv.ReviewRepo.GetReviewByWeek(reminder.UserID, weekStart.Format(reviewDateLayout)); err == nil && review != nil {
+			message.Body = review.Summary
+			message.Data["review_id"] = review.ID
+		}
+		return message, true, nil
+	}
+}
+
+// pendingHabits are the reminder's active habits that are due today and not
+// yet completed: the one it names, or all of the user's. A reminder for a
+// habit that no longer exists has nothing pending.
+func (sv *ReminderService) pendingHabits(reminder entities.ReminderModel, today time.Time, location *time.Location) ([]entities.HabitModel, error) {
+	habits, err := sv.HabitsRepo.GetHabitsByUserID(reminder.UserID)
+	if err != nil {
+		return nil, err
+	}
+	pending := []entities.HabitModel{}
+	for _, habit := range *habits {
+		if reminder.HabitID != nil && habit.ID != *reminder.HabitID {
+			continue
+		}
+		if habitStatus(habit) != HabitStatusActive {
+			continue
+		}
+		checkIns, err := sv.HabitsRepo.GetCheckInsByHabitID(habit.ID)
+		if err != nil {
+			return nil, err
+		}
+		occurrences := HabitOccurrences(habit, *checkIns, today, today.AddDate(0, 0, 1), today, location)
+		if len(occurrences) > 0 && !occurrences[0].Completed {
+			pending = append(pending, habit)
+		}
+	}
+	return pending, nil
+}
+
+var reminderTexts = map[string]map[string]string{
+	locale.English: {
+		"habit_title":  "Habit reminder",
+		"habit_body":   "Still to do today: %s",
+		"habit_none":   "All of today's habits are done.",
+		"mood_title":   "How are you feeling?",
+		"mood_body":    "Take a moment to log today's mood.",
+		"review_title": "Your weekly review",
+		"review_body":  "Take a few minutes to look back at your week.",
+	},
+	locale.Thai: {
+		"habit_title":  "เตือนนิสัย",
+		"habit_body":   "สิ่งที่ยังต้องทำวันนี้: %s",
+		"habit_none":   "วันนี้ทำนิสัยครบทุกอย่างแล้ว",
+		"mood_title":   "วันนี้รู้สึกอย่างไรบ้าง",
+		"mood_body":    "ใช้เวลาสักครู่บันทึกอารมณ์ของวันนี้",
+		"review_title": "สรุปประจำสัปดาห์ของคุณ",
+		"review_body":  "ใช้เวลาสักครู่ทบทวนสัปดาห์ที่ผ่านมา",
+	},
+}
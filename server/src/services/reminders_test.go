@@ -0,0 +1,84 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/notify"
+	"testing"
+	"time"
+)
+
+func TestValidateReminderReturnsValidationErrors(t *testing.T) {
+	service := &ReminderService{Channels: map[string]notify.IChannel{notify.ChannelWebhook: &fakeChannel{}}}
+	tests := []struct {
+		name     string
+		reminder entities.ReminderResponse
+	}{
+		{name: "unknown kind", reminder: entities.ReminderResponse{Kind: "daily", Time: "08:00", Channels: []string{notify.ChannelWebhook}}},
+		{name: "bad time", reminder: entities.ReminderResponse{Kind: ReminderMoodLog, Time: "8am", Channels: []string{notify.ChannelWebhook}}},
+		{name: "unknown timezone", reminder: entities.ReminderResponse{Kind: ReminderMoodLog, Time: "08:00", Timezone: "Mars/Olympus", Channels: []string{notify.ChannelWebhook}}},
+		{name: "no channel", reminder: entities.ReminderResponse{Kind: ReminderMoodLog, Time: "08:00"}},
+		{name: "bad webhook", reminder: entities.ReminderResponse{Kind: ReminderMoodLog, Time: "08:00", Channels: []string{notify.ChannelWebhook}, WebhookURL: "not a url"}},
+		{name: "channel not configured", reminder: entities.ReminderResponse{Kind: ReminderMoodLog, Time: "08:00", Channels: []string{notify.ChannelEmail}, Email: "me@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.validateReminder("user", &tt.reminder)
+			if !IsValidationError(err) {
+				t.Errorf("validateReminder() error = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+type fakeWeeklyReviewRepository struct {
+	weeks []string
+}
+
+func (repo *fakeWeeklyReviewRepository) UpsertReview(data entities.WeeklyReviewResponse) (*entities.WeeklyReviewModel, error) {
+	return &entities.WeeklyReviewModel{}, nil
+}
+
+func (repo *fakeWeeklyReviewRepository) GetReviewsByUserID(userID string) (*[]entities.WeeklyReviewModel, error) {
+	return &[]entities.WeeklyReviewModel{}, nil
+}
+
+func (repo *fakeWeeklyReviewRepository) GetReviewByWeek(userID string, weekStart string) (*entities.WeeklyReviewModel, error) {
+	repo.weeks = append(repo.weeks, weekStart)
+	return nil, nil
+}
+
+func TestWeeklyReviewReminderUsesReminderTimezone(t *testing.T) {
+	// 20:00 UTC on Sunday 12 April 2026 is already Monday in Bangkok, so the
+	// review there is of the week starting 6 April; in London it is still
+	// Sunday and the last complete week started on 30 March.
+	now := time.Date(2026, 4, 12, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"Asia/Bangkok", "2026-04-06"},
+		{"Europe/London", "2026-03-30"},
+	}
+	for _, tt := range tests {
+		location, err := time.LoadLocation(tt.timezone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reviews := &fakeWeeklyReviewRepository{}
+		service := &ReminderService{
+			ReviewRepo: reviews,
+			UserRepo:   &fakeUsersRepository{users: map[string]entities.UserProfileModel{}},
+		}
+		reminder := entities.ReminderModel{ID: "reminder", UserID: "user", Kind: ReminderWeeklyReview}
+		message, _, err := service.reminderMessage(reminder, now, location, true)
+		if err != nil {
+			t.Fatalf("reminderMessage() error = %v", err)
+		}
+		if got := message.Data["week_start"]; got != tt.want {
+			t.Errorf("%s: week_start = %v, want %s", tt.timezone, got, tt.want)
+		}
+		if len(reviews.weeks) != 1 || reviews.weeks[0] != tt.want {
+			t.Errorf("%s: looked up reviews for %v, want %s", tt.timezone, reviews.weeks, tt.want)
+		}
+	}
+}
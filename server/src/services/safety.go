@@ -0,0 +1,578 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/configuration"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/locale"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// Rule categories understood by the safety checker.
+const (
+	SafetyAllergen   = "allergen"
+	SafetyStrenuous  = "strenuous_exercise"
+	SafetyMedication = "medication"
+)
+
+// What happens to text flagged by a rule, from mildest to strictest.
+// Regenerate falls back to redact when the model keeps producing the advice.
+const (
+	SafetyActionDisclaimer = "disclaimer"
+	SafetyActionRedact     = "redact"
+	SafetyActionRegenerate = "regenerate"
+)
+
+// listMarker strips Markdown bullets and numbering so a redaction keeps them.
+var listMarker = regexp.MustCompile(`^([-*•]|\d+[.)])\s+`)
+
+// SafetyRule describes one kind of unsafe advice.
+//
+//   - allergen rules flag sentences naming one of the user's allergies, or a
+//     food listed for it in Synonyms, unless that mention is left out by a
+//     Negations phrase in the three words before it ("no peanuts", "avoid
+//     almonds") or a FreeMarkers phrase right after it ("peanut-free").
+//   - strenuous_exercise rules only apply when one of the user's medical
+//     conditions contains an entry of Conditions, and flag sentences matching
+//     Keywords or Patterns.
+//   - medication rules always apply and flag sentences matching Keywords or
+//     Patterns.
+//
+// Sentences containing any Exempt phrase ("avoid", "ask your doctor") are
+// never flagged by the rule. Matching is case-insensitive. English phrases
+// match whole words; Thai is written without spaces between words, so Thai
+// phrases match anywhere. Disclaimer and Redaction are keyed by language and
+// fall back to English.
+type SafetyRule struct {
+	ID          string              `json:"id"`
+	Category    string              `json:"category"`
+	Action      string              `json:"action"`
+	Keywords    []string            `json:"keywords"`
+	Patterns    []string            `json:"patterns"`
+	Conditions  []string            `json:"conditions"`
+	Synonyms    map[string][]string `json:"synonyms"`
+	Negations   []string            `json:"negations"`
+	FreeMarkers []string            `json:"free_markers"`
+	Exempt      []string            `json:"exempt"`
+	Disclaimer  map[string]string   `json:"disclaimer"`
+	Redaction   map[string]string   `json:"redaction"`
+
+	patterns []*regexp.Regexp
+}
+
+type SafetyConfig struct {
+	Rules            []SafetyRule `json:"rules"`
+	MaxRegenerations int          `json:"max_regenerations"`
+}
+
+// SafetyFinding is one flagged sentence.
+type SafetyFinding struct {
+	RuleID   string `json:"rule_id"`
+	Category string `json:"category"`
+	Action   string `json:"action"`
+	Match    string `json:"match"`
+	Sentence string `json:"sentence"`
+}
+
+type SafetyReport struct {
+	Findings    []SafetyFinding `json:"findings"`
+	Regenerated int             `json:"regenerated"`
+	Redacted    int             `json:"redacted"`
+	Disclaimers int             `json:"disclaimers"`
+}
+
+// SafetyRegenerator asks the model for a new answer. guidance explains what
+// was unsafe and includes the previous draft.
+type SafetyRegenerator func(guidance string) (string, error)
+
+type SafetyService struct {
+	HealthRepo repositories.IHealthBackgroundRepository
+	UserRepo   repositories.IUsersRepository
+	Config     SafetyConfig
+}
+
+type ISafetyService interface {
+	Check(text string, health *entities.HealthBackgroundModel) []SafetyFinding
+	Review(userID string, text string, regenerate SafetyRegenerator) (string, SafetyReport)
+}
+
+func DefaultSafetyConfig() SafetyConfig {
+	return SafetyConfig{
+		MaxRegenerations: 1,
+		Rules: []SafetyRule{
+			{
+				ID:       "allergen-food",
+				Category: SafetyAllergen,
+				Action:   SafetyActionRegenerate,
+				Synonyms: map[string][]string{
+					"peanut":    {"peanut", "peanuts", "peanut butter", "satay", "groundnut", "ถั่วลิสง", "เนยถั่ว", "สะเต๊ะ"},
+					"tree nut":  {"almond", "almonds", "cashew", "cashews", "walnut", "walnuts", "pecan", "pistachio", "hazelnut", "macadamia", "อัลมอนด์", "มะม่วงหิมพานต์", "วอลนัท"},
+					"nut":       {"almond", "almonds", "cashew", "cashews", "walnut", "walnuts", "pecan", "pistachio", "hazelnut", "peanut", "peanuts", "nut butter", "mixed nuts", "ถั่วลิสง", "อัลมอนด์", "มะม่วงหิมพานต์", "วอลนัท", "เนยถั่ว"},
+					"shellfish": {"shrimp", "prawn", "prawns", "crab", "lobster", "oyster", "oysters", "mussel", "mussels", "clam", "clams", "squid", "scallop", "กุ้ง", "ปู", "หอย", "ปลาหมึก"},
+					"seafood":   {"fish", "salmon", "tuna", "shrimp", "prawn", "crab", "squid", "fish sauce", "อาหารทะเล", "กุ้ง", "ปู", "หอย", "ปลาหมึก", "น้ำปลา"},
+					"fish":      {"fish", "salmon", "tuna", "mackerel", "sardine", "sardines", "cod", "tilapia", "fish oil", "fish sauce", "เนื้อปลา", "ปลาแซลมอน", "ปลาทูน่า", "ปลาทู", "น้ำปลา"},
+					"dairy":     {"milk", "cheese", "yogurt", "yoghurt", "butter", "cream", "whey", "kefir", "นมวัว", "นมสด", "ชีส", "โยเกิร์ต", "เนยสด", "ครีม"},
+					"lactose":   {"milk", "cheese", "yogurt", "yoghurt", "cream", "whey", "ice cream", "นมวัว", "นมสด", "ชีส", "โยเกิร์ต", "ครีม", "ไอศกรีม"},
+					"milk":      {"milk", "cheese", "yogurt", "yoghurt", "butter", "cream", "whey", "นมวัว", "นมสด", "ชีส", "โยเกิร์ต", "เนยสด"},
+					"egg":       {"egg", "eggs", "omelette", "omelet", "mayonnaise", "ไข่", "มายองเนส"},
+					"gluten":    {"wheat", "bread", "pasta", "barley", "rye", "couscous", "seitan", "noodles", "ข้าวสาลี", "ขนมปัง", "พาสต้า", "บะหมี่"},
+					"wheat":     {"wheat", "bread", "pasta", "couscous", "seitan", "ข้าวสาลี", "ขนมปัง", "พาสต้า"},
+					"soy":       {"soy", "soya", "tofu", "tempeh", "edamame", "soy milk", "soy sauce", "ถั่วเหลือง", "เต้าหู้", "ซีอิ๊ว"},
+					"sesame":    {"sesame", "tahini", "เมล็ดงา", "น้ำมันงา", "งาขาว", "งาดำ"},
+					// Allergies written in Thai.
+					"ถั่วลิสง":   {"peanut", "peanuts", "peanut butter", "satay", "ถั่วลิสง", "เนยถั่ว", "สะเต๊ะ"},
+					"อาหารทะเล":  {"fish", "shrimp", "prawn", "crab", "squid", "fish sauce", "อาหารทะเล", "กุ้ง", "ปู", "หอย", "ปลาหมึก", "น้ำปลา"},
+					"กุ้ง":       {"shrimp", "prawn", "prawns", "กุ้ง"},
+					"ปลา":        {"fish", "salmon", "tuna", "fish sauce", "เนื้อปลา", "ปลาแซลมอน", "ปลาทูน่า", "ปลาทู", "น้ำปลา"},
+					"นม":         {"milk", "cheese", "yogurt", "cream", "whey", "นมวัว", "นมสด", "ชีส", "โยเกิร์ต", "ครีม"},
+					"ไข่":        {"egg", "eggs", "omelette", "mayonnaise", "ไข่", "มายองเนส"},
+					"กลูเตน":     {"wheat", "bread", "pasta", "noodles", "ข้าวสาลี", "ขนมปัง", "พาสต้า", "บะหมี่"},
+					"ถั่วเหลือง": {"soy", "tofu", "soy milk", "soy sauce", "ถั่วเหลือง", "เต้าหู้", "ซีอิ๊ว"},
+					"งา":         {"sesame", "tahini", "เมล็ดงา", "น้ำมันงา", "งาขาว", "งาดำ"},
+				},
+				Negations:   []string{"no", "without", "avoid", "avoiding", "instead of", "substitute", "replace", "exclude", "skip", "free of", "free from", "don't eat", "do not eat", "allergic to", "allergy to", "ไม่ใส่", "ไม่มี", "ไม่กิน", "งด", "หลีกเลี่ยง", "ปราศจาก", "แทน", "แพ้"},
+				FreeMarkers: []string{"-free", " free", " allergy", " allergies"},
+				Disclaimer: map[string]string{
+					locale.English: "Some food suggestions were adjusted because they may conflict with your listed allergies. Always check ingredient labels.",
+					locale.Thai:    "คำแนะนำด้านอาหารบางส่วนถูกปรับ เพราะอาจขัดกับอาหารที่คุณแพ้ตามที่ระบุไว้ โปรดตรวจสอบส่วนผสมบนฉลากทุกครั้ง",
+				},
+				Redaction: map[string]string{
+					locale.English: "[Removed: this suggestion conflicts with your listed allergies.]",
+					locale.Thai:    "[นำออกแล้ว: คำแนะนำนี้ขัดกับอาหารที่คุณแพ้ตามที่ระบุไว้]",
+				},
+			},
+			{
+				ID:         "strenuous-exercise",
+				Category:   SafetyStrenuous,
+				Action:     SafetyActionDisclaimer,
+				Conditions: []string{"heart", "cardiac", "hypertension", "high blood pressure", "asthma", "copd", "pregnan", "diabetes", "epilepsy", "injury", "arthritis", "osteoporosis", "hernia", "surgery", "kidney", "stroke", "หัวใจ", "ความดัน", "หอบหืด", "ถุงลมโป่งพอง", "ตั้งครรภ์", "เบาหวาน", "ลมชัก", "บาดเจ็บ", "ข้ออักเสบ", "กระดูกพรุน", "ไส้เลื่อน", "ผ่าตัด", "ไต", "หลอดเลือดสมอง"},
+				Keywords:   []string{"hiit", "high-intensity", "high intensity", "sprint", "sprints", "marathon", "heavy lifting", "heavy weights", "max effort", "crossfit", "powerlifting", "deadlift", "burpee", "burpees", "plyometric", "interval training", "to failure", "hot yoga", "วิ่งมาราธอน", "มาราธอน", "วิ่งสปรินต์", "ยกน้ำหนักหนัก", "ออกกำลังกายหนัก", "ออกกำลังกายอย่างหนัก", "เบอร์พี", "ครอสฟิต", "โยคะร้อน"},
+				Exempt:     []string{"avoid", "instead of", "doctor", "physician", "not recommended", "หลีกเลี่ยง", "แพทย์", "หมอ", "ไม่แนะนำ"},
+				Disclaimer: map[string]string{
+					locale.English: "Because of your medical conditions, check with your doctor before starting strenuous exercise, and stop if you feel pain, dizziness or shortness of breath.",
+					locale.Thai:    "เนื่องจากโรคประจำตัวของคุณ โปรดปรึกษาแพทย์ก่อนเริ่มออกกำลังกายอย่างหนัก และหยุดทันทีหากรู้สึกเจ็บ เวียนศีรษะ หรือหายใจไม่ทัน",
+				},
+				Redaction: map[string]string{
+					locale.English: "[Removed: strenuous exercise advice that may not be safe with your medical conditions.]",
+					locale.Thai:    "[นำออกแล้ว: คำแนะนำการออกกำลังกายอย่างหนักที่อาจไม่ปลอดภัยกับโรคประจำตัวของคุณ]",
+				},
+			},
+			{
+				ID:       "medication-change",
+				Category: SafetyMedication,
+				Action:   SafetyActionRedact,
+				Keywords: []string{"stop taking", "stop your medication", "discontinue", "increase your dose", "increase the dose", "reduce your dose", "reduce the dose", "lower your dose", "double your dose", "skip your medication", "skip a dose", "switch your medication", "change your medication", "come off your medication", "wean off", "หยุดกินยา", "หยุดยา", "หยุดใช้ยา", "เลิกกินยา", "งดยา", "ข้ามยา", "เพิ่มยา", "ลดยา", "เพิ่มขนาดยา", "ลดขนาดยา", "เปลี่ยนยา"},
+				// Doses in ml or units are left out: they are mostly water
+				// and portions, and drug doses come with mg or a pill count.
+				Patterns: []string{`\b\d+(\.\d+)?\s?(mg|mcg|µg|ug|iu)\b`, `\b\d+\s?(tablets?|pills?|capsules?)\b`, `\d+(\.\d+)?\s?(มก\.|มิลลิกรัม|ไมโครกรัม)`, `ยา\S*\s?\d+\s?(เม็ด|แคปซูล)`},
+				Exempt:   []string{"ask your doctor", "consult your doctor", "talk to your doctor", "speak to your doctor", "your pharmacist", "as prescribed", "your physician", "ปรึกษาแพทย์", "ปรึกษาเภสัชกร", "ถามแพทย์", "ตามที่แพทย์สั่ง"},
+				Disclaimer: map[string]string{
+					locale.English: "This assistant cannot give medication or dosage advice. Talk to your doctor or pharmacist before changing any medication.",
+					locale.Thai:    "ผู้ช่วยนี้ไม่สามารถให้คำแนะนำเรื่องยาหรือขนาดยาได้ โปรดปรึกษาแพทย์หรือเภสัชกรก่อนเปลี่ยนแปลงการใช้ยา",
+				},
+				Redaction: map[string]string{
+					locale.English: "[Removed: medication or dosage advice. Please ask your doctor or pharmacist.]",
+					locale.Thai:    "[นำออกแล้ว: คำแนะนำเรื่องยาหรือขนาดยา โปรดปรึกษาแพทย์หรือเภสัชกร]",
+				},
+			},
+		},
+	}
+}
+
+// NewSafetyConfigFromEnv uses the built-in rules unless SAFETY_RULES_PATH
+// points to a JSON file with the same shape as SafetyConfig, in which case
+// that file replaces them. SAFETY_MAX_REGENERATIONS overrides the retry count.
+func NewSafetyConfigFromEnv() SafetyConfig {
+	config := DefaultSafetyConfig()
+	if path := os.Getenv("SAFETY_RULES_PATH"); path != "" {
+		loaded, err := LoadSafetyConfig(path)
+		if err != nil {
+			fiberlog.Errorf("SafetyService -> NewSafetyConfigFromEnv: %s \n", err)
+		} else {
+			config = *loaded
+		}
+	}
+	config.MaxRegenerations = configuration.GetEnvInt("SAFETY_MAX_REGENERATIONS", config.MaxRegenerations)
+	return config
+}
+
+func LoadSafetyConfig(path string) (*SafetyConfig, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config SafetyConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid safety rules in %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// NewSafetyService drops rules it cannot use, logging why, so a typo in the
+// rules file disables that rule instead of the whole server.
+func NewSafetyService(healthRepo repositories.IHealthBackgroundRepository, userRepo repositories.IUsersRepository, config SafetyConfig) ISafetyService {
+	rules := []SafetyRule{}
+	for _, rule := range config.Rules {
+		if err := compileSafetyRule(&rule); err != nil {
+			fiberlog.Errorf("SafetyService -> NewSafetyService: %s \n", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	config.Rules = rules
+	return &SafetyService{
+		HealthRepo: healthRepo,
+		UserRepo:   userRepo,
+		Config:     config,
+	}
+}
+
+func compileSafetyRule(rule *SafetyRule) error {
+	switch rule.Category {
+	case SafetyAllergen, SafetyStrenuous, SafetyMedication:
+	default:
+		return fmt.Errorf("safety rule %s: unknown category %q", rule.ID, rule.Category)
+	}
+	switch rule.Action {
+	case SafetyActionDisclaimer, SafetyActionRedact, SafetyActionRegenerate:
+	default:
+		return fmt.Errorf("safety rule %s: unknown action %q", rule.ID, rule.Action)
+	}
+	rule.patterns = nil
+	for _, pattern := range rule.Patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return fmt.Errorf("safety rule %s: %w", rule.ID, err)
+		}
+		rule.patterns = append(rule.patterns, re)
+	}
+	return nil
+}
+
+// Check returns every sentence of text that breaks a rule for this user.
+// health may be nil, in which case only medication rules can match.
+func (sv *SafetyService) Check(text string, health *entities.HealthBackgroundModel) []SafetyFinding {
+	findings := []SafetyFinding{}
+	for _, line := range strings.Split(text, "\n") {
+		line = listMarker.ReplaceAllString(strings.TrimSpace(line), "")
+		for _, sentence := range splitSentences(line) {
+			if finding, ok := sv.checkSentence(sentence, health); ok {
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings
+}
+
+func (sv *SafetyService) checkSentence(sentence string, health *entities.HealthBackgroundModel) (SafetyFinding, bool) {
+	lower := strings.ToLower(sentence)
+	for _, rule := range sv.Config.Rules {
+		if containsAny(lower, rule.Exempt) != "" {
+			continue
+		}
+		match := ""
+		switch rule.Category {
+		case SafetyAllergen:
+			if health != nil {
+				match = rule.allergenMatch(lower, allergenTerms(rule, health.Allergies))
+			}
+		case SafetyStrenuous:
+			if health != nil && conditionApplies(rule.Conditions, health.Medical_Conditions) {
+				match = rule.matchText(lower)
+			}
+		case SafetyMedication:
+			match = rule.matchText(lower)
+		}
+		if match != "" {
+			return SafetyFinding{
+				RuleID:   rule.ID,
+				Category: rule.Category,
+				Action:   rule.Action,
+				Match:    match,
+				Sentence: sentence,
+			}, true
+		}
+	}
+	return SafetyFinding{}, false
+}
+
+// Review checks text and returns the version that is safe to show. Rules
+// asking for regeneration call regenerate up to MaxRegenerations times; what
+// is still flagged afterwards is redacted. Disclaimers for every rule that
+// fired are appended at the end, in the user's language.
+func (sv *SafetyService) Review(userID string, text string, regenerate SafetyRegenerator) (string, SafetyReport) {
+	health, err := sv.HealthRepo.FindByUserID(userID)
+	if err != nil {
+		// Users without a health background still get the medication rules.
+		health = nil
+	}
+	report := SafetyReport{Findings: sv.Check(text, health)}
+	if len(report.Findings) == 0 {
+		return text, report
+	}
+	lang := locale.Default()
+	if sv.UserRepo != nil {
+		lang = userLanguage(sv.UserRepo, userID)
+	}
+
+	fired := append([]SafetyFinding{}, report.Findings...)
+	findings := report.Findings
+	for attempt := 0; regenerate != nil && attempt < sv.Config.MaxRegenerations && hasAction(findings, SafetyActionRegenerate); attempt++ {
+		revised, err := regenerate(regenerationGuidance(text, findings))
+		if err != nil {
+			fiberlog.Errorf("SafetyService -> Review: %s \n", err)
+			break
+		}
+		report.Regenerated++
+		text = revised
+		findings = sv.Check(text, health)
+		fired = append(fired, findings...)
+	}
+
+	redactions := map[string]string{}
+	for _, finding := range findings {
+		if finding.Action == SafetyActionDisclaimer {
+			continue
+		}
+		redactions[finding.Sentence] = safetyText(sv.rule(finding.RuleID).Redaction, lang)
+	}
+	if len(redactions) > 0 {
+		text, report.Redacted = redactSentences(text, redactions)
+	}
+
+	disclaimers := []string{}
+	seen := map[string]bool{}
+	for _, finding := range fired {
+		disclaimer := safetyText(sv.rule(finding.RuleID).Disclaimer, lang)
+		if disclaimer == "" || seen[disclaimer] {
+			continue
+		}
+		seen[disclaimer] = true
+		disclaimers = append(disclaimers, disclaimer)
+	}
+	if len(disclaimers) > 0 {
+		text = strings.TrimRight(text, "\n") + "\n\n---\n" + "⚠️ " + strings.Join(disclaimers, "\n⚠️ ")
+		report.Disclaimers = len(disclaimers)
+	}
+	return text, report
+}
+
+// safetyText is the text for lang, or the English one when there is none.
+func safetyText(texts map[string]string, lang string) string {
+	if text, ok := texts[lang]; ok {
+		return text
+	}
+	return texts[locale.English]
+}
+
+// logSafetyReport logs what the review of an answer for userID flagged and
+// what it did about it. where names the caller, as in the other logs.
+func logSafetyReport(where string, userID string, report SafetyReport) {
+	if len(report.Findings) == 0 {
+		return
+	}
+	for _, finding := range report.Findings {
+		fiberlog.Warnf("%s: safety rule %s (%s, %s) matched %q for user %s \n", where, finding.RuleID, finding.Category, finding.Action, finding.Match, userID)
+	}
+	fiberlog.Warnf("%s: %d regenerated, %d redacted, %d disclaimers for user %s \n", where, report.Regenerated, report.Redacted, report.Disclaimers, userID)
+}
+
+func (sv *SafetyService) rule(id string) SafetyRule {
+	for _, rule := range sv.Config.Rules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return SafetyRule{}
+}
+
+func (rule SafetyRule) matchText(lower string) string {
+	if match := containsAny(lower, rule.Keywords); match != "" {
+		return match
+	}
+	for _, re := range rule.patterns {
+		if match := re.FindString(lower); match != "" {
+			return match
+		}
+	}
+	return ""
+}
+
+// allergenTerms expands the user's allergies with the foods the rule lists for
+// them, so "peanuts" also catches "satay".
+func allergenTerms(rule SafetyRule, allergies []string) []string {
+	terms := []string{}
+	for _, allergy := range allergies {
+		allergy = strings.ToLower(strings.TrimSpace(allergy))
+		if allergy == "" || allergy == "none" || allergy == "-" {
+			continue
+		}
+		singular := strings.TrimSuffix(allergy, "s")
+		terms = append(terms, allergy, singular)
+		for key, foods := range rule.Synonyms {
+			if containsAny(allergy, []string{key}) != "" || containsAny(singular, []string{key}) != "" {
+				terms = append(terms, foods...)
+			}
+		}
+	}
+	return terms
+}
+
+func conditionApplies(conditions []string, userConditions []string) bool {
+	for _, userCondition := range userConditions {
+		if containsAny(strings.ToLower(userCondition), conditions) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// allergenMatch returns the first allergen term in lower that is not left
+// out by a negation tied to it, or "".
+func (rule SafetyRule) allergenMatch(lower string, terms []string) string {
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		if term == "" {
+			continue
+		}
+		for _, start := range phraseIndexes(lower, term) {
+			if !rule.allergenNegated(lower, start, start+len(term)) {
+				return strings.TrimSpace(term)
+			}
+		}
+	}
+	return ""
+}
+
+func (rule SafetyRule) allergenNegated(lower string, start int, end int) bool {
+	for _, marker := range rule.FreeMarkers {
+		if marker != "" && strings.HasPrefix(lower[end:], strings.ToLower(marker)) {
+			return true
+		}
+	}
+	return containsAny(negationWindow(lower[:start]), rule.Negations) != ""
+}
+
+var clausePunctuation = regexp.MustCompile(`[,;:!?()]`)
+
+// negationWindow is the part of before that a negation can be in and still
+// apply to what follows: the last three words of the clause. Thai separates
+// phrases rather than words with spaces, so the window ends at the first
+// Thai phrase.
+func negationWindow(before string) string {
+	if bounds := clausePunctuation.FindAllStringIndex(before, -1); len(bounds) > 0 {
+		before = before[bounds[len(bounds)-1][1]:]
+	}
+	words := strings.Fields(before)
+	window := []string{}
+	for i := len(words) - 1; i >= 0 && len(window) < 3; i-- {
+		if strings.Contains(words[i], "-") {
+			// "no-bake" or "sugar-free" describe something else.
+			window = append([]string{""}, window...)
+			continue
+		}
+		window = append([]string{words[i]}, window...)
+		if strings.ContainsFunc(words[i], func(r rune) bool { return unicode.Is(unicode.Thai, r) }) {
+			break
+		}
+	}
+	return strings.Join(window, " ")
+}
+
+// containsAny returns the first phrase found in lower as a whole word, or "".
+func containsAny(lower string, phrases []string) string {
+	for _, phrase := range phrases {
+		phrase = strings.ToLower(phrase)
+		if phrase == "" {
+			continue
+		}
+		if len(phraseIndexes(lower, phrase)) > 0 {
+			return strings.TrimSpace(phrase)
+		}
+	}
+	return ""
+}
+
+// phraseIndexes returns where phrase starts in lower as a whole word.
+func phraseIndexes(lower string, phrase string) []int {
+	indexes := []int{}
+	for start := 0; ; {
+		i := strings.Index(lower[start:], phrase)
+		if i < 0 {
+			return indexes
+		}
+		i += start
+		if isWordBoundary(lower, i-1) && isWordBoundary(lower, i+len(phrase)) {
+			indexes = append(indexes, i)
+		}
+		start = i + 1
+	}
+}
+
+func isWordBoundary(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return true
+	}
+	c := text[i]
+	return !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_')
+}
+
+// splitSentences breaks a line after ".", "!" or "?" followed by a space.
+func splitSentences(line string) []string {
+	sentences := []string{}
+	start := 0
+	for i := 0; i < len(line)-1; i++ {
+		if (line[i] == '.' || line[i] == '!' || line[i] == '?') && line[i+1] == ' ' {
+			if s := strings.TrimSpace(line[start : i+1]); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = i + 1
+		}
+	}
+	if s := strings.TrimSpace(line[start:]); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+func redactSentences(text string, redactions map[string]string) (string, int) {
+	count := 0
+	for sentence, note := range redactions {
+		if note == "" {
+			note = "[Removed]"
+		}
+		if strings.Contains(text, sentence) {
+			text = strings.ReplaceAll(text, sentence, note)
+			count++
+		}
+	}
+	return text, count
+}
+
+func hasAction(findings []SafetyFinding, action string) bool {
+	for _, finding := range findings {
+		if finding.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+func regenerationGuidance(draft string, findings []SafetyFinding) string {
+	var builder strings.Builder
+	builder.WriteString("Your previous answer contained advice that is unsafe for this user:\n")
+	for _, finding := range findings {
+		fmt.Fprintf(&builder, "- %q (%s, matched %q)\n", finding.Sentence, strings.ReplaceAll(finding.Category, "_", " "), finding.Match)
+	}
+	builder.WriteString("Rewrite the complete answer without this advice. Do not recommend foods the user is allergic to, strenuous exercise that their medical conditions make risky, or any medication dosage or medication change. Keep everything else the same.\n\n")
+	builder.WriteString("Previous answer:\n")
+	builder.WriteString(draft)
+	return builder.String()
+}
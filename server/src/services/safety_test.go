@@ -0,0 +1,228 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"strings"
+	"testing"
+)
+
+type fakeHealthRepository struct {
+	health map[string]entities.HealthBackgroundModel
+}
+
+func (repo *fakeHealthRepository) InsertHealthBackground(data entities.HealthBackgroundResponse) error {
+	return nil
+}
+
+func (repo *fakeHealthRepository) FindAll() (*[]entities.HealthBackgroundModel, error) {
+	return &[]entities.HealthBackgroundModel{}, nil
+}
+
+func (repo *fakeHealthRepository) FindByUserID(id string) (*entities.HealthBackgroundModel, error) {
+	health, ok := repo.health[id]
+	if !ok {
+		return nil, fmt.Errorf("no health background for %s", id)
+	}
+	return &health, nil
+}
+
+func (repo *fakeHealthRepository) DeleteHealthBackground(id string) error {
+	return nil
+}
+
+// fakeLLM answers plan generation with replies in order, repeating the last
+// one, and keeps the prompts and stored plans. Methods it does not override
+// panic through the nil embedded interface.
+type fakeLLM struct {
+	repositories.IAiGenRepository
+	replies []string
+	prompts []string
+	stored  []entities.GeneratedPlanResponse
+}
+
+func (llm *fakeLLM) GenerateLifeGoal(prompt string) (string, entities.LLMUsage, error) {
+	llm.prompts = append(llm.prompts, prompt)
+	reply := llm.replies[min(len(llm.prompts), len(llm.replies))-1]
+	return reply, entities.LLMUsage{Model: "fake", PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20}, nil
+}
+
+func (llm *fakeLLM) InsertGoal(data entities.GeneratedPlanResponse) (*entities.GeneratedPlan, error) {
+	llm.stored = append(llm.stored, data)
+	return &entities.GeneratedPlan{ID: "plan", UserID: data.UserID, Generated_Plan: data.Generated_Plan}, nil
+}
+
+type fakePromptRepository struct {
+	repositories.IAipromptRepository
+}
+
+func (repo *fakePromptRepository) GetPromptByUserID(id string) (*entities.AiPromptModel, error) {
+	return &entities.AiPromptModel{ID: "prompt", UserID: id, Prompt: "Make me a plan."}, nil
+}
+
+type fakeUsage struct {
+	IUsageService
+	records int
+}
+
+func (usage *fakeUsage) Record(userID string, feature string, data entities.LLMUsage) {
+	usage.records++
+}
+
+func TestSafetyCheck(t *testing.T) {
+	safety := NewSafetyService(nil, nil, DefaultSafetyConfig())
+	peanuts := &entities.HealthBackgroundModel{Allergies: []string{"peanuts"}}
+	thaiShrimp := &entities.HealthBackgroundModel{Allergies: []string{"แพ้กุ้ง"}}
+	heart := &entities.HealthBackgroundModel{Medical_Conditions: []string{"heart disease"}}
+	thaiHeart := &entities.HealthBackgroundModel{Medical_Conditions: []string{"โรคหัวใจ"}}
+	tests := []struct {
+		name     string
+		text     string
+		health   *entities.HealthBackgroundModel
+		category string
+	}{
+		{"free as a word", "Feel free to snack on peanuts.", peanuts, SafetyAllergen},
+		{"no before another word", "No problem, snack on peanuts.", peanuts, SafetyAllergen},
+		{"negation out of reach", "Avoid sugary drinks and snack on peanuts.", peanuts, SafetyAllergen},
+		{"no-bake", "No-bake peanut bars are a quick snack.", peanuts, SafetyAllergen},
+		{"synonym", "Try chicken satay for lunch.", peanuts, SafetyAllergen},
+		{"peanut-free", "Choose a peanut-free granola.", peanuts, ""},
+		{"no peanuts", "Pick a granola with no peanuts.", peanuts, ""},
+		{"without any", "Make the sauce without any peanuts.", peanuts, ""},
+		{"instead of", "Use sunflower seeds instead of peanuts.", peanuts, ""},
+		{"allergy named", "Because of your peanut allergy, skip the satay.", peanuts, ""},
+		{"thai allergen", "มื้อเย็นลองกุ้งผัดกระเทียม", thaiShrimp, SafetyAllergen},
+		{"thai english synonym", "Add grilled shrimp to the salad.", thaiShrimp, SafetyAllergen},
+		{"thai negated", "สั่งผัดไทยแบบไม่ใส่กุ้ง", thaiShrimp, ""},
+		{"thai negation out of reach", "ไม่มีปัญหา กินกุ้งได้", thaiShrimp, SafetyAllergen},
+		{"water in ml", "Drink 500 ml of water with each meal.", nil, ""},
+		{"portion in units", "Aim for 2 units of vegetables at dinner.", nil, ""},
+		{"dose in mg", "Take 200 mg of ibuprofen before bed.", nil, SafetyMedication},
+		{"pill count", "Take 2 pills of melatonin.", nil, SafetyMedication},
+		{"medication change", "You can stop taking your blood pressure pills.", nil, SafetyMedication},
+		{"thai medication change", "ถ้ารู้สึกดีขึ้นก็หยุดกินยาความดันได้", nil, SafetyMedication},
+		{"thai dose", "กินพาราเซตามอล 500 มก. ก่อนนอน", nil, SafetyMedication},
+		{"thai pill count", "กินยาแก้ปวด 2 เม็ด", nil, SafetyMedication},
+		{"thai nuts by count", "กินอัลมอนด์ 10 เม็ด เป็นของว่าง", nil, ""},
+		{"thai exempt", "ควรปรึกษาแพทย์ก่อนหยุดกินยา", nil, ""},
+		{"strenuous", "Try a 20 minute HIIT session.", heart, SafetyStrenuous},
+		{"strenuous without condition", "Try a 20 minute HIIT session.", peanuts, ""},
+		{"thai strenuous", "ลองซ้อมวิ่งมาราธอนสัปดาห์ละสามวัน", thaiHeart, SafetyStrenuous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := safety.Check(tt.text, tt.health)
+			if tt.category == "" {
+				if len(findings) != 0 {
+					t.Errorf("Check(%q) = %+v, want no findings", tt.text, findings)
+				}
+				return
+			}
+			if len(findings) != 1 || findings[0].Category != tt.category {
+				t.Errorf("Check(%q) = %+v, want one %s finding", tt.text, findings, tt.category)
+			}
+		})
+	}
+}
+
+func TestGenerateLifeGoalReviewsWithFakeLLM(t *testing.T) {
+	unsafe := "Breakfast: oatmeal.\nSnack: a handful of peanuts."
+	safe := "Breakfast: oatmeal.\nSnack: an apple."
+	tests := []struct {
+		name        string
+		replies     []string
+		wantCalls   int
+		wantPlan    []string
+		notInPlan   []string
+		wantInRetry string
+	}{
+		{
+			name:      "safe draft is kept",
+			replies:   []string{safe},
+			wantCalls: 1,
+			wantPlan:  []string{"an apple"},
+			notInPlan: []string{"⚠️"},
+		},
+		{
+			name:        "unsafe draft is regenerated",
+			replies:     []string{unsafe, safe},
+			wantCalls:   2,
+			wantPlan:    []string{"an apple", "conflict with your listed allergies"},
+			notInPlan:   []string{"peanuts"},
+			wantInRetry: "a handful of peanuts",
+		},
+		{
+			name:        "still unsafe after regenerating is redacted",
+			replies:     []string{unsafe},
+			wantCalls:   2,
+			wantPlan:    []string{"Breakfast: oatmeal.", "[Removed: this suggestion conflicts with your listed allergies.]"},
+			notInPlan:   []string{"peanuts"},
+			wantInRetry: "Rewrite the complete answer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUsersRepository{users: map[string]entities.UserProfileModel{"user": {UserID: "user"}}}
+			health := &fakeHealthRepository{health: map[string]entities.HealthBackgroundModel{"user": {UserID: "user", Allergies: []string{"Peanuts"}}}}
+			llm := &fakeLLM{replies: tt.replies}
+			usage := &fakeUsage{}
+			service := NewAiGenService(llm, &fakePromptRepository{}, nil, users, health, nil, nil, nil, usage, nil,
+				NewSafetyService(health, users, DefaultSafetyConfig()), NewPrivacyService(users, DefaultPrivacyConfig()))
+
+			plan, err := service.GenerateLifeGoal("user")
+			if err != nil {
+				t.Fatalf("GenerateLifeGoal() error = %v", err)
+			}
+			if len(llm.prompts) != tt.wantCalls || usage.records != tt.wantCalls {
+				t.Errorf("model called %d times, usage recorded %d times, want %d", len(llm.prompts), usage.records, tt.wantCalls)
+			}
+			if tt.wantInRetry != "" && !strings.Contains(llm.prompts[len(llm.prompts)-1], tt.wantInRetry) {
+				t.Errorf("regeneration prompt = %q, want it to contain %q", llm.prompts[len(llm.prompts)-1], tt.wantInRetry)
+			}
+			if len(llm.stored) != 1 || llm.stored[0].Generated_Plan != plan.Generated_Plan {
+				t.Fatalf("stored plans = %+v, want the reviewed plan once", llm.stored)
+			}
+			for _, want := range tt.wantPlan {
+				if !strings.Contains(plan.Generated_Plan, want) {
+					t.Errorf("plan = %q, want it to contain %q", plan.Generated_Plan, want)
+				}
+			}
+			for _, unwanted := range tt.notInPlan {
+				if strings.Contains(plan.Generated_Plan, unwanted) {
+					t.Errorf("plan = %q, want no %q", plan.Generated_Plan, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestSafetyReviewInUserLanguage(t *testing.T) {
+	health := &fakeHealthRepository{health: map[string]entities.HealthBackgroundModel{
+		"thai":    {UserID: "thai", Allergies: []string{"Peanuts"}},
+		"english": {UserID: "english", Allergies: []string{"Peanuts"}},
+	}}
+	users := &fakeUsersRepository{users: map[string]entities.UserProfileModel{
+		"thai":    {UserID: "thai", Language: "th"},
+		"english": {UserID: "english", Language: "en"},
+	}}
+	safety := NewSafetyService(health, users, DefaultSafetyConfig())
+	tests := []struct {
+		userID string
+		want   []string
+	}{
+		{"thai", []string{"[นำออกแล้ว: คำแนะนำนี้ขัดกับอาหารที่คุณแพ้ตามที่ระบุไว้]", "⚠️ คำแนะนำด้านอาหารบางส่วนถูกปรับ"}},
+		{"english", []string{"[Removed: this suggestion conflicts with your listed allergies.]", "⚠️ Some food suggestions were adjusted"}},
+	}
+	for _, tt := range tests {
+		text, report := safety.Review(tt.userID, "Breakfast: oatmeal.\nSnack: a handful of peanuts.", nil)
+		if report.Redacted != 1 || report.Disclaimers != 1 {
+			t.Errorf("%s: report = %+v, want one redaction and one disclaimer", tt.userID, report)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(text, want) {
+				t.Errorf("%s: Review() = %q, want it to contain %q", tt.userID, text, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"math"
+	"strings"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+// Savings goal statuses.
+const (
+	SavingsAchieved   = "achieved"
+	SavingsOnTrack    = "on_track"
+	SavingsOffTrack   = "off_track"
+	SavingsOverdue    = "overdue"
+	SavingsNoDeadline = "no_deadline"
+)
+
+const (
+	maxSavingsGoalName = 100
+	averageMonthDays   = 365.25 / 12
+)
+
+// validateSavingsGoal normalises the goal and checks that the life goal it
+// links to, if any, is the user's.
+func (sv *FinanceService) validateSavingsGoal(userID string, goal *entities.SavingsGoalResponse) error {
+	goal.Name = strings.TrimSpace(goal.Name)
+	if goal.Name == "" {
+		return invalidf("name is required")
+	}
+	if len([]rune(goal.Name)) > maxSavingsGoalName {
+		return invalidf("name cannot be longer than %d characters", maxSavingsGoalName)
+	}
+	if goal.Target <= 0 {
+		return invalidf("target must be greater than 0")
+	}
+	currency, err := money.Validate(goal.Currency)
+	if err != nil {
+		return invalid(err)
+	}
+	goal.Currency = currency
+	if err := goal.Target.CheckFits("target", currency); err != nil {
+		return invalid(err)
+	}
+	start, err := time.Parse(habitDateLayout, goal.StartDate)
+	if err != nil {
+		return invalidf("start_date must be YYYY-MM-DD")
+	}
+	if goal.Deadline != nil && *goal.Deadline == "" {
+		goal.Deadline = nil
+	}
+	if goal.Deadline != nil {
+		deadline, err := time.Parse(habitDateLayout, *goal.Deadline)
+		if err != nil {
+			return invalidf("deadline must be YYYY-MM-DD")
+		}
+		if !deadline.After(start) {
+			return invalidf("deadline must be after start_date")
+		}
+	}
+	if goal.LifeGoalID != nil && *goal.LifeGoalID == "" {
+		goal.LifeGoalID = nil
+	}
+	if goal.LifeGoalID != nil {
+		lifeGoal, err := sv.LifeGoalRepo.FindByID(*goal.LifeGoalID)
+		if err != nil || lifeGoal == nil || lifeGoal.UserID != userID {
+			return invalidf("life goal with ID %s not found", *goal.LifeGoalID)
+		}
+	}
+	return nil
+}
+
+// CreateSavingsGoal defaults the currency to the finance profile's and the
+// start date to today. What is already saved, Current, is recorded as a
+// contribution on the start date.
+func (sv *FinanceService) CreateSavingsGoal(userID string, body entities.SavingsGoalBody) (*entities.SavingsGoalProgress, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	if body.Target == nil {
+		return nil, invalidf("target is required")
+	}
+	if body.Current != nil && *body.Current < 0 {
+		return nil, invalidf("current cannot be negative")
+	}
+	today := localDate(time.Now(), userLocation(sv.UserRepo, userID))
+	now := time.Now().Add(7 * time.Hour)
+	goal := entities.SavingsGoalResponse{
+		UserID:     userID,
+		Name:       body.Name,
+		Target:     *body.Target,
+		Currency:   firstNonEmpty(body.Currency, sv.financeCurrency(userID)),
+		StartDate:  firstNonEmpty(body.StartDate, today.Format(habitDateLayout)),
+		Deadline:   body.Deadline,
+		LifeGoalID: body.LifeGoalID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if body.Notes != nil {
+		goal.Notes = strings.TrimSpace(*body.Notes)
+	}
+	if err := sv.validateSavingsGoal(userID, &goal); err != nil {
+		return nil, err
+	}
+	if body.Current != nil {
+		if err := body.Current.CheckFits("current", goal.Currency); err != nil {
+			return nil, invalid(err)
+		}
+	}
+	if goal.StartDate > today.Format(habitDateLayout) && body.Current != nil && *body.Current > 0 {
+		return nil, invalidf("a goal starting in the future cannot have savings yet")
+	}
+	data, err := sv.SavingsRepo.InsertSavingsGoal(goal)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> CreateSavingsGoal: %s \n", err)
+		return nil, err
+	}
+	contributions := []entities.SavingsContributionModel{}
+	if body.Current != nil && *body.Current > 0 {
+		contribution, err := sv.SavingsRepo.InsertContribution(entities.SavingsContributionResponse{
+			GoalID:    data.ID,
+			UserID:    userID,
+			Amount:    *body.Current,
+			Date:      data.StartDate,
+			CreatedAt: now,
+		})
+		if err != nil {
+			fiberlog.Errorf("FinanceService -> CreateSavingsGoal: %s \n", err)
+			return nil, err
+		}
+		contributions = append(contributions, *contribution)
+	}
+	progress := SavingsGoalStatus(*data, contributions, today)
+	// The goal is saved by now, so it is returned without the base currency
+	// figures rather than reported as not created.
+	if err := sv.savingsInBaseCurrency(&progress, sv.financeCurrency(userID), today); err != nil {
+		fiberlog.Errorf("FinanceService -> CreateSavingsGoal: %s \n", err)
+	}
+	return &progress, nil
+}
+
+// GetSavingsGoals lists the user's goals with their progress, only those
+// linked to lifeGoalID when it is not empty.
+func (sv *FinanceService) GetSavingsGoals(userID string, lifeGoalID string) (*[]entities.SavingsGoalProgress, error) {
+	goals, err := sv.SavingsRepo.GetSavingsGoalsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> GetSavingsGoals: %s \n", err)
+		return nil, err
+	}
+	contributions, err := sv.SavingsRepo.GetContributionsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> GetSavingsGoals: %s \n", err)
+		return nil, err
+	}
+	byGoal := map[string][]entities.SavingsContributionModel{}
+	for _, contribution := range *contributions {
+		byGoal[contribution.GoalID] = append(byGoal[contribution.GoalID], contribution)
+	}
+	today := localDate(time.Now(), userLocation(sv.UserRepo, userID))
+	base := sv.financeCurrency(userID)
+	progress := []entities.SavingsGoalProgress{}
+	for _, goal := range *goals {
+		if lifeGoalID != "" && (goal.LifeGoalID == nil || *goal.LifeGoalID != lifeGoalID) {
+			continue
+		}
+		status := SavingsGoalStatus(goal, byGoal[goal.ID], today)
+		if err := sv.savingsInBaseCurrency(&status, base, today); err != nil {
+			fiberlog.Errorf("FinanceService -> GetSavingsGoals: %s \n", err)
+			return nil, err
+		}
+		progress = append(progress, status)
+	}
+	return &progress, nil
+}
+
+func (sv *FinanceService) userSavingsGoal(userID string, goalID string) (*entities.SavingsGoalModel, error) {
+	goal, err := sv.SavingsRepo.GetSavingsGoalByID(goalID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> userSavingsGoal: %s \n", err)
+		return nil, err
+	}
+	if goal.UserID != userID {
+		return nil, fmt.Errorf("savings goal does not belong to user")
+	}
+	return goal, nil
+}
+
+// savingsGoalProgress is the goal's progress with its contributions listed.
+func (sv *FinanceService) savingsGoalProgress(userID string, goal entities.SavingsGoalModel) (*entities.SavingsGoalProgress, error) {
+	contributions, err := sv.SavingsRepo.GetContributionsByGoalID(goal.ID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> savingsGoalProgress: %s \n", err)
+		return nil, err
+	}
+	today := localDate(time.Now(), userLocation(sv.UserRepo, userID))
+	progress := SavingsGoalStatus(goal, *contributions, today)
+	if err := sv.savingsInBaseCurrency(&progress, sv.financeCurrency(userID), today); err != nil {
+		fiberlog.Errorf("FinanceService -> savingsGoalProgress: %s \n", err)
+		return nil, err
+	}
+	progress.Contributions = contributions
+	return &progress, nil
+}
+
+// savingsInBaseCurrency adds what has been saved and the target in the base
+// currency at today's rate, when the goal is in another currency. It fails
+// when an amount is too large to convert.
+func (sv *FinanceService) savingsInBaseCurrency(progress *entities.SavingsGoalProgress, base string, today time.Time) error {
+	if base == "" {
+		return nil
+	}
+	progress.BaseCurrency = base
+	date := today.Format(habitDateLayout)
+	convert := sv.converter()
+	current, ok, err := convertAmount(convert, progress.Current, progress.Currency, base, date)
+	if err != nil {
+		return err
+	}
+	if ok {
+		progress.CurrentInBase = &current
+	}
+	target, ok, err := convertAmount(convert, progress.Target, progress.Currency, base, date)
+	if err != nil {
+		return err
+	}
+	if ok {
+		progress.TargetInBase = &target
+	}
+	return nil
+}
+
+func (sv *FinanceService) GetSavingsGoal(userID string, goalID string) (*entities.SavingsGoalProgress, error) {
+	goal, err := sv.userSavingsGoal(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return sv.savingsGoalProgress(userID, *goal)
+}
+
+func (sv *FinanceService) UpdateSavingsGoal(userID string, goalID string, body entities.SavingsGoalBody) (*entities.SavingsGoalProgress, error) {
+	current, err := sv.userSavingsGoal(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if body.Current != nil {
+		return nil, invalidf("current cannot be edited; add a contribution instead")
+	}
+	if body.Currency != "" && money.Normalize(body.Currency) != current.Currency {
+		contributions, err := sv.SavingsRepo.GetContributionsByGoalID(goalID)
+		if err != nil {
+			fiberlog.Errorf("FinanceService -> UpdateSavingsGoal: %s \n", err)
+			return nil, err
+		}
+		if len(*contributions) > 0 {
+			return nil, invalidf("currency cannot change once the goal has contributions")
+		}
+	}
+	goal := entities.SavingsGoalResponse{
+		Name:       firstNonEmpty(body.Name, current.Name),
+		Target:     current.Target,
+		Currency:   firstNonEmpty(body.Currency, current.Currency),
+		StartDate:  firstNonEmpty(body.StartDate, current.StartDate),
+		Deadline:   current.Deadline,
+		LifeGoalID: current.LifeGoalID,
+		Notes:      current.Notes,
+	}
+	if body.Target != nil {
+		goal.Target = *body.Target
+	}
+	if body.Deadline != nil {
+		goal.Deadline = body.Deadline
+	}
+	if body.LifeGoalID != nil {
+		goal.LifeGoalID = body.LifeGoalID
+	}
+	if body.Notes != nil {
+		goal.Notes = strings.TrimSpace(*body.Notes)
+	}
+	if err := sv.validateSavingsGoal(userID, &goal); err != nil {
+		return nil, err
+	}
+	data, err := sv.SavingsRepo.UpdateSavingsGoal(goalID, entities.SavingsGoalUpdate{
+		Name:       goal.Name,
+		Target:     goal.Target,
+		Currency:   goal.Currency,
+		StartDate:  goal.StartDate,
+		Deadline:   goal.Deadline,
+		LifeGoalID: goal.LifeGoalID,
+		Notes:      goal.Notes,
+		UpdatedAt:  time.Now().Add(7 * time.Hour),
+	})
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> UpdateSavingsGoal: %s \n", err)
+		return nil, err
+	}
+	return sv.savingsGoalProgress(userID, *data)
+}
+
+func (sv *FinanceService) DeleteSavingsGoal(userID string, goalID string) error {
+	if _, err := sv.userSavingsGoal(userID, goalID); err != nil {
+		return err
+	}
+	if err := sv.SavingsRepo.DeleteSavingsGoal(goalID); err != nil {
+		fiberlog.Errorf("FinanceService -> DeleteSavingsGoal: %s \n", err)
+		return err
+	}
+	return nil
+}
+
+// AddContribution records money put towards the goal, or taken out of it
+// with a negative amount, on a date that defaults to today. A withdrawal
+// cannot take out more than has been saved.
+func (sv *FinanceService) AddContribution(userID string, goalID string, data entities.SavingsContributionResponse) (*entities.SavingsGoalProgress, error) {
+	goal, err := sv.userSavingsGoal(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if data.Amount == 0 {
+		return nil, invalidf("amount cannot be 0")
+	}
+	if err := data.Amount.CheckFits("amount", goal.Currency); err != nil {
+		return nil, invalid(err)
+	}
+	today := localDate(time.Now(), userLocation(sv.UserRepo, userID)).Format(habitDateLayout)
+	data.Date = firstNonEmpty(data.Date, today)
+	if _, err := time.Parse(habitDateLayout, data.Date); err != nil {
+		return nil, invalidf("date must be YYYY-MM-DD")
+	}
+	if data.Date > today {
+		return nil, invalidf("date cannot be in the future")
+	}
+	if data.Date < goal.StartDate {
+		return nil, invalidf("date cannot be before the goal's start_date")
+	}
+	if data.Amount < 0 {
+		contributions, err := sv.SavingsRepo.GetContributionsByGoalID(goalID)
+		if err != nil {
+			fiberlog.Errorf("FinanceService -> AddContribution: %s \n", err)
+			return nil, err
+		}
+		if savedAmount(*contributions)+data.Amount < 0 {
+			return nil, invalidf("cannot withdraw more than has been saved")
+		}
+	}
+	data.GoalID = goalID
+	data.UserID = userID
+	data.Notes = strings.TrimSpace(data.Notes)
+	data.CreatedAt = time.Now().Add(7 * time.Hour)
+	if _, err := sv.SavingsRepo.InsertContribution(data); err != nil {
+		fiberlog.Errorf("FinanceService -> AddContribution: %s \n", err)
+		return nil, err
+	}
+	return sv.savingsGoalProgress(userID, *goal)
+}
+
+func (sv *FinanceService) DeleteContribution(userID string, goalID string, contributionID string) (*entities.SavingsGoalProgress, error) {
+	goal, err := sv.userSavingsGoal(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	contribution, err := sv.SavingsRepo.GetContributionByID(contributionID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> DeleteContribution: %s \n", err)
+		return nil, err
+	}
+	if contribution.GoalID != goalID {
+		return nil, fmt.Errorf("contribution does not belong to savings goal")
+	}
+	if err := sv.SavingsRepo.DeleteContribution(contributionID); err != nil {
+		fiberlog.Errorf("FinanceService -> DeleteContribution: %s \n", err)
+		return nil, err
+	}
+	return sv.savingsGoalProgress(userID, *goal)
+}
+
+func savedAmount(contributions []entities.SavingsContributionModel) money.Amount {
+	var saved money.Amount
+	for _, contribution := range contributions {
+		saved += contribution.Amount
+	}
+	return saved
+}
+
+// SavingsGoalStatus works out the goal's progress on today from its
+// contributions. Months are 365.25/12 days. A goal is on track while at least
+// as much is saved as an even pace from its start date to its deadline would
+// have by today.
+func SavingsGoalStatus(goal entities.SavingsGoalModel, contributions []entities.SavingsContributionModel, today time.Time) entities.SavingsGoalProgress {
+	progress := entities.SavingsGoalProgress{SavingsGoalModel: goal}
+	current := savedAmount(contributions)
+	progress.Current = current
+	progress.Remaining = max(goal.Target-current, 0)
+	if goal.Target > 0 {
+		progress.Progress = roundTo(money.Ratio(current, goal.Target)*100, 1)
+	}
+
+	start, err := time.Parse(habitDateLayout, goal.StartDate)
+	if err != nil {
+		start = today
+	}
+	monthsSaving := math.Max(today.Sub(start).Hours()/24/averageMonthDays, 1)
+	average := current.Float() / monthsSaving
+	progress.AverageMonthly = max(money.FromFloat(average).Round(goal.Currency), 0)
+	if progress.Remaining > 0 && average > 0 {
+		projected := today.AddDate(0, 0, int(math.Ceil(progress.Remaining.Float()/average*averageMonthDays))).Format(habitDateLayout)
+		progress.ProjectedDate = &projected
+	}
+
+	var deadline time.Time
+	if goal.Deadline != nil {
+		deadline, err = time.Parse(habitDateLayout, *goal.Deadline)
+	}
+	switch {
+	case current >= goal.Target:
+		progress.Status = SavingsAchieved
+	case goal.Deadline == nil || err != nil:
+		progress.Status = SavingsNoDeadline
+	case today.After(deadline):
+		progress.Status = SavingsOverdue
+	default:
+		progress.Status = SavingsOnTrack
+	}
+	if goal.Deadline == nil || err != nil {
+		return progress
+	}
+
+	monthsLeft := math.Max(deadline.Sub(today).Hours()/24/averageMonthDays, 0)
+	required := progress.Remaining
+	if monthsLeft > 1 {
+		required = money.FromFloat(progress.Remaining.Float() / monthsLeft).Round(goal.Currency)
+	}
+	progress.MonthsLeft = floatPtr(roundTo(monthsLeft, 1))
+	progress.RequiredMonthly = &required
+
+	share := 1.0
+	if total := deadline.Sub(start).Hours(); total > 0 {
+		share = math.Min(math.Max(today.Sub(start).Hours()/total, 0), 1)
+	}
+	expected := money.FromFloat(goal.Target.Float() * share).Round(goal.Currency)
+	progress.Expected = &expected
+	if progress.Status == SavingsOnTrack && current < expected {
+		progress.Status = SavingsOffTrack
+	}
+	return progress
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
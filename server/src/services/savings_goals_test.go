@@ -0,0 +1,43 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"testing"
+)
+
+func TestValidateSavingsGoalReturnsValidationErrors(t *testing.T) {
+	service := &FinanceService{}
+	valid := entities.SavingsGoalResponse{Name: "Trip", Target: money.FromMinor(3000000, "THB"), Currency: "THB", StartDate: "2026-01-01"}
+	tests := []struct {
+		name   string
+		change func(goal *entities.SavingsGoalResponse)
+	}{
+		{name: "no name", change: func(goal *entities.SavingsGoalResponse) { goal.Name = " " }},
+		{name: "zero target", change: func(goal *entities.SavingsGoalResponse) { goal.Target = 0 }},
+		{name: "unknown currency", change: func(goal *entities.SavingsGoalResponse) { goal.Currency = "XYZ" }},
+		{name: "too many decimals", change: func(goal *entities.SavingsGoalResponse) {
+			goal.Currency = "JPY"
+			goal.Target = money.FromMinor(1005, "USD")
+		}},
+		{name: "bad start date", change: func(goal *entities.SavingsGoalResponse) { goal.StartDate = "soon" }},
+		{name: "deadline before start", change: func(goal *entities.SavingsGoalResponse) {
+			deadline := "2025-12-31"
+			goal.Deadline = &deadline
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := valid
+			tt.change(&goal)
+			err := service.validateSavingsGoal("user", &goal)
+			if !IsValidationError(err) {
+				t.Errorf("validateSavingsGoal() error = %v, want a validation error", err)
+			}
+		})
+	}
+	goal := valid
+	if err := service.validateSavingsGoal("user", &goal); err != nil {
+		t.Errorf("validateSavingsGoal() error = %v, want nil", err)
+	}
+}
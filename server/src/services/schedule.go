@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"go-fiber-template/domain/entities"
 	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/recurrence"
+	"sort"
+	"strings"
 
 	"time"
 
@@ -20,6 +23,11 @@ type IScheduleService interface {
 	GetScheduleByID(id string) (*entities.ScheduleModel, error)
 	GetScheduleByUserID(id string) (*entities.ScheduleModel, error)
 	UpdateSchedule(id string, schedule entities.ScheduleResponse) error
+	AddScheduleBlock(id string, block entities.ScheduleBlockResponse) error
+	GetScheduleBlocksByUserID(id string) (*[]entities.ScheduleBlockModel, error)
+	// GetScheduleOccurrences expands the user's blocks into dated occurrences
+	// from from to to, both inclusive.
+	GetScheduleOccurrences(id string, from time.Time, to time.Time) (*[]entities.ScheduleOccurrence, error)
 }
 func NewScheduleService(scheduleRepo repositories.IScheduleRepository) IScheduleService {
 	return &ScheduleService{
@@ -90,4 +98,147 @@ func (sv *ScheduleService) UpdateSchedule(id string, schedule entities.ScheduleR
 	return nil
 }
 
+var scheduleDays = map[string]string{
+	"mon": "Monday", "monday": "Monday",
+	"tue": "Tuesday", "tuesday": "Tuesday",
+	"wed": "Wednesday", "wednesday": "Wednesday",
+	"thu": "Thursday", "thursday": "Thursday",
+	"fri": "Friday", "friday": "Friday",
+	"sat": "Saturday", "saturday": "Saturday",
+	"sun": "Sunday", "sunday": "Sunday",
+}
+
+var weekdaysByName = map[string]time.Weekday{
+	"Sunday": time.Sunday, "Monday": time.Monday, "Tuesday": time.Tuesday, "Wednesday": time.Wednesday,
+	"Thursday": time.Thursday, "Friday": time.Friday, "Saturday": time.Saturday,
+}
+
+// ValidateScheduleBlock normalises day names and checks the time fields. A
+// block repeats by its recurrence rule when it has one, such as
+// FREQ=MONTHLY;BYDAY=1SU, and otherwise weekly on its days. Days are filled
+// from the rule's BYDAY so clients that only read days keep working.
+func ValidateScheduleBlock(block *entities.ScheduleBlockResponse) error {
+	block.Title = strings.TrimSpace(block.Title)
+	if block.Title == "" {
+		return fmt.Errorf("schedule block title cannot be empty")
+	}
+	if block.Recurrence == "" && len(block.Days) == 0 {
+		return fmt.Errorf("schedule block needs at least one day")
+	}
+	days := make([]string, 0, len(block.Days))
+	for _, day := range block.Days {
+		name, ok := scheduleDays[strings.ToLower(strings.TrimSpace(day))]
+		if !ok {
+			return fmt.Errorf("invalid day %q", day)
+		}
+		days = append(days, name)
+	}
+	block.Days = days
+	if block.Recurrence != "" {
+		rule, err := recurrence.Parse(block.Recurrence)
+		if err != nil {
+			return fmt.Errorf("invalid recurrence: %s", err)
+		}
+		if rule.Flexible() {
+			return fmt.Errorf("schedule block recurrence must name its days, e.g. FREQ=WEEKLY;BYDAY=MO")
+		}
+		block.Recurrence = rule.String()
+		if len(rule.ByDay) > 0 {
+			block.Days = []string{}
+			for _, day := range rule.Weekdays() {
+				block.Days = append(block.Days, day.String())
+			}
+		}
+	} else {
+		block.Recurrence = scheduleBlockRule(block.Days).String()
+	}
+	if _, err := time.Parse("15:04", block.StartTime); err != nil {
+		return fmt.Errorf("start time must be HH:MM")
+	}
+	if block.DurationMinutes < 5 || block.DurationMinutes > 12*60 {
+		return fmt.Errorf("duration must be between 5 and 720 minutes")
+	}
+	return nil
+}
+
+// scheduleBlockRule repeats weekly on days, which are normalised day names.
+func scheduleBlockRule(days []string) recurrence.Rule {
+	rule := recurrence.Rule{Freq: recurrence.Weekly, Interval: 1}
+	for _, day := range days {
+		rule.ByDay = append(rule.ByDay, recurrence.WeekdayNum{Day: weekdaysByName[day]})
+	}
+	return rule
+}
+
+func (sv *ScheduleService) AddScheduleBlock(id string, block entities.ScheduleBlockResponse) error {
+	if id == "" {
+		return fmt.Errorf("userID cannot be empty")
+	}
+	if err := ValidateScheduleBlock(&block); err != nil {
+		fiberlog.Errorf("ScheduleService -> AddScheduleBlock: %s \n", err)
+		return err
+	}
+	block.UserID = id
+	block.CreatedAt = time.Now().Add(7 * time.Hour)
+	block.UpdatedAt = time.Now().Add(7 * time.Hour)
+
+	err := sv.ScheduleRepo.CreateScheduleBlock(block)
+	if err != nil {
+		fiberlog.Errorf("ScheduleService -> AddScheduleBlock: %s \n", err)
+		fmt.Println("Error inserting schedule block:", err)
+		return err
+	}
+	return nil
+}
+
+func (sv *ScheduleService) GetScheduleBlocksByUserID(id string) (*[]entities.ScheduleBlockModel, error) {
+	data, err := sv.ScheduleRepo.GetScheduleBlocksByUserID(id)
+	if err != nil {
+		fiberlog.Errorf("ScheduleService -> GetScheduleBlocksByUserID: %s \n", err)
+		fmt.Println("Error fetching schedule blocks by user ID:", err)
+		return nil, err
+	}
+	return data, nil
+}
 
+// GetScheduleOccurrences lists every block's occurrences in date order, then
+// by start time. Blocks stored before recurrence rules existed repeat weekly
+// on their days.
+func (sv *ScheduleService) GetScheduleOccurrences(id string, from time.Time, to time.Time) (*[]entities.ScheduleOccurrence, error) {
+	if err := validateOccurrenceRange(from, to); err != nil {
+		return nil, err
+	}
+	blocks, err := sv.ScheduleRepo.GetScheduleBlocksByUserID(id)
+	if err != nil {
+		fiberlog.Errorf("ScheduleService -> GetScheduleOccurrences: %s \n", err)
+		return nil, err
+	}
+	occurrences := []entities.ScheduleOccurrence{}
+	for _, block := range *blocks {
+		rule, err := recurrence.Parse(block.Recurrence)
+		if block.Recurrence == "" || err != nil {
+			rule = scheduleBlockRule(block.Days)
+		}
+		start := from
+		if !block.CreatedAt.IsZero() {
+			start = block.CreatedAt
+		}
+		for _, occurrence := range rule.Between(start, from, to.AddDate(0, 0, 1)) {
+			occurrences = append(occurrences, entities.ScheduleOccurrence{
+				BlockID:         block.ID,
+				Title:           block.Title,
+				Date:            occurrence.Start.Format(habitDateLayout),
+				StartTime:       block.StartTime,
+				DurationMinutes: block.DurationMinutes,
+				Notes:           block.Notes,
+			})
+		}
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if occurrences[i].Date != occurrences[j].Date {
+			return occurrences[i].Date < occurrences[j].Date
+		}
+		return occurrences[i].StartTime < occurrences[j].StartTime
+	})
+	return &occurrences, nil
+}
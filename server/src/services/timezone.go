@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/repositories"
+	"os"
+	"time"
+	// Embedded so timezones resolve on images without a zoneinfo database.
+	_ "time/tzdata"
+)
+
+// DefaultTimezone is used for users without a timezone on their profile.
+// It is DEFAULT_TIMEZONE when set, otherwise Asia/Bangkok.
+func DefaultTimezone() string {
+	if name := os.Getenv("DEFAULT_TIMEZONE"); name != "" {
+		return name
+	}
+	return "Asia/Bangkok"
+}
+
+// LoadTimezone loads an IANA timezone name; an empty name is the default.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone()
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return location, nil
+}
+
+// userLocation returns the user's timezone, or the default one when the
+// profile cannot be read or names an unknown zone.
+func userLocation(userRepo repositories.IUsersRepository, userID string) *time.Location {
+	name := ""
+	if user, err := userRepo.FindByID(userID); err == nil && user != nil {
+		name = user.Timezone
+	}
+	return timezoneOrDefault(name)
+}
+
+// timezoneOrDefault loads name, falling back to the default timezone and then
+// UTC when it cannot be loaded.
+func timezoneOrDefault(name string) *time.Location {
+	if location, err := LoadTimezone(name); err == nil {
+		return location
+	}
+	if location, err := LoadTimezone(""); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// localDate is the calendar date of t in location, at midnight UTC, so dates
+// from different zones compare and format the same way.
+func localDate(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
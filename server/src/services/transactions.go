@@ -0,0 +1,626 @@
+package services
+
+import (
+	"fmt"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"sort"
+	"strings"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	TransactionIncome  = "income"
+	TransactionExpense = "expense"
+
+	ExpenseFixed    = "fixed"
+	ExpenseFlexible = "flexible"
+)
+
+const (
+	monthLayout             = "2006-01"
+	defaultTransactionCat   = "other"
+	maxTransactionCatLength = 40
+	defaultFinanceMonths    = 6
+	maxFinanceMonths        = 24
+	recentFinanceMonths     = 3
+)
+
+// fixedExpenseCategories default to fixed expenses; every other category is
+// flexible unless the transaction says otherwise.
+var fixedExpenseCategories = map[string]bool{
+	"housing": true, "rent": true, "mortgage": true, "utilities": true, "insurance": true,
+	"debt": true, "loan": true, "education": true, "tuition": true, "subscriptions": true,
+	"childcare": true, "phone": true, "internet": true,
+}
+
+// TransactionQuery filters the transactions listed. Dates are inclusive.
+type TransactionQuery struct {
+	From     *time.Time
+	To       *time.Time
+	Type     string
+	Category string
+}
+
+// ParseTransactionQuery reads the from and to (YYYY-MM-DD), type and
+// category query parameters.
+func ParseTransactionQuery(from string, to string, kind string, category string) (TransactionQuery, error) {
+	query := TransactionQuery{Type: strings.ToLower(kind), Category: normalizeCategory(category)}
+	if category == "" {
+		query.Category = ""
+	}
+	if query.Type != "" && query.Type != TransactionIncome && query.Type != TransactionExpense {
+		return TransactionQuery{}, fmt.Errorf("type must be income or expense")
+	}
+	for _, param := range []struct {
+		Name  string
+		Value string
+		Date  **time.Time
+	}{{"from", from, &query.From}, {"to", to, &query.To}} {
+		if param.Value == "" {
+			continue
+		}
+		date, err := time.Parse(habitDateLayout, param.Value)
+		if err != nil {
+			return TransactionQuery{}, fmt.Errorf("invalid %s date: %s", param.Name, param.Value)
+		}
+		*param.Date = &date
+	}
+	return query, nil
+}
+
+// MonthRange is a span of months, both inclusive, given as their first
+// days. Either end left out is filled in by GetMonthlySummaries.
+type MonthRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// ParseMonthRange reads the from and to months (YYYY-MM).
+func ParseMonthRange(from string, to string) (MonthRange, error) {
+	months := MonthRange{}
+	for _, param := range []struct {
+		Name  string
+		Value string
+		Month **time.Time
+	}{{"from", from, &months.From}, {"to", to, &months.To}} {
+		if param.Value == "" {
+			continue
+		}
+		month, err := time.Parse(monthLayout, param.Value)
+		if err != nil {
+			return MonthRange{}, fmt.Errorf("invalid %s month: %s", param.Name, param.Value)
+		}
+		*param.Month = &month
+	}
+	return months, nil
+}
+
+// resolve fills in the months left out: to defaults to the current month
+// in location and from to six months up to to.
+func (months MonthRange) resolve(location *time.Location) (time.Time, time.Time, error) {
+	now := localDate(time.Now(), location)
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if months.To != nil {
+		end = *months.To
+	}
+	start := end.AddDate(0, 1-defaultFinanceMonths, 0)
+	if months.From != nil {
+		start = *months.From
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, invalidf("from month cannot be after to month")
+	}
+	if monthsBetween(start, end) >= maxFinanceMonths {
+		return time.Time{}, time.Time{}, invalidf("month range cannot be longer than %d months", maxFinanceMonths)
+	}
+	return start, end, nil
+}
+
+func monthsBetween(from time.Time, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+func normalizeCategory(category string) string {
+	category = strings.Join(strings.Fields(strings.ToLower(category)), " ")
+	if category == "" {
+		return defaultTransactionCat
+	}
+	return category
+}
+
+// ValidateTransaction checks a transaction and fills in its defaults: the
+// category other, and fixed or flexible from the category for expenses.
+func ValidateTransaction(transaction *entities.TransactionResponse) error {
+	transaction.Type = strings.ToLower(strings.TrimSpace(transaction.Type))
+	if transaction.Type != TransactionIncome && transaction.Type != TransactionExpense {
+		return invalidf("type must be income or expense")
+	}
+	if transaction.Amount <= 0 {
+		return invalidf("amount must be greater than 0")
+	}
+	currency, err := money.Validate(transaction.Currency)
+	if err != nil {
+		return invalid(err)
+	}
+	transaction.Currency = currency
+	if err := transaction.Amount.CheckFits("amount", currency); err != nil {
+		return invalid(err)
+	}
+	transaction.Category = normalizeCategory(transaction.Category)
+	if len([]rune(transaction.Category)) > maxTransactionCatLength {
+		return invalidf("category cannot be longer than %d characters", maxTransactionCatLength)
+	}
+	transaction.ExpenseType = strings.ToLower(strings.TrimSpace(transaction.ExpenseType))
+	switch {
+	case transaction.Type == TransactionIncome:
+		transaction.ExpenseType = ""
+	case transaction.ExpenseType == "" && fixedExpenseCategories[transaction.Category]:
+		transaction.ExpenseType = ExpenseFixed
+	case transaction.ExpenseType == "":
+		transaction.ExpenseType = ExpenseFlexible
+	case transaction.ExpenseType != ExpenseFixed && transaction.ExpenseType != ExpenseFlexible:
+		return invalidf("expense_type must be fixed or flexible")
+	}
+	date, err := time.Parse(habitDateLayout, transaction.Date)
+	if err != nil {
+		return invalidf("date must be YYYY-MM-DD")
+	}
+	if transaction.RecurringEnd != nil && *transaction.RecurringEnd == "" {
+		transaction.RecurringEnd = nil
+	}
+	if transaction.RecurringEnd != nil {
+		if !transaction.Recurring {
+			return invalidf("recurring_end needs a recurring transaction")
+		}
+		end, err := time.Parse(habitDateLayout, *transaction.RecurringEnd)
+		if err != nil {
+			return invalidf("recurring_end must be YYYY-MM-DD")
+		}
+		if end.Before(date) {
+			return invalidf("recurring_end cannot be before date")
+		}
+	}
+	return nil
+}
+
+// financeCurrency is the currency of the user's finance profile, or empty
+// when they have none.
+func (sv *FinanceService) financeCurrency(userID string) string {
+	if finance, err := sv.FinanceRepo.GetAllFinanceByUserID(userID); err == nil && finance != nil {
+		return profileCurrency(finance.Currency)
+	}
+	return ""
+}
+
+// profileCurrency is currency as an ISO 4217 code, or empty when the
+// finance profile holds something else, such as a currency name.
+func profileCurrency(currency string) string {
+	currency = money.Normalize(currency)
+	if !money.Valid(currency) {
+		return ""
+	}
+	return currency
+}
+
+// converter converts with the exchange rates, or only passes amounts through
+// when there are none.
+func (sv *FinanceService) converter() CurrencyConverter {
+	if sv.Rates == nil {
+		return nil
+	}
+	return sv.Rates.Convert
+}
+
+// CreateTransaction defaults the date to today in the user's timezone and
+// the currency to the one on their finance profile.
+func (sv *FinanceService) CreateTransaction(userID string, body entities.TransactionBody) (*entities.TransactionModel, error) {
+	if body.Amount == nil {
+		return nil, invalidf("amount is required")
+	}
+	now := time.Now().Add(7 * time.Hour)
+	transaction := entities.TransactionResponse{
+		UserID:       userID,
+		Type:         body.Type,
+		Amount:       *body.Amount,
+		Currency:     firstNonEmpty(body.Currency, sv.financeCurrency(userID)),
+		Category:     body.Category,
+		ExpenseType:  body.ExpenseType,
+		Date:         body.Date,
+		RecurringEnd: body.RecurringEnd,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if transaction.Date == "" {
+		transaction.Date = localDate(time.Now(), userLocation(sv.UserRepo, userID)).Format(habitDateLayout)
+	}
+	if body.Notes != nil {
+		transaction.Notes = strings.TrimSpace(*body.Notes)
+	}
+	if body.Recurring != nil {
+		transaction.Recurring = *body.Recurring
+	}
+	if err := ValidateTransaction(&transaction); err != nil {
+		return nil, err
+	}
+	data, err := sv.TransactionRepo.InsertTransaction(transaction)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> CreateTransaction: %s \n", err)
+		return nil, err
+	}
+	if data.Type == TransactionExpense {
+		go sv.alertBudgets(userID)
+	}
+	return data, nil
+}
+
+// ListTransactions returns the stored transactions, newest first. Recurring
+// transactions are listed once, on the date they started.
+func (sv *FinanceService) ListTransactions(userID string, query TransactionQuery) (*[]entities.TransactionModel, error) {
+	data, err := sv.TransactionRepo.GetTransactionsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> ListTransactions: %s \n", err)
+		return nil, err
+	}
+	transactions := []entities.TransactionModel{}
+	for _, transaction := range *data {
+		date, err := time.Parse(habitDateLayout, transaction.Date)
+		if err != nil {
+			continue
+		}
+		if (query.From != nil && date.Before(*query.From)) || (query.To != nil && date.After(*query.To)) {
+			continue
+		}
+		if (query.Type != "" && transaction.Type != query.Type) || (query.Category != "" && transaction.Category != query.Category) {
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date > transactions[j].Date
+	})
+	return &transactions, nil
+}
+
+func (sv *FinanceService) userTransaction(userID string, transactionID string) (*entities.TransactionModel, error) {
+	transaction, err := sv.TransactionRepo.GetTransactionByID(transactionID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> userTransaction: %s \n", err)
+		return nil, err
+	}
+	if transaction.UserID != userID {
+		return nil, fmt.Errorf("transaction does not belong to user")
+	}
+	return transaction, nil
+}
+
+func (sv *FinanceService) UpdateTransaction(userID string, transactionID string, body entities.TransactionBody) (*entities.TransactionModel, error) {
+	current, err := sv.userTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	transaction := entities.TransactionResponse{
+		UserID:       userID,
+		Type:         firstNonEmpty(body.Type, current.Type),
+		Amount:       current.Amount,
+		Currency:     firstNonEmpty(body.Currency, current.Currency),
+		Category:     firstNonEmpty(body.Category, current.Category),
+		Date:         firstNonEmpty(body.Date, current.Date),
+		Notes:        current.Notes,
+		Recurring:    current.Recurring,
+		RecurringEnd: current.RecurringEnd,
+	}
+	// A new category picks its own default unless the type is given too.
+	if body.ExpenseType != "" || body.Category == "" {
+		transaction.ExpenseType = firstNonEmpty(body.ExpenseType, current.ExpenseType)
+	}
+	if body.Amount != nil {
+		transaction.Amount = *body.Amount
+	}
+	if body.Notes != nil {
+		transaction.Notes = strings.TrimSpace(*body.Notes)
+	}
+	if body.Recurring != nil {
+		transaction.Recurring = *body.Recurring
+		if !transaction.Recurring {
+			transaction.RecurringEnd = nil
+		}
+	}
+	if body.RecurringEnd != nil {
+		transaction.RecurringEnd = body.RecurringEnd
+	}
+	if err := ValidateTransaction(&transaction); err != nil {
+		return nil, err
+	}
+	data, err := sv.TransactionRepo.UpdateTransaction(transactionID, entities.TransactionUpdate{
+		Type:         transaction.Type,
+		Amount:       transaction.Amount,
+		Currency:     transaction.Currency,
+		Category:     transaction.Category,
+		ExpenseType:  transaction.ExpenseType,
+		Date:         transaction.Date,
+		Notes:        transaction.Notes,
+		Recurring:    transaction.Recurring,
+		RecurringEnd: transaction.RecurringEnd,
+		UpdatedAt:    time.Now().Add(7 * time.Hour),
+	})
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> UpdateTransaction: %s \n", err)
+		return nil, err
+	}
+	if data.Type == TransactionExpense {
+		go sv.alertBudgets(userID)
+	}
+	return data, nil
+}
+
+func (sv *FinanceService) DeleteTransaction(userID string, transactionID string) error {
+	if _, err := sv.userTransaction(userID, transactionID); err != nil {
+		return err
+	}
+	if err := sv.TransactionRepo.DeleteTransaction(transactionID); err != nil {
+		fiberlog.Errorf("FinanceService -> DeleteTransaction: %s \n", err)
+		return err
+	}
+	return nil
+}
+
+// GetMonthlySummaries totals each month in months in the user's base
+// currency, the one of their finance profile.
+func (sv *FinanceService) GetMonthlySummaries(userID string, months MonthRange) (*[]entities.FinanceMonthSummary, error) {
+	from, to, err := months.resolve(userLocation(sv.UserRepo, userID))
+	if err != nil {
+		return nil, err
+	}
+	data, err := sv.TransactionRepo.GetTransactionsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> GetMonthlySummaries: %s \n", err)
+		return nil, err
+	}
+	summaries, err := MonthlyFinanceSummaries(*data, sv.financeCurrency(userID), from, to, sv.converter())
+	if err != nil {
+		fiberlog.Errorf("FinanceService -> GetMonthlySummaries: %s \n", err)
+		return nil, err
+	}
+	return &summaries, nil
+}
+
+// TransactionOccursIn reports the day transaction falls on in month, the
+// first of a month. A recurring transaction falls on the same day of every
+// month from its date on, or on the month's last day when it is shorter.
+func TransactionOccursIn(transaction entities.TransactionModel, month time.Time) (time.Time, bool) {
+	date, err := time.Parse(habitDateLayout, transaction.Date)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if start.Equal(month) {
+		return date, true
+	}
+	if !transaction.Recurring || month.Before(start) {
+		return time.Time{}, false
+	}
+	lastDay := month.AddDate(0, 1, -1).Day()
+	day := month.AddDate(0, 0, min(date.Day(), lastDay)-1)
+	if transaction.RecurringEnd != nil {
+		if end, err := time.Parse(habitDateLayout, *transaction.RecurringEnd); err == nil && day.After(end) {
+			return time.Time{}, false
+		}
+	}
+	return day, true
+}
+
+// MonthlyFinanceSummaries totals each month from from to to in currency,
+// converting each occurrence of a transaction at the rate of its day and
+// rounding it to currency's minor unit. Without a currency the one used by
+// the most transactions is taken.
+func MonthlyFinanceSummaries(transactions []entities.TransactionModel, currency string, from time.Time, to time.Time, convert CurrencyConverter) ([]entities.FinanceMonthSummary, error) {
+	if currency == "" {
+		currency = mostUsedCurrency(transactions)
+	}
+	summaries := []entities.FinanceMonthSummary{}
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		summary := entities.FinanceMonthSummary{
+			Month:         month.Format(monthLayout),
+			Currency:      currency,
+			Expense:       []entities.FinanceCategorySummary{},
+			IncomeSources: []entities.FinanceCategorySummary{},
+		}
+		expenses := map[string]*entities.FinanceCategorySummary{}
+		income := map[string]*entities.FinanceCategorySummary{}
+		others := map[string]bool{}
+		for _, transaction := range transactions {
+			day, ok := TransactionOccursIn(transaction, month)
+			if !ok {
+				continue
+			}
+			amount, ok, err := convertAmount(convert, transaction.Amount, transaction.Currency, currency, day.Format(habitDateLayout))
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				others[transaction.Currency] = true
+				continue
+			}
+			summary.Transactions++
+			categories := expenses
+			if transaction.Type == TransactionIncome {
+				categories = income
+				summary.Income += amount
+			} else {
+				summary.Expenses += amount
+				if transaction.ExpenseType == ExpenseFixed {
+					summary.FixedExpenses += amount
+				} else {
+					summary.FlexibleExpenses += amount
+				}
+			}
+			key := transaction.Category + "\x00" + transaction.ExpenseType
+			if categories[key] == nil {
+				categories[key] = &entities.FinanceCategorySummary{Category: transaction.Category, ExpenseType: transaction.ExpenseType}
+			}
+			categories[key].Amount += amount
+			categories[key].Count++
+		}
+		summary.Expense = categorySummaries(expenses, summary.Expenses)
+		summary.IncomeSources = categorySummaries(income, summary.Income)
+		summary.OtherCurrencies = currencyList(others)
+		summary.Net = summary.Income - summary.Expenses
+		if summary.Income > 0 {
+			summary.SavingsRate = roundTo(money.Ratio(summary.Net, summary.Income), 4)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func categorySummaries(categories map[string]*entities.FinanceCategorySummary, total money.Amount) []entities.FinanceCategorySummary {
+	list := []entities.FinanceCategorySummary{}
+	for _, category := range categories {
+		if total > 0 {
+			category.Share = roundTo(money.Ratio(category.Amount, total), 4)
+		}
+		list = append(list, *category)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Amount != list[j].Amount {
+			return list[i].Amount > list[j].Amount
+		}
+		return list[i].Category < list[j].Category
+	})
+	return list
+}
+
+func mostUsedCurrency(transactions []entities.TransactionModel) string {
+	counts := map[string]int{}
+	best := ""
+	for _, transaction := range transactions {
+		counts[transaction.Currency]++
+		if counts[transaction.Currency] > counts[best] || (counts[transaction.Currency] == counts[best] && transaction.Currency < best) {
+			best = transaction.Currency
+		}
+	}
+	return best
+}
+
+// RecentFinanceMonths picks the summaries the plan prompt uses: the last
+// three complete months before today's that have transactions, or today's
+// month when none of them has.
+func RecentFinanceMonths(summaries []entities.FinanceMonthSummary, today time.Time) []entities.FinanceMonthSummary {
+	current := today.Format(monthLayout)
+	earliest := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -recentFinanceMonths, 0).Format(monthLayout)
+	recent := []entities.FinanceMonthSummary{}
+	var currentMonth *entities.FinanceMonthSummary
+	for i, summary := range summaries {
+		switch {
+		case summary.Transactions == 0:
+		case summary.Month == current:
+			currentMonth = &summaries[i]
+		case summary.Month >= earliest && summary.Month < current:
+			recent = append(recent, summary)
+		}
+	}
+	if len(recent) == 0 && currentMonth != nil {
+		recent = append(recent, *currentMonth)
+	}
+	return recent
+}
+
+// FinanceWithSummaries replaces the income and expenses of the finance
+// profile with the monthly averages of summaries, in their currency.
+func FinanceWithSummaries(finance entities.FinanceModel, summaries []entities.FinanceMonthSummary) (entities.FinanceModel, error) {
+	if len(summaries) == 0 {
+		return finance, nil
+	}
+	var income, expenses money.Amount
+	for _, summary := range summaries {
+		income += summary.Income
+		expenses += summary.Expenses
+	}
+	income, err := income.Div(int64(len(summaries)))
+	if err != nil {
+		return finance, err
+	}
+	expenses, err = expenses.Div(int64(len(summaries)))
+	if err != nil {
+		return finance, err
+	}
+	finance.Currency = summaries[0].Currency
+	finance.Income = income.Round(finance.Currency)
+	finance.Expenses = expenses.Round(finance.Currency)
+	return finance, nil
+}
+
+var spendingPromptTexts = map[string]map[string]string{
+	"en": {
+		"intro":         "My tracked spending, averaged over %s: fixed expenses %s %s and flexible expenses %s %s a month.",
+		"category":      "%s %s %s (%s)",
+		"largest":       " Largest expense categories: %s.",
+		ExpenseFixed:    "fixed",
+		ExpenseFlexible: "flexible",
+	},
+	"th": {
+		"intro":         "ค่าใช้จ่ายที่ฉันบันทึกไว้ เฉลี่ยช่วง %s: ค่าใช้จ่ายคงที่ %s %s และค่าใช้จ่ายผันแปร %s %s ต่อเดือน",
+		"category":      "%s %s %s (%s)",
+		"largest":       " หมวดที่ใช้จ่ายมากที่สุด: %s",
+		ExpenseFixed:    "คงที่",
+		ExpenseFlexible: "ผันแปร",
+	},
+}
+
+// SpendingPrompt describes the average monthly spending of summaries for
+// the plan prompt, with the five largest expense categories.
+func SpendingPrompt(lang string, summaries []entities.FinanceMonthSummary) (string, error) {
+	if len(summaries) == 0 {
+		return "", nil
+	}
+	texts := spendingPromptTexts[lang]
+	if texts == nil {
+		texts = spendingPromptTexts["en"]
+	}
+	currency := summaries[0].Currency
+	months := int64(len(summaries))
+	var fixed, flexible money.Amount
+	categories := map[string]*entities.FinanceCategorySummary{}
+	for _, summary := range summaries {
+		fixed += summary.FixedExpenses
+		flexible += summary.FlexibleExpenses
+		for _, category := range summary.Expense {
+			key := category.Category + "\x00" + category.ExpenseType
+			if categories[key] == nil {
+				categories[key] = &entities.FinanceCategorySummary{Category: category.Category, ExpenseType: category.ExpenseType}
+			}
+			categories[key].Amount += category.Amount
+		}
+	}
+	period := summaries[0].Month
+	if len(summaries) > 1 {
+		period += " – " + summaries[len(summaries)-1].Month
+	}
+	fixed, err := fixed.Div(months)
+	if err != nil {
+		return "", err
+	}
+	flexible, err = flexible.Div(months)
+	if err != nil {
+		return "", err
+	}
+	prompt := fmt.Sprintf(texts["intro"], period, fixed.Format(currency), currency, flexible.Format(currency), currency)
+	largest := []string{}
+	for i, category := range categorySummaries(categories, 0) {
+		if i == 5 {
+			break
+		}
+		average, err := category.Amount.Div(months)
+		if err != nil {
+			return "", err
+		}
+		largest = append(largest, fmt.Sprintf(texts["category"], category.Category, average.Format(currency), currency, texts[category.ExpenseType]))
+	}
+	if len(largest) > 0 {
+		prompt += fmt.Sprintf(texts["largest"], strings.Join(largest, ", "))
+	}
+	return prompt, nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/money"
+	"testing"
+)
+
+func TestValidateTransactionReturnsValidationErrors(t *testing.T) {
+	end := "2026-01-01"
+	tests := []struct {
+		name        string
+		transaction entities.TransactionResponse
+	}{
+		{name: "unknown type", transaction: entities.TransactionResponse{Type: "gift", Amount: money.FromMinor(100, "THB"), Currency: "THB", Date: "2026-02-01"}},
+		{name: "zero amount", transaction: entities.TransactionResponse{Type: TransactionExpense, Currency: "THB", Date: "2026-02-01"}},
+		{name: "unknown currency", transaction: entities.TransactionResponse{Type: TransactionExpense, Amount: money.FromMinor(100, "THB"), Currency: "XYZ", Date: "2026-02-01"}},
+		{name: "bad date", transaction: entities.TransactionResponse{Type: TransactionExpense, Amount: money.FromMinor(100, "THB"), Currency: "THB", Date: "01/02/2026"}},
+		{name: "end before date", transaction: entities.TransactionResponse{Type: TransactionExpense, Amount: money.FromMinor(100, "THB"), Currency: "THB", Date: "2026-02-01", Recurring: true, RecurringEnd: &end}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTransaction(&tt.transaction)
+			if !IsValidationError(err) {
+				t.Errorf("ValidateTransaction() error = %v, want a validation error", err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go-fiber-template/domain/entities"
 	"go-fiber-template/domain/repositories"
+	"go-fiber-template/src/locale"
 
 	// "go-fiber-template/httpclient"
 	"time"
@@ -51,6 +52,15 @@ func (sv *usersService) GetAllUsers() (*[]entities.UserProfileModel, error) {
 
 func (sv *usersService) InsertNewUser(id string, data entities.UserProfileResponse) error {
 	data.UserID = id
+	data.TenantID = ""
+	if data.Language == "" {
+		data.Language = locale.Default()
+	} else if data.Language = locale.Normalize(data.Language); data.Language == "" {
+		return fmt.Errorf("unsupported language")
+	}
+	if _, err := LoadTimezone(data.Timezone); err != nil {
+		return err
+	}
 	data.CreatedAt = time.Now().Add(7 * time.Hour)
 	data.UpdatedAt = time.Now().Add(7 * time.Hour)
 	err := sv.UsersRepository.InsertUser(data)
@@ -103,6 +113,19 @@ func (sv *usersService) UpdateUser(data entities.UserProfileModel) error {
 	if data.Fullname == "" {
 		data.Fullname = OriginalData.Fullname
 	}
+	// The tenant decides how much personal data is redacted, so only an
+	// administrator may change it.
+	data.TenantID = OriginalData.TenantID
+	if data.Timezone == "" {
+		data.Timezone = OriginalData.Timezone
+	} else if _, err := LoadTimezone(data.Timezone); err != nil {
+		return err
+	}
+	if data.Language == "" {
+		data.Language = OriginalData.Language
+	} else if data.Language = locale.Normalize(data.Language); data.Language == "" {
+		return fmt.Errorf("unsupported language")
+	}
 
 	err = sv.UsersRepository.UpdateUser(data)
 	if err != nil {
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"encoding/json"
+	"go-fiber-template/domain/entities"
+	"strings"
+	"testing"
+)
+
+// insertRecordingUsersRepository keeps the JSON body of the last insert.
+type insertRecordingUsersRepository struct {
+	fakeUsersRepository
+	body string
+}
+
+func (repo *insertRecordingUsersRepository) InsertUser(data entities.UserProfileResponse) error {
+	body, err := json.Marshal(data)
+	repo.body = string(body)
+	return err
+}
+
+func TestInsertNewUserDoesNotSendTenant(t *testing.T) {
+	repo := &insertRecordingUsersRepository{}
+	service := NewUsersService(repo, nil, nil, nil, nil, nil)
+	if err := service.InsertNewUser("user", entities.UserProfileResponse{Fullname: "Somchai", TenantID: "clinic", Timezone: "Asia/Bangkok"}); err != nil {
+		t.Fatalf("InsertNewUser() error = %v", err)
+	}
+	if strings.Contains(repo.body, "tenant_id") {
+		t.Errorf("insert body = %s, want no tenant_id so an upsert keeps the stored tenant", repo.body)
+	}
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationError is a problem with what the caller sent, such as an
+// unknown currency or an amount with too many decimal places. Its message
+// is meant for them, unlike repository errors, which are only logged.
+type ValidationError struct {
+	Message string
+}
+
+func (err *ValidationError) Error() string {
+	return err.Message
+}
+
+// invalidf formats a ValidationError.
+func invalidf(format string, args ...any) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// invalid marks err, from checking the caller's input, as a ValidationError.
+func invalid(err error) error {
+	if err == nil || IsValidationError(err) {
+		return err
+	}
+	return &ValidationError{Message: err.Error()}
+}
+
+// IsValidationError reports whether err is or wraps a ValidationError.
+func IsValidationError(err error) bool {
+	var validation *ValidationError
+	return errors.As(err, &validation)
+}
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/configuration"
+	"go-fiber-template/domain/aimodel"
+	"go-fiber-template/domain/entities"
+	"go-fiber-template/domain/repositories"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+)
+
+const reviewDateLayout = "2006-01-02"
+
+// reviewPlanMaxChars bounds how much of the active plan goes into the prompt.
+const reviewPlanMaxChars = 6000
+
+// WeeklyReviewConfig controls the scheduled run. Reviews cover the previous
+// Monday to Sunday and are generated from Hour on Weekday in each user's
+// timezone onwards. CheckInterval is how often the scheduler looks for users
+// that are still missing a review.
+type WeeklyReviewConfig struct {
+	Enabled       bool
+	Weekday       time.Weekday
+	Hour          int
+	CheckInterval time.Duration
+}
+
+type WeeklyReviewService struct {
+	ReviewRepo repositories.IWeeklyReviewRepository
+	AiGenRepo  repositories.IAiGenRepository
+	HabitsRepo repositories.IHabitRepository
+	MoodRepo   repositories.IMoodRepository
+	UserRepo   repositories.IUsersRepository
+	Usage      IUsageService
+	Safety     ISafetyService
+	Privacy    IPrivacyService
+	Config     WeeklyReviewConfig
+	running    sync.Mutex
+}
+
+type IWeeklyReviewService interface {
+	// Generate writes the review of the week starting weekStart, replacing an
+	// earlier review of the same week. A zero weekStart means last week.
+	Generate(userID string, weekStart time.Time) (*entities.WeeklyReviewModel, error)
+	GetReviewsByUserID(userID string) (*[]entities.WeeklyReviewModel, error)
+	GetLatestReview(userID string) (*entities.WeeklyReviewModel, error)
+	Start()
+}
+
+func NewWeeklyReviewConfigFromEnv() WeeklyReviewConfig {
+	return WeeklyReviewConfig{
+		Enabled:       configuration.GetEnvInt("WEEKLY_REVIEW_ENABLED", 1) == 1,
+		Weekday:       time.Weekday(configuration.GetEnvInt("WEEKLY_REVIEW_DAY", int(time.Monday)) % 7),
+		Hour:          configuration.GetEnvInt("WEEKLY_REVIEW_HOUR", 6),
+		CheckInterval: time.Duration(configuration.GetEnvInt("WEEKLY_REVIEW_CHECK_MINUTES", 60)) * time.Minute,
+	}
+}
+
+func NewWeeklyReviewService(reviewRepo repositories.IWeeklyReviewRepository, aiGenRepo repositories.IAiGenRepository, habitsRepo repositories.IHabitRepository, moodRepo repositories.IMoodRepository, userRepo repositories.IUsersRepository, usage IUsageService, safety ISafetyService, privacy IPrivacyService, config WeeklyReviewConfig) IWeeklyReviewService {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = time.Hour
+	}
+	return &WeeklyReviewService{
+		ReviewRepo: reviewRepo,
+		AiGenRepo:  aiGenRepo,
+		HabitsRepo: habitsRepo,
+		MoodRepo:   moodRepo,
+		UserRepo:   userRepo,
+		Usage:      usage,
+		Safety:     safety,
+		Privacy:    privacy,
+		Config:     config,
+	}
+}
+
+// ReviewWeekStart returns the Monday of the last complete week before now.
+func ReviewWeekStart(now time.Time) time.Time {
+	return WeekStart(now).AddDate(0, 0, -7)
+}
+
+// WeekStart returns the Monday of the week containing t, at midnight.
+func WeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func (sv *WeeklyReviewService) Generate(userID string, weekStart time.Time) (*entities.WeeklyReviewModel, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	location := userLocation(sv.UserRepo, userID)
+	if weekStart.IsZero() {
+		weekStart = ReviewWeekStart(time.Now().In(location))
+	}
+	weekStart = WeekStart(weekStart)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	habits, err := sv.HabitsRepo.GetHabitsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewService -> Generate: %s \n", err)
+		return nil, err
+	}
+	checkIns, err := sv.HabitsRepo.GetCheckInsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewService -> Generate: %s \n", err)
+		return nil, err
+	}
+	moods, err := sv.MoodRepo.GetMoodById(userID)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewService -> Generate: %s \n", err)
+		return nil, err
+	}
+	plans, err := sv.AiGenRepo.GetGenGoalByUserID(userID)
+	if err != nil {
+		plans = &[]entities.GeneratedPlan{}
+	}
+
+	habitStats := WeeklyHabitStats(*habits, *checkIns, weekStart, location)
+	weekMoods := moodsInWeek(*moods, weekStart, location)
+	moodStat := weeklyMoodStat(weekMoods)
+	plan := activePlan(*plans)
+
+	redaction := sv.Privacy.NewRedaction(userID)
+	prompt := weeklyReviewPrompt(weekStart, habitStats, weekMoods, plan, userLanguage(sv.UserRepo, userID))
+	prompt = withInstruction(redaction.Redact(prompt), redaction.Instruction())
+	text, usage, err := sv.AiGenRepo.GenerateWeeklyReview(prompt)
+	sv.Usage.Record(userID, aimodel.FeatureReview, usage)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewService -> Generate: %s \n", err)
+		return nil, err
+	}
+	content, err := ParseWeeklyReview(redaction.Restore(text))
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewService -> Generate: %s \n", err)
+		return nil, err
+	}
+	// Adjustments are advice, so they get the same health-safety review as
+	// plans and chat replies.
+	for i, adjustment := range content.Adjustments {
+		var report SafetyReport
+		content.Adjustments[i], report = sv.Safety.Review(userID, adjustment, nil)
+		logSafetyReport("WeeklyReviewService -> Generate", userID, report)
+	}
+
+	data := entities.WeeklyReviewResponse{
+		UserID:      userID,
+		WeekStart:   weekStart.Format(reviewDateLayout),
+		WeekEnd:     weekEnd.Format(reviewDateLayout),
+		Summary:     content.Summary,
+		Wins:        content.Wins,
+		Misses:      content.Misses,
+		Patterns:    content.Patterns,
+		Adjustments: content.Adjustments,
+		Habits:      habitStats,
+		Mood:        moodStat,
+		CreatedAt:   time.Now().Add(7 * time.Hour),
+	}
+	if plan != nil {
+		data.PlanID = &plan.ID
+	}
+	review, err := sv.ReviewRepo.UpsertReview(data)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewService -> Generate: %s \n", err)
+		return nil, err
+	}
+	return review, nil
+}
+
+func (sv *WeeklyReviewService) GetReviewsByUserID(userID string) (*[]entities.WeeklyReviewModel, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	data, err := sv.ReviewRepo.GetReviewsByUserID(userID)
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewService -> GetReviewsByUserID: %s \n", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+func (sv *WeeklyReviewService) GetLatestReview(userID string) (*entities.WeeklyReviewModel, error) {
+	data, err := sv.GetReviewsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(*data) == 0 {
+		return nil, fmt.Errorf("no weekly review found for user ID: %s", userID)
+	}
+	return &(*data)[0], nil
+}
+
+// Start runs the scheduler in the background.
+func (sv *WeeklyReviewService) Start() {
+	if !sv.Config.Enabled {
+		return
+	}
+	go func() {
+		sv.runDue()
+		ticker := time.NewTicker(sv.Config.CheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			sv.runDue()
+		}
+	}()
+}
+
+// runDue reviews last week for every user whose local review time has come,
+// who has no review of it yet and had any habit or mood activity. A failed
+// user is retried on the next check until their week is over.
+func (sv *WeeklyReviewService) runDue() {
+	if !sv.running.TryLock() {
+		return
+	}
+	defer sv.running.Unlock()
+
+	users, err := sv.UserRepo.FindAll()
+	if err != nil {
+		fiberlog.Errorf("WeeklyReviewService -> runDue: %s \n", err)
+		return
+	}
+	for _, user := range *users {
+		now := time.Now().In(timezoneOrDefault(user.Timezone))
+		if !reviewDue(now, sv.Config) {
+			continue
+		}
+		weekStart := ReviewWeekStart(now)
+		existing, err := sv.ReviewRepo.GetReviewByWeek(user.UserID, weekStart.Format(reviewDateLayout))
+		if err != nil || existing != nil {
+			continue
+		}
+		if !sv.hadActivity(user.UserID, weekStart) {
+			continue
+		}
+		if _, err := sv.Generate(user.UserID, weekStart); err != nil {
+			fiberlog.Errorf("WeeklyReviewService -> runDue: user %s: %s \n", user.UserID, err)
+		}
+	}
+}
+
+// reviewDue is true from the configured weekday and hour until the end of
+// that week, so a server that was down at the scheduled time catches up.
+func reviewDue(now time.Time, config WeeklyReviewConfig) bool {
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	scheduledDay := (int(config.Weekday) + 6) % 7
+	if daysSinceMonday != scheduledDay {
+		return daysSinceMonday > scheduledDay
+	}
+	return now.Hour() >= config.Hour
+}
+
+func (sv *WeeklyReviewService) hadActivity(userID string, weekStart time.Time) bool {
+	location := userLocation(sv.UserRepo, userID)
+	first, last := weekStart.Format(habitDateLayout), weekStart.AddDate(0, 0, 6).Format(habitDateLayout)
+	if checkIns, err := sv.HabitsRepo.GetCheckInsByUserID(userID); err == nil {
+		for _, checkIn := range *checkIns {
+			if checkIn.Date >= first && checkIn.Date <= last {
+				return true
+			}
+		}
+	}
+	if moods, err := sv.MoodRepo.GetMoodById(userID); err == nil {
+		return len(moodsInWeek(*moods, weekStart, location)) > 0
+	}
+	return false
+}
+
+// WeeklyHabitStats counts completions in the week starting weekStart from
+// the user's check-ins. A habit is expected TargetCount times for every
+// occurrence of its rule that lies wholly in the week, so a Mon/Wed/Fri
+// habit is expected three times and a monthly one not at all. Each
+// occurrence adds at most TargetCount completions, counting only its
+// check-ins in the week, and check-ins on days the habit is not due do not
+// count. Paused habits have no target and archived habits are left out.
+// location is the user's timezone.
+func WeeklyHabitStats(habits []entities.HabitModel, checkIns []entities.HabitCheckInModel, weekStart time.Time, location *time.Location) []entities.WeeklyHabitStat {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	first, last := weekStart.Format(habitDateLayout), weekEnd.AddDate(0, 0, -1).Format(habitDateLayout)
+	byHabit := map[int][]entities.HabitCheckInModel{}
+	for _, checkIn := range checkIns {
+		byHabit[checkIn.HabitID] = append(byHabit[checkIn.HabitID], checkIn)
+	}
+	stats := []entities.WeeklyHabitStat{}
+	for _, habit := range habits {
+		if (!habit.CreatedAt.IsZero() && !habitCreatedDate(habit, location).Before(weekEnd)) || habit.Status == HabitStatusArchived {
+			continue
+		}
+		stat := entities.WeeklyHabitStat{
+			HabitID:    habit.ID,
+			Name:       habit.Name,
+			Category:   habit.Category,
+			Frequency:  habit.Frequency,
+			Recurrence: HabitRule(habit).String(),
+		}
+		for _, occurrence := range HabitOccurrences(habit, byHabit[habit.ID], weekStart, weekEnd, weekStart, location) {
+			if occurrence.Start >= first && occurrence.End <= last {
+				if !occurrence.Paused || occurrence.Completed {
+					stat.Expected += occurrence.Target
+				}
+				stat.Completed += min(occurrence.CheckIns, occurrence.Target)
+				continue
+			}
+			inWeek := 0
+			for _, checkIn := range byHabit[habit.ID] {
+				if checkIn.Date >= max(first, occurrence.Start) && checkIn.Date <= min(last, occurrence.End) {
+					inWeek++
+				}
+			}
+			stat.Completed += min(inWeek, occurrence.Target)
+		}
+		if habit.Status == HabitStatusPaused {
+			stat.Expected = 0
+		}
+		if stat.Expected > 0 {
+			stat.Rate = min(float64(stat.Completed)/float64(stat.Expected), 1)
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// moodsInWeek returns the entries dated in the week starting weekStart, with
+// Date filled in for entries logged before dates were stored.
+func moodsInWeek(moods []entities.MoodModel, weekStart time.Time, location *time.Location) []entities.MoodModel {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	inWeek := []entities.MoodModel{}
+	for _, mood := range moods {
+		date := MoodDate(mood, location)
+		if !date.Before(weekStart) && date.Before(weekEnd) {
+			mood.Date = date.Format(habitDateLayout)
+			inWeek = append(inWeek, mood)
+		}
+	}
+	sort.Slice(inWeek, func(i, j int) bool {
+		if inWeek[i].Date != inWeek[j].Date {
+			return inWeek[i].Date < inWeek[j].Date
+		}
+		return inWeek[i].CreatedAt.Before(inWeek[j].CreatedAt)
+	})
+	return inWeek
+}
+
+func weeklyMoodStat(moods []entities.MoodModel) entities.WeeklyMoodStat {
+	stat := entities.WeeklyMoodStat{Entries: len(moods), Counts: map[string]int{}}
+	scores := []int{}
+	for _, mood := range moods {
+		key := strings.ToLower(strings.TrimSpace(mood.Mood))
+		if score := MoodScore(mood); score > 0 {
+			scores = append(scores, score)
+		}
+		stat.Counts[key]++
+		if stat.Counts[key] > stat.Counts[stat.MostCommon] || (stat.Counts[key] == stat.Counts[stat.MostCommon] && key < stat.MostCommon) {
+			stat.MostCommon = key
+		}
+	}
+	if len(scores) > 0 {
+		average := moodDimension(scores, nil, 0).Average
+		stat.AverageScore = &average
+	}
+	return stat
+}
+
+// activePlan is the most recently generated plan or revision.
+func activePlan(plans []entities.GeneratedPlan) *entities.GeneratedPlan {
+	var active *entities.GeneratedPlan
+	for i := range plans {
+		if active == nil || plans[i].CreatedAt.After(active.CreatedAt) {
+			active = &plans[i]
+		}
+	}
+	return active
+}
+
+func weeklyReviewPrompt(weekStart time.Time, habits []entities.WeeklyHabitStat, moods []entities.MoodModel, plan *entities.GeneratedPlan, lang string) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "You are the user's AI life-planning coach. Write their review of the week from %s to %s.\n", weekStart.Format(reviewDateLayout), weekStart.AddDate(0, 0, 6).Format(reviewDateLayout))
+	builder.WriteString("Base every statement on the data below. Wins are habits kept or plan steps advanced, misses are habits or plan steps that slipped, patterns connect habits and mood (for example days with low mood and skipped habits), and adjustments are small, concrete changes for next week.\n\n")
+
+	builder.WriteString("Habit completions this week, with each habit's schedule as an RRULE:\n")
+	if len(habits) == 0 {
+		builder.WriteString("- no habits tracked\n")
+	}
+	for _, habit := range habits {
+		if habit.Expected > 0 {
+			fmt.Fprintf(&builder, "- %s (%s, %s): %d of %d\n", habit.Name, habit.Recurrence, habit.Category, habit.Completed, habit.Expected)
+		} else {
+			fmt.Fprintf(&builder, "- %s (%s, %s): %d times\n", habit.Name, habit.Recurrence, habit.Category, habit.Completed)
+		}
+	}
+
+	builder.WriteString("\nMood entries this week:\n")
+	if len(moods) == 0 {
+		builder.WriteString("- no mood entries\n")
+	}
+	for _, mood := range moods {
+		date, _ := time.Parse(habitDateLayout, mood.Date)
+		fmt.Fprintf(&builder, "- %s %s", date.Format("Mon 2006-01-02"), mood.Mood)
+		if levels := moodLevels(mood); levels[0] > 0 {
+			fmt.Fprintf(&builder, " (mood %d/5", levels[0])
+			if levels[1] > 0 {
+				fmt.Fprintf(&builder, ", energy %d/5", levels[1])
+			}
+			if levels[2] > 0 {
+				fmt.Fprintf(&builder, ", stress %d/5", levels[2])
+			}
+			builder.WriteString(")")
+		}
+		if len(mood.Tags) > 0 {
+			fmt.Fprintf(&builder, " [%s]", strings.Join(mood.Tags, ", "))
+		}
+		if note := strings.TrimSpace(mood.Note); note != "" {
+			fmt.Fprintf(&builder, ": %s", note)
+		}
+		builder.WriteString("\n")
+	}
+
+	if plan != nil {
+		builder.WriteString("\nThe user's current life plan:\n")
+		builder.WriteString(firstN(plan.Generated_Plan, reviewPlanMaxChars))
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("\nAnswer with a single JSON object and nothing else, in this shape: ")
+	builder.WriteString(`{"summary":"two or three sentences","wins":["..."],"misses":["..."],"patterns":["..."],"adjustments":["..."]}`)
+	builder.WriteString(". Use at most five items per list and an empty list when there is nothing to say. ")
+	builder.WriteString(replyLanguageInstruction(lang))
+	return builder.String()
+}
+
+// ParseWeeklyReview reads the JSON object from the model's answer, ignoring
+// Markdown code fences and text around it.
+func ParseWeeklyReview(text string) (*entities.WeeklyReviewContent, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("weekly review is not a JSON object")
+	}
+	var content entities.WeeklyReviewContent
+	if err := json.Unmarshal([]byte(text[start:end+1]), &content); err != nil {
+		return nil, fmt.Errorf("invalid weekly review: %w", err)
+	}
+	content.Summary = strings.TrimSpace(content.Summary)
+	content.Wins = cleanReviewItems(content.Wins)
+	content.Misses = cleanReviewItems(content.Misses)
+	content.Patterns = cleanReviewItems(content.Patterns)
+	content.Adjustments = cleanReviewItems(content.Adjustments)
+	if content.Summary == "" && len(content.Wins)+len(content.Misses)+len(content.Patterns)+len(content.Adjustments) == 0 {
+		return nil, fmt.Errorf("weekly review is empty")
+	}
+	return &content, nil
+}
+
+func cleanReviewItems(items []string) []string {
+	cleaned := []string{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			cleaned = append(cleaned, item)
+		}
+	}
+	return cleaned
+}
+
+func firstN(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n])
+}
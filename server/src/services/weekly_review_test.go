@@ -0,0 +1,111 @@
+package services
+
+import (
+	"go-fiber-template/domain/entities"
+	"testing"
+	"time"
+)
+
+func TestWeeklyHabitStatsCountsCheckIns(t *testing.T) {
+	// Monday 6 April 2026.
+	weekStart := time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(7 * time.Hour)
+	checkIns := func(habitID int, times int, dates ...string) []entities.HabitCheckInModel {
+		models := []entities.HabitCheckInModel{}
+		for _, date := range dates {
+			for i := 0; i < times; i++ {
+				models = append(models, entities.HabitCheckInModel{HabitID: habitID, Date: date})
+			}
+		}
+		return models
+	}
+	week := []string{"2026-04-06", "2026-04-07", "2026-04-08", "2026-04-09", "2026-04-10", "2026-04-11", "2026-04-12"}
+	tests := []struct {
+		name      string
+		habit     entities.HabitModel
+		checkIns  []entities.HabitCheckInModel
+		completed int
+		expected  int
+	}{
+		{
+			name:      "twice a day done twice every day",
+			habit:     entities.HabitModel{ID: 1, Recurrence: "FREQ=DAILY", TargetCount: 2},
+			checkIns:  checkIns(1, 2, week...),
+			completed: 14, expected: 14,
+		},
+		{
+			name:      "extra check-ins do not count past the target",
+			habit:     entities.HabitModel{ID: 1, Recurrence: "FREQ=DAILY", TargetCount: 2},
+			checkIns:  append(checkIns(1, 3, week[:3]...), checkIns(1, 1, week[3])...),
+			completed: 7, expected: 14,
+		},
+		{
+			name:      "check-ins on days not due",
+			habit:     entities.HabitModel{ID: 1, Recurrence: "FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+			checkIns:  checkIns(1, 1, "2026-04-06", "2026-04-07", "2026-04-08"),
+			completed: 2, expected: 3,
+		},
+		{
+			name:      "check-ins of the week before",
+			habit:     entities.HabitModel{ID: 1, Recurrence: "FREQ=DAILY"},
+			checkIns:  checkIns(1, 1, "2026-04-05", "2026-04-06"),
+			completed: 1, expected: 7,
+		},
+		{
+			name:      "monthly habit done in the week",
+			habit:     entities.HabitModel{ID: 1, Recurrence: "FREQ=MONTHLY", TargetCount: 2},
+			checkIns:  checkIns(1, 1, "2026-04-01", "2026-04-08", "2026-04-09", "2026-04-10"),
+			completed: 2, expected: 0,
+		},
+		{
+			name:      "paused habit",
+			habit:     entities.HabitModel{ID: 1, Recurrence: "FREQ=DAILY", Status: HabitStatusPaused},
+			checkIns:  checkIns(1, 1, "2026-04-06"),
+			completed: 1, expected: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.habit.CreatedAt = created
+			other := entities.HabitCheckInModel{HabitID: 2, Date: "2026-04-06"}
+			stats := WeeklyHabitStats([]entities.HabitModel{tt.habit}, append(tt.checkIns, other), weekStart, time.UTC)
+			if len(stats) != 1 {
+				t.Fatalf("stats = %+v, want one habit", stats)
+			}
+			if stats[0].Completed != tt.completed || stats[0].Expected != tt.expected {
+				t.Errorf("completed %d of %d, want %d of %d", stats[0].Completed, stats[0].Expected, tt.completed, tt.expected)
+			}
+			if tt.completed == tt.expected && tt.expected > 0 && stats[0].Rate != 1 {
+				t.Errorf("rate = %v, want 1", stats[0].Rate)
+			}
+		})
+	}
+}
+
+func TestReviewDueInUserTimezone(t *testing.T) {
+	config := WeeklyReviewConfig{Weekday: time.Monday, Hour: 6}
+	// 22:30 UTC on Sunday 12 April 2026 is 07:30 on Monday in Tokyo, 05:30 on
+	// Monday in Bangkok and Sunday evening in New York, which is still due for
+	// the week before.
+	instant := time.Date(2026, 4, 12, 22, 30, 0, 0, time.UTC)
+	tests := []struct {
+		timezone  string
+		due       bool
+		weekStart string
+	}{
+		{"Asia/Tokyo", true, "2026-04-06"},
+		{"Asia/Bangkok", false, "2026-04-06"},
+		{"America/New_York", true, "2026-03-30"},
+		{"Not/AZone", false, "2026-04-06"},
+	}
+	t.Setenv("DEFAULT_TIMEZONE", "Asia/Bangkok")
+	for _, tt := range tests {
+		now := instant.In(timezoneOrDefault(tt.timezone))
+		if got := reviewDue(now, config); got != tt.due {
+			t.Errorf("reviewDue(%s) = %v, want %v", tt.timezone, got, tt.due)
+		}
+		if got := ReviewWeekStart(now).Format(reviewDateLayout); got != tt.weekStart {
+			t.Errorf("ReviewWeekStart(%s) = %s, want %s", tt.timezone, got, tt.weekStart)
+		}
+	}
+}
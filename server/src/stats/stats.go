@@ -0,0 +1,187 @@
+// Package stats has the few statistics the insights need: means, Welch's
+// t-test for comparing two groups of days and Pearson's correlation, with
+// two-tailed p-values from Student's t distribution.
+package stats
+
+import (
+	"math"
+)
+
+// Mean is 0 for no values.
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total / float64(len(values))
+}
+
+// Variance is the sample variance, 0 for fewer than two values.
+func Variance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := Mean(values)
+	total := 0.0
+	for _, value := range values {
+		total += (value - mean) * (value - mean)
+	}
+	return total / float64(len(values)-1)
+}
+
+// Median is 0 for no values.
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j] < sorted[j-1]; j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
+
+// Comparison is the difference between the means of two groups, a minus b.
+type Comparison struct {
+	MeanA      float64
+	MeanB      float64
+	Difference float64
+	SizeA      int
+	SizeB      int
+	PValue     float64
+}
+
+// Welch compares the means of a and b without assuming equal variances. The
+// p-value is 1 when either group has fewer than two values or neither varies.
+func Welch(a []float64, b []float64) Comparison {
+	comparison := Comparison{
+		MeanA:  Mean(a),
+		MeanB:  Mean(b),
+		SizeA:  len(a),
+		SizeB:  len(b),
+		PValue: 1,
+	}
+	comparison.Difference = comparison.MeanA - comparison.MeanB
+	if len(a) < 2 || len(b) < 2 {
+		return comparison
+	}
+	va := Variance(a) / float64(len(a))
+	vb := Variance(b) / float64(len(b))
+	if va+vb == 0 {
+		if comparison.Difference != 0 {
+			comparison.PValue = 0
+		}
+		return comparison
+	}
+	t := comparison.Difference / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) / (va*va/float64(len(a)-1) + vb*vb/float64(len(b)-1))
+	comparison.PValue = StudentTwoTailed(t, df)
+	return comparison
+}
+
+// Pearson returns the correlation of x and y, which must have the same
+// length, and its p-value. Both are 0 and 1 when there are fewer than three
+// pairs or either series is constant.
+func Pearson(x []float64, y []float64) (float64, float64) {
+	n := len(x)
+	if n < 3 || len(y) != n {
+		return 0, 1
+	}
+	mx, my := Mean(x), Mean(y)
+	sxy, sxx, syy := 0.0, 0.0, 0.0
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, 1
+	}
+	r := sxy / math.Sqrt(sxx*syy)
+	if math.Abs(r) >= 1 {
+		return r, 0
+	}
+	t := r * math.Sqrt(float64(n-2)/(1-r*r))
+	return r, StudentTwoTailed(t, float64(n-2))
+}
+
+// StudentTwoTailed is the probability of a t statistic at least as extreme
+// as t with df degrees of freedom.
+func StudentTwoTailed(t float64, df float64) float64 {
+	if df <= 0 || math.IsNaN(t) {
+		return 1
+	}
+	return regularizedBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// regularizedBeta is I_x(a, b), evaluated with the continued fraction from
+// Numerical Recipes.
+func regularizedBeta(x float64, a float64, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lbeta, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	front := math.Exp(lbeta - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(x, a, b) / a
+	}
+	return 1 - front*betaFraction(1-x, b, a)/b
+}
+
+func betaFraction(x float64, a float64, b float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 3e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		numerator := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		numerator = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
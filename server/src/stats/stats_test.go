@@ -0,0 +1,128 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func near(got float64, want float64, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestStudentTwoTailed(t *testing.T) {
+	// Reference values from the closed forms for small df and from
+	// integrating the t density numerically.
+	tests := []struct {
+		t    float64
+		df   float64
+		want float64
+	}{
+		{2, 10, 0.0733880348},
+		{-2, 10, 0.0733880348},
+		{1, 1, 0.5},
+		{2, 2, 1 - 2/math.Sqrt(6)},
+		{math.Sqrt(6), 4, 0.0704839969},
+		{3, 5.5, 0.0267234807},
+		{0, 10, 1},
+		{1, 0, 1},
+		{math.NaN(), 10, 1},
+	}
+	for _, tt := range tests {
+		if got := StudentTwoTailed(tt.t, tt.df); !near(got, tt.want, 1e-8) {
+			t.Errorf("StudentTwoTailed(%v, %v) = %.10f, want %.10f", tt.t, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestRegularizedBeta(t *testing.T) {
+	tests := []struct {
+		x, a, b float64
+		want    float64
+	}{
+		{0.3, 1, 1, 0.3},
+		{0.3, 2, 1, 0.09},
+		{0.3, 1, 2, 1 - 0.49},
+		{0.5, 4, 4, 0.5},
+		{0.5, 0.5, 0.5, 0.5},
+		{0.9, 3, 1, 0.729},
+		{0, 2, 3, 0},
+		{1, 2, 3, 1},
+	}
+	for _, tt := range tests {
+		if got := regularizedBeta(tt.x, tt.a, tt.b); !near(got, tt.want, 1e-10) {
+			t.Errorf("regularizedBeta(%v, %v, %v) = %.12f, want %.12f", tt.x, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWelch(t *testing.T) {
+	// Equal sizes and variances give t = -sqrt(6) with exactly 4 degrees of
+	// freedom.
+	comparison := Welch([]float64{1, 2, 3}, []float64{3, 4, 5})
+	if comparison.MeanA != 2 || comparison.MeanB != 4 || comparison.Difference != -2 || comparison.SizeA != 3 || comparison.SizeB != 3 {
+		t.Errorf("Welch() = %+v, want means 2 and 4", comparison)
+	}
+	if !near(comparison.PValue, 0.0704839969, 1e-8) {
+		t.Errorf("Welch() p = %.10f, want 0.0704839969", comparison.PValue)
+	}
+
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{"one value", []float64{1}, []float64{3, 4, 5}, 1},
+		{"no variance, same mean", []float64{2, 2}, []float64{2, 2, 2}, 1},
+		{"no variance, different mean", []float64{2, 2}, []float64{3, 3}, 0},
+	}
+	for _, tt := range tests {
+		if got := Welch(tt.a, tt.b).PValue; got != tt.want {
+			t.Errorf("%s: Welch() p = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPearson(t *testing.T) {
+	tests := []struct {
+		name  string
+		x, y  []float64
+		wantR float64
+		wantP float64
+	}{
+		// r = 0.8 over five pairs is t = 2.3094 with 3 degrees of freedom.
+		{"correlated", []float64{1, 2, 3, 4, 5}, []float64{2, 1, 4, 3, 5}, 0.8, 0.1040880387},
+		{"anticorrelated", []float64{1, 2, 3, 4, 5}, []float64{4, 5, 2, 3, 1}, -0.8, 0.1040880387},
+		{"perfect", []float64{1, 2, 3}, []float64{2, 4, 6}, 1, 0},
+		{"two pairs", []float64{1, 2}, []float64{2, 1}, 0, 1},
+		{"constant", []float64{1, 2, 3}, []float64{5, 5, 5}, 0, 1},
+		{"different lengths", []float64{1, 2, 3}, []float64{1, 2}, 0, 1},
+	}
+	for _, tt := range tests {
+		r, p := Pearson(tt.x, tt.y)
+		if !near(r, tt.wantR, 1e-12) || !near(p, tt.wantP, 1e-8) {
+			t.Errorf("%s: Pearson() = %.10f, %.10f, want %v, %v", tt.name, r, p, tt.wantR, tt.wantP)
+		}
+	}
+}
+
+func TestMeanVarianceMedian(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+	if got := Mean(values); got != 2.5 {
+		t.Errorf("Mean() = %v, want 2.5", got)
+	}
+	if got := Variance(values); !near(got, 5.0/3, 1e-12) {
+		t.Errorf("Variance() = %v, want 5/3", got)
+	}
+	if got := Median(values); got != 2.5 {
+		t.Errorf("Median() = %v, want 2.5", got)
+	}
+	if got := Median([]float64{5, 1, 3}); got != 3 {
+		t.Errorf("Median() = %v, want 3", got)
+	}
+	if Mean(nil) != 0 || Variance([]float64{1}) != 0 || Median(nil) != 0 {
+		t.Error("empty input should give 0")
+	}
+	if values[0] != 4 {
+		t.Errorf("Median() sorted its input: %v", values)
+	}
+}